@@ -0,0 +1,472 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// RecordBuilder builds record batches from typed values without reflection,
+// for the code generated by arrow-gen and the readers of text formats.
+type RecordBuilder struct {
+	Schema  *schema.Schema
+	Columns []*ColumnBuilder
+}
+
+// Create a builder of the record batches of the schema, whose fields are supported by NewColumnBuilder.
+func NewRecordBuilder(s *schema.Schema) (*RecordBuilder, error) {
+	b := &RecordBuilder{Schema: s}
+
+	for _, field := range s.Fields {
+		c, err := NewColumnBuilder(field)
+
+		if err != nil {
+			return nil, err
+		}
+
+		b.Columns = append(b.Columns, c)
+	}
+
+	return b, nil
+}
+
+// Create a record batch of the appended values, the columns are reset to build the next one,
+// and the values are dropped on errors.
+func (b *RecordBuilder) NewRecordBatch() (*vector.RecordBatch, error) {
+	length := 0
+
+	if len(b.Columns) > 0 {
+		length = b.Columns[0].length
+	}
+
+	e := &encoder{batch: &vector.RecordBatch{Length: length}}
+
+	for _, c := range b.Columns {
+		err := c.error()
+
+		if err == nil && c.length != length {
+			err = fmt.Errorf("field %s has %d values, expected %d", c.field.Name, c.length, length)
+		}
+
+		if err != nil {
+			for _, c := range b.Columns {
+				c.reset()
+			}
+
+			return nil, err
+		}
+	}
+
+	for _, c := range b.Columns {
+		c.finish(e)
+	}
+
+	return e.batch, nil
+}
+
+// ColumnBuilder appends the values of a field, the Append methods must match its type.
+//
+// The values of nested fields are appended to the builders of their children: a list or map is appended
+// after its values, one value is appended to each child of a struct, and the value of a union
+// is appended to the child returned by AppendUnion.
+type ColumnBuilder struct {
+	field    *schema.Field
+	children []*ColumnBuilder
+
+	length    int
+	nullCount int
+	validity  []byte
+	offsets   []byte // nil for fixed width values
+	types     []byte // the type ids of unions
+	data      []byte
+	err       error // the first error of the appended values, reported by NewRecordBatch
+}
+
+// Create a builder of the values of a field which is not dictionary encoded.
+// The fields with children must be List, Map, Struct or Union, and their children are supported the same way.
+func NewColumnBuilder(field *schema.Field) (*ColumnBuilder, error) {
+	if field.Layout == nil {
+		return nil, fmt.Errorf("field %s has no layout", field.Name)
+	}
+
+	if field.Dictionary != nil {
+		return nil, fmt.Errorf("field %s of type %s is dictionary encoded", field.Name, field.Type)
+	}
+
+	c := &ColumnBuilder{field: field}
+
+	if len(field.Children) > 0 && field.Type != schema.List && field.Type != schema.Struct && !c.isUnion() && !c.isMap() {
+		return nil, fmt.Errorf("field %s of type %s is not supported by the builder", field.Name, field.Type)
+	}
+
+	for _, child := range field.Children {
+		b, err := NewColumnBuilder(child)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.children = append(c.children, b)
+	}
+
+	c.reset()
+
+	return c, nil
+}
+
+// Returns the builder of the i-th child of the field.
+func (c *ColumnBuilder) Child(i int) *ColumnBuilder { return c.children[i] }
+
+// Reset the column and its children for the next batch, reserving the capacity of the previous one.
+func (c *ColumnBuilder) reset() {
+	for _, child := range c.children {
+		child.reset()
+	}
+
+	c.length, c.nullCount, c.err = 0, 0, nil
+	c.validity = make([]byte, 0, cap(c.validity))
+	c.types = make([]byte, 0, cap(c.types))
+	c.data = make([]byte, 0, cap(c.data))
+
+	for _, layout := range c.field.Layout.Vectors {
+		if layout.Type == vector.Offset {
+			if c.isUnion() {
+				c.offsets = make([]byte, 0, cap(c.offsets))
+			} else {
+				c.offsets = make([]byte, 4, cap(c.offsets)+4)
+			}
+		}
+	}
+}
+
+func (c *ColumnBuilder) isMap() bool {
+	_, ok := c.field.Type.(*schema.Map)
+
+	return ok
+}
+
+func (c *ColumnBuilder) isUnion() bool {
+	_, ok := c.field.Type.(*schema.Union)
+
+	return ok
+}
+
+// Returns the first error of the appended values of the column or its children.
+func (c *ColumnBuilder) error() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	for _, child := range c.children {
+		if c.field.Type == schema.Struct && child.length != c.length {
+			return fmt.Errorf("field %s.%s has %d values, expected %d", c.field.Name, child.field.Name, child.length, c.length)
+		}
+
+		if err := child.error(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Append the buffers of the column and its children to the batch, and reset them.
+func (c *ColumnBuilder) finish(e *encoder) {
+	e.addNode(c.length, c.nullCount)
+
+	for _, layout := range c.field.Layout.Vectors {
+		switch layout.Type {
+		case vector.Validity:
+			if c.nullCount > 0 {
+				e.addBuffer(c.validity)
+			} else {
+				e.addBuffer(nil)
+			}
+
+		case vector.Offset:
+			e.addBuffer(c.offsets)
+
+		case vector.Type:
+			e.addBuffer(c.types)
+
+		default:
+			e.addBuffer(c.data)
+		}
+	}
+
+	for _, child := range c.children {
+		child.finish(e)
+	}
+
+	c.reset()
+}
+
+// Start the next value, and mark it valid or null.
+func (c *ColumnBuilder) next(valid bool) {
+	if c.length%8 == 0 {
+		c.validity = append(c.validity, 0)
+	}
+
+	if valid {
+		c.validity[c.length>>3] |= 1 << uint(c.length&7)
+	} else {
+		c.nullCount++
+	}
+
+	c.length++
+}
+
+func (c *ColumnBuilder) appendFixed(bits uint64, size int) {
+	var buf [8]byte
+
+	binary.LittleEndian.PutUint64(buf[:], bits)
+
+	c.data = append(c.data, buf[:size]...)
+}
+
+// Append a null, as zeros or an empty value. The children of a struct are appended nulls,
+// or empty values if they are not nullable, and the null of a union is appended to its first child.
+func (c *ColumnBuilder) AppendNull() {
+	if c.isUnion() {
+		c.AppendUnion(0).AppendNull()
+
+		return
+	}
+
+	c.next(false)
+	c.appendEmpty()
+}
+
+// Append a valid empty value, as zeros or an empty value, of a non-nullable child of a null struct.
+func (c *ColumnBuilder) appendValidEmpty() {
+	if c.isUnion() {
+		c.AppendUnion(0).appendValidEmpty()
+
+		return
+	}
+
+	c.next(true)
+	c.appendEmpty()
+}
+
+// Append the data of an empty value, after its validity.
+func (c *ColumnBuilder) appendEmpty() {
+	switch {
+	case c.offsets != nil:
+		c.offsets = append(c.offsets, c.offsets[len(c.offsets)-4:]...)
+
+	case c.field.Type == schema.Bool:
+		if (c.length-1)%8 == 0 {
+			c.data = append(c.data, 0)
+		}
+
+	case c.field.Type == schema.Struct:
+		for _, child := range c.children {
+			if child.field.Nullable {
+				child.AppendNull()
+			} else {
+				child.appendValidEmpty()
+			}
+		}
+
+	default:
+		for _, layout := range c.field.Layout.Vectors {
+			if layout.Type == vector.Data {
+				c.data = append(c.data, make([]byte, layout.BitWidth/8)...)
+			}
+		}
+	}
+}
+
+// Append a list of the values appended to the child since the previous list of a List field,
+// or a map of the entries appended to the child of a Map field.
+func (c *ColumnBuilder) AppendList() {
+	c.next(true)
+
+	n := c.children[0].length
+
+	if n > math.MaxInt32 && c.err == nil {
+		c.err = fmt.Errorf("field %s has more than %d values", c.field.Name, math.MaxInt32)
+	}
+
+	c.appendInt32(&c.offsets, n)
+}
+
+// Append a valid struct of a Struct field, a value must be appended to each child.
+func (c *ColumnBuilder) AppendStruct() { c.next(true) }
+
+// Append a value of the i-th child of a Union field, and returns the builder of the child,
+// to which the value must be appended. The other children of sparse unions are appended nulls.
+func (c *ColumnBuilder) AppendUnion(i int) *ColumnBuilder {
+	union := c.field.Type.(*schema.Union)
+	id := i
+
+	if len(union.TypeIDs) > 0 {
+		id = union.TypeIDs[i]
+	}
+
+	c.types = append(c.types, byte(id))
+	c.length++
+
+	if union.Mode == schema.Dense {
+		c.appendInt32(&c.offsets, c.children[i].length)
+	} else {
+		for j, child := range c.children {
+			if j != i {
+				child.AppendNull()
+			}
+		}
+	}
+
+	return c.children[i]
+}
+
+func (c *ColumnBuilder) appendInt32(buf *[]byte, v int) {
+	var b [4]byte
+
+	binary.LittleEndian.PutUint32(b[:], uint32(v))
+
+	*buf = append(*buf, b[:]...)
+}
+
+func (c *ColumnBuilder) AppendBool(v bool) {
+	c.next(true)
+
+	if (c.length-1)%8 == 0 {
+		c.data = append(c.data, 0)
+	}
+
+	if v {
+		c.data[(c.length-1)>>3] |= 1 << uint((c.length-1)&7)
+	}
+}
+
+func (c *ColumnBuilder) AppendInt8(v int8)   { c.next(true); c.appendFixed(uint64(v), 1) }
+func (c *ColumnBuilder) AppendInt16(v int16) { c.next(true); c.appendFixed(uint64(v), 2) }
+func (c *ColumnBuilder) AppendInt32(v int32) { c.next(true); c.appendFixed(uint64(v), 4) }
+func (c *ColumnBuilder) AppendInt64(v int64) { c.next(true); c.appendFixed(uint64(v), 8) }
+
+func (c *ColumnBuilder) AppendUint8(v uint8)   { c.next(true); c.appendFixed(uint64(v), 1) }
+func (c *ColumnBuilder) AppendUint16(v uint16) { c.next(true); c.appendFixed(uint64(v), 2) }
+func (c *ColumnBuilder) AppendUint32(v uint32) { c.next(true); c.appendFixed(uint64(v), 4) }
+func (c *ColumnBuilder) AppendUint64(v uint64) { c.next(true); c.appendFixed(v, 8) }
+
+func (c *ColumnBuilder) AppendFloat32(v float32) {
+	c.next(true)
+	c.appendFixed(uint64(math.Float32bits(v)), 4)
+}
+
+func (c *ColumnBuilder) AppendFloat64(v float64) {
+	c.next(true)
+	c.appendFixed(math.Float64bits(v), 8)
+}
+
+// Append a string of a Utf8 field.
+func (c *ColumnBuilder) AppendString(v string) {
+	c.next(true)
+
+	c.data = append(c.data, v...)
+
+	c.appendOffset()
+}
+
+// Append the bytes of a Binary field, or of a FixedSizeBinary field of the same width.
+func (c *ColumnBuilder) AppendBytes(v []byte) {
+	c.next(true)
+
+	c.data = append(c.data, v...)
+
+	if c.offsets != nil {
+		c.appendOffset()
+	}
+}
+
+func (c *ColumnBuilder) appendOffset() {
+	if len(c.data) > math.MaxInt32 && c.err == nil {
+		c.err = fmt.Errorf("field %s has more than %d bytes", c.field.Name, math.MaxInt32)
+	}
+
+	c.appendInt32(&c.offsets, len(c.data))
+}
+
+// Append a time of a Timestamp field, in its unit.
+func (c *ColumnBuilder) AppendTime(v time.Time) {
+	unit := c.field.Type.(*schema.Timestamp).Unit
+
+	if !inUnitRange(v, unit) && c.err == nil {
+		c.err = fmt.Errorf("field %s has time %s at %d out of the range of %s", c.field.Name, v, c.length, c.field.Type)
+	}
+
+	c.AppendInt64(toUnit(v, unit))
+}
+
+// Append a duration of a Duration field, in its unit.
+func (c *ColumnBuilder) AppendDuration(v time.Duration) {
+	c.AppendInt64(int64(v) / columns.UnitNanos(c.field.Type.(*schema.Duration).Unit))
+}
+
+// Append a date of a Date field, the days of the time in its location.
+func (c *ColumnBuilder) AppendDate(v time.Time) {
+	y, m, d := v.Date()
+	days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+
+	if c.field.Type.(*schema.DateType).Unit == schema.DateDay {
+		if (days < math.MinInt32 || days > math.MaxInt32) && c.err == nil {
+			c.err = fmt.Errorf("field %s has date %s at %d out of the range of %s", c.field.Name, v, c.length, c.field.Type)
+		}
+
+		c.AppendInt32(int32(days))
+	} else {
+		c.AppendInt64(days * 86400000)
+	}
+}
+
+// Append a time since midnight of a Time field, in its unit.
+func (c *ColumnBuilder) AppendTimeOfDay(v time.Duration) {
+	tp := c.field.Type.(*schema.TimeType)
+
+	if (v < 0 || v >= 24*time.Hour) && c.err == nil {
+		c.err = fmt.Errorf("field %s has time of day %s at %d out of a day", c.field.Name, v, c.length)
+	}
+
+	if n := int64(v) / columns.UnitNanos(tp.Unit); tp.BitWidth == 32 {
+		c.AppendInt32(int32(n))
+	} else {
+		c.AppendInt64(n)
+	}
+}
+
+// Append the unscaled value of a Decimal field, which must have at most the digits of its precision.
+func (c *ColumnBuilder) AppendDecimal(v *big.Int) {
+	tp := c.field.Type.(*schema.Decimal)
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(tp.Precision)), nil)
+
+	if v.CmpAbs(limit) >= 0 && c.err == nil {
+		c.err = fmt.Errorf("field %s has decimal %s at %d out of the precision of %s", c.field.Name, v, c.length, c.field.Type)
+	}
+
+	c.next(true)
+
+	// the two's complement in little endian
+	size := tp.BitWidth / 8
+	bits := v
+
+	if v.Sign() < 0 {
+		bits = new(big.Int).Add(v, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+
+	be := bits.Bytes()
+	le := make([]byte, size)
+
+	for i := 0; i < len(be) && i < size; i++ {
+		le[i] = be[len(be)-1-i]
+	}
+
+	c.data = append(c.data, le...)
+}
@@ -0,0 +1,108 @@
+// Command arrow-gen generates the marshalers of Go struct types to and from record batches,
+// without reflection, for the types annotated with a go:generate directive in their package.
+//
+//	//go:generate arrow-gen -type Row,Event
+//
+// For each type T it writes to t_arrow.go, after the first type, the functions
+//
+//	func TSchema() *schema.Schema
+//	func NewTBuilder() (*arrow.RecordBuilder, error)
+//	func AppendT(b *arrow.RecordBuilder, values []T)
+//	func ReadT(batch *vector.RecordBatch) ([]T, error)
+//
+// The supported field types are described by the package github.com/flier/arrow/gen/record.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flier/arrow/gen/record"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "the comma separated names of the struct types")
+	output := flag.String("output", "", "the output file, t_arrow.go in the package directory by default")
+
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	types := strings.Split(*typeNames, ",")
+
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(types[0])+"_arrow.go")
+	}
+
+	if err := generate(dir, types, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(dir string, types []string, output string) error {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return filepath.Join(dir, fi.Name()) != filepath.Clean(output)
+	}, parser.ParseComments)
+
+	if err != nil {
+		return fmt.Errorf("fail to parse %s, %s", dir, err)
+	}
+
+	pkg := findPackage(pkgs, types[0])
+
+	if pkg == nil {
+		return fmt.Errorf("type %s not found in %s", types[0], dir)
+	}
+
+	var files []*ast.File
+	var names []string
+
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		files = append(files, pkg.Files[name])
+	}
+
+	var buf bytes.Buffer
+
+	if err := record.Generate(&buf, pkg.Name, files, types); err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, buf.Bytes(), 0644)
+}
+
+// Returns the package declaring the type, the test package of the directory is considered as well.
+func findPackage(pkgs map[string]*ast.Package, typeName string) *ast.Package {
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			if f.Scope.Lookup(typeName) != nil {
+				return pkg
+			}
+		}
+	}
+
+	return nil
+}
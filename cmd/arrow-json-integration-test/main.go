@@ -0,0 +1,210 @@
+// Command arrow-json-integration-test converts between Arrow files and the JSON format
+// of the Arrow integration tests, or checks that both describe the same data.
+//
+//	arrow-json-integration-test -mode JSON_TO_ARROW -json in.json -arrow out.arrow
+//	arrow-json-integration-test -mode ARROW_TO_JSON -arrow in.arrow -json out.json
+//	arrow-json-integration-test -mode VALIDATE -json golden.json -arrow out.arrow
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/flier/arrow/file"
+	"github.com/flier/arrow/integration"
+)
+
+func main() {
+	arrowPath := flag.String("arrow", "", "the Arrow file")
+	jsonPath := flag.String("json", "", "the integration JSON file")
+	mode := flag.String("mode", "VALIDATE", "JSON_TO_ARROW, ARROW_TO_JSON or VALIDATE")
+	verbose := flag.Bool("verbose", false, "print what is done")
+
+	flag.Parse()
+
+	if *arrowPath == "" || *jsonPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch *mode {
+	case "JSON_TO_ARROW":
+		err = jsonToArrow(*jsonPath, *arrowPath)
+	case "ARROW_TO_JSON":
+		err = arrowToJSON(*arrowPath, *jsonPath)
+	case "VALIDATE":
+		err = validate(*jsonPath, *arrowPath)
+	default:
+		err = fmt.Errorf("unknown mode %s", *mode)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *verbose {
+		fmt.Printf("%s %s and %s done\n", *mode, *jsonPath, *arrowPath)
+	}
+}
+
+func jsonToArrow(jsonPath, arrowPath string) error {
+	f, err := readJSON(jsonPath)
+
+	if err != nil {
+		return err
+	}
+
+	return writeArrow(arrowPath, f)
+}
+
+func arrowToJSON(arrowPath, jsonPath string) error {
+	f, err := readArrow(arrowPath)
+
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(jsonPath)
+
+	if err != nil {
+		return err
+	}
+
+	if err := integration.Write(out, f); err != nil {
+		out.Close()
+
+		return fmt.Errorf("fail to write %s, %s", jsonPath, err)
+	}
+
+	return out.Close()
+}
+
+func validate(jsonPath, arrowPath string) error {
+	expected, err := readJSON(jsonPath)
+
+	if err != nil {
+		return err
+	}
+
+	actual, err := readArrow(arrowPath)
+
+	if err != nil {
+		return err
+	}
+
+	if err := integration.Compare(expected, actual); err != nil {
+		return fmt.Errorf("%s and %s differ, %s", jsonPath, arrowPath, err)
+	}
+
+	return nil
+}
+
+func readJSON(path string) (*integration.File, error) {
+	in, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer in.Close()
+
+	f, err := integration.Read(in)
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s, %s", path, err)
+	}
+
+	return f, nil
+}
+
+func readArrow(path string) (*integration.File, error) {
+	in, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer in.Close()
+
+	info, err := in.Stat()
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := file.NewReader(in, info.Size())
+	r.Validate = true
+
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to read %s, %s", path, err)
+	}
+
+	dictionaries, err := r.ReadDictionaries()
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to read dictionaries of %s, %s", path, err)
+	}
+
+	f := &integration.File{Schema: footer.Schema, Dictionaries: dictionaries}
+
+	for _, block := range footer.RecordBatches {
+		batch, err := r.ReadRecordBatch(block)
+
+		if err != nil {
+			return nil, fmt.Errorf("fail to read %s, %s", path, err)
+		}
+
+		f.Batches = append(f.Batches, batch)
+	}
+
+	return f, nil
+}
+
+func writeArrow(path string, f *integration.File) error {
+	out, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	w := file.NewWriter(out, f.Schema)
+
+	if err := writeBatches(w, f); err != nil {
+		out.Close()
+
+		return fmt.Errorf("fail to write %s, %s", path, err)
+	}
+
+	return out.Close()
+}
+
+func writeBatches(w *file.Writer, f *integration.File) error {
+	var ids []int64
+
+	for id := range f.Dictionaries {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if err := w.WriteDictionaryBatch(id, f.Dictionaries[id]); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range f.Batches {
+		if err := w.WriteRecordBatch(batch); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
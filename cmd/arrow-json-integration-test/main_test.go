@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob("../../integration/testdata/*.json")
+
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no fixtures, %v", err)
+	}
+
+	dir := t.TempDir()
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		arrowPath := filepath.Join(dir, name+".arrow")
+		jsonPath := filepath.Join(dir, name)
+
+		if err := jsonToArrow(path, arrowPath); err != nil {
+			t.Fatalf("JSON_TO_ARROW %s, %s", name, err)
+		}
+
+		if err := validate(path, arrowPath); err != nil {
+			t.Errorf("VALIDATE %s, %s", name, err)
+		}
+
+		if err := arrowToJSON(arrowPath, jsonPath); err != nil {
+			t.Fatalf("ARROW_TO_JSON %s, %s", name, err)
+		}
+
+		expected, err := os.ReadFile(path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := os.ReadFile(jsonPath)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("%s changed by a round trip through an Arrow file\n%s", name, actual)
+		}
+	}
+}
+
+func TestValidateMismatch(t *testing.T) {
+	dir := t.TempDir()
+	arrowPath := filepath.Join(dir, "nested.arrow")
+
+	if err := jsonToArrow("../../integration/testdata/nested.json", arrowPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validate("../../integration/testdata/primitive.json", arrowPath); err == nil {
+		t.Error("validate the Arrow file of another JSON file")
+	}
+
+	buf, err := os.ReadFile(arrowPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(arrowPath, buf[:len(buf)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validate("../../integration/testdata/nested.json", arrowPath); err == nil {
+		t.Error("validate a truncated Arrow file")
+	}
+}
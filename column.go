@@ -0,0 +1,15 @@
+package arrow
+
+import (
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Column reads the values of a field of a record batch without reflection, for the code generated by arrow-gen.
+type Column = columns.Column
+
+// Returns the columns of the fields of the schema in the record batch, which is validated against it.
+func NewColumns(s *schema.Schema, batch *vector.RecordBatch) ([]*Column, error) {
+	return columns.New(s, batch)
+}
@@ -0,0 +1,84 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+)
+
+func TestNestedColumns(t *testing.T) {
+	s, err := schema.Parse("struct<id: int32, items: list<item: struct<name: utf8, value: sparse_union<n: int64, s: utf8>>>>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewRecordBuilder(s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, items := b.Columns[0], b.Columns[1]
+	item := items.Child(0)
+
+	id.AppendInt32(1)
+	item.AppendStruct()
+	item.Child(0).AppendString("a")
+	item.Child(1).AppendUnion(0).AppendInt64(7)
+	item.AppendStruct()
+	item.Child(0).AppendNull()
+	item.Child(1).AppendUnion(1).AppendString("x")
+	items.AppendList()
+
+	id.AppendNull()
+	items.AppendNull()
+
+	id.AppendInt32(3)
+	item.AppendNull()
+	items.AppendList()
+
+	batch, err := b.NewRecordBatch()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := NewColumns(s, batch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`{"id":1,"items":[{"name":"a","value":7},{"value":"x"}]}`,
+		`{}`,
+		`{"id":3,"items":[null]}`,
+	}
+
+	var buf bytes.Buffer
+
+	for i, row := range expected {
+		buf.Reset()
+
+		columns.AppendJSONRow(&buf, cols, i, time.RFC3339, true)
+
+		if buf.String() != row {
+			t.Errorf("row %d is %s, expected %s", i, buf.String(), row)
+		}
+	}
+
+	if s := cols[1].Format(0, time.RFC3339); s != `[{"name":"a","value":7},{"name":null,"value":"x"}]` {
+		t.Errorf("items formatted as %s", s)
+	}
+
+	item.AppendStruct()
+	items.AppendList()
+
+	if _, err := b.NewRecordBatch(); err == nil {
+		t.Errorf("expected an error for the struct without values")
+	}
+}
@@ -0,0 +1,495 @@
+// Package csv reads and writes CSV as record batches.
+//
+// The columns are of the primitive types Int, FloatingPoint in single or double precision,
+// Bool, Utf8, Binary and Timestamp.
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const (
+	DefaultBatchSize = 1024
+	DefaultInferRows = 100
+)
+
+var (
+	// The values read as nulls by default.
+	DefaultNullValues = []string{"", "NULL", "null"}
+
+	// The formats of timestamps tried by default, a fractional second is accepted after the seconds.
+	DefaultTimestampFormats = []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+)
+
+// ReadOptions controls how the CSV is read, the zero value reads a header and infers the schema.
+type ReadOptions struct {
+	Schema    *schema.Schema // the schema of the columns, inferred from the first rows if nil
+	NoHeader  bool           // the first row holds values instead of the names of the columns
+	BatchSize int            // the number of rows of each batch, DefaultBatchSize if zero
+	InferRows int            // the number of rows the schema is inferred from, DefaultInferRows if zero
+
+	NullValues       []string // the values read as nulls of nullable fields, DefaultNullValues if nil
+	TimestampFormats []string // the formats of timestamps, DefaultTimestampFormats if nil
+
+	Comma            rune // the field delimiter, ',' if zero
+	Comment          rune // the lines beginning with it are ignored, if not zero
+	LazyQuotes       bool // a quote may appear in an unquoted field, and a non-doubled quote in a quoted field
+	TrimLeadingSpace bool // the leading white space of the fields is ignored
+}
+
+// ParseError is the error of a value which can't be parsed as the type of its column.
+type ParseError struct {
+	Row    int // the line of the record in the input, from 1
+	Column string
+	Value  string
+	Type   schema.Type
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("row %d column %s: fail to parse %q as %s, %s", e.Row, e.Column, e.Value, e.Type, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Reader reads the CSV as record batches of the Schema.
+type Reader struct {
+	Schema *schema.Schema
+
+	opts    ReadOptions
+	csv     *csv.Reader
+	builder *arrow.RecordBuilder
+	nulls   map[string]bool
+
+	pending [][]string // the rows read to infer the schema, and their lines
+	lines   []int
+	err     error
+}
+
+// Create a reader of the CSV, reading the header and the rows to infer the schema.
+func NewReader(r io.Reader, opts ReadOptions) (*Reader, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	if opts.InferRows <= 0 {
+		opts.InferRows = DefaultInferRows
+	}
+
+	if opts.NullValues == nil {
+		opts.NullValues = DefaultNullValues
+	}
+
+	if opts.TimestampFormats == nil {
+		opts.TimestampFormats = DefaultTimestampFormats
+	}
+
+	cr := csv.NewReader(r)
+
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+
+	cr.Comment = opts.Comment
+	cr.LazyQuotes = opts.LazyQuotes
+	cr.TrimLeadingSpace = opts.TrimLeadingSpace
+
+	reader := &Reader{opts: opts, csv: cr, nulls: make(map[string]bool)}
+
+	for _, null := range opts.NullValues {
+		reader.nulls[null] = true
+	}
+
+	var names []string
+
+	if !opts.NoHeader {
+		header, err := cr.Read()
+
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("fail to read CSV header, %s", err)
+		}
+
+		names = header
+	}
+
+	if opts.Schema != nil {
+		if err := checkSchema(opts.Schema, names); err != nil {
+			return nil, err
+		}
+
+		reader.Schema = opts.Schema
+	} else {
+		s, err := reader.infer(names)
+
+		if err != nil {
+			return nil, err
+		}
+
+		reader.Schema = s
+	}
+
+	builder, err := arrow.NewRecordBuilder(reader.Schema)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader.builder = builder
+
+	return reader, nil
+}
+
+// Check the fields of the schema are supported, and are named by the header if any.
+func checkSchema(s *schema.Schema, names []string) error {
+	if names != nil && len(names) != len(s.Fields) {
+		return fmt.Errorf("CSV header has %d columns, the schema has %d fields", len(names), len(s.Fields))
+	}
+
+	for i, field := range s.Fields {
+		if !supported(field) {
+			return fmt.Errorf("field %s of type %s is not supported by CSV", field.Name, field.Type)
+		}
+
+		if names != nil && names[i] != field.Name {
+			return fmt.Errorf("CSV column %d is named %s, expected field %s", i, names[i], field.Name)
+		}
+	}
+
+	return nil
+}
+
+func supported(field *schema.Field) bool {
+	if field.Dictionary != nil || len(field.Children) > 0 {
+		return false
+	}
+
+	switch tp := field.Type.(type) {
+	case *schema.Int, *schema.Timestamp:
+		return true
+
+	case *schema.FloatingPoint:
+		return tp.Precision == schema.Single || tp.Precision == schema.Double
+	}
+
+	return field.Type == schema.Bool || field.Type == schema.Utf8 || field.Type == schema.Binary
+}
+
+// The types tried by the inference, in the order they are preferred.
+const (
+	inferInt = 1 << iota
+	inferFloat
+	inferBool
+	inferTimestamp
+	inferAll = inferInt | inferFloat | inferBool | inferTimestamp
+)
+
+// Infer the schema from the first rows, which are kept to be read by Read.
+//
+// The columns are nullable Int of 64 bits, FloatingPoint in double precision, Bool or
+// Timestamp in microseconds if all their values are parsed as the type, and Utf8 otherwise.
+func (r *Reader) infer(names []string) (*schema.Schema, error) {
+	for len(r.pending) < r.opts.InferRows {
+		record, line, err := r.read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("fail to read CSV, %s", err)
+		}
+
+		r.pending = append(r.pending, record)
+		r.lines = append(r.lines, line)
+	}
+
+	columns := len(names)
+
+	if names == nil && len(r.pending) > 0 {
+		columns = len(r.pending[0])
+	}
+
+	s := &schema.Schema{}
+
+	for i := 0; i < columns; i++ {
+		candidates, seen := inferAll, false
+
+		for _, record := range r.pending {
+			if value := record[i]; !r.nulls[value] {
+				candidates &= r.inferValue(value)
+				seen = true
+			}
+		}
+
+		var tp schema.Type = schema.Utf8
+
+		switch {
+		case !seen: // only nulls
+		case candidates&inferInt != 0:
+			tp = schema.NewInt(64, true)
+		case candidates&inferFloat != 0:
+			tp = schema.NewFloatingPoint(schema.Double)
+		case candidates&inferBool != 0:
+			tp = schema.Bool
+		case candidates&inferTimestamp != 0:
+			tp = schema.NewTimeStamp(schema.Microsecond)
+		}
+
+		name := fmt.Sprintf("f%d", i)
+
+		if names != nil {
+			name = names[i]
+		}
+
+		field, err := schema.NewField(name, true, tp)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Fields = append(s.Fields, field)
+	}
+
+	return s, nil
+}
+
+// Returns the types the value is parsed as.
+func (r *Reader) inferValue(value string) int {
+	var types int
+
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		types |= inferInt
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		types |= inferFloat
+	}
+
+	if _, err := strconv.ParseBool(value); err == nil {
+		types |= inferBool
+	}
+
+	if _, err := r.parseTime(value); err == nil {
+		types |= inferTimestamp
+	}
+
+	return types
+}
+
+// Returns the next record and its line, the rows read to infer the schema first.
+func (r *Reader) next() ([]string, int, error) {
+	if len(r.pending) > 0 {
+		record, line := r.pending[0], r.lines[0]
+		r.pending, r.lines = r.pending[1:], r.lines[1:]
+
+		return record, line, nil
+	}
+
+	return r.read()
+}
+
+// Returns the next record of the CSV and its line.
+func (r *Reader) read() ([]string, int, error) {
+	record, err := r.csv.Read()
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+
+	return record, line, nil
+}
+
+// Read the next batch of at most BatchSize rows, returns io.EOF after the last one.
+// The reader fails with the first error.
+func (r *Reader) Read() (*vector.RecordBatch, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	rows := 0
+
+	for ; rows < r.opts.BatchSize; rows++ {
+		record, line, err := r.next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err == nil && len(record) != len(r.Schema.Fields) {
+			err = fmt.Errorf("record on line %d has %d fields, the schema has %d", line, len(record), len(r.Schema.Fields))
+		}
+
+		if err != nil {
+			r.err = fmt.Errorf("fail to read CSV, %s", err)
+
+			return nil, r.err
+		}
+
+		if err := r.appendRecord(record, line); err != nil {
+			r.err = err
+
+			return nil, err
+		}
+	}
+
+	if rows == 0 {
+		r.err = io.EOF
+
+		return nil, io.EOF
+	}
+
+	batch, err := r.builder.NewRecordBatch()
+
+	if err != nil {
+		r.err = err
+
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *Reader) appendRecord(record []string, line int) error {
+	for i, field := range r.Schema.Fields {
+		value := record[i]
+		c := r.builder.Columns[i]
+
+		if field.Nullable && r.nulls[value] {
+			c.AppendNull()
+
+			continue
+		}
+
+		if err := r.appendValue(c, field, value); err != nil {
+			if ne, ok := err.(*strconv.NumError); ok {
+				err = ne.Err
+			}
+
+			return &ParseError{Row: line, Column: field.Name, Value: value, Type: field.Type, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (r *Reader) appendValue(c *arrow.ColumnBuilder, field *schema.Field, value string) error {
+	switch tp := field.Type.(type) {
+	case *schema.Int:
+		if tp.Signed {
+			v, err := strconv.ParseInt(value, 10, tp.BitWidth)
+
+			if err != nil {
+				return err
+			}
+
+			switch tp.BitWidth {
+			case 8:
+				c.AppendInt8(int8(v))
+			case 16:
+				c.AppendInt16(int16(v))
+			case 32:
+				c.AppendInt32(int32(v))
+			default:
+				c.AppendInt64(v)
+			}
+		} else {
+			v, err := strconv.ParseUint(value, 10, tp.BitWidth)
+
+			if err != nil {
+				return err
+			}
+
+			switch tp.BitWidth {
+			case 8:
+				c.AppendUint8(uint8(v))
+			case 16:
+				c.AppendUint16(uint16(v))
+			case 32:
+				c.AppendUint32(uint32(v))
+			default:
+				c.AppendUint64(v)
+			}
+		}
+
+	case *schema.FloatingPoint:
+		if tp.Precision == schema.Single {
+			v, err := strconv.ParseFloat(value, 32)
+
+			if err != nil {
+				return err
+			}
+
+			c.AppendFloat32(float32(v))
+		} else {
+			v, err := strconv.ParseFloat(value, 64)
+
+			if err != nil {
+				return err
+			}
+
+			c.AppendFloat64(v)
+		}
+
+	case *schema.Timestamp:
+		t, err := r.parseTime(value)
+
+		if err != nil {
+			return err
+		}
+
+		c.AppendTime(t)
+
+	default:
+		switch field.Type {
+		case schema.Bool:
+			v, err := strconv.ParseBool(value)
+
+			if err != nil {
+				return err
+			}
+
+			c.AppendBool(v)
+
+		case schema.Utf8:
+			if !utf8.ValidString(value) {
+				return errors.New("invalid UTF-8")
+			}
+
+			c.AppendString(value)
+
+		case schema.Binary:
+			c.AppendBytes([]byte(value))
+		}
+	}
+
+	return nil
+}
+
+// Parse the time in the first of the formats it matches, times without a zone are in UTC.
+func (r *Reader) parseTime(value string) (time.Time, error) {
+	for _, format := range r.opts.TimestampFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("unknown timestamp format")
+}
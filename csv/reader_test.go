@@ -0,0 +1,166 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow/schema"
+)
+
+func TestReadInfer(t *testing.T) {
+	text := `id,score,ok,at,name,empty
+1,1.5,true,2024-01-02T03:04:05Z,alice,
+2,2,false,2024-01-02 03:04:05.5,"bob, jr",
+NULL,,,2024-01-02,"say ""hi""",
+4,-1e3,t,,x,null
+`
+
+	r, err := NewReader(strings.NewReader(text), ReadOptions{BatchSize: 3})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "struct<id: int64, score: float64, ok: bool, at: timestamp[us], name: utf8, empty: utf8>"
+
+	if r.Schema.String() != expected {
+		t.Fatalf("inferred %s, expected %s", r.Schema, expected)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Schema.Validate(batch); err != nil {
+		t.Fatalf("invalid batch, %s", err)
+	}
+
+	if batch.Length != 3 || batch.Nodes[0].NullCount != 1 || batch.Nodes[5].NullCount != 3 {
+		t.Errorf("batch of %d rows and nodes %v, expected 3 rows", batch.Length, batch.Nodes)
+	}
+
+	if id := batch.Buffers[1].BigInt(1); id != 2 {
+		t.Errorf("id %d at 1, expected 2", id)
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC)
+
+	if v := batch.Buffers[7].BigInt(1); v != at.UnixNano()/1000 {
+		t.Errorf("at %d at 1, expected %d", v, at.UnixNano()/1000)
+	}
+
+	names, offsets := string(batch.Buffers[10].Bytes()), batch.Buffers[9]
+
+	if name := names[offsets.Int(2):offsets.Int(3)]; name != `say "hi"` {
+		t.Errorf("name %q at 2, expected quoted value", name)
+	}
+
+	batch, err = r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if batch.Length != 1 {
+		t.Errorf("batch of %d rows, expected 1", batch.Length)
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("got error %v, expected EOF", err)
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	s, err := schema.Parse("struct<code: uint8 not null, ratio: float32, label: binary>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "7;0.25;a\n# comment\n255; ;b\"c\n"
+
+	r, err := NewReader(strings.NewReader(text), ReadOptions{
+		Schema:     s,
+		NoHeader:   true,
+		Comma:      ';',
+		Comment:    '#',
+		LazyQuotes: true,
+		NullValues: []string{" "},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Validate(batch); err != nil {
+		t.Fatalf("invalid batch, %s", err)
+	}
+
+	if code := batch.Buffers[1].UInt1(1); code != 255 {
+		t.Errorf("code %d at 1, expected 255", code)
+	}
+
+	if ratio := batch.Buffers[3].Float4(0); ratio != 0.25 {
+		t.Errorf("ratio %f at 0, expected 0.25", ratio)
+	}
+
+	if batch.Nodes[1].NullCount != 1 {
+		t.Errorf("ratio has %d nulls, expected 1", batch.Nodes[1].NullCount)
+	}
+
+	if label := string(batch.Buffers[6].Bytes()); label != `ab"c` {
+		t.Errorf("labels %q, expected lazy quotes", label)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	s, err := schema.Parse("struct<id: int8 not null, name: utf8>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(strings.NewReader("id,name\n1,a\n\n300,b\n"), ReadOptions{Schema: s})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.Read()
+
+	var pe *ParseError
+
+	if !errors.As(err, &pe) || pe.Row != 4 || pe.Column != "id" || !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("got error %v, expected out of range id at row 4", err)
+	}
+
+	if _, again := r.Read(); again != err {
+		t.Errorf("got error %v after %v", again, err)
+	}
+
+	if _, err := NewReader(strings.NewReader("id,title\n"), ReadOptions{Schema: s}); err == nil || !strings.Contains(err.Error(), "CSV column 1 is named title") {
+		t.Errorf("got error %v, expected column title mismatch", err)
+	}
+
+	r, err = NewReader(strings.NewReader("id,name\n1,a\n2\n"), ReadOptions{InferRows: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Read(); err == nil || !strings.Contains(err.Error(), "wrong number of fields") {
+		t.Errorf("got error %v, expected wrong number of fields", err)
+	}
+}
@@ -0,0 +1,110 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// WriteOptions controls how the CSV is written, the zero value writes a header and empty nulls.
+type WriteOptions struct {
+	Comma           rune   // the field delimiter, ',' if zero
+	NoHeader        bool   // the names of the columns are not written
+	NullValue       string // the value written for nulls
+	TimestampFormat string // the format of timestamps, time.RFC3339Nano if empty
+	UseCRLF         bool   // the lines end with \r\n instead of \n
+}
+
+// Writer writes the record batches of the Schema as CSV.
+//
+// Times are written in UTC, dates as 2006-01-02 and times of day as 15:04:05.999999999.
+// Binary values are written as their bytes, and the values of nested fields as JSON,
+// where structs are objects, lists are arrays and maps are arrays of key and value objects.
+// Dictionary encoded fields are written as their values.
+type Writer struct {
+	Schema *schema.Schema
+
+	opts   WriteOptions
+	csv    *csv.Writer
+	header bool // the header has been written
+}
+
+// Create a writer of the record batches of the schema.
+func NewWriter(w io.Writer, s *schema.Schema, opts WriteOptions) *Writer {
+	if opts.TimestampFormat == "" {
+		opts.TimestampFormat = time.RFC3339Nano
+	}
+
+	cw := csv.NewWriter(w)
+
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+
+	cw.UseCRLF = opts.UseCRLF
+
+	return &Writer{Schema: s, opts: opts, csv: cw, header: opts.NoHeader}
+}
+
+func (w *Writer) writeHeader() error {
+	if w.header {
+		return nil
+	}
+
+	w.header = true
+
+	names := make([]string, len(w.Schema.Fields))
+
+	for i, field := range w.Schema.Fields {
+		names[i] = field.Name
+	}
+
+	return w.csv.Write(names)
+}
+
+// Write the rows of the record batch, which is validated against the schema.
+func (w *Writer) Write(batch *vector.RecordBatch) error {
+	cols, err := columns.New(w.Schema, batch)
+
+	if err != nil {
+		return err
+	}
+
+	if err := w.writeHeader(); err != nil {
+		return fmt.Errorf("fail to write CSV header, %s", err)
+	}
+
+	record := make([]string, len(cols))
+
+	for i := 0; i < batch.Length; i++ {
+		for j, c := range cols {
+			if c.IsNull(i) {
+				record[j] = w.opts.NullValue
+			} else {
+				record[j] = c.Format(i, w.opts.TimestampFormat)
+			}
+		}
+
+		if err := w.csv.Write(record); err != nil {
+			return fmt.Errorf("fail to write CSV, %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Flush the written rows, and the header if no batch has been written.
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return fmt.Errorf("fail to write CSV header, %s", err)
+	}
+
+	w.csv.Flush()
+
+	return w.csv.Error()
+}
@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow"
+)
+
+type point struct {
+	X, Y int32
+}
+
+type row struct {
+	ID     int64
+	Name   *string `arrow:"name,dict"`
+	Score  float64
+	Ok     bool
+	At     time.Time `arrow:"at,unit=ms"`
+	Tags   []string
+	Origin *point
+	Attrs  map[string]int8
+}
+
+func TestWrite(t *testing.T) {
+	name := `say "hi", <b>`
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	rows := []row{
+		{ID: 1, Name: &name, Score: 1.5, Ok: true, At: at, Tags: []string{"a", "b"}, Origin: &point{1, -2}, Attrs: map[string]int8{"k": 1}},
+		{ID: -2, Score: -0.25, At: at.Add(time.Hour)},
+	}
+
+	batch, s, err := arrow.Marshal(rows)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, s, WriteOptions{Comma: ';', NullValue: "NA", TimestampFormat: "2006-01-02 15:04:05.000"})
+
+	if err := w.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `ID;name;Score;Ok;at;Tags;Origin;Attrs
+1;"say ""hi"", <b>";1.5;true;2024-01-02 03:04:05.006;"[""a"",""b""]";"{""X"":1,""Y"":-2}";"[{""key"":""k"",""value"":1}]"
+-2;NA;-0.25;false;2024-01-02 04:04:05.006;[];NA;[]
+`
+
+	if buf.String() != expected {
+		t.Errorf("written\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	text := "id,score,ok,at,name\n1,1.5,true,2024-01-02T03:04:05.5Z,alice\n,,,,\n"
+
+	r, err := NewReader(strings.NewReader(text), ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, r.Schema, WriteOptions{})
+
+	if err := w.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != text {
+		t.Errorf("written\n%s\nexpected\n%s", buf.String(), text)
+	}
+
+	buf.Reset()
+
+	w = NewWriter(&buf, r.Schema, WriteOptions{})
+
+	if err := w.Flush(); err != nil || buf.String() != "id,score,ok,at,name\n" {
+		t.Errorf("written %q, error %v, expected the header", buf.String(), err)
+	}
+}
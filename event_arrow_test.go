@@ -0,0 +1,137 @@
+// Code generated by arrow-gen. DO NOT EDIT.
+
+package arrow_test
+
+import (
+	"reflect"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+var eventArrowSchema = func() *schema.Schema {
+	s, err := schema.FromStruct(reflect.TypeOf(Event{}))
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}()
+
+// EventSchema returns the schema of the record batches of Event values, it must not be modified.
+func EventSchema() *schema.Schema { return eventArrowSchema }
+
+// NewEventBuilder creates a builder of the record batches of Event values, appended by AppendEvent.
+func NewEventBuilder() (*arrow.RecordBuilder, error) { return arrow.NewRecordBuilder(eventArrowSchema) }
+
+// AppendEvent appends the values to the builder created by NewEventBuilder.
+func AppendEvent(b *arrow.RecordBuilder, values []Event) {
+	for i := range values {
+		v := &values[i]
+
+		b.Columns[0].AppendInt64(v.ID)
+
+		b.Columns[1].AppendString(v.Kind)
+
+		if v.Source == nil {
+			b.Columns[2].AppendNull()
+		} else {
+			b.Columns[2].AppendString(*v.Source)
+		}
+
+		b.Columns[3].AppendInt8(v.Level)
+
+		b.Columns[4].AppendUint64(uint64(v.Count))
+
+		if v.Ratio == nil {
+			b.Columns[5].AppendNull()
+		} else {
+			b.Columns[5].AppendFloat32(*v.Ratio)
+		}
+
+		b.Columns[6].AppendBool(v.Ok)
+
+		b.Columns[7].AppendBytes(v.Digest[:])
+
+		b.Columns[8].AppendBytes(v.Payload)
+
+		b.Columns[9].AppendTime(v.At)
+
+		if v.Elapsed == nil {
+			b.Columns[10].AppendNull()
+		} else {
+			b.Columns[10].AppendDuration(*v.Elapsed)
+		}
+
+		b.Columns[11].AppendString(v.Note)
+
+		b.Columns[12].AppendFloat64(float64(v.Temp))
+
+		if v.Grade == nil {
+			b.Columns[13].AppendNull()
+		} else {
+			b.Columns[13].AppendInt64(int64(*v.Grade))
+		}
+	}
+}
+
+// ReadEvent reads the Event values of a record batch of EventSchema.
+func ReadEvent(batch *vector.RecordBatch) ([]Event, error) {
+	columns, err := arrow.NewColumns(eventArrowSchema, batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]Event, batch.Length)
+
+	for i := range values {
+		v := &values[i]
+
+		v.ID = columns[0].Int64(i)
+
+		v.Kind = columns[1].String(i)
+
+		if !columns[2].IsNull(i) {
+			x := columns[2].String(i)
+			v.Source = &x
+		}
+
+		v.Level = columns[3].Int8(i)
+
+		v.Count = uint(columns[4].Uint64(i))
+
+		if !columns[5].IsNull(i) {
+			x := columns[5].Float32(i)
+			v.Ratio = &x
+		}
+
+		v.Ok = columns[6].Bool(i)
+
+		copy(v.Digest[:], columns[7].Bytes(i))
+
+		v.Payload = columns[8].Bytes(i)
+
+		v.At = columns[9].Time(i)
+
+		if !columns[10].IsNull(i) {
+			x := columns[10].Duration(i)
+			v.Elapsed = &x
+		}
+
+		if !columns[11].IsNull(i) {
+			v.Note = columns[11].String(i)
+		}
+
+		v.Temp = Celsius(columns[12].Float64(i))
+
+		if !columns[13].IsNull(i) {
+			x := grade(columns[13].Int64(i))
+			v.Grade = &x
+		}
+	}
+
+	return values, nil
+}
@@ -4,6 +4,7 @@ import (
 	fb "github.com/google/flatbuffers/go"
 
 	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
 )
 
 type Block struct {
@@ -20,6 +21,15 @@ func UnmarshalBlock(block *flatbuf.Block) *Block {
 	}
 }
 
+// Unmarshal the block of a V4 or V5 footer.
+func UnmarshalBlockV5(block *v5.Block) *Block {
+	return &Block{
+		Offset:      block.Offset(),
+		MetadataLen: int(block.MetaDataLength()),
+		BodyLen:     block.BodyLength(),
+	}
+}
+
 func (b *Block) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	return flatbuf.CreateBlock(builder, b.Offset, int32(b.MetadataLen), b.BodyLen), nil
 }
@@ -0,0 +1,255 @@
+package file
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Compression is the codec compressing each buffer of the record batch bodies written since V5.
+type Compression int
+
+const (
+	Uncompressed Compression = iota
+	LZ4Frame
+	Zstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case Uncompressed:
+		return "UNCOMPRESSED"
+	case LZ4Frame:
+		return "LZ4_FRAME"
+	case Zstd:
+		return "ZSTD"
+	default:
+		return fmt.Sprintf("%d", int(c))
+	}
+}
+
+// The uncompressed length prefixing a buffer stored raw, because compression does not shrink it.
+const rawLength = -1
+
+// The maximum ratios of the uncompressed to the compressed lengths of valid buffers, which bound the
+// lengths they claim before any memory is allocated: a byte extends an LZ4 match by 255 bytes at most,
+// and a zstd RLE block of 4 bytes holds 128KB at most.
+const (
+	lz4MaxRatio  = 255
+	zstdMaxRatio = 128 << 10 / 4
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+func initZstd() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1)); zstdErr != nil {
+			return
+		}
+
+		zstdDecoder, zstdErr = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(math.MaxInt32))
+	})
+
+	return zstdErr
+}
+
+// Returns the batch with its buffers compressed by the codec and packed into a new body,
+// the batch itself is returned if it is not compressed.
+func compressRecordBatch(c Compression, batch *vector.RecordBatch) (*vector.RecordBatch, error) {
+	var codec int8
+
+	switch c {
+	case Uncompressed:
+		return batch, nil
+	case LZ4Frame:
+		codec = v5.CompressionTypeLZ4_FRAME
+	case Zstd:
+		if err := initZstd(); err != nil {
+			return nil, err
+		}
+
+		codec = v5.CompressionTypeZSTD
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", c)
+	}
+
+	compressed := &vector.RecordBatch{
+		Length:      batch.Length,
+		Nodes:       batch.Nodes,
+		Compression: &vector.BodyCompression{Codec: codec},
+	}
+
+	var offset int64
+
+	for _, buffer := range batch.Buffers {
+		buf := compressBuffer(codec, buffer.Bytes())
+
+		compressed.Buffers = append(compressed.Buffers, memory.NewBuffer(buf))
+		compressed.Layouts = append(compressed.Layouts, &vector.Buffer{Offset: offset, Size: int64(len(buf))})
+
+		offset = align(offset + int64(len(buf)))
+	}
+
+	return compressed, nil
+}
+
+// Returns the buffer compressed by the codec after its uncompressed length,
+// or stored raw after rawLength if compression does not shrink it. Empty buffers stay empty.
+func compressBuffer(codec int8, buf []byte) []byte {
+	if len(buf) == 0 {
+		return nil
+	}
+
+	out := binary.LittleEndian.AppendUint64(make([]byte, 0, 8+len(buf)), uint64(len(buf)))
+
+	switch codec {
+	case v5.CompressionTypeLZ4_FRAME:
+		out = lz4Compress(out, buf)
+	case v5.CompressionTypeZSTD:
+		out = zstdEncoder.EncodeAll(buf, out)
+	}
+
+	if len(out) >= 8+len(buf) {
+		out = binary.LittleEndian.AppendUint64(out[:0], ^uint64(0)) // rawLength
+		out = append(out, buf...)
+	}
+
+	return out
+}
+
+// Decompress the buffers of the batch into a new body from the allocator, they are packed
+// as an uncompressed batch is written, and the memory of the compressed body is released.
+func decompressRecordBatch(allocator memory.Allocator, rb *vector.RecordBatch) error {
+	if rb.Compression == nil {
+		return nil
+	}
+
+	if rb.Compression.Codec == v5.CompressionTypeZSTD {
+		if err := initZstd(); err != nil {
+			return err
+		}
+	}
+
+	var layouts []*vector.Buffer
+	var size int64
+
+	for i, buffer := range rb.Buffers {
+		n, err := uncompressedLength(rb.Compression.Codec, buffer.Bytes())
+
+		if err != nil {
+			return fmt.Errorf("fail to decompress buffer %d, %s", i, err)
+		}
+
+		size = align(size)
+		layouts = append(layouts, &vector.Buffer{Offset: size, Size: n})
+		size += n
+	}
+
+	if size > math.MaxInt32 {
+		return fmt.Errorf("decompressed body of %d bytes is too large", size)
+	}
+
+	mem := memory.NewMemory(allocator, int(size))
+	body := mem.Bytes()
+
+	var buffers []*memory.Buffer
+
+	for i, buffer := range rb.Buffers {
+		layout := layouts[i]
+		dst := body[layout.Offset : layout.Offset+layout.Size]
+
+		if err := decompressBuffer(rb.Compression.Codec, dst, buffer.Bytes()); err != nil {
+			mem.Release()
+
+			return fmt.Errorf("fail to decompress buffer %d, %s", i, err)
+		}
+
+		buffers = append(buffers, memory.NewBuffer(dst))
+	}
+
+	rb.Release()
+
+	rb.Buffers, rb.Layouts, rb.Memory, rb.Compression = buffers, layouts, mem, nil
+
+	return nil
+}
+
+// Returns the uncompressed length of the buffer compressed by the codec.
+func uncompressedLength(codec int8, buf []byte) (int64, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+
+	if len(buf) < 8 {
+		return 0, errors.New("missing uncompressed length")
+	}
+
+	n := int64(binary.LittleEndian.Uint64(buf))
+
+	if n == rawLength {
+		return int64(len(buf) - 8), nil
+	}
+
+	var ratio int64
+
+	switch codec {
+	case v5.CompressionTypeLZ4_FRAME:
+		ratio = lz4MaxRatio
+	case v5.CompressionTypeZSTD:
+		ratio = zstdMaxRatio
+	default:
+		return 0, fmt.Errorf("unsupported compression codec %d", codec)
+	}
+
+	if n < 0 || n > math.MaxInt32 || n > int64(len(buf)-8)*ratio {
+		return 0, fmt.Errorf("invalid uncompressed length %d of %d compressed bytes", n, len(buf)-8)
+	}
+
+	return n, nil
+}
+
+// Decompress the buffer into dst, which has its uncompressed length.
+func decompressBuffer(codec int8, dst, buf []byte) error {
+	if len(buf) == 0 {
+		return nil
+	}
+
+	if int64(binary.LittleEndian.Uint64(buf)) == rawLength {
+		copy(dst, buf[8:])
+
+		return nil
+	}
+
+	var out []byte
+	var err error
+
+	switch codec {
+	case v5.CompressionTypeLZ4_FRAME:
+		out, err = lz4Decompress(dst[:0], buf[8:], len(dst))
+	case v5.CompressionTypeZSTD:
+		out, err = zstdDecoder.DecodeAll(buf[8:], dst[:0])
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(out) != len(dst) || len(out) > 0 && &out[0] != &dst[0] {
+		return fmt.Errorf("decompressed %d bytes, expected %d", len(out), len(dst))
+	}
+
+	return nil
+}
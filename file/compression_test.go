@@ -0,0 +1,291 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// The frame of lz4Input written by the lz4 command with block checksums.
+var (
+	lz4Input = []byte("arrow arrow arrow columnar columnar columnar data data data 0123456789 0123456789")
+	lz4Frame = []byte{
+		0x04, 0x22, 0x4d, 0x18, 0x74, 0x40, 0xbd, 0x33, 0x00, 0x00, 0x00, 0x68,
+		0x61, 0x72, 0x72, 0x6f, 0x77, 0x20, 0x06, 0x00, 0x8f, 0x63, 0x6f, 0x6c,
+		0x75, 0x6d, 0x6e, 0x61, 0x72, 0x09, 0x00, 0x00, 0x47, 0x64, 0x61, 0x74,
+		0x61, 0x05, 0x00, 0xf0, 0x06, 0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36,
+		0x37, 0x38, 0x39, 0x20, 0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37,
+		0x38, 0x39, 0x25, 0x3f, 0x14, 0xcd, 0x00, 0x00, 0x00, 0x00, 0xde, 0xe0,
+		0x8a, 0xe5,
+	}
+)
+
+func TestXXH32(t *testing.T) {
+	tests := []struct {
+		input string
+		hash  uint32
+	}{
+		{"", 0x02cc5d05},
+		{"abc", 0x32d153ff},
+		{"Nobody inspects the spammish repetition", 0xe2293b2f},
+	}
+
+	for _, test := range tests {
+		if hash := xxh32([]byte(test.input), 0); hash != test.hash {
+			t.Errorf("xxh32(%q) = %#x, expected %#x", test.input, hash, test.hash)
+		}
+	}
+}
+
+func TestLZ4(t *testing.T) {
+	buf, err := lz4Decompress(nil, lz4Frame, len(lz4Input))
+
+	if err != nil || !bytes.Equal(buf, lz4Input) {
+		t.Errorf("decompress %q, %v, expected %q", buf, err, lz4Input)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	random := make([]byte, 100000)
+
+	r.Read(random)
+
+	var mixed []byte
+
+	for len(mixed) < lz4BlockMax*2 {
+		if r.Intn(2) == 0 {
+			mixed = append(mixed, random[:r.Intn(300)]...)
+		} else {
+			mixed = append(mixed, bytes.Repeat([]byte{byte(r.Intn(4))}, r.Intn(100))...)
+		}
+	}
+
+	inputs := [][]byte{nil, []byte("a"), lz4Input, bytes.Repeat([]byte{1, 2, 3, 0}, 100000), random, mixed}
+
+	for _, input := range inputs {
+		frame := lz4Compress(nil, input)
+		buf, err := lz4Decompress(nil, frame, len(input))
+
+		if err != nil || !bytes.Equal(buf, input) {
+			t.Errorf("round trip %d bytes, %v", len(input), err)
+		}
+	}
+
+	if frame := lz4Compress(nil, mixed); len(frame) > len(mixed)/4 {
+		t.Errorf("compress %d bytes to %d", len(mixed), len(frame))
+	}
+}
+
+func TestLZ4Error(t *testing.T) {
+	corrupt := append([]byte{}, lz4Frame...)
+	corrupt[len(corrupt)-1]++
+
+	tests := []struct {
+		frame []byte
+		size  int
+		err   string
+	}{
+		{lz4Frame[:6], len(lz4Input), "malformed LZ4 frame"},
+		{lz4Frame[:40], len(lz4Input), "malformed LZ4 frame"},
+		{lz4Frame, len(lz4Input) - 1, "malformed LZ4 frame"},
+		{lz4Frame, len(lz4Input) + 1, "LZ4 frame of 81 bytes, expected 82"},
+		{corrupt, len(lz4Input), "LZ4 frame checksum mismatch"},
+		{lz4Compress(nil, lz4Input), 10, "LZ4 frame of 81 bytes, expected 10"},
+	}
+
+	for _, test := range tests {
+		if _, err := lz4Decompress(nil, test.frame, test.size); err == nil || err.Error() != test.err {
+			t.Errorf("decompress with error %v, expected %s", err, test.err)
+		}
+	}
+}
+
+func TestCompressBuffer(t *testing.T) {
+	repetitive := bytes.Repeat([]byte{42, 0, 0, 0}, 1000)
+	random := make([]byte, 1000)
+
+	rand.New(rand.NewSource(1)).Read(random)
+
+	for _, codec := range []int8{v5.CompressionTypeLZ4_FRAME, v5.CompressionTypeZSTD} {
+		if err := initZstd(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, buf := range [][]byte{nil, repetitive, random} {
+			compressed := compressBuffer(codec, buf)
+
+			switch {
+			case len(buf) == 0 && len(compressed) != 0:
+				t.Errorf("compress empty buffer to %d bytes", len(compressed))
+			case len(buf) == len(random) && int64(binary.LittleEndian.Uint64(compressed)) != rawLength:
+				t.Errorf("compress random bytes with codec %d, expected them raw", codec)
+			case len(buf) == len(repetitive) && len(compressed) > len(buf)/10:
+				t.Errorf("compress %d repetitive bytes to %d with codec %d", len(buf), len(compressed), codec)
+			}
+
+			n, err := uncompressedLength(codec, compressed)
+
+			if err != nil || n != int64(len(buf)) {
+				t.Fatalf("uncompressed length %d, %v, expected %d", n, err, len(buf))
+			}
+
+			dst := make([]byte, n)
+
+			if err := decompressBuffer(codec, dst, compressed); err != nil || !bytes.Equal(dst, buf) {
+				t.Errorf("decompress with codec %d, %v", codec, err)
+			}
+		}
+	}
+}
+
+func TestUncompressedLength(t *testing.T) {
+	if err := initZstd(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the densest buffers the codecs write are within their maximum ratios
+	zeros := make([]byte, 1<<22)
+
+	for _, codec := range []int8{v5.CompressionTypeLZ4_FRAME, v5.CompressionTypeZSTD} {
+		if n, err := uncompressedLength(codec, compressBuffer(codec, zeros)); err != nil || n != int64(len(zeros)) {
+			t.Errorf("uncompressed length %d, %v with codec %d, expected %d", n, err, codec, len(zeros))
+		}
+	}
+
+	claim := func(n uint64, payload ...byte) []byte {
+		return append(binary.LittleEndian.AppendUint64(nil, n), payload...)
+	}
+
+	tests := []struct {
+		codec int8
+		buf   []byte
+	}{
+		{v5.CompressionTypeLZ4_FRAME, claim(1<<31-1, 0x04, 0x22, 0x4d, 0x18)},
+		{v5.CompressionTypeLZ4_FRAME, claim(255*4+1, 0x04, 0x22, 0x4d, 0x18)},
+		{v5.CompressionTypeZSTD, claim(1<<31-1, 0x28, 0xb5, 0x2f, 0xfd)},
+		{v5.CompressionTypeZSTD, claim(1<<31, 0x28, 0xb5, 0x2f, 0xfd)},
+		{v5.CompressionTypeZSTD, claim(1<<63, 0x28, 0xb5, 0x2f, 0xfd)},
+		{v5.CompressionTypeZSTD, claim(1)},
+		{2, claim(1, 0)},
+	}
+
+	for _, test := range tests {
+		allocator := &countingAllocator{}
+		batch := &vector.RecordBatch{
+			Length:      1,
+			Buffers:     []*memory.Buffer{memory.NewBuffer(test.buf)},
+			Compression: &vector.BodyCompression{Codec: test.codec},
+		}
+
+		if err := decompressRecordBatch(allocator, batch); err == nil || allocator.live != 0 {
+			t.Errorf("decompress %d bytes claiming %d with codec %d, %v",
+				len(test.buf), binary.LittleEndian.Uint64(test.buf), test.codec, err)
+		}
+	}
+}
+
+func TestRoundTripCompression(t *testing.T) {
+	for _, compression := range []Compression{LZ4Frame, Zstd} {
+		err := quick.Check(func(f randomFile) bool {
+			out := &writeSeeker{}
+			w := NewWriter(out, f.Schema)
+			w.Compression = compression
+
+			for _, batch := range f.Batches {
+				if err := w.WriteRecordBatch(batch); err != nil {
+					t.Errorf("fail to write %s batch, %s", compression, err)
+					return false
+				}
+			}
+
+			if err := w.Flush(); err != nil {
+				t.Errorf("fail to write %s file, %s", compression, err)
+				return false
+			}
+
+			r := NewReader(bytes.NewReader(out.buf), int64(len(out.buf)))
+			r.Validate = true
+
+			return checkFile(t, r, f)
+		}, &quick.Config{MaxCount: 200})
+
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestReadCompressed(t *testing.T) {
+	s := &schema.Schema{Fields: []*schema.Field{
+		{Name: "id", Type: schema.NewInt(64, true), Layout: newLayout(vector.ValidityVector, vector.Value64Vector)},
+		{Name: "score", Type: schema.NewInt(32, true), Layout: newLayout(vector.ValidityVector, vector.Value32Vector)},
+	}}
+
+	ids := memory.NewBuffer(make([]byte, 8*4096))
+	scores := memory.NewBuffer(make([]byte, 4*4096))
+
+	for i := 0; i < 4096; i++ {
+		ids.PutBigInt(i, int64(i))
+		scores.PutInt(i, int32(i%10))
+	}
+
+	batch := &vector.RecordBatch{
+		Length:  4096,
+		Nodes:   []*vector.FieldNode{{Length: 4096}, {Length: 4096}},
+		Buffers: []*memory.Buffer{memory.NewBuffer(nil), ids, memory.NewBuffer(nil), scores},
+		Layouts: []*vector.Buffer{{Offset: 0, Size: 0}, {Offset: 0, Size: 8 * 4096}, {Offset: 32768, Size: 0}, {Offset: 32768, Size: 4 * 4096}},
+	}
+
+	out := &writeSeeker{}
+	w := NewWriter(out, s)
+	w.Compression = Zstd
+
+	if err := w.WriteRecordBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.buf) > len(ids.Bytes())/3 {
+		t.Errorf("compressed file of %d bytes, expected it to shrink", len(out.buf))
+	}
+
+	r := NewReader(bytes.NewReader(out.buf), int64(len(out.buf)))
+	r.Validate = true
+
+	defer r.Close()
+
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rb, _, err := r.ReadProjectedRecordBatch(footer.RecordBatches[0], "score")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rb.Release()
+
+	if len(rb.Buffers) != 2 || !bytes.Equal(rb.Buffers[1].Bytes(), scores.Bytes()) {
+		t.Errorf("projected buffers %v, expected the scores", rb.Buffers)
+	}
+
+	w = NewWriter(&writeSeeker{}, s)
+	w.Version = schema.V1
+	w.Compression = LZ4Frame
+
+	if err := w.WriteRecordBatch(batch); err == nil {
+		t.Error("write compressed batch in V1 metadata")
+	}
+}
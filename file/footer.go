@@ -1,11 +1,13 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 
 	fb "github.com/google/flatbuffers/go"
 
 	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
 	"github.com/flier/arrow/schema"
 )
 
@@ -14,12 +16,17 @@ const (
 )
 
 type Footer struct {
+	// The metadata version of the file, which determines the encoding of its blocks.
+	Version schema.MetadataVersion
+
 	Schema        *schema.Schema
 	Dictionaries  []*Block
 	RecordBatches []*Block
 }
 
-func UnmarshalFooter(footer *flatbuf.Footer) (*Footer, error) {
+func UnmarshalFooter(footer *flatbuf.Footer) (f *Footer, err error) {
+	defer recoverMalformed(&err)
+
 	var dictionaries []*Block
 	var recordBatches []*Block
 	var block flatbuf.Block
@@ -36,20 +43,74 @@ func UnmarshalFooter(footer *flatbuf.Footer) (*Footer, error) {
 		}
 	}
 
-	s, err := schema.UnmarshalSchema(footer.Schema(nil))
+	fs := footer.Schema(nil)
+
+	if fs == nil {
+		return nil, errors.New("missing schema")
+	}
+
+	s, err := schema.UnmarshalSchema(fs)
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse schema, %s", err)
+	}
+
+	return &Footer{
+		Version:       schema.V1,
+		Schema:        s,
+		Dictionaries:  dictionaries,
+		RecordBatches: recordBatches,
+	}, nil
+}
+
+// Unmarshal the footer of a V4 or V5 file.
+func UnmarshalFooterV5(footer *v5.Footer) (f *Footer, err error) {
+	defer recoverMalformed(&err)
+
+	var dictionaries []*Block
+	var recordBatches []*Block
+	var block v5.Block
+
+	for i := 0; i < footer.DictionariesLength(); i++ {
+		if footer.Dictionaries(&block, i) {
+			dictionaries = append(dictionaries, UnmarshalBlockV5(&block))
+		}
+	}
+
+	for i := 0; i < footer.RecordBatchesLength(); i++ {
+		if footer.RecordBatches(&block, i) {
+			recordBatches = append(recordBatches, UnmarshalBlockV5(&block))
+		}
+	}
+
+	fs := footer.Schema(nil)
+
+	if fs == nil {
+		return nil, errors.New("missing schema")
+	}
+
+	version := schema.MetadataVersion(footer.Version())
+
+	s, err := schema.UnmarshalSchemaV5(fs, version)
 
 	if err != nil {
 		return nil, fmt.Errorf("fail to parse schema, %s", err)
 	}
 
 	return &Footer{
+		Version:       version,
 		Schema:        s,
 		Dictionaries:  dictionaries,
 		RecordBatches: recordBatches,
 	}, nil
 }
 
+// Marshal the footer in the encoding of its metadata version.
 func (f *Footer) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	if f.Version != schema.V1 {
+		return f.marshalV5(builder)
+	}
+
 	schemaOffset, err := f.Schema.Marshal(builder)
 
 	if err != nil {
@@ -77,9 +138,39 @@ func (f *Footer) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	return flatbuf.FooterEnd(builder), nil
 }
 
+func (f *Footer) marshalV5(builder *fb.Builder) (fb.UOffsetT, error) {
+	schemaOffset, err := f.Schema.MarshalV5(builder)
+
+	if err != nil {
+		return 0, fmt.Errorf("fail to marshal schema, %s", err)
+	}
+
+	v5.FooterStartDictionariesVector(builder, len(f.Dictionaries))
+	dicsOffset, err := f.marshalBlocks(builder, f.Dictionaries)
+
+	if err != nil {
+		return 0, fmt.Errorf("fail to marshal dictionaries, %s", err)
+	}
+
+	v5.FooterStartRecordBatchesVector(builder, len(f.RecordBatches))
+	rbsOffset, err := f.marshalBlocks(builder, f.RecordBatches)
+
+	if err != nil {
+		return 0, fmt.Errorf("fail to marshal recordBatches, %s", err)
+	}
+
+	v5.FooterStart(builder)
+	v5.FooterAddVersion(builder, int16(f.Version))
+	v5.FooterAddSchema(builder, schemaOffset)
+	v5.FooterAddDictionaries(builder, dicsOffset)
+	v5.FooterAddRecordBatches(builder, rbsOffset)
+	return v5.FooterEnd(builder), nil
+}
+
 func (f *Footer) marshalBlocks(builder *fb.Builder, blocks []*Block) (fb.UOffsetT, error) {
-	for _, block := range blocks {
-		if _, err := block.Marshal(builder); err != nil {
+	// vectors are built back to front
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if _, err := blocks[i].Marshal(builder); err != nil {
 			return 0, err
 		}
 	}
@@ -0,0 +1,146 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// writeSeeker is an in-memory io.WriteSeeker.
+type writeSeeker struct {
+	buf []byte
+	pos int64
+}
+
+func (w *writeSeeker) Write(p []byte) (int, error) {
+	if end := w.pos + int64(len(p)); end > int64(len(w.buf)) {
+		w.buf = append(w.buf, make([]byte, end-int64(len(w.buf)))...)
+	}
+
+	n := copy(w.buf[w.pos:], p)
+	w.pos += int64(n)
+
+	return n, nil
+}
+
+func (w *writeSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += w.pos
+	case io.SeekEnd:
+		offset += int64(len(w.buf))
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	w.pos = offset
+
+	return offset, nil
+}
+
+func newLayout(vectors ...*vector.VectorLayout) *vector.TypeLayout {
+	return &vector.TypeLayout{Vectors: vectors}
+}
+
+// Write a small file with an Int and a Utf8 column in two record batches compressed by the codec.
+func seedFile(tb testing.TB, compression Compression) []byte {
+	s := &schema.Schema{Fields: []*schema.Field{
+		{Name: "id", Type: schema.NewInt(32, true), Layout: newLayout(vector.ValidityVector, vector.Value32Vector)},
+		{Name: "name", Nullable: true, Type: schema.Utf8, Layout: newLayout(vector.ValidityVector, vector.OffsetVector, vector.ByteVector)},
+	}}
+
+	out := &writeSeeker{}
+	w := NewWriter(out, s)
+	w.Compression = compression
+
+	for i := 0; i < 2; i++ {
+		ids := memory.NewBuffer(make([]byte, 8))
+		ids.PutInt(0, int32(i*2))
+		ids.PutInt(1, int32(i*2+1))
+
+		offsets := memory.NewBuffer(make([]byte, 16))
+		offsets.PutInt(1, 3)
+		offsets.PutInt(2, 3)
+
+		batch := &vector.RecordBatch{
+			Length: 2,
+			Nodes:  []*vector.FieldNode{{Length: 2}, {Length: 2, NullCount: 1}},
+			Buffers: []*memory.Buffer{
+				memory.NewBuffer(nil),
+				ids,
+				memory.NewBuffer([]byte{1, 0, 0, 0, 0, 0, 0, 0}),
+				offsets,
+				memory.NewBuffer([]byte("foo\x00\x00\x00\x00\x00")),
+			},
+			Layouts: []*vector.Buffer{{Offset: 0, Size: 0}, {Offset: 0, Size: 8}, {Offset: 8, Size: 8}, {Offset: 16, Size: 16}, {Offset: 32, Size: 8}},
+		}
+
+		if err := w.WriteRecordBatch(batch); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return out.buf
+}
+
+func FuzzReadFooter(f *testing.F) {
+	f.Add(seedFile(f, Uncompressed))
+	f.Add([]byte(Magic + "\x00\x00" + Magic))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := NewReader(bytes.NewReader(data), int64(len(data)))
+
+		r.ReadFooter()
+	})
+}
+
+func FuzzReadRecordBatch(f *testing.F) {
+	f.Add(seedFile(f, Uncompressed))
+	f.Add(seedFile(f, LZ4Frame))
+	f.Add(seedFile(f, Zstd))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, validate := range []bool{false, true} {
+			r := NewReader(bytes.NewReader(data), int64(len(data)))
+			r.Validate = validate
+
+			footer, err := r.ReadFooter()
+
+			if err != nil {
+				return
+			}
+
+			for _, block := range footer.RecordBatches {
+				if batch, err := r.ReadRecordBatch(block); err == nil {
+					batch.Release()
+				}
+
+				for _, field := range footer.Schema.Fields {
+					if batch, _, err := r.ReadProjectedRecordBatch(block, field.Name); err == nil {
+						batch.Release()
+					}
+				}
+			}
+
+			it := r.Iter()
+
+			for it.Next() {
+			}
+
+			it.Release()
+			r.Close()
+		}
+	})
+}
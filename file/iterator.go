@@ -0,0 +1,71 @@
+package file
+
+import (
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Iterator walks the record batches of a file in order.
+//
+//	it := r.Iter()
+//	defer it.Release()
+//
+//	for it.Next() {
+//		batch := it.Record()
+//		...
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// The current record is released when Next is called, Retain it to keep it longer.
+type Iterator struct {
+	r      *Reader
+	index  int
+	record *vector.RecordBatch
+	err    error
+}
+
+// Advance to the next record batch, returns false at the end of the file or on error.
+func (it *Iterator) Next() bool {
+	it.Release()
+
+	if it.err != nil {
+		return false
+	}
+
+	n, err := it.r.NumRecordBatches()
+
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	if it.index >= n {
+		return false
+	}
+
+	it.record, it.err = it.r.RecordBatch(it.index)
+
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+
+	return true
+}
+
+// Returns the current record batch.
+func (it *Iterator) Record() *vector.RecordBatch { return it.record }
+
+// Returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error { return it.err }
+
+// Release the current record batch.
+func (it *Iterator) Release() {
+	if it.record != nil {
+		it.record.Release()
+		it.record = nil
+	}
+}
@@ -0,0 +1,338 @@
+package file
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// The LZ4 frame format, each compressed buffer of a record batch is a single frame.
+const (
+	lz4Magic        = 0x184D2204
+	lz4Version      = 1 << 6
+	lz4BlockIndep   = 1 << 5
+	lz4BlockCksum   = 1 << 4
+	lz4ContentSize  = 1 << 3
+	lz4ContentCksum = 1 << 2
+	lz4DictID       = 1 << 0
+	lz4BlockMax     = 4 << 20 // the block size written, 4MB as the block max size 7
+	lz4Uncompressed = 1 << 31 // the flag of a block size for an uncompressed block
+
+	lz4MinMatch     = 4
+	lz4LastLiterals = 5  // the last bytes of a block are literals
+	lz4MatchLimit   = 12 // the last match starts before the last bytes
+	lz4MaxOffset    = 65535
+	lz4HashLog      = 14
+)
+
+var errLZ4Frame = errors.New("malformed LZ4 frame")
+
+// Append the LZ4 frame of the independent blocks of src, with its content size and checksum.
+func lz4Compress(dst, src []byte) []byte {
+	descriptor := []byte{lz4Version | lz4BlockIndep | lz4ContentSize | lz4ContentCksum, 7 << 4}
+	descriptor = binary.LittleEndian.AppendUint64(descriptor, uint64(len(src)))
+
+	dst = binary.LittleEndian.AppendUint32(dst, lz4Magic)
+	dst = append(dst, descriptor...)
+	dst = append(dst, byte(xxh32(descriptor, 0)>>8))
+
+	table := make([]int32, 1<<lz4HashLog)
+
+	for block := src; len(block) > 0; {
+		n := min(len(block), lz4BlockMax)
+
+		for i := range table {
+			table[i] = 0
+		}
+
+		start := len(dst)
+		dst = lz4CompressBlock(append(dst, 0, 0, 0, 0), block[:n], table)
+
+		if size := len(dst) - start - 4; size < n {
+			binary.LittleEndian.PutUint32(dst[start:], uint32(size))
+		} else {
+			dst = binary.LittleEndian.AppendUint32(dst[:start], uint32(n)|lz4Uncompressed)
+			dst = append(dst, block[:n]...)
+		}
+
+		block = block[n:]
+	}
+
+	dst = binary.LittleEndian.AppendUint32(dst, 0)
+
+	return binary.LittleEndian.AppendUint32(dst, xxh32(src, 0))
+}
+
+// Append the LZ4 block of src, the matches are found greedily in the hash table of the positions.
+func lz4CompressBlock(dst, src []byte, table []int32) []byte {
+	anchor := 0
+
+	for i := 0; i+lz4MatchLimit < len(src); {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 2654435761) >> (32 - lz4HashLog)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i++
+
+			continue
+		}
+
+		for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+			i, ref = i-1, ref-1
+		}
+
+		length := lz4MinMatch
+
+		for i+length < len(src)-lz4LastLiterals && src[i+length] == src[ref+length] {
+			length++
+		}
+
+		dst = lz4Sequence(dst, src[anchor:i], i-ref, length)
+		i += length
+		anchor = i
+	}
+
+	// the last sequence has only literals
+	dst = append(dst, byte(min(len(src)-anchor, 15))<<4)
+	dst = lz4Length(dst, len(src)-anchor)
+
+	return append(dst, src[anchor:]...)
+}
+
+func lz4Sequence(dst, literals []byte, offset, length int) []byte {
+	length -= lz4MinMatch
+
+	dst = append(dst, byte(min(len(literals), 15))<<4|byte(min(length, 15)))
+	dst = lz4Length(dst, len(literals))
+	dst = append(dst, literals...)
+	dst = append(dst, byte(offset), byte(offset>>8))
+
+	return lz4Length(dst, length)
+}
+
+// Append the bytes of the length beyond the 4 bits of the token.
+func lz4Length(dst []byte, n int) []byte {
+	if n < 15 {
+		return dst
+	}
+
+	for n -= 15; n >= 255; n -= 255 {
+		dst = append(dst, 255)
+	}
+
+	return append(dst, byte(n))
+}
+
+// Decompress the LZ4 frame of size bytes, the blocks may be linked and checksummed.
+func lz4Decompress(dst, src []byte, size int) ([]byte, error) {
+	if len(src) < 7 || binary.LittleEndian.Uint32(src) != lz4Magic {
+		return nil, errLZ4Frame
+	}
+
+	flags := src[4]
+
+	if flags&0xC0 != lz4Version || flags&lz4DictID != 0 {
+		return nil, fmt.Errorf("unsupported LZ4 frame flags %#x", flags)
+	}
+
+	n := 6
+
+	if flags&lz4ContentSize != 0 {
+		n += 8
+	}
+
+	if len(src) < n+1 || byte(xxh32(src[4:n], 0)>>8) != src[n] {
+		return nil, errLZ4Frame
+	}
+
+	if flags&lz4ContentSize != 0 && binary.LittleEndian.Uint64(src[6:]) != uint64(size) {
+		return nil, fmt.Errorf("LZ4 frame of %d bytes, expected %d", binary.LittleEndian.Uint64(src[6:]), size)
+	}
+
+	start := len(dst)
+	src = src[n+1:]
+
+	for {
+		if len(src) < 4 {
+			return nil, errLZ4Frame
+		}
+
+		blockSize := binary.LittleEndian.Uint32(src)
+		src = src[4:]
+
+		if blockSize == 0 {
+			break
+		}
+
+		n := int(blockSize &^ lz4Uncompressed)
+
+		if n > len(src) {
+			return nil, errLZ4Frame
+		}
+
+		var err error
+
+		if blockSize&lz4Uncompressed != 0 {
+			if len(dst)-start+n > size {
+				return nil, errLZ4Frame
+			}
+
+			dst = append(dst, src[:n]...)
+		} else if dst, err = lz4DecompressBlock(dst, src[:n], start+size); err != nil {
+			return nil, err
+		}
+
+		src = src[n:]
+
+		if flags&lz4BlockCksum != 0 {
+			if len(src) < 4 {
+				return nil, errLZ4Frame
+			}
+
+			src = src[4:]
+		}
+	}
+
+	if flags&lz4ContentCksum != 0 {
+		if len(src) < 4 {
+			return nil, errLZ4Frame
+		}
+
+		if binary.LittleEndian.Uint32(src) != xxh32(dst[start:], 0) {
+			return nil, errors.New("LZ4 frame checksum mismatch")
+		}
+	}
+
+	if len(dst)-start != size {
+		return nil, fmt.Errorf("LZ4 frame of %d bytes, expected %d", len(dst)-start, size)
+	}
+
+	return dst, nil
+}
+
+// Append the decompressed LZ4 block, the matches may refer to the previous blocks in dst,
+// which can not grow beyond limit.
+func lz4DecompressBlock(dst, src []byte, limit int) ([]byte, error) {
+	var literals, length int
+	var ok bool
+
+	for i := 0; ; {
+		if i >= len(src) {
+			return nil, errLZ4Frame
+		}
+
+		token := int(src[i])
+		i++
+
+		literals, i, ok = lz4ReadLength(src, i, token>>4)
+
+		if !ok || literals > len(src)-i || literals > limit-len(dst) {
+			return nil, errLZ4Frame
+		}
+
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+
+		if i == len(src) {
+			return dst, nil
+		}
+
+		if i+2 > len(src) {
+			return nil, errLZ4Frame
+		}
+
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+
+		length, i, ok = lz4ReadLength(src, i, token&15)
+
+		if length += lz4MinMatch; !ok || offset == 0 || offset > len(dst) || length > limit-len(dst) {
+			return nil, errLZ4Frame
+		}
+
+		// the match may overlap the bytes it appends, which repeat every offset bytes
+		for pos := len(dst) - offset; length > 0; {
+			n := min(length, offset)
+			dst = append(dst, dst[pos:pos+n]...)
+			pos, length = pos+n, length-n
+		}
+	}
+}
+
+// Returns the length of the 4 bits of a token followed by its extra bytes.
+func lz4ReadLength(src []byte, i, n int) (int, int, bool) {
+	if n < 15 {
+		return n, i, true
+	}
+
+	for {
+		if i >= len(src) || n > 1<<30 {
+			return 0, i, false
+		}
+
+		b := src[i]
+		i++
+		n += int(b)
+
+		if b != 255 {
+			return n, i, true
+		}
+	}
+}
+
+const (
+	xxhPrime1 uint32 = 2654435761
+	xxhPrime2 uint32 = 2246822519
+	xxhPrime3 uint32 = 3266489917
+	xxhPrime4 uint32 = 668265263
+	xxhPrime5 uint32 = 374761393
+)
+
+// Returns the 32 bits xxHash of the bytes, which checksums the LZ4 frames.
+func xxh32(b []byte, seed uint32) uint32 {
+	n := len(b)
+
+	var h uint32
+
+	if n >= 16 {
+		v1, v2, v3, v4 := seed+xxhPrime1+xxhPrime2, seed+xxhPrime2, seed, seed-xxhPrime1
+
+		for ; len(b) >= 16; b = b[16:] {
+			v1 = xxhRound(v1, binary.LittleEndian.Uint32(b))
+			v2 = xxhRound(v2, binary.LittleEndian.Uint32(b[4:]))
+			v3 = xxhRound(v3, binary.LittleEndian.Uint32(b[8:]))
+			v4 = xxhRound(v4, binary.LittleEndian.Uint32(b[12:]))
+		}
+
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxhPrime5
+	}
+
+	h += uint32(n)
+
+	for ; len(b) >= 4; b = b[4:] {
+		h += binary.LittleEndian.Uint32(b) * xxhPrime3
+		h = bits.RotateLeft32(h, 17) * xxhPrime4
+	}
+
+	for _, c := range b {
+		h += uint32(c) * xxhPrime5
+		h = bits.RotateLeft32(h, 11) * xxhPrime1
+	}
+
+	h ^= h >> 15
+	h *= xxhPrime2
+	h ^= h >> 13
+	h *= xxhPrime3
+	h ^= h >> 16
+
+	return h
+}
+
+func xxhRound(acc, input uint32) uint32 {
+	return bits.RotateLeft32(acc+input*xxhPrime2, 13) * xxhPrime1
+}
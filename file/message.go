@@ -0,0 +1,206 @@
+package file
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	fb "github.com/google/flatbuffers/go"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// The marker preceding the length of an encapsulated message since the 0.15 format.
+const continuation = 0xFFFFFFFF
+
+var (
+	errDeltaDictionary = errors.New("delta dictionary batch is not supported")
+)
+
+// Returns the Message flatbuffer of an encapsulated message,
+// which is prefixed by its length and, since the 0.15 format, a continuation marker.
+func messageBytes(metadata []byte) ([]byte, error) {
+	if len(metadata) >= 4 && binary.LittleEndian.Uint32(metadata) == continuation {
+		metadata = metadata[4:]
+	}
+
+	if len(metadata) < 4 {
+		return nil, errTooSmall
+	}
+
+	size := int64(int32(binary.LittleEndian.Uint32(metadata)))
+
+	if size <= 0 || size > int64(len(metadata))-4 {
+		return nil, errInvalidBlock
+	}
+
+	return metadata[4 : 4+size], nil
+}
+
+// Returns the header table of the encapsulated message, which must be of the given type.
+func getMessageHeader(metadata []byte, headerType byte) (header fb.Table, err error) {
+	defer recoverMalformed(&err)
+
+	buf, err := messageBytes(metadata)
+
+	if err != nil {
+		return header, err
+	}
+
+	if err := checkRoot(buf); err != nil {
+		return header, err
+	}
+
+	msg := v5.GetRootAsMessage(buf, 0)
+
+	if msg.HeaderType() != headerType {
+		return header, fmt.Errorf("unexpected %s message, expected %s",
+			v5.EnumNamesMessageHeader[int(msg.HeaderType())], v5.EnumNamesMessageHeader[int(headerType)])
+	}
+
+	if !msg.Header(&header) {
+		return header, errInvalidBlock
+	}
+
+	return header, nil
+}
+
+// Decode the record batch from the block metadata in the encoding of the metadata version.
+func unmarshalRecordBatch(version schema.MetadataVersion, metadata, body []byte) (*vector.RecordBatch, error) {
+	if version == schema.V1 {
+		if err := checkRoot(metadata); err != nil {
+			return nil, err
+		}
+
+		return vector.UnmarshalRecordBatch(flatbuf.GetRootAsRecordBatch(metadata, 0), body)
+	}
+
+	header, err := getMessageHeader(metadata, v5.MessageHeaderRecordBatch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var batch v5.RecordBatch
+
+	batch.Init(header.Bytes, header.Pos)
+
+	return vector.UnmarshalRecordBatchV5(&batch, body)
+}
+
+// Decode the dictionary batch from the block metadata in the encoding of the metadata version,
+// returns the dictionary id and its values.
+func unmarshalDictionaryBatch(version schema.MetadataVersion, metadata, body []byte) (int64, *vector.RecordBatch, error) {
+	if version == schema.V1 {
+		id, batch, err := getDictionaryBatch(metadata)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		rb, err := vector.UnmarshalRecordBatch(batch, body)
+
+		return id, rb, err
+	}
+
+	id, batch, err := getDictionaryBatchV5(metadata)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rb, err := vector.UnmarshalRecordBatchV5(batch, body)
+
+	return id, rb, err
+}
+
+func getDictionaryBatch(buf []byte) (id int64, batch *flatbuf.RecordBatch, err error) {
+	defer recoverMalformed(&err)
+
+	if err := checkRoot(buf); err != nil {
+		return 0, nil, err
+	}
+
+	dict := flatbuf.GetRootAsDictionaryBatch(buf, 0)
+
+	if batch = dict.Data(nil); batch == nil {
+		return 0, nil, errInvalidBlock
+	}
+
+	return dict.Id(), batch, nil
+}
+
+func getDictionaryBatchV5(metadata []byte) (id int64, batch *v5.RecordBatch, err error) {
+	defer recoverMalformed(&err)
+
+	header, err := getMessageHeader(metadata, v5.MessageHeaderDictionaryBatch)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var dict v5.DictionaryBatch
+
+	dict.Init(header.Bytes, header.Pos)
+
+	if dict.IsDelta() != 0 {
+		return 0, nil, errDeltaDictionary
+	}
+
+	if batch = dict.Data(nil); batch == nil {
+		return 0, nil, errInvalidBlock
+	}
+
+	return dict.Id(), batch, nil
+}
+
+// Decode the length, field nodes, buffer locations and compression of the record batch from the block metadata,
+// without touching its body.
+func unmarshalRecordBatchLayout(version schema.MetadataVersion, metadata []byte) (length int64, nodes []*vector.FieldNode, buffers []*vector.Buffer, compression *vector.BodyCompression, err error) {
+	defer recoverMalformed(&err)
+
+	if version == schema.V1 {
+		if err := checkRoot(metadata); err != nil {
+			return 0, nil, nil, nil, err
+		}
+
+		batch := flatbuf.GetRootAsRecordBatch(metadata, 0)
+
+		if nodes, err = vector.UnmarshalFieldNodes(batch); err != nil {
+			return 0, nil, nil, nil, err
+		}
+
+		if buffers, err = vector.UnmarshalBuffers(batch); err != nil {
+			return 0, nil, nil, nil, err
+		}
+
+		return int64(batch.Length()), nodes, buffers, nil, nil
+	}
+
+	header, err := getMessageHeader(metadata, v5.MessageHeaderRecordBatch)
+
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+
+	var batch v5.RecordBatch
+
+	batch.Init(header.Bytes, header.Pos)
+
+	if nodes, err = vector.UnmarshalFieldNodesV5(&batch); err != nil {
+		return 0, nil, nil, nil, err
+	}
+
+	if buffers, err = vector.UnmarshalBuffersV5(&batch); err != nil {
+		return 0, nil, nil, nil, err
+	}
+
+	if compression, err = vector.UnmarshalBodyCompressionV5(&batch); err != nil {
+		return 0, nil, nil, nil, err
+	}
+
+	return batch.Length(), nodes, buffers, compression, nil
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package file
+
+import (
+	"errors"
+)
+
+// Memory mapping is not supported on this platform.
+func OpenMmap(path string) (*Reader, error) {
+	return nil, errors.New("memory mapped files are not supported on this platform")
+}
@@ -0,0 +1,55 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/flier/arrow/memory"
+)
+
+// Open the file and map it into memory, the record batches read from it share the mapping.
+//
+// The mapping is private and copy-on-write, so writes to the buffers never reach the file.
+// It is unmapped when the reader is closed and every batch has been released.
+func OpenMmap(path string) (*Reader, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	fi, err := f.Stat()
+
+	if err != nil {
+		return nil, err
+	}
+
+	size := fi.Size()
+
+	if size <= 0 || int64(int(size)) != size {
+		return nil, fmt.Errorf("fail to map file, invalid size %d", size)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE)
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to map file, %s", err)
+	}
+
+	return &Reader{
+		in:   io.NewSectionReader(bytes.NewReader(data), 0, size),
+		data: memory.WrapMemory(data, munmap),
+	}, nil
+}
+
+func munmap(data []byte) {
+	syscall.Munmap(data)
+}
@@ -0,0 +1,226 @@
+package file
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Read the record batch of the block with only the columns of the given field paths.
+//
+// Only the metadata and the buffers of the projected columns are read from the file,
+// the paths are resolved as by schema.Schema.Project against the schema of the file.
+func (r *Reader) ReadProjectedRecordBatch(block *Block, paths ...string) (*vector.RecordBatch, *schema.Schema, error) {
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proj, err := footer.Schema.Project(paths...)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to project schema, %s", err)
+	}
+
+	if block.MetadataLen < 0 || block.BodyLen < 0 {
+		return nil, nil, errInvalidBlock
+	}
+
+	in, data, err := r.acquire()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data != nil {
+		rb, err := mapProjectedRecordBatch(data, block, footer.Version, proj)
+
+		if err != nil {
+			data.Release()
+
+			return nil, nil, err
+		}
+
+		return r.finishProjected(rb, proj)
+	}
+
+	if block.Offset < 0 || block.Offset > in.Size()-int64(block.MetadataLen)-block.BodyLen {
+		return nil, nil, errInvalidBlock
+	}
+
+	metadata := r.allocator().Allocate(block.MetadataLen)
+
+	defer r.allocator().Free(metadata)
+
+	if _, err := in.ReadAt(metadata, block.Offset); err != nil {
+		return nil, nil, fmt.Errorf("fail to read metadata, %s", err)
+	}
+
+	rb, layouts, err := projectRecordBatch(metadata, block, footer.Version, proj)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.readProjectedBody(in, block, proj.Buffers, layouts, rb); err != nil {
+		return nil, nil, err
+	}
+
+	return r.finishProjected(rb, proj)
+}
+
+// Decompress the buffers of the projected record batch and validate it against the projected schema.
+func (r *Reader) finishProjected(rb *vector.RecordBatch, proj *schema.Projection) (*vector.RecordBatch, *schema.Schema, error) {
+	if err := decompressRecordBatch(r.allocator(), rb); err != nil {
+		rb.Release()
+
+		return nil, nil, fmt.Errorf("fail to parse records, %s", err)
+	}
+
+	if r.Validate {
+		if err := proj.Schema.Validate(rb); err != nil {
+			rb.Release()
+
+			return nil, nil, fmt.Errorf("invalid record batch, %s", err)
+		}
+	}
+
+	return rb, proj.Schema, nil
+}
+
+// Decode the projected record batch from its metadata, returns the batch without buffers
+// and where the projected buffers are located in the body.
+func projectRecordBatch(metadata []byte, block *Block, version schema.MetadataVersion, proj *schema.Projection) (*vector.RecordBatch, []*vector.Buffer, error) {
+	length, nodes, buffers, compression, err := unmarshalRecordBatchLayout(version, metadata)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if int64(int(length)) != length {
+		return nil, nil, fmt.Errorf("batch length %d overflows", length)
+	}
+
+	var layouts []*vector.Buffer
+
+	rb := &vector.RecordBatch{Length: int(length), Compression: compression}
+
+	for _, i := range proj.Nodes {
+		if i >= len(nodes) {
+			return nil, nil, fmt.Errorf("missing field node %d of %d", i, len(nodes))
+		}
+
+		rb.Nodes = append(rb.Nodes, nodes[i])
+	}
+
+	for _, i := range proj.Buffers {
+		if i >= len(buffers) {
+			return nil, nil, fmt.Errorf("missing buffer %d of %d", i, len(buffers))
+		}
+
+		layout := buffers[i]
+
+		if layout.Offset < 0 || layout.Size < 0 || layout.Offset > block.BodyLen-layout.Size {
+			return nil, nil, fmt.Errorf("buffer %d [%d, %d) out of body range [0, %d)", i, layout.Offset, layout.Offset+layout.Size, block.BodyLen)
+		}
+
+		layouts = append(layouts, layout)
+	}
+
+	return rb, layouts, nil
+}
+
+// Decode the projected record batch in place, its buffers point into the mapped file.
+func mapProjectedRecordBatch(data *memory.Memory, block *Block, version schema.MetadataVersion, proj *schema.Projection) (*vector.RecordBatch, error) {
+	buf := data.Bytes()
+
+	if block.Offset < 0 || block.Offset > int64(len(buf))-int64(block.MetadataLen)-block.BodyLen {
+		return nil, errInvalidBlock
+	}
+
+	metadata := buf[block.Offset : block.Offset+int64(block.MetadataLen)]
+	body := buf[block.Offset+int64(block.MetadataLen):]
+
+	rb, layouts, err := projectRecordBatch(metadata, block, version, proj)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layout := range layouts {
+		rb.Buffers = append(rb.Buffers, memory.NewBuffer(body[layout.Offset:layout.Offset+layout.Size]))
+	}
+
+	rb.Layouts = layouts
+	rb.Memory = data
+
+	return rb, nil
+}
+
+// Read the projected buffers into a packed body, adjacent buffers of the batch are read at once.
+func (r *Reader) readProjectedBody(in io.ReaderAt, block *Block, indices []int, layouts []*vector.Buffer, rb *vector.RecordBatch) error {
+	type run struct {
+		start, end int64 // range in the body of the file
+		dest       int64 // offset in the packed body
+	}
+
+	var runs []*run
+	var size int64
+
+	owners := make([]*run, len(layouts))
+
+	for i, layout := range layouts {
+		if i > 0 && indices[i] == indices[i-1]+1 && layout.Offset >= runs[len(runs)-1].end {
+			last := runs[len(runs)-1]
+
+			size += layout.Offset + layout.Size - last.end
+			last.end = layout.Offset + layout.Size
+		} else {
+			size = align(size)
+
+			runs = append(runs, &run{layout.Offset, layout.Offset + layout.Size, size})
+
+			size += layout.Size
+		}
+
+		owners[i] = runs[len(runs)-1]
+
+		// the buffers of a valid batch do not overlap, so the packed body only adds the padding of the runs
+		if size > block.BodyLen+7*int64(len(runs)) {
+			return fmt.Errorf("projected buffers of %d bytes overflow the body of %d bytes", size, block.BodyLen)
+		}
+	}
+
+	mem := memory.NewMemory(r.allocator(), int(size))
+	body := mem.Bytes()
+
+	bodyOffset := block.Offset + int64(block.MetadataLen)
+
+	for _, run := range runs {
+		if _, err := in.ReadAt(body[run.dest:run.dest+run.end-run.start], bodyOffset+run.start); err != nil {
+			mem.Release()
+
+			return fmt.Errorf("fail to read buffers, %s", err)
+		}
+	}
+
+	for i, layout := range layouts {
+		offset := owners[i].dest + layout.Offset - owners[i].start
+
+		rb.Buffers = append(rb.Buffers, memory.NewBuffer(body[offset:offset+layout.Size]))
+		rb.Layouts = append(rb.Layouts, &vector.Buffer{Offset: offset, Size: layout.Size})
+	}
+
+	rb.Memory = mem
+
+	return nil
+}
+
+// align on 8 byte boundaries
+func align(n int64) int64 {
+	return (n + 7) &^ 7
+}
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+func typeLayout(t *testing.T, tp schema.Type) *vector.TypeLayout {
+	layout, err := schema.NewTypeLayout(tp)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return layout
+}
+
+// Write a file with the columns id, point.x, point.name and score in two record batches.
+func projectFile(t *testing.T) (*schema.Schema, []byte) {
+	s := &schema.Schema{Fields: []*schema.Field{
+		{Name: "id", Type: schema.NewInt(32, true), Layout: typeLayout(t, schema.NewInt(32, true))},
+		{Name: "point", Type: schema.Struct, Layout: typeLayout(t, schema.Struct), Children: []*schema.Field{
+			{Name: "x", Type: schema.NewInt(64, true), Layout: typeLayout(t, schema.NewInt(64, true))},
+			{Name: "name", Type: schema.Utf8, Layout: typeLayout(t, schema.Utf8)},
+		}},
+		{Name: "score", Type: schema.NewInt(64, true), Layout: typeLayout(t, schema.NewInt(64, true))},
+	}}
+
+	out := &writeSeeker{}
+	w := NewWriter(out, s)
+
+	for i := 0; i < 2; i++ {
+		ids := memory.NewBuffer(make([]byte, 8))
+		xs := memory.NewBuffer(make([]byte, 16))
+		offsets := memory.NewBuffer(make([]byte, 16))
+		scores := memory.NewBuffer(make([]byte, 16))
+
+		for j := 0; j < 2; j++ {
+			ids.PutInt(j, int32(i*2+j))
+			xs.PutBigInt(j, int64(-i*2-j))
+			scores.PutBigInt(j, int64(100+i*2+j))
+		}
+
+		offsets.PutInt(1, 2)
+		offsets.PutInt(2, 5)
+
+		batch := &vector.RecordBatch{
+			Length: 2,
+			Nodes:  []*vector.FieldNode{{Length: 2}, {Length: 2}, {Length: 2}, {Length: 2}, {Length: 2}},
+			Buffers: []*memory.Buffer{
+				memory.NewBuffer(nil), ids,
+				memory.NewBuffer(nil),
+				memory.NewBuffer(nil), xs,
+				memory.NewBuffer(nil), offsets, memory.NewBuffer([]byte("abcde\x00\x00\x00")),
+				memory.NewBuffer(nil), scores,
+			},
+		}
+
+		var offset int64
+
+		for _, buf := range batch.Buffers {
+			batch.Layouts = append(batch.Layouts, &vector.Buffer{Offset: offset, Size: int64(buf.Len())})
+			offset += int64(buf.Len())
+		}
+
+		if err := w.WriteRecordBatch(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	return s, out.buf
+}
+
+func TestReadProjectedRecordBatch(t *testing.T) {
+	s, buf := projectFile(t)
+	path := filepath.Join(t.TempDir(), "project.arrow")
+
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := [][]string{
+		{"id"},
+		{"score", "id"},
+		{"point"},
+		{"point.name"},
+		{"point.x", "score"},
+		{"point.name", "point"},
+		{"id", "point", "score"},
+	}
+
+	for _, mmap := range []bool{false, true} {
+		r := NewReader(bytes.NewReader(buf), int64(len(buf)))
+
+		if mmap {
+			var err error
+
+			if r, err = OpenMmap(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r.Validate = true
+
+		footer, err := r.ReadFooter()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, paths := range tests {
+			proj, err := s.Project(paths...)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, block := range footer.RecordBatches {
+				full, err := r.ReadRecordBatch(block)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rb, ps, err := r.ReadProjectedRecordBatch(block, paths...)
+
+				if err != nil {
+					t.Fatalf("fail to project %v, %s", paths, err)
+				}
+
+				if !reflect.DeepEqual(ps, proj.Schema) {
+					t.Errorf("project %v with schema %s, expected %s", paths, ps, proj.Schema)
+				}
+
+				if rb.Length != full.Length || len(rb.Nodes) != len(proj.Nodes) || len(rb.Buffers) != len(proj.Buffers) {
+					t.Fatalf("project %v with %d nodes and %d buffers, expected %d and %d",
+						paths, len(rb.Nodes), len(rb.Buffers), len(proj.Nodes), len(proj.Buffers))
+				}
+
+				for i, node := range proj.Nodes {
+					if *rb.Nodes[i] != *full.Nodes[node] {
+						t.Errorf("project %v with node %d %v, expected %v", paths, i, rb.Nodes[i], full.Nodes[node])
+					}
+				}
+
+				for i, buffer := range proj.Buffers {
+					if !bytes.Equal(rb.Buffers[i].Bytes(), full.Buffers[buffer].Bytes()) {
+						t.Errorf("project %v with buffer %d %v, expected %v", paths, i, rb.Buffers[i].Bytes(), full.Buffers[buffer].Bytes())
+					}
+				}
+
+				rb.Release()
+				full.Release()
+			}
+		}
+
+		if _, _, err := r.ReadProjectedRecordBatch(footer.RecordBatches[0], "point.y"); err == nil {
+			t.Error("project a missing field")
+		}
+
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadProjectedBodyOverflow(t *testing.T) {
+	r := NewReader(bytes.NewReader(make([]byte, 64)), 64)
+	block := &Block{Offset: 0, MetadataLen: 8, BodyLen: 16}
+
+	// overlapping buffers claim more bytes than the body has
+	layouts := []*vector.Buffer{{Offset: 0, Size: 16}, {Offset: 0, Size: 16}, {Offset: 0, Size: 16}}
+	rb := &vector.RecordBatch{}
+
+	if err := r.readProjectedBody(r.in, block, []int{0, 2, 4}, layouts, rb); err == nil || rb.Memory != nil {
+		t.Error("read projected buffers overflowing the body")
+	}
+
+	if err := r.readProjectedBody(r.in, block, []int{0}, layouts[:1], rb); err != nil || rb.Buffers[0].Len() != 16 {
+		t.Errorf("read projected buffer, %v", err)
+	}
+}
@@ -5,8 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"runtime"
+	"sync"
 
 	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
 	"github.com/flier/arrow/schema/vector"
 )
 
@@ -15,28 +20,98 @@ var (
 	errBadMagic      = errors.New("missing magic number")
 	errInvalidFooter = errors.New("invalid footer")
 	errInvalidBlock  = errors.New("invalid block")
+	errClosed        = errors.New("reader closed")
+	errInvalidRoot   = errors.New("invalid flatbuffer root")
 )
 
+// Reader reads record batches from a file, it is safe for concurrent use.
 type Reader struct {
-	in     *io.SectionReader
-	footer *Footer
+	// The allocator for record batch memory, memory.DefaultAllocator if nil.
+	Allocator memory.Allocator
+
+	// Check the structure of every record batch read against the schema,
+	// corrupt batches are reported as errors, see schema.Schema.Validate.
+	Validate bool
+
+	mu   sync.Mutex // guards in and data
+	in   *io.SectionReader
+	data *memory.Memory
+
+	footerMu sync.Mutex
+	footer   *Footer
+
+	dictMu       sync.Mutex
+	dictionaries map[int64]*vector.RecordBatch
+}
+
+func NewReader(in io.ReaderAt, size int64) *Reader {
+	return &Reader{in: io.NewSectionReader(in, 0, size)}
+}
+
+func (r *Reader) allocator() memory.Allocator {
+	if r.Allocator != nil {
+		return r.Allocator
+	}
+
+	return memory.DefaultAllocator
+}
+
+// Returns the input of an open reader, the mapping is retained until the returned release is called.
+func (r *Reader) acquire() (*io.SectionReader, *memory.Memory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.in == nil {
+		return nil, nil, errClosed
+	}
+
+	if r.data != nil {
+		r.data.Retain()
+	}
+
+	return r.in, r.data, nil
 }
 
 func (r *Reader) ReadFooter() (*Footer, error) {
+	r.footerMu.Lock()
+	defer r.footerMu.Unlock()
+
 	if r.footer != nil {
 		return r.footer, nil
 	}
 
+	in, data, err := r.acquire()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		defer data.Release()
+	}
+
+	footer, err := readFooter(in)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.footer = footer
+
+	return footer, nil
+}
+
+func readFooter(in *io.SectionReader) (*Footer, error) {
 	minSize := int64(len(Magic)*2 + 4)
 
-	if r.in.Size() <= minSize {
+	if in.Size() <= minSize {
 		return nil, errTooSmall
 	}
 
 	buf := make([]byte, 4+len(Magic))
-	off := r.in.Size() - int64(len(buf))
+	off := in.Size() - int64(len(buf))
 
-	if _, err := r.in.ReadAt(buf, off); err != nil {
+	if _, err := in.ReadAt(buf, off); err != nil {
 		return nil, fmt.Errorf("fail to read magic, %s", err)
 	}
 
@@ -46,7 +121,7 @@ func (r *Reader) ReadFooter() (*Footer, error) {
 
 	footerLength := int64(int32(binary.LittleEndian.Uint32(buf[:4])))
 
-	if footerLength <= 0 || footerLength+minSize > r.in.Size() {
+	if footerLength <= 0 || footerLength+minSize > in.Size() {
 		return nil, errInvalidFooter
 	}
 
@@ -54,36 +129,321 @@ func (r *Reader) ReadFooter() (*Footer, error) {
 
 	buf = make([]byte, footerLength)
 
-	if _, err := r.in.ReadAt(buf, off); err != nil {
+	if _, err := in.ReadAt(buf, off); err != nil {
 		return nil, fmt.Errorf("fail to read footer, %s", err)
 	}
 
-	if footer, err := UnmarshalFooter(flatbuf.GetRootAsFooter(buf, 0)); err != nil {
+	footer, err := unmarshalFooter(buf)
+
+	if err != nil {
 		return nil, fmt.Errorf("fail to parse footer, %s", err)
-	} else {
-		r.footer = footer
 	}
 
-	return r.footer, nil
+	return footer, nil
 }
 
-// TODO: read dictionaries
+// Decode the footer in the encoding of its metadata version,
+// which is the first field of the footer in every version.
+func unmarshalFooter(buf []byte) (footer *Footer, err error) {
+	defer recoverMalformed(&err)
+
+	if err := checkRoot(buf); err != nil {
+		return nil, err
+	}
+
+	switch version := schema.MetadataVersion(v5.GetRootAsFooter(buf, 0).Version()); version {
+	case schema.V1:
+		return UnmarshalFooter(flatbuf.GetRootAsFooter(buf, 0))
+	case schema.V4, schema.V5:
+		return UnmarshalFooterV5(v5.GetRootAsFooter(buf, 0))
+	default:
+		return nil, fmt.Errorf("unsupported metadata version, %s", version)
+	}
+}
+
+// Close the reader, a mapped file is unmapped once every batch read from it has been released.
+func (r *Reader) Close() error {
+	r.dictMu.Lock()
+
+	if r.dictionaries != nil {
+		releaseDictionaries(r.dictionaries)
+		r.dictionaries = nil
+	}
+
+	r.dictMu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.in = nil
+
+	if r.data != nil {
+		r.data.Release()
+		r.data = nil
+	}
+
+	return nil
+}
+
+// Read the metadata and body of the block.
+//
+// The memory either comes from the reader's allocator or is shared with the mapped file.
+func (r *Reader) readBlock(block *Block) ([]byte, *memory.Memory, error) {
+	if block.MetadataLen < 0 || block.BodyLen < 0 {
+		return nil, nil, errInvalidBlock
+	}
 
-func (r *Reader) ReadRecordBatch(block *Block) (*vector.RecordBatch, error) {
 	bufSize := int64(block.MetadataLen) + block.BodyLen
 
-	if bufSize < 0 {
-		return nil, errInvalidBlock
+	in, data, err := r.acquire()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data != nil {
+		buf := data.Bytes()
+
+		if block.Offset < 0 || block.Offset > int64(len(buf))-bufSize {
+			data.Release()
+
+			return nil, nil, errInvalidBlock
+		}
+
+		return buf[block.Offset : block.Offset+bufSize], data, nil
+	}
+
+	if block.Offset < 0 || block.Offset > in.Size()-bufSize {
+		return nil, nil, errInvalidBlock
+	}
+
+	mem := memory.NewMemory(r.allocator(), int(bufSize))
+
+	if _, err := in.ReadAt(mem.Bytes(), block.Offset); err != nil {
+		mem.Release()
+
+		return nil, nil, err
+	}
+
+	return mem.Bytes(), mem, nil
+}
+
+// Read the record batch of the block, its memory is recycled when the batch is released.
+//
+// The buffers of a batch read from a mapped file point straight into the mapping.
+func (r *Reader) ReadRecordBatch(block *Block) (*vector.RecordBatch, error) {
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return nil, err
 	}
 
-	buf := make([]byte, bufSize)
+	buf, mem, err := r.readBlock(block)
 
-	if _, err := r.in.ReadAt(buf, block.Offset); err != nil {
+	if err != nil {
 		return nil, fmt.Errorf("fail to read records, %s", err)
 	}
 
-	batch := flatbuf.GetRootAsRecordBatch(buf[:block.MetadataLen], 0)
-	body := buf[block.MetadataLen:]
+	rb, err := unmarshalRecordBatch(footer.Version, buf[:block.MetadataLen], buf[block.MetadataLen:])
+
+	if err != nil {
+		mem.Release()
+
+		return nil, fmt.Errorf("fail to parse records, %s", err)
+	}
+
+	rb.Memory = mem
+
+	if err := decompressRecordBatch(r.allocator(), rb); err != nil {
+		rb.Release()
+
+		return nil, fmt.Errorf("fail to parse records, %s", err)
+	}
+
+	if r.Validate {
+		if err := r.validate(rb); err != nil {
+			rb.Release()
+
+			return nil, err
+		}
+	}
+
+	return rb, nil
+}
+
+func (r *Reader) validate(rb *vector.RecordBatch) error {
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return err
+	}
+
+	if err := footer.Schema.Validate(rb); err != nil {
+		return fmt.Errorf("invalid record batch, %s", err)
+	}
+
+	return nil
+}
+
+// Read the dictionary batch of the block, returns the dictionary id and its values.
+func (r *Reader) ReadDictionaryBatch(block *Block) (int64, *vector.RecordBatch, error) {
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	buf, mem, err := r.readBlock(block)
+
+	if err != nil {
+		return 0, nil, fmt.Errorf("fail to read dictionary, %s", err)
+	}
+
+	id, rb, err := unmarshalDictionaryBatch(footer.Version, buf[:block.MetadataLen], buf[block.MetadataLen:])
+
+	if err != nil {
+		mem.Release()
+
+		return 0, nil, fmt.Errorf("fail to parse dictionary, %s", err)
+	}
+
+	rb.Memory = mem
+
+	if err := decompressRecordBatch(r.allocator(), rb); err != nil {
+		rb.Release()
+
+		return 0, nil, fmt.Errorf("fail to parse dictionary, %s", err)
+	}
+
+	if r.Validate {
+		if err := rb.Validate(); err != nil {
+			rb.Release()
+
+			return 0, nil, fmt.Errorf("invalid dictionary batch, %s", err)
+		}
+	}
+
+	return id, rb, nil
+}
+
+// Check the root table offset of the flatbuffer lies within the buffer.
+func checkRoot(buf []byte) error {
+	if len(buf) < 4 {
+		return errTooSmall
+	}
+
+	if off := binary.LittleEndian.Uint32(buf); int64(off) > int64(len(buf))-4 {
+		return errInvalidRoot
+	}
+
+	return nil
+}
+
+// Turn the runtime panic raised by accessing a malformed flatbuffer into an error.
+func recoverMalformed(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(runtime.Error); ok {
+			*err = fmt.Errorf("malformed metadata, %s", e)
+		} else {
+			panic(r)
+		}
+	}
+}
+
+// Read all the dictionaries of the file, keyed by their ids.
+//
+// The dictionaries are owned by the reader and released when it is closed.
+func (r *Reader) ReadDictionaries() (map[int64]*vector.RecordBatch, error) {
+	r.dictMu.Lock()
+	defer r.dictMu.Unlock()
+
+	if r.dictionaries != nil {
+		return r.dictionaries, nil
+	}
+
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return nil, err
+	}
+
+	dictionaries := make(map[int64]*vector.RecordBatch, len(footer.Dictionaries))
+
+	for _, block := range footer.Dictionaries {
+		id, dict, err := r.ReadDictionaryBatch(block)
+
+		if err != nil {
+			releaseDictionaries(dictionaries)
+
+			return nil, err
+		}
+
+		if prev, exists := dictionaries[id]; exists {
+			prev.Release()
+		}
+
+		dictionaries[id] = dict
+	}
+
+	r.dictionaries = dictionaries
+
+	return dictionaries, nil
+}
+
+func releaseDictionaries(dictionaries map[int64]*vector.RecordBatch) {
+	for _, dict := range dictionaries {
+		dict.Release()
+	}
+}
+
+// Returns the number of record batches in the file.
+func (r *Reader) NumRecordBatches() (int, error) {
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(footer.RecordBatches), nil
+}
+
+// Read the i-th record batch of the file with its dictionaries resolved,
+// which are retained with the batch and stay valid after the reader is closed.
+func (r *Reader) RecordBatch(i int) (*vector.RecordBatch, error) {
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if i < 0 || i >= len(footer.RecordBatches) {
+		return nil, fmt.Errorf("record batch %d out of range [0, %d)", i, len(footer.RecordBatches))
+	}
+
+	dictionaries, err := r.ReadDictionaries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rb, err := r.ReadRecordBatch(footer.RecordBatches[i])
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the batch holds its own references, the dictionaries of the reader are released when it is closed
+	rb.Dictionaries = make(map[int64]*vector.RecordBatch, len(dictionaries))
+
+	for id, dict := range dictionaries {
+		dict.Retain()
+		rb.Dictionaries[id] = dict
+	}
+
+	return rb, nil
+}
 
-	return vector.UnmarshalRecordBatch(batch, body)
+// Returns an iterator over the record batches of the file.
+func (r *Reader) Iter() *Iterator {
+	return &Iterator{r: r}
 }
@@ -0,0 +1,181 @@
+package file
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Write a file with a dictionary encoded Utf8 column in three record batches,
+// the i-th batch has the indices i and i+1 of the dictionary ["a", "bc", "def", "ghij"].
+func dictFile(t *testing.T) []byte {
+	s := &schema.Schema{Fields: []*schema.Field{
+		{
+			Name:       "name",
+			Type:       schema.Utf8,
+			Dictionary: &schema.DictionaryEncoding{ID: 1},
+			Layout:     newLayout(vector.ValidityVector, vector.Value32Vector),
+		},
+	}}
+
+	out := &writeSeeker{}
+	w := NewWriter(out, s)
+
+	offsets := memory.NewBuffer(make([]byte, 24))
+
+	for i, n := range []int32{0, 1, 3, 6, 10} {
+		offsets.PutInt(i, n)
+	}
+
+	dict := &vector.RecordBatch{
+		Length:  4,
+		Nodes:   []*vector.FieldNode{{Length: 4}},
+		Buffers: []*memory.Buffer{memory.NewBuffer(nil), offsets, memory.NewBuffer([]byte("abcdefghij\x00\x00\x00\x00\x00\x00"))},
+		Layouts: []*vector.Buffer{{Offset: 0, Size: 0}, {Offset: 0, Size: 24}, {Offset: 24, Size: 16}},
+	}
+
+	if err := w.WriteDictionaryBatch(1, dict); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		indices := memory.NewBuffer(make([]byte, 8))
+		indices.PutInt(0, int32(i))
+		indices.PutInt(1, int32(i+1))
+
+		batch := &vector.RecordBatch{
+			Length:  2,
+			Nodes:   []*vector.FieldNode{{Length: 2}},
+			Buffers: []*memory.Buffer{memory.NewBuffer(nil), indices},
+			Layouts: []*vector.Buffer{{Offset: 0, Size: 0}, {Offset: 0, Size: 8}},
+		}
+
+		if err := w.WriteRecordBatch(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.buf
+}
+
+func checkDictBatch(t *testing.T, rb *vector.RecordBatch, i int) {
+	if rb.Length != 2 || rb.Buffers[1].Int(0) != int32(i) || rb.Buffers[1].Int(1) != int32(i+1) {
+		t.Errorf("batch %d has indices %v, expected [%d %d]", i, rb.Buffers[1].Bytes(), i, i+1)
+	}
+
+	dict := rb.Dictionaries[1]
+
+	if dict == nil || len(rb.Dictionaries) != 1 {
+		t.Fatalf("batch %d has dictionaries %v, expected the id 1", i, rb.Dictionaries)
+	}
+
+	if values := dict.Buffers[2].Bytes(); dict.Length != 4 || !bytes.HasPrefix(values, []byte("abcdefghij")) {
+		t.Errorf("batch %d has dictionary values %q", i, values)
+	}
+}
+
+func TestReaderRecordBatch(t *testing.T) {
+	buf := dictFile(t)
+	r := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	r.Allocator = memory.NewPoolAllocator()
+	r.Validate = true
+
+	n, err := r.NumRecordBatches()
+
+	if err != nil || n != 3 {
+		t.Fatalf("%d record batches, %v, expected 3", n, err)
+	}
+
+	var batches []*vector.RecordBatch
+
+	for i := 0; i < n; i++ {
+		rb, err := r.RecordBatch(i)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkDictBatch(t, rb, i)
+
+		batches = append(batches, rb)
+	}
+
+	for _, i := range []int{-1, 3} {
+		if _, err := r.RecordBatch(i); err == nil {
+			t.Errorf("read record batch %d of 3", i)
+		}
+	}
+
+	// the batches hold their dictionaries after the reader is closed, until each is released
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, rb := range batches {
+		checkDictBatch(t, rb, i)
+
+		if dict := rb.Dictionaries[1]; dict.Memory.Bytes() == nil {
+			t.Errorf("dictionary of batch %d is released with the reader", i)
+		}
+
+		rb.Release()
+	}
+
+	if dict := batches[0].Dictionaries[1]; dict.Memory.Bytes() != nil {
+		t.Error("dictionary is not released with the last batch")
+	}
+
+	if _, err := r.RecordBatch(0); err == nil {
+		t.Error("read record batch from a closed reader")
+	}
+}
+
+func TestIterator(t *testing.T) {
+	buf := dictFile(t)
+	r := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	r.Validate = true
+
+	it := r.Iter()
+
+	var kept *vector.RecordBatch
+	var i int
+
+	for ; it.Next(); i++ {
+		checkDictBatch(t, it.Record(), i)
+
+		if i == 1 {
+			kept = it.Record()
+			kept.Retain()
+		}
+	}
+
+	if err := it.Err(); err != nil || i != 3 {
+		t.Errorf("iterate %d batches, %v, expected 3", i, err)
+	}
+
+	if it.Next() || it.Record() != nil {
+		t.Error("iterate beyond the last batch")
+	}
+
+	it.Release()
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkDictBatch(t, kept, 1)
+	kept.Release()
+
+	it = r.Iter()
+
+	if it.Next() || it.Err() == nil {
+		t.Error("iterate a closed reader without error")
+	}
+}
@@ -0,0 +1,575 @@
+package file
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	fb "github.com/google/flatbuffers/go"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const (
+	maxDepth   = 3
+	maxFields  = 4
+	maxBatches = 3
+	maxLength  = 64
+)
+
+// randomFile is a random schema with record batches of random values, it implements quick.Generator.
+type randomFile struct {
+	Schema  *schema.Schema
+	Batches []*vector.RecordBatch
+}
+
+func (randomFile) Generate(r *rand.Rand, size int) reflect.Value {
+	s := &schema.Schema{}
+
+	for i := r.Intn(maxFields + 1); i > 0; i-- {
+		s.Fields = append(s.Fields, randomField(r, 0))
+	}
+
+	f := randomFile{Schema: s}
+
+	for i := r.Intn(maxBatches + 1); i > 0; i-- {
+		length := r.Intn(maxLength + 1)
+
+		b := &batchBuilder{r: r, batch: &vector.RecordBatch{Length: length}}
+
+		for _, field := range s.Fields {
+			b.field(field, length)
+		}
+
+		f.Batches = append(f.Batches, b.batch)
+	}
+
+	return reflect.ValueOf(f)
+}
+
+var typeIDs = []int{
+	flatbuf.TypeNull,
+	flatbuf.TypeInt,
+	flatbuf.TypeFloatingPoint,
+	flatbuf.TypeBinary,
+	flatbuf.TypeUtf8,
+	flatbuf.TypeBool,
+	flatbuf.TypeDecimal,
+	flatbuf.TypeDate,
+	flatbuf.TypeTime,
+	flatbuf.TypeTimestamp,
+	flatbuf.TypeInterval,
+	flatbuf.TypeList,
+	flatbuf.TypeStruct_,
+	flatbuf.TypeUnion,
+	v5.TypeFixedSizeBinary,
+	v5.TypeFixedSizeList,
+	v5.TypeMap,
+	v5.TypeDuration,
+	v5.TypeLargeBinary,
+	v5.TypeLargeUtf8,
+	v5.TypeLargeList,
+}
+
+func randomField(r *rand.Rand, depth int) *schema.Field {
+	var tp schema.Type
+	var children []*schema.Field
+
+	nested := depth < maxDepth
+
+	for tp == nil {
+		switch typeIDs[r.Intn(len(typeIDs))] {
+		case flatbuf.TypeNull:
+			tp = schema.Null
+		case flatbuf.TypeInt:
+			tp = schema.NewInt([]int{8, 16, 32, 64}[r.Intn(4)], r.Intn(2) == 0)
+		case flatbuf.TypeFloatingPoint:
+			tp = schema.NewFloatingPoint([]schema.Precision{schema.Half, schema.Single, schema.Double}[r.Intn(3)])
+		case flatbuf.TypeBinary:
+			tp = schema.Binary
+		case flatbuf.TypeUtf8:
+			tp = schema.Utf8
+		case flatbuf.TypeBool:
+			tp = schema.Bool
+		case flatbuf.TypeDecimal:
+			tp = schema.NewDecimal(schema.Precision(r.Intn(38)+1), r.Intn(10))
+		case flatbuf.TypeDate:
+			tp = schema.NewDate(schema.DateUnit(r.Intn(2)))
+		case flatbuf.TypeTime:
+			tp = schema.NewTime(schema.TimeUnit(r.Intn(4)))
+		case flatbuf.TypeTimestamp:
+			tp = schema.NewTimeStamp(schema.TimeUnit(r.Intn(4)))
+		case flatbuf.TypeInterval:
+			tp = schema.NewInterval(schema.IntervalUnit(r.Intn(2)))
+		case flatbuf.TypeList, v5.TypeLargeList:
+			if nested {
+				tp = schema.List
+
+				if r.Intn(2) == 0 {
+					tp = schema.LargeList
+				}
+
+				children = []*schema.Field{randomField(r, depth+1)}
+			}
+		case v5.TypeFixedSizeList:
+			if nested {
+				tp = schema.NewFixedSizeList(r.Intn(4))
+				children = []*schema.Field{randomField(r, depth+1)}
+			}
+		case v5.TypeMap:
+			if depth+1 < maxDepth {
+				key := randomField(r, maxDepth)
+				key.Nullable = false
+
+				entries, err := schema.NewField("entries", false, schema.Struct, key, randomField(r, depth+2))
+
+				if err != nil {
+					panic(err)
+				}
+
+				tp = schema.NewMap(r.Intn(2) == 0)
+				children = []*schema.Field{entries}
+			}
+		case v5.TypeFixedSizeBinary:
+			tp = schema.NewFixedSizeBinary(r.Intn(16) + 1)
+		case v5.TypeDuration:
+			tp = schema.NewDuration(schema.TimeUnit(r.Intn(4)))
+		case v5.TypeLargeBinary:
+			tp = schema.LargeBinary
+		case v5.TypeLargeUtf8:
+			tp = schema.LargeUtf8
+		case flatbuf.TypeStruct_:
+			if nested {
+				tp = schema.Struct
+
+				for i := r.Intn(maxFields); i > 0; i-- {
+					children = append(children, randomField(r, depth+1))
+				}
+			}
+		case flatbuf.TypeUnion:
+			if nested {
+				for i := r.Intn(maxFields) + 1; i > 0; i-- {
+					children = append(children, randomField(r, depth+1))
+				}
+
+				var ids []int
+
+				if r.Intn(2) == 0 {
+					for _, id := range r.Perm(100)[:len(children)] {
+						ids = append(ids, id)
+					}
+				}
+
+				tp = schema.NewUnion(schema.UnionMode(r.Intn(2)), ids)
+			}
+		}
+	}
+
+	layout, err := schema.NewTypeLayout(tp)
+
+	if err != nil {
+		panic(err)
+	}
+
+	field := &schema.Field{
+		Name:     randomString(r, 8),
+		Nullable: r.Intn(2) == 0,
+		Type:     tp,
+		Children: children,
+		Layout:   layout,
+	}
+
+	// only the leaf fields are encoded, the dictionaries themselves are not generated
+	if children == nil && r.Intn(4) == 0 {
+		// V1 metadata reserves the dictionary id 0
+		field.Dictionary = &schema.DictionaryEncoding{ID: 1 + r.Int63n(1<<62)}
+
+		if field.Layout, err = schema.NewTypeLayout(field.Dictionary.Index()); err != nil {
+			panic(err)
+		}
+	}
+
+	return field
+}
+
+func randomString(r *rand.Rand, n int) string {
+	runes := make([]rune, r.Intn(n+1))
+
+	for i := range runes {
+		switch r.Intn(3) {
+		case 0:
+			runes[i] = rune('a' + r.Intn(26))
+		case 1:
+			runes[i] = rune(0x80 + r.Intn(0x800))
+		default:
+			runes[i] = rune(0x4e00 + r.Intn(0x5000))
+		}
+	}
+
+	return string(runes)
+}
+
+// batchBuilder fills a record batch with random values of a schema.
+type batchBuilder struct {
+	r     *rand.Rand
+	batch *vector.RecordBatch
+	size  int64
+}
+
+func (b *batchBuilder) addBuffer(buf []byte) {
+	b.size = (b.size + 7) &^ 7
+
+	b.batch.Buffers = append(b.batch.Buffers, memory.NewBuffer(buf))
+	b.batch.Layouts = append(b.batch.Layouts, &vector.Buffer{Offset: b.size, Size: int64(len(buf))})
+
+	b.size += int64(len(buf))
+}
+
+func (b *batchBuilder) randomBytes(n int) []byte {
+	buf := make([]byte, n)
+
+	b.r.Read(buf)
+
+	return buf
+}
+
+func (b *batchBuilder) field(field *schema.Field, length int) {
+	r := b.r
+
+	validity := make([]byte, (length+7)/8)
+	nulls := 0
+
+	// only the fields with a validity bitmap may have nulls
+	nullable := field.Nullable && len(field.Layout.Vectors) > 0 && field.Layout.Vectors[0].Type == vector.Validity
+
+	for i := 0; i < length; i++ {
+		if nullable && r.Intn(3) == 0 {
+			nulls++
+		} else {
+			validity[i/8] |= 1 << uint(i%8)
+		}
+	}
+
+	b.batch.Nodes = append(b.batch.Nodes, &vector.FieldNode{Length: length, NullCount: nulls})
+
+	var childLengths []int
+	var typeWidth int
+
+	tp := field.Type.Value()
+
+	if field.Dictionary != nil {
+		tp = flatbuf.TypeInt
+	}
+
+	for _, layout := range field.Layout.Vectors {
+		switch layout.Type {
+		case vector.Validity:
+			if nulls == 0 && r.Intn(2) == 0 {
+				b.addBuffer(nil)
+			} else {
+				b.addBuffer(validity)
+			}
+
+		case vector.Offset:
+			if tp == flatbuf.TypeUnion {
+				offsets := memory.NewBuffer(make([]byte, length*4))
+				counts := make([]int, len(childLengths))
+				types := b.batch.Buffers[len(b.batch.Buffers)-1]
+
+				for i := 0; i < length; i++ {
+					child := childIndex(field.Type.(*schema.Union), typeID(types, typeWidth, i))
+
+					offsets.PutInt(i, int32(counts[child]))
+					counts[child]++
+				}
+
+				b.addBuffer(offsets.Bytes())
+
+				childLengths = counts
+			} else {
+				offsets := memory.NewBuffer(make([]byte, (length+1)*layout.BitWidth/8))
+				values := make([][]byte, length)
+				total := 0
+
+				for i := 0; i < length; i++ {
+					switch tp {
+					case flatbuf.TypeUtf8, v5.TypeLargeUtf8:
+						values[i] = []byte(randomString(r, 4))
+					case flatbuf.TypeBinary, v5.TypeLargeBinary:
+						values[i] = b.randomBytes(r.Intn(8))
+					default:
+						values[i] = make([]byte, r.Intn(4))
+					}
+
+					total += len(values[i])
+
+					if layout.BitWidth == 64 {
+						offsets.PutBigInt(i+1, int64(total))
+					} else {
+						offsets.PutInt(i+1, int32(total))
+					}
+				}
+
+				b.addBuffer(offsets.Bytes())
+
+				if tp == flatbuf.TypeList || tp == v5.TypeLargeList || tp == v5.TypeMap {
+					childLengths = []int{total}
+				} else {
+					b.addBuffer(bytes.Join(values, nil))
+				}
+			}
+
+		case vector.Type:
+			union := field.Type.(*schema.Union)
+			types := memory.NewBuffer(make([]byte, length*layout.BitWidth/8))
+
+			for i := 0; i < length; i++ {
+				id := r.Intn(len(field.Children))
+
+				if len(union.TypeIDs) > 0 {
+					id = union.TypeIDs[id]
+				}
+
+				if layout.BitWidth == 8 {
+					types.PutTinyInt(i, int8(id))
+				} else {
+					types.PutInt(i, int32(id))
+				}
+			}
+
+			b.addBuffer(types.Bytes())
+
+			typeWidth = layout.BitWidth
+			childLengths = make([]int, len(field.Children))
+
+			for i := range childLengths {
+				childLengths[i] = length
+			}
+
+		case vector.Data:
+			if tp == flatbuf.TypeUtf8 || tp == flatbuf.TypeBinary || tp == v5.TypeLargeUtf8 || tp == v5.TypeLargeBinary {
+				continue // added with the offsets
+			}
+
+			b.addBuffer(b.randomBytes((length*layout.BitWidth + 7) / 8))
+		}
+	}
+
+	if list, ok := field.Type.(*schema.FixedSizeList); ok {
+		childLengths = []int{length * list.ListSize}
+	}
+
+	for i, child := range field.Children {
+		childLength := length
+
+		if childLengths != nil {
+			childLength = childLengths[i]
+		}
+
+		b.field(child, childLength)
+	}
+}
+
+// Returns the i-th union type id, in 8 bits since V4 and in 32 bits before.
+func typeID(types *memory.Buffer, bitWidth, i int) int {
+	if bitWidth == 8 {
+		return int(types.TinyInt(i))
+	}
+
+	return int(types.Int(i))
+}
+
+func childIndex(union *schema.Union, typeID int) int {
+	for i, id := range union.TypeIDs {
+		if id == typeID {
+			return i
+		}
+	}
+
+	return typeID
+}
+
+func writeFile(f randomFile, version schema.MetadataVersion) ([]byte, error) {
+	out := &writeSeeker{}
+	w := NewWriter(out, f.Schema)
+	w.Version = version
+
+	for _, batch := range f.Batches {
+		if err := w.WriteRecordBatch(batch); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return out.buf, nil
+}
+
+func checkFile(t *testing.T, r *Reader, f randomFile) bool {
+	defer r.Close()
+
+	footer, err := r.ReadFooter()
+
+	if err != nil {
+		t.Errorf("fail to read footer, %s", err)
+		return false
+	}
+
+	if !reflect.DeepEqual(footer.Schema, f.Schema) {
+		t.Errorf("schema mismatch")
+		return false
+	}
+
+	it := r.Iter()
+	defer it.Release()
+
+	i := 0
+
+	for ; it.Next(); i++ {
+		if i >= len(f.Batches) {
+			t.Errorf("more than %d batches", len(f.Batches))
+			return false
+		}
+
+		if !equalBatch(t, it.Record(), f.Batches[i]) {
+			t.Errorf("batch %d mismatch", i)
+			return false
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		t.Errorf("fail to read batch %d, %s", i, err)
+		return false
+	}
+
+	if i != len(f.Batches) {
+		t.Errorf("read %d batches, expected %d", i, len(f.Batches))
+		return false
+	}
+
+	return true
+}
+
+func equalBatch(t *testing.T, got, expected *vector.RecordBatch) bool {
+	if got.Length != expected.Length {
+		t.Errorf("length %d, expected %d", got.Length, expected.Length)
+		return false
+	}
+
+	if !reflect.DeepEqual(got.Nodes, expected.Nodes) {
+		t.Errorf("nodes %v, expected %v", got.Nodes, expected.Nodes)
+		return false
+	}
+
+	if !reflect.DeepEqual(got.Layouts, expected.Layouts) {
+		t.Errorf("layouts %v, expected %v", got.Layouts, expected.Layouts)
+		return false
+	}
+
+	if len(got.Buffers) != len(expected.Buffers) {
+		t.Errorf("%d buffers, expected %d", len(got.Buffers), len(expected.Buffers))
+		return false
+	}
+
+	for i, buf := range got.Buffers {
+		if !bytes.Equal(buf.Bytes(), expected.Buffers[i].Bytes()) {
+			t.Errorf("buffer %d %v, expected %v", i, buf.Bytes(), expected.Buffers[i].Bytes())
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestRandomBatchesAreValid(t *testing.T) {
+	err := quick.Check(func(f randomFile) bool {
+		for i, batch := range f.Batches {
+			if err := f.Schema.Validate(batch); err != nil {
+				t.Errorf("batch %d is invalid, %s", i, err)
+				return false
+			}
+		}
+
+		return true
+	}, nil)
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	err := quick.Check(func(f randomFile) bool {
+		for _, version := range []schema.MetadataVersion{schema.V1, schema.V5} {
+			// the types added since V4 can not be written in V1 metadata
+			if _, err := f.Schema.Marshal(fb.NewBuilder(0)); version == schema.V1 && err != nil {
+				continue
+			}
+
+			buf, err := writeFile(f, version)
+
+			if err != nil {
+				t.Errorf("fail to write %s file, %s", version, err)
+				return false
+			}
+
+			r := NewReader(bytes.NewReader(buf), int64(len(buf)))
+			r.Validate = true
+
+			if !checkFile(t, r, f) {
+				t.Errorf("%s file mismatch", version)
+				return false
+			}
+		}
+
+		return true
+	}, &quick.Config{MaxCount: 500})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRoundTripMmap(t *testing.T) {
+	dir := t.TempDir()
+
+	err := quick.Check(func(f randomFile) bool {
+		buf, err := writeFile(f, schema.V5)
+
+		if err != nil {
+			t.Errorf("fail to write file, %s", err)
+			return false
+		}
+
+		path := filepath.Join(dir, "roundtrip.arrow")
+
+		if err := os.WriteFile(path, buf, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := OpenMmap(path)
+
+		if err != nil {
+			t.Errorf("fail to map file, %s", err)
+			return false
+		}
+
+		r.Validate = true
+
+		return checkFile(t, r, f)
+	}, nil)
+
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,202 @@
+package file
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/flier/arrow/schema/vector"
+)
+
+type ScanOptions struct {
+	// The number of record batches decoded concurrently, runtime.GOMAXPROCS(0) if not positive.
+	Parallelism int
+
+	// Deliver the record batches in file order instead of as soon as they are decoded.
+	Ordered bool
+}
+
+type scanResult struct {
+	index int
+	batch *vector.RecordBatch
+	err   error
+}
+
+// Decode the record batches on a pool of workers and pass them to fn with their index in the file.
+//
+// fn is called from the calling goroutine, one batch at a time, and the batch is released when it returns,
+// Retain it to keep it longer. The scan stops at the first error returned by fn or raised by decoding,
+// or when the context is cancelled.
+func (r *Reader) Scan(ctx context.Context, opts ScanOptions, fn func(index int, batch *vector.RecordBatch) error) error {
+	n, err := r.NumRecordBatches()
+
+	if err != nil {
+		return err
+	}
+
+	// load the dictionaries once before the workers share them
+	if _, err := r.ReadDictionaries(); err != nil {
+		return err
+	}
+
+	parallelism := opts.Parallelism
+
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+
+	if parallelism > n {
+		parallelism = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// every dispatched batch holds a token until it is delivered, bounding the batches in flight
+	tokens := make(chan struct{}, 2*parallelism)
+	indexes := make(chan int)
+	results := make(chan scanResult, cap(tokens))
+
+	go func() {
+		defer close(indexes)
+
+		for i := 0; i < n; i++ {
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				batch, err := r.RecordBatch(index)
+
+				results <- scanResult{index, batch, err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	err = deliver(ctx, opts.Ordered, results, tokens, fn)
+
+	cancel()
+
+	for res := range results {
+		if res.batch != nil {
+			res.batch.Release()
+		}
+	}
+
+	return err
+}
+
+func deliver(ctx context.Context, ordered bool, results <-chan scanResult, tokens <-chan struct{}, fn func(int, *vector.RecordBatch) error) error {
+	pending := make(map[int]*vector.RecordBatch)
+
+	defer func() {
+		for _, batch := range pending {
+			batch.Release()
+		}
+	}()
+
+	call := func(index int, batch *vector.RecordBatch) error {
+		defer batch.Release()
+
+		<-tokens
+
+		// the results may be ready along with the cancellation, which is checked first
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return fn(index, batch)
+	}
+
+	next := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case res, ok := <-results:
+			if !ok {
+				return nil
+			}
+
+			if res.err != nil {
+				return res.err
+			}
+
+			if !ordered {
+				if err := call(res.index, res.batch); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			pending[res.index] = res.batch
+
+			for {
+				batch, found := pending[next]
+
+				if !found {
+					break
+				}
+
+				delete(pending, next)
+
+				if err := call(next, batch); err != nil {
+					return err
+				}
+
+				next++
+			}
+		}
+	}
+}
+
+// Read all the record batches of the file in order, decoding them on parallelism workers.
+//
+// The caller owns the returned batches and should release them.
+func (r *Reader) ReadAll(ctx context.Context, parallelism int) ([]*vector.RecordBatch, error) {
+	var batches []*vector.RecordBatch
+
+	err := r.Scan(ctx, ScanOptions{Parallelism: parallelism, Ordered: true}, func(index int, batch *vector.RecordBatch) error {
+		batch.Retain()
+
+		batches = append(batches, batch)
+
+		return nil
+	})
+
+	if err != nil {
+		for _, batch := range batches {
+			batch.Release()
+		}
+
+		return nil, err
+	}
+
+	return batches, nil
+}
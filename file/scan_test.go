@@ -0,0 +1,240 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// countingAllocator tracks the buffers allocated and not freed yet.
+type countingAllocator struct {
+	live int64
+}
+
+func (a *countingAllocator) Allocate(size int) []byte {
+	atomic.AddInt64(&a.live, 1)
+
+	return make([]byte, size)
+}
+
+func (a *countingAllocator) Free(buf []byte) {
+	atomic.AddInt64(&a.live, -1)
+}
+
+// Write a file of n record batches with an Int column, the i-th batch has the single value i.
+func scanFile(t *testing.T, n int) []byte {
+	s := &schema.Schema{Fields: []*schema.Field{
+		{Name: "i", Type: schema.NewInt(64, true), Layout: newLayout(vector.ValidityVector, vector.Value64Vector)},
+	}}
+
+	out := &writeSeeker{}
+	w := NewWriter(out, s)
+
+	for i := 0; i < n; i++ {
+		values := memory.NewBuffer(make([]byte, 8))
+		values.PutBigInt(0, int64(i))
+
+		batch := &vector.RecordBatch{
+			Length:  1,
+			Nodes:   []*vector.FieldNode{{Length: 1}},
+			Buffers: []*memory.Buffer{memory.NewBuffer(nil), values},
+			Layouts: []*vector.Buffer{{Offset: 0, Size: 0}, {Offset: 0, Size: 8}},
+		}
+
+		if err := w.WriteRecordBatch(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.buf
+}
+
+// Returns a reader of the file and a check that every batch is released and every worker is gone.
+func scanReader(t *testing.T, n int) (*Reader, func()) {
+	buf := scanFile(t, n)
+	allocator := &countingAllocator{}
+	goroutines := runtime.NumGoroutine()
+
+	r := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	r.Allocator = allocator
+	r.Validate = true
+
+	return r, func() {
+		t.Helper()
+
+		// the workers may still be exiting after Scan returns
+		for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if n := runtime.NumGoroutine(); n > goroutines {
+			t.Errorf("%d goroutines leaked", n-goroutines)
+		}
+
+		if live := atomic.LoadInt64(&allocator.live); live != 0 {
+			t.Errorf("%d batches are not released", live)
+		}
+	}
+}
+
+func TestScanOrdered(t *testing.T) {
+	r, check := scanReader(t, 50)
+
+	var indexes []int
+
+	err := r.Scan(context.Background(), ScanOptions{Parallelism: 4, Ordered: true}, func(index int, batch *vector.RecordBatch) error {
+		if v := batch.Buffers[1].BigInt(0); v != int64(index) {
+			t.Errorf("batch %d has value %d", index, v)
+		}
+
+		indexes = append(indexes, index)
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, index := range indexes {
+		if index != i {
+			t.Fatalf("deliver batch %d at %d", index, i)
+		}
+	}
+
+	if len(indexes) != 50 {
+		t.Errorf("deliver %d batches, expected 50", len(indexes))
+	}
+
+	check()
+}
+
+func TestScanUnordered(t *testing.T) {
+	r, check := scanReader(t, 50)
+
+	seen := make(map[int]int)
+
+	err := r.Scan(context.Background(), ScanOptions{Parallelism: 8}, func(index int, batch *vector.RecordBatch) error {
+		if v := batch.Buffers[1].BigInt(0); v != int64(index) {
+			t.Errorf("batch %d has value %d", index, v)
+		}
+
+		seen[index]++
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		if seen[i] != 1 {
+			t.Errorf("deliver batch %d %d times", i, seen[i])
+		}
+	}
+
+	if len(seen) != 50 {
+		t.Errorf("deliver %d batches, expected 50", len(seen))
+	}
+
+	check()
+}
+
+func TestScanCancel(t *testing.T) {
+	for _, ordered := range []bool{true, false} {
+		r, check := scanReader(t, 100)
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+
+		err := r.Scan(ctx, ScanOptions{Parallelism: 4, Ordered: ordered}, func(index int, batch *vector.RecordBatch) error {
+			if calls++; calls == 10 {
+				cancel()
+			}
+
+			return nil
+		})
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("scan with error %v, expected it cancelled", err)
+		}
+
+		if calls != 10 {
+			t.Errorf("deliver %d batches after cancelled at 10", calls)
+		}
+
+		check()
+	}
+}
+
+func TestScanError(t *testing.T) {
+	stop := errors.New("stop")
+
+	for _, ordered := range []bool{true, false} {
+		r, check := scanReader(t, 100)
+		calls := 0
+
+		err := r.Scan(context.Background(), ScanOptions{Parallelism: 4, Ordered: ordered}, func(index int, batch *vector.RecordBatch) error {
+			if calls++; calls == 5 {
+				return stop
+			}
+
+			return nil
+		})
+
+		if err != stop {
+			t.Errorf("scan with error %v, expected %s", err, stop)
+		}
+
+		if calls != 5 {
+			t.Errorf("deliver %d batches after failed at 5", calls)
+		}
+
+		check()
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	r, check := scanReader(t, 20)
+
+	batches, err := r.ReadAll(context.Background(), 3)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 20 {
+		t.Fatalf("read %d batches, expected 20", len(batches))
+	}
+
+	for i, batch := range batches {
+		if v := batch.Buffers[1].BigInt(0); v != int64(i) {
+			t.Errorf("batch %d has value %d", i, v)
+		}
+
+		batch.Release()
+	}
+
+	check()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if batches, err := r.ReadAll(ctx, 3); err == nil || batches != nil {
+		t.Errorf("read %d batches with a cancelled context", len(batches))
+	}
+
+	check()
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	fb "github.com/google/flatbuffers/go"
 
+	v5 "github.com/flier/arrow/flatbuf/v5"
 	"github.com/flier/arrow/schema"
 	"github.com/flier/arrow/schema/vector"
 )
@@ -16,13 +18,39 @@ var (
 	errInvalidRecordBatch = errors.New("invalid recordBatch")
 )
 
+var (
+	builders = sync.Pool{
+		New: func() interface{} { return fb.NewBuilder(1024) },
+	}
+
+	zeros [4096]byte
+)
+
 type Writer struct {
+	// The metadata version of the file, schema.V5 unless changed before the first write.
+	Version schema.MetadataVersion
+
+	// The codec compressing the buffers of the batches written afterwards, which requires schema.V5.
+	Compression Compression
+
 	out           io.WriteSeeker
 	schema        *schema.Schema
+	dictionaries  []*Block
 	recordBatches []*Block
 	pos           int64
 }
 
+func NewWriter(out io.WriteSeeker, s *schema.Schema) *Writer {
+	return &Writer{Version: schema.V5, out: out, schema: s}
+}
+
+// The header of a message, which can be encoded in every metadata version.
+type header interface {
+	schema.Marshaler
+
+	MarshalV5(builder *fb.Builder) (fb.UOffsetT, error)
+}
+
 // align on 8 byte boundaries
 func (w *Writer) align() error {
 	if w.pos%8 != 0 {
@@ -33,69 +61,204 @@ func (w *Writer) align() error {
 }
 
 func (w *Writer) WriteRecordBatch(batch *vector.RecordBatch) error {
-	if w.pos == 0 {
-		if err := w.writeMagic(); err != nil {
-			return err
-		}
+	batch, err := w.compress(batch)
+
+	if err != nil {
+		return err
 	}
 
-	if err := w.align(); err != nil {
+	block, err := w.writeBatch(v5.MessageHeaderRecordBatch, batch, batch)
+
+	if err != nil {
 		return err
 	}
 
-	// write metadata header
+	w.recordBatches = append(w.recordBatches, block)
 
-	off := w.pos
+	return nil
+}
 
-	if err := w.Marshal(batch); err != nil {
+// Write the values of the dictionary with the id, referred to by the fields of the schema encoded with it.
+func (w *Writer) WriteDictionaryBatch(id int64, batch *vector.RecordBatch) error {
+	batch, err := w.compress(batch)
+
+	if err != nil {
 		return err
 	}
 
-	if err := w.align(); err != nil {
+	block, err := w.writeBatch(v5.MessageHeaderDictionaryBatch, &vector.DictionaryBatch{ID: id, Data: batch}, batch)
+
+	if err != nil {
 		return err
 	}
 
-	// write body
+	w.dictionaries = append(w.dictionaries, block)
 
-	bodyOffset := w.pos
+	return nil
+}
+
+// Returns the batch with its buffers compressed by the codec of the writer.
+func (w *Writer) compress(batch *vector.RecordBatch) (*vector.RecordBatch, error) {
+	if w.Compression == Uncompressed {
+		return batch, nil
+	}
+
+	if w.Version != schema.V5 {
+		return nil, fmt.Errorf("compression is not supported by %s metadata", w.Version)
+	}
+
+	if len(batch.Buffers) != len(batch.Layouts) {
+		return nil, errors.New("the layout does not match buffers")
+	}
+
+	return compressRecordBatch(w.Compression, batch)
+}
+
+// Write the metadata header followed by the body of the batch, returns the block of the file it spans.
+func (w *Writer) writeBatch(headerType byte, h header, batch *vector.RecordBatch) (*Block, error) {
+	if err := w.writeHeader(); err != nil {
+		return nil, err
+	}
 
 	if len(batch.Buffers) != len(batch.Layouts) {
-		return errors.New("the layout does not match buffers")
+		return nil, errors.New("the layout does not match buffers")
+	}
+
+	// write metadata header
+
+	off := w.pos
+
+	var bodyLength int64
+
+	for _, layout := range batch.Layouts {
+		if end := align(layout.Offset + layout.Size); end > bodyLength {
+			bodyLength = end
+		}
+	}
+
+	if w.Version == schema.V1 {
+		if err := w.Marshal(h); err != nil {
+			return nil, err
+		}
+	} else if err := w.writeMessage(headerType, h, bodyLength); err != nil {
+		return nil, err
+	}
+
+	if err := w.align(); err != nil {
+		return nil, err
 	}
 
+	// write body
+
+	bodyOffset := w.pos
+
 	for i, buffer := range batch.Buffers {
 		layout := batch.Layouts[i]
 
 		startPosition := bodyOffset + layout.Offset
 
 		if err := w.writeZeros(startPosition - w.pos); err != nil {
-			return fmt.Errorf("fail to write pad bytes, %s", err)
+			return nil, fmt.Errorf("fail to write pad bytes, %s", err)
 		}
 
 		if err := w.Write(buffer.Bytes()); err != nil {
-			return fmt.Errorf("fail to write buffer, %s", err)
+			return nil, fmt.Errorf("fail to write buffer, %s", err)
 		}
 
 		if w.pos != startPosition+layout.Size {
-			return fmt.Errorf("wrong buffer size, %d", layout.Size)
+			return nil, fmt.Errorf("wrong buffer size, %d", layout.Size)
 		}
 	}
 
 	metadataLength := bodyOffset - off
 
 	if metadataLength <= 0 {
-		return errInvalidRecordBatch
+		return nil, errInvalidRecordBatch
 	}
 
-	bodyLength := w.pos - bodyOffset
+	if w.Version == schema.V1 {
+		bodyLength = w.pos - bodyOffset
+	} else if err := w.writeZeros(bodyOffset + bodyLength - w.pos); err != nil {
+		return nil, fmt.Errorf("fail to write pad bytes, %s", err)
+	}
 
-	w.recordBatches = append(w.recordBatches, &Block{off, int(metadataLength), bodyLength})
+	return &Block{off, int(metadataLength), bodyLength}, nil
+}
 
-	return nil
+// Write the magic number and, since V4, the schema message before the first batch.
+func (w *Writer) writeHeader() error {
+	if w.pos > 0 {
+		return w.align()
+	}
+
+	switch w.Version {
+	case schema.V1, schema.V4, schema.V5:
+	default:
+		return fmt.Errorf("unsupported metadata version, %s", w.Version)
+	}
+
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+
+	if err := w.align(); err != nil {
+		return err
+	}
+
+	if w.Version == schema.V1 {
+		return nil
+	}
+
+	return w.writeMessage(v5.MessageHeaderSchema, w.schema, 0)
+}
+
+// Write the encapsulated message of the header: a continuation marker,
+// the length of the Message flatbuffer padded to 8 bytes and the flatbuffer itself.
+func (w *Writer) writeMessage(headerType byte, h header, bodyLength int64) error {
+	builder := builders.Get().(*fb.Builder)
+
+	defer builders.Put(builder)
+
+	builder.Reset()
+
+	headerOffset, err := h.MarshalV5(builder)
+
+	if err != nil {
+		return err
+	}
+
+	v5.MessageStart(builder)
+	v5.MessageAddVersion(builder, int16(w.Version))
+	v5.MessageAddHeaderType(builder, headerType)
+	v5.MessageAddHeader(builder, headerOffset)
+	v5.MessageAddBodyLength(builder, bodyLength)
+	builder.Finish(v5.MessageEnd(builder))
+
+	buf := builder.FinishedBytes()
+	size := align(int64(len(buf))+8) - 8
+
+	prefix := make([]byte, 8)
+
+	binary.LittleEndian.PutUint32(prefix, continuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(size))
+
+	if err := w.Write(prefix); err != nil {
+		return fmt.Errorf("fail to write message, %s", err)
+	}
+
+	if err := w.Write(buf); err != nil {
+		return fmt.Errorf("fail to write message, %s", err)
+	}
+
+	return w.writeZeros(size - int64(len(buf)))
 }
 
 func (w *Writer) Marshal(obj schema.Marshaler) error {
-	builder := fb.NewBuilder(1024)
+	builder := builders.Get().(*fb.Builder)
+
+	defer builders.Put(builder)
+
+	builder.Reset()
 
 	off, err := obj.Marshal(builder)
 
@@ -119,6 +282,17 @@ func (w *Writer) Write(buf []byte) error {
 }
 
 func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	if w.Version != schema.V1 {
+		// the end of stream marker
+		if err := w.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}); err != nil {
+			return fmt.Errorf("fail to write end of stream, %s", err)
+		}
+	}
+
 	footerStart := w.pos
 
 	if err := w.writeFooter(); err != nil {
@@ -147,16 +321,28 @@ func (w *Writer) writeMagic() error {
 }
 
 func (w *Writer) writeZeros(n int64) error {
-	if n <= 0 {
-		return nil
+	for n > 0 {
+		size := n
+
+		if size > int64(len(zeros)) {
+			size = int64(len(zeros))
+		}
+
+		if err := w.Write(zeros[:size]); err != nil {
+			return err
+		}
+
+		n -= size
 	}
 
-	return w.Write(make([]byte, n))
+	return nil
 }
 
 func (w *Writer) writeFooter() error {
 	return w.Marshal(&Footer{
+		Version:       w.Version,
 		Schema:        w.schema,
+		Dictionaries:  w.dictionaries,
 		RecordBatches: w.recordBatches,
 	})
 }
@@ -0,0 +1,69 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Optional compression for the memory buffers constituting IPC message
+/// bodies. Intended for use with RecordBatch but could be used for other
+/// message types
+type BodyCompression struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsBodyCompression(buf []byte, offset flatbuffers.UOffsetT) *BodyCompression {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &BodyCompression{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *BodyCompression) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+/// Compressor library.
+/// For LZ4_FRAME, each compressed buffer must consist of a single frame.
+func (rcv *BodyCompression) Codec() int8 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.GetInt8(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+/// Compressor library.
+/// For LZ4_FRAME, each compressed buffer must consist of a single frame.
+func (rcv *BodyCompression) MutateCodec(n int8) bool {
+	return rcv._tab.MutateInt8Slot(4, n)
+}
+
+/// Indicates the way the record batch body was compressed
+func (rcv *BodyCompression) Method() int8 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.GetInt8(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+/// Indicates the way the record batch body was compressed
+func (rcv *BodyCompression) MutateMethod(n int8) bool {
+	return rcv._tab.MutateInt8Slot(6, n)
+}
+
+func BodyCompressionStart(builder *flatbuffers.Builder) {
+	builder.StartObject(2)
+}
+func BodyCompressionAddCodec(builder *flatbuffers.Builder, codec int8) {
+	builder.PrependInt8Slot(0, codec, 0)
+}
+func BodyCompressionAddMethod(builder *flatbuffers.Builder, method int8) {
+	builder.PrependInt8Slot(1, method, 0)
+}
+func BodyCompressionEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -0,0 +1,15 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// Provided for forward compatibility in case we need to support different
+/// strategies for compressing the IPC message body (like whole-body
+/// compression rather than buffer-level) in the future
+const (
+	BodyCompressionMethodBUFFER = 0
+)
+
+var EnumNamesBodyCompressionMethod = map[int]string{
+	BodyCompressionMethodBUFFER:"BUFFER",
+}
+
@@ -0,0 +1,30 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+type Bool struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsBool(buf []byte, offset flatbuffers.UOffsetT) *Bool {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &Bool{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *Bool) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func BoolStart(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func BoolEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
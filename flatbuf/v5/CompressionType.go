@@ -0,0 +1,14 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+const (
+	CompressionTypeLZ4_FRAME = 0
+	CompressionTypeZSTD = 1
+)
+
+var EnumNamesCompressionType = map[int]string{
+	CompressionTypeLZ4_FRAME:"LZ4_FRAME",
+	CompressionTypeZSTD:"ZSTD",
+}
+
@@ -0,0 +1,14 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+const (
+	DateUnitDAY = 0
+	DateUnitMILLISECOND = 1
+)
+
+var EnumNamesDateUnit = map[int]string{
+	DateUnitDAY:"DAY",
+	DateUnitMILLISECOND:"MILLISECOND",
+}
+
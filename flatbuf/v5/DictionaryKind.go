@@ -0,0 +1,17 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// ----------------------------------------------------------------------
+/// Dictionary encoding metadata
+/// Maintained for forwards compatibility, in the future
+/// Dictionaries might be explicit maps between integers and values
+/// allowing for non-contiguous index values
+const (
+	DictionaryKindDenseArray = 0
+)
+
+var EnumNamesDictionaryKind = map[int]string{
+	DictionaryKindDenseArray:"DenseArray",
+}
+
@@ -0,0 +1,45 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+type Duration struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsDuration(buf []byte, offset flatbuffers.UOffsetT) *Duration {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &Duration{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *Duration) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *Duration) Unit() int16 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.GetInt16(o + rcv._tab.Pos)
+	}
+	return 1
+}
+
+func (rcv *Duration) MutateUnit(n int16) bool {
+	return rcv._tab.MutateInt16Slot(4, n)
+}
+
+func DurationStart(builder *flatbuffers.Builder) {
+	builder.StartObject(1)
+}
+func DurationAddUnit(builder *flatbuffers.Builder, unit int16) {
+	builder.PrependInt16Slot(0, unit, 1)
+}
+func DurationEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -0,0 +1,16 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// ----------------------------------------------------------------------
+/// Endianness of the platform producing the data
+const (
+	EndiannessLittle = 0
+	EndiannessBig = 1
+)
+
+var EnumNamesEndianness = map[int]string{
+	EndiannessLittle:"Little",
+	EndiannessBig:"Big",
+}
+
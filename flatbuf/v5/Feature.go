@@ -0,0 +1,29 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// Represents Arrow Features that might not have full support
+/// within implementations. This is intended to be used in
+/// two scenarios:
+///  1.  A mechanism for readers of Arrow Streams
+///      and files to understand that the stream or file makes
+///      use of a feature that isn't supported or unknown to
+///      the implementation (and therefore can meet the Arrow
+///      forward compatibility guarantees).
+///  2.  A means of negotiating between a client and server
+///      what features a stream is allowed to use. The enums
+///      values here are intented to represent higher level
+///      features, additional details maybe negotiated
+///      with key-value pairs specific to the protocol.
+const (
+	FeatureUNUSED = 0
+	FeatureDICTIONARY_REPLACEMENT = 1
+	FeatureCOMPRESSED_BODY = 2
+)
+
+var EnumNamesFeature = map[int]string{
+	FeatureUNUSED:"UNUSED",
+	FeatureDICTIONARY_REPLACEMENT:"DICTIONARY_REPLACEMENT",
+	FeatureCOMPRESSED_BODY:"COMPRESSED_BODY",
+}
+
@@ -0,0 +1,60 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+type Int struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsInt(buf []byte, offset flatbuffers.UOffsetT) *Int {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &Int{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *Int) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *Int) BitWidth() int32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.GetInt32(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *Int) MutateBitWidth(n int32) bool {
+	return rcv._tab.MutateInt32Slot(4, n)
+}
+
+func (rcv *Int) IsSigned() byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.GetByte(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *Int) MutateIsSigned(n byte) bool {
+	return rcv._tab.MutateByteSlot(6, n)
+}
+
+func IntStart(builder *flatbuffers.Builder) {
+	builder.StartObject(2)
+}
+func IntAddBitWidth(builder *flatbuffers.Builder, bitWidth int32) {
+	builder.PrependInt32Slot(0, bitWidth, 0)
+}
+func IntAddIsSigned(builder *flatbuffers.Builder, isSigned byte) {
+	builder.PrependByteSlot(1, isSigned, 0)
+}
+func IntEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
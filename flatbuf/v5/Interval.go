@@ -0,0 +1,45 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+type Interval struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsInterval(buf []byte, offset flatbuffers.UOffsetT) *Interval {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &Interval{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *Interval) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *Interval) Unit() int16 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.GetInt16(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *Interval) MutateUnit(n int16) bool {
+	return rcv._tab.MutateInt16Slot(4, n)
+}
+
+func IntervalStart(builder *flatbuffers.Builder) {
+	builder.StartObject(1)
+}
+func IntervalAddUnit(builder *flatbuffers.Builder, unit int16) {
+	builder.PrependInt16Slot(0, unit, 0)
+}
+func IntervalEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
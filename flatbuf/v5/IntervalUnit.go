@@ -0,0 +1,16 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+const (
+	IntervalUnitYEAR_MONTH = 0
+	IntervalUnitDAY_TIME = 1
+	IntervalUnitMONTH_DAY_NANO = 2
+)
+
+var EnumNamesIntervalUnit = map[int]string{
+	IntervalUnitYEAR_MONTH:"YEAR_MONTH",
+	IntervalUnitDAY_TIME:"DAY_TIME",
+	IntervalUnitMONTH_DAY_NANO:"MONTH_DAY_NANO",
+}
+
@@ -0,0 +1,32 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Same as Binary, but with 64-bit offsets, allowing to represent
+/// extremely large data values.
+type LargeBinary struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsLargeBinary(buf []byte, offset flatbuffers.UOffsetT) *LargeBinary {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &LargeBinary{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *LargeBinary) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func LargeBinaryStart(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func LargeBinaryEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -0,0 +1,32 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Same as List, but with 64-bit offsets, allowing to represent
+/// extremely large data values.
+type LargeList struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsLargeList(buf []byte, offset flatbuffers.UOffsetT) *LargeList {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &LargeList{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *LargeList) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func LargeListStart(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func LargeListEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -0,0 +1,32 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Same as Utf8, but with 64-bit offsets, allowing to represent
+/// extremely large data values.
+type LargeUtf8 struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsLargeUtf8(buf []byte, offset flatbuffers.UOffsetT) *LargeUtf8 {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &LargeUtf8{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *LargeUtf8) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func LargeUtf8Start(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func LargeUtf8End(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
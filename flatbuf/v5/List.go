@@ -0,0 +1,30 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+type List struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsList(buf []byte, offset flatbuffers.UOffsetT) *List {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &List{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *List) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func ListStart(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func ListEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
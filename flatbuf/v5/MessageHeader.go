@@ -0,0 +1,30 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// ----------------------------------------------------------------------
+/// The root Message type
+/// This union enables us to easily send different message types without
+/// redundant storage, and in the future we can easily add new message types.
+///
+/// Arrow implementations do not need to implement all of the message types,
+/// which may include experimental metadata types. For maximum compatibility,
+/// it is best to send data using RecordBatch
+const (
+	MessageHeaderNONE = 0
+	MessageHeaderSchema = 1
+	MessageHeaderDictionaryBatch = 2
+	MessageHeaderRecordBatch = 3
+	MessageHeaderTensor = 4
+	MessageHeaderSparseTensor = 5
+)
+
+var EnumNamesMessageHeader = map[int]string{
+	MessageHeaderNONE:"NONE",
+	MessageHeaderSchema:"Schema",
+	MessageHeaderDictionaryBatch:"DictionaryBatch",
+	MessageHeaderRecordBatch:"RecordBatch",
+	MessageHeaderTensor:"Tensor",
+	MessageHeaderSparseTensor:"SparseTensor",
+}
+
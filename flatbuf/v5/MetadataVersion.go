@@ -0,0 +1,29 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// Logical types, vector layouts, and schemas
+///
+/// Format Version History.
+///
+/// - V1 (0.1.0): initial version, field layouts are part of the metadata.
+/// - V2 (0.2.0): layouts are removed, derived from the types.
+/// - V3 (0.3.0): padding and alignment of buffers to 8 bytes.
+/// - V4 (>= 0.8.0): record batch lengths and field nodes are 64 bit.
+/// - V5 (>= 1.0.0): unions have no validity bitmap.
+const (
+	MetadataVersionV1 = 0
+	MetadataVersionV2 = 1
+	MetadataVersionV3 = 2
+	MetadataVersionV4 = 3
+	MetadataVersionV5 = 4
+)
+
+var EnumNamesMetadataVersion = map[int]string{
+	MetadataVersionV1:"V1",
+	MetadataVersionV2:"V2",
+	MetadataVersionV3:"V3",
+	MetadataVersionV4:"V4",
+	MetadataVersionV5:"V5",
+}
+
@@ -0,0 +1,16 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+const (
+	PrecisionHALF = 0
+	PrecisionSINGLE = 1
+	PrecisionDOUBLE = 2
+)
+
+var EnumNamesPrecision = map[int]string{
+	PrecisionHALF:"HALF",
+	PrecisionSINGLE:"SINGLE",
+	PrecisionDOUBLE:"DOUBLE",
+}
+
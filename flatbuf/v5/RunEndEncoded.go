@@ -0,0 +1,34 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Contains two child arrays, run_ends and values.
+/// The run_ends child array must be a 16/32/64-bit integer array
+/// which encodes the indices at which the run with the value in
+/// each corresponding index in the values child array ends.
+type RunEndEncoded struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsRunEndEncoded(buf []byte, offset flatbuffers.UOffsetT) *RunEndEncoded {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &RunEndEncoded{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *RunEndEncoded) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func RunEndEncodedStart(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func RunEndEncodedEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
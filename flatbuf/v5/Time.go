@@ -0,0 +1,67 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Time is either a 32-bit or 64-bit signed integer type representing an
+/// elapsed time since midnight, stored in either of four units: seconds,
+/// milliseconds, microseconds or nanoseconds.
+///
+/// The integer `bitWidth` depends on the `unit` and must be one of the following:
+/// * SECOND and MILLISECOND: 32 bits
+/// * MICROSECOND and NANOSECOND: 64 bits
+type Time struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsTime(buf []byte, offset flatbuffers.UOffsetT) *Time {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &Time{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *Time) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *Time) Unit() int16 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.GetInt16(o + rcv._tab.Pos)
+	}
+	return 1
+}
+
+func (rcv *Time) MutateUnit(n int16) bool {
+	return rcv._tab.MutateInt16Slot(4, n)
+}
+
+func (rcv *Time) BitWidth() int32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.GetInt32(o + rcv._tab.Pos)
+	}
+	return 32
+}
+
+func (rcv *Time) MutateBitWidth(n int32) bool {
+	return rcv._tab.MutateInt32Slot(6, n)
+}
+
+func TimeStart(builder *flatbuffers.Builder) {
+	builder.StartObject(2)
+}
+func TimeAddUnit(builder *flatbuffers.Builder, unit int16) {
+	builder.PrependInt16Slot(0, unit, 1)
+}
+func TimeAddBitWidth(builder *flatbuffers.Builder, bitWidth int32) {
+	builder.PrependInt32Slot(1, bitWidth, 32)
+}
+func TimeEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -0,0 +1,67 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+/// ----------------------------------------------------------------------
+/// Top-level Type value, enabling extensible type-specific metadata. We can
+/// add new logical types to Type without breaking backwards compatibility
+const (
+	TypeNONE = 0
+	TypeNull = 1
+	TypeInt = 2
+	TypeFloatingPoint = 3
+	TypeBinary = 4
+	TypeUtf8 = 5
+	TypeBool = 6
+	TypeDecimal = 7
+	TypeDate = 8
+	TypeTime = 9
+	TypeTimestamp = 10
+	TypeInterval = 11
+	TypeList = 12
+	TypeStruct_ = 13
+	TypeUnion = 14
+	TypeFixedSizeBinary = 15
+	TypeFixedSizeList = 16
+	TypeMap = 17
+	TypeDuration = 18
+	TypeLargeBinary = 19
+	TypeLargeUtf8 = 20
+	TypeLargeList = 21
+	TypeRunEndEncoded = 22
+	TypeBinaryView = 23
+	TypeUtf8View = 24
+	TypeListView = 25
+	TypeLargeListView = 26
+)
+
+var EnumNamesType = map[int]string{
+	TypeNONE:"NONE",
+	TypeNull:"Null",
+	TypeInt:"Int",
+	TypeFloatingPoint:"FloatingPoint",
+	TypeBinary:"Binary",
+	TypeUtf8:"Utf8",
+	TypeBool:"Bool",
+	TypeDecimal:"Decimal",
+	TypeDate:"Date",
+	TypeTime:"Time",
+	TypeTimestamp:"Timestamp",
+	TypeInterval:"Interval",
+	TypeList:"List",
+	TypeStruct_:"Struct_",
+	TypeUnion:"Union",
+	TypeFixedSizeBinary:"FixedSizeBinary",
+	TypeFixedSizeList:"FixedSizeList",
+	TypeMap:"Map",
+	TypeDuration:"Duration",
+	TypeLargeBinary:"LargeBinary",
+	TypeLargeUtf8:"LargeUtf8",
+	TypeLargeList:"LargeList",
+	TypeRunEndEncoded:"RunEndEncoded",
+	TypeBinaryView:"BinaryView",
+	TypeUtf8View:"Utf8View",
+	TypeListView:"ListView",
+	TypeLargeListView:"LargeListView",
+}
+
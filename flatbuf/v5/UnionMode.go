@@ -0,0 +1,14 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+const (
+	UnionModeSparse = 0
+	UnionModeDense = 1
+)
+
+var EnumNamesUnionMode = map[int]string{
+	UnionModeSparse:"Sparse",
+	UnionModeDense:"Dense",
+}
+
@@ -0,0 +1,31 @@
+// automatically generated by the FlatBuffers compiler, do not modify
+
+package v5
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+/// Unicode with UTF-8 encoding
+type Utf8 struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsUtf8(buf []byte, offset flatbuffers.UOffsetT) *Utf8 {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &Utf8{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func (rcv *Utf8) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func Utf8Start(builder *flatbuffers.Builder) {
+	builder.StartObject(0)
+}
+func Utf8End(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
@@ -0,0 +1,335 @@
+// Package record generates the marshalers of Go struct types to and from record batches,
+// specialized to the fields of each type, without reflection or interface{} boxing.
+//
+// The generated code uses the column builders and readers of the arrow package,
+// and the schema derived by schema.FromStruct, so it produces the same batches as arrow.Marshal.
+// The fields must be of the primitive types
+//
+//	bool, int8, ..., int64, int, uint8, ..., uint64, uint, float32, float64,
+//	string, []byte, [N]byte, time.Time, time.Duration
+//
+// or pointers to them. A named type declared in the package is marshaled by the kind of its underlying type
+// as schema.FromStruct does, so a named time.Duration is an int64 and a named time.Time is not supported.
+// The named types of other packages are not resolved. Lists, maps, nested and embedded structs
+// and dictionary encoded fields are left to arrow.Marshal.
+package record
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type model struct {
+	Package string
+	Types   []*structModel
+}
+
+type structModel struct {
+	Name   string
+	Fields []*fieldModel
+}
+
+func (m *structModel) Schema() string     { return name("", m.Name, "Schema") }
+func (m *structModel) SchemaVar() string  { return lowerFirst(m.Name) + "ArrowSchema" }
+func (m *structModel) NewBuilder() string { return name("New", m.Name, "Builder") }
+func (m *structModel) Append() string     { return name("Append", m.Name, "") }
+func (m *structModel) Read() string       { return name("Read", m.Name, "") }
+
+type fieldModel struct {
+	Name     string
+	Index    int    // the index of the field in the schema
+	Method   string // the suffix of the Append and accessor methods
+	Type     string // the Go type without the pointer
+	Pointer  bool
+	Nullable bool
+
+	conv  string // the conversion of the accessor result to the Go type, if any
+	array bool
+}
+
+// The argument of the Append method of the field of v.
+func (f *fieldModel) Arg() string {
+	x := "v." + f.Name
+
+	switch {
+	case f.array:
+		return x + "[:]" // a pointer to an array is sliced as well
+	case f.Pointer:
+		x = "*" + x
+	}
+
+	if f.conv != "" { // int, uint and named types are appended as the types of the Append methods
+		if f.Method == "Bytes" {
+			return "[]byte(" + x + ")"
+		}
+
+		return strings.ToLower(f.Method) + "(" + x + ")"
+	}
+
+	return x
+}
+
+// The statements reading the value of the field into x, declared by them if it is a pointer.
+func (f *fieldModel) Read(x string) string {
+	get := fmt.Sprintf("columns[%d].%s(i)", f.Index, f.Method)
+
+	if f.array {
+		if f.Pointer {
+			return fmt.Sprintf("var %s %s\ncopy(%s[:], %s)", x, f.Type, x, get)
+		}
+
+		return fmt.Sprintf("copy(%s[:], %s)", x, get)
+	}
+
+	if f.conv != "" {
+		get = f.conv + "(" + get + ")"
+	}
+
+	if f.Pointer {
+		return fmt.Sprintf("%s := %s", x, get)
+	}
+
+	return fmt.Sprintf("%s = %s", x, get)
+}
+
+// The accessor methods and conversions of the primitive Go types.
+var primitives = map[string]struct{ method, conv string }{
+	"bool":          {"Bool", ""},
+	"int8":          {"Int8", ""},
+	"int16":         {"Int16", ""},
+	"int32":         {"Int32", ""},
+	"rune":          {"Int32", ""},
+	"int64":         {"Int64", ""},
+	"int":           {"Int64", "int"},
+	"uint8":         {"Uint8", ""},
+	"byte":          {"Uint8", ""},
+	"uint16":        {"Uint16", ""},
+	"uint32":        {"Uint32", ""},
+	"uint64":        {"Uint64", ""},
+	"uint":          {"Uint64", "uint"},
+	"float32":       {"Float32", ""},
+	"float64":       {"Float64", ""},
+	"string":        {"String", ""},
+	"[]byte":        {"Bytes", ""},
+	"[]uint8":       {"Bytes", ""},
+	"time.Time":     {"Time", ""},
+	"time.Duration": {"Duration", ""},
+}
+
+// Generate the marshalers of the named struct types, declared in the files of the package.
+func Generate(w io.Writer, pkg string, files []*ast.File, types []string) error {
+	m := &model{Package: pkg}
+
+	for _, typeName := range types {
+		var spec *ast.StructType
+
+		if ts := findType(files, typeName); ts != nil {
+			spec, _ = ts.Type.(*ast.StructType)
+		}
+
+		if spec == nil {
+			return fmt.Errorf("struct %s not found in package %s", typeName, pkg)
+		}
+
+		s, err := newStructModel(typeName, spec, files)
+
+		if err != nil {
+			return err
+		}
+
+		m.Types = append(m.Types, s)
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, m); err != nil {
+		return fmt.Errorf("fail to generate code, %s", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		return fmt.Errorf("fail to format generated code, %s", err)
+	}
+
+	_, err = w.Write(src)
+
+	return err
+}
+
+// Returns the declaration of the named type in the files, or nil if it is not found.
+func findType(files []*ast.File, typeName string) *ast.TypeSpec {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+
+				if ts.Name.Name == typeName {
+					return ts
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create the model of the fields, skipped and numbered as schema.FromStruct does.
+func newStructModel(typeName string, st *ast.StructType, files []*ast.File) (*structModel, error) {
+	s := &structModel{Name: typeName}
+
+	for _, field := range st.Fields.List {
+		var tag string
+
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+
+			if err != nil {
+				return nil, fmt.Errorf("field of %s has invalid tag %s", typeName, field.Tag.Value)
+			}
+
+			tag = reflect.StructTag(unquoted).Get("arrow")
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("embedded field %s of %s is not supported, use arrow.Marshal", typeString(field.Type), typeName)
+		}
+
+		nullable := false
+
+		for _, opt := range strings.Split(tag, ",")[1:] {
+			switch {
+			case opt == "nullable":
+				nullable = true
+			case strings.HasPrefix(opt, "dict"):
+				return nil, fmt.Errorf("dictionary encoded field %s.%s is not supported, use arrow.Marshal", typeName, field.Names[0].Name)
+			}
+		}
+
+		for _, ident := range field.Names {
+			if !ast.IsExported(ident.Name) {
+				continue
+			}
+
+			f, err := newFieldModel(ident.Name, field.Type, files)
+
+			if err != nil {
+				return nil, fmt.Errorf("field %s.%s %s", typeName, ident.Name, err)
+			}
+
+			f.Index = len(s.Fields)
+			f.Nullable = nullable || f.Pointer
+
+			s.Fields = append(s.Fields, f)
+		}
+	}
+
+	return s, nil
+}
+
+func newFieldModel(fieldName string, expr ast.Expr, files []*ast.File) (*fieldModel, error) {
+	f := &fieldModel{Name: fieldName}
+
+	if star, ok := expr.(*ast.StarExpr); ok {
+		f.Pointer = true
+		expr = star.X
+	}
+
+	f.Type = typeString(expr)
+
+	if f.resolve(expr, files, false) {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("has type %s not supported, use arrow.Marshal", f.Type)
+}
+
+// Resolve the accessor method of the type, which is the underlying type of the field type if named.
+func (f *fieldModel) resolve(expr ast.Expr, files []*ast.File, named bool) bool {
+	tp := typeString(expr)
+
+	if p, found := primitives[tp]; found {
+		switch {
+		case !named:
+			f.Method, f.conv = p.method, p.conv
+		case tp == "time.Time":
+			return false
+		case tp == "time.Duration":
+			f.Method, f.conv = "Int64", f.Type
+		default:
+			f.Method, f.conv = p.method, f.Type
+		}
+
+		return true
+	}
+
+	if array, ok := expr.(*ast.ArrayType); ok && array.Len != nil {
+		if elem := typeString(array.Elt); elem == "byte" || elem == "uint8" {
+			f.Method, f.array = "Bytes", true
+
+			return true
+		}
+	}
+
+	if ident, ok := expr.(*ast.Ident); ok {
+		if ts := findType(files, ident.Name); ts != nil {
+			// an alias is the same type, so it needs no conversion
+			return f.resolve(ts.Type, files, named || !ts.Assign.IsValid())
+		}
+	}
+
+	return false
+}
+
+func typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+
+	if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		return fmt.Sprintf("%T", expr)
+	}
+
+	return buf.String()
+}
+
+// Returns the name of a generated function, unexported for unexported types.
+func name(prefix, typeName, suffix string) string {
+	s := prefix + upperFirst(typeName) + suffix
+
+	if !ast.IsExported(typeName) {
+		return lowerFirst(s)
+	}
+
+	return s
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+
+	return string(r)
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+
+	return string(r)
+}
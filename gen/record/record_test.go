@@ -0,0 +1,112 @@
+package record
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, src string) []*ast.File {
+	f, err := parser.ParseFile(token.NewFileSet(), "src.go", src, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []*ast.File{f}
+}
+
+// The generated code of the events of the arrow package is up to date.
+func TestGenerate(t *testing.T) {
+	src, err := os.ReadFile("../../generated_test.go")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile("../../event_arrow_test.go")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := Generate(&buf, "arrow_test", parse(t, string(src)), []string{"Event"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("generated code is out of date, run go generate in the arrow package\n%s", buf.Bytes())
+	}
+}
+
+func TestGenerateUnexported(t *testing.T) {
+	var buf bytes.Buffer
+
+	src := "package p\n\ntype point struct {\n\tX, Y *int32\n\tname string\n}\n"
+
+	if err := Generate(&buf, "p", parse(t, src), []string{"point"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"func appendPoint(", "func readPoint(", "func newPointBuilder(", "func pointSchema(", "b.Columns[1].AppendInt32(*v.Y)"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("generated code has no %s\n%s", s, buf.String())
+		}
+	}
+
+	if strings.Contains(buf.String(), "name") {
+		t.Errorf("generated code refers to unexported field name\n%s", buf.String())
+	}
+}
+
+func TestGenerateNamed(t *testing.T) {
+	var buf bytes.Buffer
+
+	src := "package p\n\ntype Row struct {\n\tTemp *Celsius\n\tWait Timeout\n\tHash Digest\n\tBlob Blob\n\tAt Stamp\n}\n\n" +
+		"type Celsius float64\n\ntype Timeout time.Duration\n\ntype Digest [4]byte\n\ntype Blob []byte\n\ntype Stamp = time.Time\n"
+
+	if err := Generate(&buf, "p", parse(t, src), []string{"Row"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		"b.Columns[0].AppendFloat64(float64(*v.Temp))", "x := Celsius(columns[0].Float64(i))",
+		"b.Columns[1].AppendInt64(int64(v.Wait))", "v.Wait = Timeout(columns[1].Int64(i))",
+		"b.Columns[2].AppendBytes(v.Hash[:])", "copy(v.Hash[:], columns[2].Bytes(i))",
+		"b.Columns[3].AppendBytes([]byte(v.Blob))", "v.Blob = Blob(columns[3].Bytes(i))",
+		"b.Columns[4].AppendTime(v.At)", "v.At = columns[4].Time(i)",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("generated code has no %s\n%s", s, buf.String())
+		}
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"type Row struct {\n\tTags []string\n}", "field Row.Tags has type []string not supported"},
+		{"type Row struct {\n\tName string `arrow:\"name,dict\"`\n}", "dictionary encoded field Row.Name is not supported"},
+		{"type Base struct{}\n\ntype Row struct {\n\tBase\n}", "embedded field Base of Row is not supported"},
+		{"type Other struct{}", "struct Row not found in package p"},
+		{"type Row int", "struct Row not found in package p"},
+		{"type Stamp time.Time\n\ntype Row struct {\n\tAt Stamp\n}", "field Row.At has type Stamp not supported"},
+		{"type Row struct {\n\tAt sql.NullTime\n}", "field Row.At has type sql.NullTime not supported"},
+	}
+
+	for _, test := range tests {
+		err := Generate(&bytes.Buffer{}, "p", parse(t, "package p\n\n"+test.src), []string{"Row"})
+
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("got error %v, expected %s", err, test.err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package record
+
+import (
+	"text/template"
+)
+
+var tmpl = template.Must(template.New("record").Parse(`// Code generated by arrow-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"reflect"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+{{range .Types}}
+var {{.SchemaVar}} = func() *schema.Schema {
+	s, err := schema.FromStruct(reflect.TypeOf({{.Name}}{}))
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}()
+
+// {{.Schema}} returns the schema of the record batches of {{.Name}} values, it must not be modified.
+func {{.Schema}}() *schema.Schema { return {{.SchemaVar}} }
+
+// {{.NewBuilder}} creates a builder of the record batches of {{.Name}} values, appended by {{.Append}}.
+func {{.NewBuilder}}() (*arrow.RecordBuilder, error) { return arrow.NewRecordBuilder({{.SchemaVar}}) }
+
+// {{.Append}} appends the values to the builder created by {{.NewBuilder}}.
+func {{.Append}}(b *arrow.RecordBuilder, values []{{.Name}}) {
+	for i := range values {
+		v := &values[i]
+{{range .Fields}}{{if .Pointer}}
+		if v.{{.Name}} == nil {
+			b.Columns[{{.Index}}].AppendNull()
+		} else {
+			b.Columns[{{.Index}}].Append{{.Method}}({{.Arg}})
+		}
+{{else}}
+		b.Columns[{{.Index}}].Append{{.Method}}({{.Arg}})
+{{end}}{{end}}	}
+}
+
+// {{.Read}} reads the {{.Name}} values of a record batch of {{.Schema}}.
+func {{.Read}}(batch *vector.RecordBatch) ([]{{.Name}}, error) {
+	columns, err := arrow.NewColumns({{.SchemaVar}}, batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]{{.Name}}, batch.Length)
+
+	for i := range values {
+		v := &values[i]
+{{range .Fields}}{{if .Pointer}}
+		if !columns[{{.Index}}].IsNull(i) {
+			{{.Read "x"}}
+			v.{{.Name}} = &x
+		}
+{{else if .Nullable}}
+		if !columns[{{.Index}}].IsNull(i) {
+			{{.Read (printf "v.%s" .Name)}}
+		}
+{{else}}
+		{{.Read (printf "v.%s" .Name)}}
+{{end}}{{end}}	}
+
+	return values, nil
+}
+{{end}}`))
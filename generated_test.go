@@ -0,0 +1,137 @@
+package arrow_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow"
+)
+
+//go:generate go run ./cmd/arrow-gen -type Event -output event_arrow_test.go
+
+type Event struct {
+	ID       int64
+	Kind     string `arrow:"kind"`
+	Source   *string
+	Level    int8
+	Count    uint
+	Ratio    *float32
+	Ok       bool
+	Digest   [4]byte
+	Payload  []byte
+	At       time.Time      `arrow:"at,unit=ms,tz=UTC"`
+	Elapsed  *time.Duration `arrow:"elapsed,unit=us"`
+	Note     string         `arrow:"note,nullable"`
+	Temp     Celsius
+	Grade    *grade
+	internal int
+	Skipped  int `arrow:"-"`
+}
+
+type Celsius float64
+
+type grade int
+
+func events() []Event {
+	source := "sensor"
+	ratio := float32(0.5)
+	elapsed := 1500 * time.Microsecond
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	g := grade(3)
+
+	return []Event{
+		{ID: 1, Kind: "start", Source: &source, Level: -1, Count: 7, Ratio: &ratio, Ok: true,
+			Digest: [4]byte{1, 2, 3, 4}, Payload: []byte("x"), At: at, Elapsed: &elapsed, Note: "first",
+			Temp: -12.5, Grade: &g},
+		{ID: 2, Kind: "stop", Payload: []byte{}, At: at.Add(time.Minute)},
+		{ID: 3, Kind: "", Level: 9, Ok: true, Payload: []byte("yz"), At: at},
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	values := events()
+
+	b, err := NewEventBuilder()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	AppendEvent(b, values)
+
+	batch, err := b.NewRecordBatch()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _, err := arrow.Marshal(values)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(batch.Nodes, expected.Nodes) || !reflect.DeepEqual(batch.Layouts, expected.Layouts) {
+		t.Fatalf("nodes %v and layouts %v, expected %v and %v", batch.Nodes, batch.Layouts, expected.Nodes, expected.Layouts)
+	}
+
+	for i, buf := range batch.Buffers {
+		if !bytes.Equal(buf.Bytes(), expected.Buffers[i].Bytes()) {
+			t.Errorf("buffer %d is %v, expected %v", i, buf.Bytes(), expected.Buffers[i].Bytes())
+		}
+	}
+
+	decoded, err := ReadEvent(batch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("decoded %+v, expected %+v", decoded, values)
+	}
+
+	if next, err := b.NewRecordBatch(); err != nil || next.Length != 0 {
+		t.Errorf("next batch of %v rows, error %v, expected an empty batch", next, err)
+	}
+}
+
+// Returns n rows of the events.
+func manyEvents(n int) []Event {
+	var values []Event
+
+	for len(values) < n {
+		values = append(values, events()...)
+	}
+
+	return values[:n]
+}
+
+func BenchmarkAppendEvent(b *testing.B) {
+	values := manyEvents(1000)
+	builder, err := NewEventBuilder()
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		AppendEvent(builder, values)
+
+		if _, err := builder.NewRecordBatch(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalEvent(b *testing.B) {
+	values := manyEvents(1000)
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := arrow.Marshal(values); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
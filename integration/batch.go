@@ -0,0 +1,681 @@
+package integration
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+type decoder struct {
+	batch *vector.RecordBatch
+	size  int64
+}
+
+func decodeBatch(s *schema.Schema, jb *jsonBatch) (*vector.RecordBatch, error) {
+	if len(jb.Columns) != len(s.Fields) {
+		return nil, fmt.Errorf("%d columns for %d fields", len(jb.Columns), len(s.Fields))
+	}
+
+	d := &decoder{batch: &vector.RecordBatch{Length: jb.Count}}
+
+	for i, field := range s.Fields {
+		if err := d.decodeColumn(field, jb.Columns[i], field.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Validate(d.batch); err != nil {
+		return nil, err
+	}
+
+	return d.batch, nil
+}
+
+// Append a buffer to the body of the batch, at the next 8 byte boundary.
+func (d *decoder) addBuffer(buf []byte) {
+	d.size = (d.size + 7) &^ 7
+
+	d.batch.Buffers = append(d.batch.Buffers, memory.NewBuffer(buf))
+	d.batch.Layouts = append(d.batch.Layouts, &vector.Buffer{Offset: d.size, Size: int64(len(buf))})
+
+	d.size += int64(len(buf))
+}
+
+func (d *decoder) decodeColumn(field *schema.Field, col *jsonColumn, path string) error {
+	if col.Name != field.Name {
+		return fmt.Errorf("column %s does not match field %s", col.Name, path)
+	}
+
+	if field.Layout == nil {
+		return fmt.Errorf("field %s has no layout", path)
+	}
+
+	if col.Validity != nil && len(col.Validity) != col.Count {
+		return fmt.Errorf("column %s has %d validity values, expected %d", path, len(col.Validity), col.Count)
+	}
+
+	nulls := 0
+
+	for _, layout := range field.Layout.Vectors {
+		if layout.Type == vector.Validity {
+			for _, valid := range col.Validity {
+				if valid == 0 {
+					nulls++
+				}
+			}
+
+			break
+		}
+	}
+
+	d.batch.Nodes = append(d.batch.Nodes, &vector.FieldNode{Length: col.Count, NullCount: nulls})
+
+	var values []byte // the values of variable width types, decoded with their offsets
+
+	tp := columnType(field)
+
+	for _, layout := range field.Layout.Vectors {
+		var buf []byte
+		var err error
+
+		switch layout.Type {
+		case vector.Validity:
+			buf = make([]byte, (col.Count+7)/8)
+
+			for i := 0; i < col.Count; i++ {
+				if col.Validity == nil || col.Validity[i] != 0 {
+					buf[i/8] |= 1 << uint(i%8)
+				}
+			}
+
+		case vector.Offset:
+			if isVariableWidth(tp) {
+				buf, values, err = decodeValues(tp, layout.BitWidth, col)
+			} else {
+				buf, err = decodeInts(col.Offset, layout.BitWidth)
+			}
+
+		case vector.Type:
+			buf, err = decodeInts(col.TypeID, layout.BitWidth)
+
+		case vector.Data:
+			if values != nil {
+				buf = values
+			} else {
+				buf, err = decodeData(tp, layout.BitWidth, col)
+			}
+
+		default:
+			err = fmt.Errorf("unknown vector type %s", layout.Type)
+		}
+
+		if err != nil {
+			return fmt.Errorf("column %s, %s", path, err)
+		}
+
+		d.addBuffer(buf)
+	}
+
+	if len(col.Children) != len(field.Children) {
+		return fmt.Errorf("column %s has %d children, expected %d", path, len(col.Children), len(field.Children))
+	}
+
+	for i, child := range field.Children {
+		if err := d.decodeColumn(child, col.Children[i], path+"."+child.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeInts(values []json.RawMessage, bitWidth int) ([]byte, error) {
+	buf := make([]byte, len(values)*bitWidth/8)
+
+	for i, value := range values {
+		var number json.Number
+
+		if err := json.Unmarshal(value, &number); err != nil {
+			return nil, fmt.Errorf("invalid integer value at %d, %s", i, err)
+		}
+
+		n, err := parseInt(number)
+
+		if err != nil {
+			return nil, err
+		}
+
+		putInt(buf, bitWidth, i, n)
+	}
+
+	return buf, nil
+}
+
+func decodeData(t schema.Type, bitWidth int, col *jsonColumn) ([]byte, error) {
+	if len(col.Data) != col.Count {
+		return nil, fmt.Errorf("%d data values, expected %d", len(col.Data), col.Count)
+	}
+
+	switch t.Value() {
+	case flatbuf.TypeBool:
+		buf := make([]byte, (col.Count+7)/8)
+
+		for i, value := range col.Data {
+			var b bool
+
+			if err := json.Unmarshal(value, &b); err != nil {
+				return nil, fmt.Errorf("invalid bool value at %d, %s", i, err)
+			}
+
+			if b {
+				buf[i/8] |= 1 << uint(i%8)
+			}
+		}
+
+		return buf, nil
+
+	case flatbuf.TypeFloatingPoint:
+		buf := make([]byte, col.Count*bitWidth/8)
+
+		for i, value := range col.Data {
+			var f float64
+
+			if err := json.Unmarshal(value, &f); err != nil {
+				return nil, fmt.Errorf("invalid floating point value at %d, %s", i, err)
+			}
+
+			switch bitWidth {
+			case 16:
+				binary.LittleEndian.PutUint16(buf[i*2:], floatToHalf(float32(f)))
+			case 32:
+				binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(f)))
+			case 64:
+				binary.LittleEndian.PutUint64(buf[i*8:], math.Float64bits(f))
+			default:
+				return nil, fmt.Errorf("unsupported floating point width %d", bitWidth)
+			}
+		}
+
+		return buf, nil
+
+	case flatbuf.TypeDecimal:
+		size := bitWidth / 8
+		buf := make([]byte, col.Count*size)
+
+		for i, value := range col.Data {
+			var s string
+
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, fmt.Errorf("invalid decimal value at %d, %s", i, err)
+			}
+
+			n, ok := new(big.Int).SetString(s, 10)
+
+			if !ok || !putDecimal(buf[i*size:(i+1)*size], n) {
+				return nil, fmt.Errorf("invalid decimal value %q at %d", s, i)
+			}
+		}
+
+		return buf, nil
+
+	case v5.TypeFixedSizeBinary:
+		size := bitWidth / 8
+		buf := make([]byte, 0, col.Count*size)
+
+		for i, value := range col.Data {
+			var s string
+
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, fmt.Errorf("invalid binary value at %d, %s", i, err)
+			}
+
+			b, err := hex.DecodeString(s)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid binary value at %d, %s", i, err)
+			}
+
+			if len(b) != size {
+				return nil, fmt.Errorf("binary value of %d bytes at %d, expected %d", len(b), i, size)
+			}
+
+			buf = append(buf, b...)
+		}
+
+		return buf, nil
+
+	default:
+		if bitWidth%8 != 0 || bitWidth > 64 {
+			return nil, fmt.Errorf("unsupported data width %d", bitWidth)
+		}
+
+		buf := make([]byte, col.Count*bitWidth/8)
+
+		for i, value := range col.Data {
+			var n json.Number
+
+			if err := json.Unmarshal(value, &n); err != nil {
+				return nil, fmt.Errorf("invalid integer value at %d, %s", i, err)
+			}
+
+			v, err := parseInt(n)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer value at %d, %s", i, err)
+			}
+
+			putInt(buf, bitWidth, i, v)
+		}
+
+		return buf, nil
+	}
+}
+
+// Returns whether the values of the type are located by offsets into the data.
+func isVariableWidth(t schema.Type) bool {
+	switch t.Value() {
+	case flatbuf.TypeUtf8, flatbuf.TypeBinary, v5.TypeLargeUtf8, v5.TypeLargeBinary:
+		return true
+	default:
+		return false
+	}
+}
+
+// Decode the strings of a variable width column, returns the offsets computed from their lengths and the bytes.
+func decodeValues(t schema.Type, bitWidth int, col *jsonColumn) (offsets, buf []byte, err error) {
+	if len(col.Data) != col.Count {
+		return nil, nil, fmt.Errorf("%d data values, expected %d", len(col.Data), col.Count)
+	}
+
+	offsets = make([]byte, (col.Count+1)*bitWidth/8)
+	buf = []byte{}
+
+	for i, value := range col.Data {
+		var s string
+
+		if err := json.Unmarshal(value, &s); err != nil {
+			return nil, nil, fmt.Errorf("invalid string value at %d, %s", i, err)
+		}
+
+		if tp := t.Value(); tp == flatbuf.TypeBinary || tp == v5.TypeLargeBinary {
+			b, err := hex.DecodeString(s)
+
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid binary value at %d, %s", i, err)
+			}
+
+			buf = append(buf, b...)
+		} else {
+			buf = append(buf, s...)
+		}
+
+		putInt(offsets, bitWidth, i+1, uint64(len(buf)))
+	}
+
+	return offsets, buf, nil
+}
+
+// Parse a signed or unsigned integer, returns its two's complement bits.
+func parseInt(n json.Number) (uint64, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return uint64(i), nil
+	}
+
+	return strconv.ParseUint(string(n), 10, 64)
+}
+
+func putInt(buf []byte, bitWidth, i int, v uint64) {
+	size := bitWidth / 8
+
+	for j := 0; j < size; j++ {
+		buf[i*size+j] = byte(v >> uint(8*j))
+	}
+}
+
+func getInt(buf []byte, bitWidth, i int, signed bool) int64 {
+	size := bitWidth / 8
+
+	var v uint64
+
+	for j := 0; j < size; j++ {
+		v |= uint64(buf[i*size+j]) << uint(8*j)
+	}
+
+	if shift := uint(64 - bitWidth); signed && shift > 0 {
+		return int64(v<<shift) >> shift
+	}
+
+	return int64(v)
+}
+
+// Put the two's complement of the decimal in little endian, returns false if it does not fit in the buffer.
+func putDecimal(buf []byte, n *big.Int) bool {
+	negative := n.Sign() < 0
+
+	if negative {
+		n = new(big.Int).Not(n) // -n-1, which has the complemented bits of n
+	}
+
+	if n.BitLen() >= 8*len(buf) {
+		return false
+	}
+
+	b := n.Bytes()
+
+	for j := range buf {
+		var v byte
+
+		if j < len(b) {
+			v = b[len(b)-1-j]
+		}
+
+		if negative {
+			v = ^v
+		}
+
+		buf[j] = v
+	}
+
+	return true
+}
+
+// Returns the decimal of the two's complement in little endian.
+func getDecimal(buf []byte) *big.Int {
+	b := make([]byte, len(buf))
+
+	for j, v := range buf {
+		b[len(buf)-1-j] = v
+	}
+
+	n := new(big.Int).SetBytes(b)
+
+	if len(buf) > 0 && buf[len(buf)-1]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(buf))))
+	}
+
+	return n
+}
+
+type encoder struct {
+	batch  *vector.RecordBatch
+	node   int
+	buffer int
+}
+
+func encodeBatch(s *schema.Schema, batch *vector.RecordBatch) (*jsonBatch, error) {
+	if err := s.Validate(batch); err != nil {
+		return nil, err
+	}
+
+	e := &encoder{batch: batch}
+	jb := &jsonBatch{Count: batch.Length, Columns: []*jsonColumn{}}
+
+	for _, field := range s.Fields {
+		col, err := e.encodeColumn(field, field.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		jb.Columns = append(jb.Columns, col)
+	}
+
+	return jb, nil
+}
+
+// Returns the type of the values of a column, the indices of a dictionary encoded field
+// or the storage type of an extension type.
+func columnType(field *schema.Field) schema.Type {
+	if field.Dictionary != nil {
+		return field.Dictionary.Index()
+	}
+
+	return schema.StorageType(field.Type)
+}
+
+func (e *encoder) encodeColumn(field *schema.Field, path string) (*jsonColumn, error) {
+	node := e.batch.Nodes[e.node]
+	e.node++
+
+	vectors := field.Layout.Vectors
+	buffers := e.batch.Buffers[e.buffer : e.buffer+len(vectors)]
+	e.buffer += len(vectors)
+
+	col := &jsonColumn{Name: field.Name, Count: node.Length}
+	tp := columnType(field)
+
+	var offsets []int64
+
+	for i, layout := range vectors {
+		buf := buffers[i].Bytes()
+
+		switch layout.Type {
+		case vector.Validity:
+			col.Validity = make([]int, node.Length)
+
+			for j := range col.Validity {
+				if len(buf) == 0 || buf[j/8]&(1<<uint(j%8)) != 0 {
+					col.Validity[j] = 1
+				}
+			}
+
+		case vector.Offset:
+			n := node.Length + 1
+
+			if tp.Value() == flatbuf.TypeUnion {
+				n = node.Length
+			} else if len(buf) == 0 {
+				n = 0
+			}
+
+			offsets = make([]int64, n)
+			col.Offset = make([]json.RawMessage, n)
+
+			for j := range offsets {
+				offsets[j] = getInt(buf, layout.BitWidth, j, true)
+				col.Offset[j] = formatInt(offsets[j], layout.BitWidth)
+			}
+
+		case vector.Type:
+			col.TypeID = make([]json.RawMessage, node.Length)
+
+			for j := range col.TypeID {
+				col.TypeID[j] = formatInt(getInt(buf, layout.BitWidth, j, true), layout.BitWidth)
+			}
+
+		case vector.Data:
+			data, err := encodeData(tp, layout.BitWidth, buf, offsets, node.Length)
+
+			if err != nil {
+				return nil, fmt.Errorf("column %s, %s", path, err)
+			}
+
+			col.Data = data
+		}
+	}
+
+	for _, child := range field.Children {
+		c, err := e.encodeColumn(child, path+"."+child.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		col.Children = append(col.Children, c)
+	}
+
+	return col, nil
+}
+
+// Returns the JSON number of the integer, 64 bit integers are written as strings.
+func formatInt(n int64, bitWidth int) json.RawMessage {
+	if bitWidth == 64 {
+		return json.RawMessage(strconv.Quote(strconv.FormatInt(n, 10)))
+	}
+
+	return json.RawMessage(strconv.FormatInt(n, 10))
+}
+
+func encodeData(t schema.Type, bitWidth int, buf []byte, offsets []int64, length int) ([]json.RawMessage, error) {
+	data := make([]json.RawMessage, length)
+
+	for i := range data {
+		var value string
+
+		switch t.Value() {
+		case flatbuf.TypeBool:
+			value = strconv.FormatBool(buf[i/8]&(1<<uint(i%8)) != 0)
+
+		case flatbuf.TypeUtf8, v5.TypeLargeUtf8:
+			value = strconv.Quote(string(buf[offsets[i]:offsets[i+1]]))
+
+		case flatbuf.TypeBinary, v5.TypeLargeBinary:
+			value = strconv.Quote(strings.ToUpper(hex.EncodeToString(buf[offsets[i]:offsets[i+1]])))
+
+		case flatbuf.TypeDecimal:
+			size := bitWidth / 8
+
+			value = strconv.Quote(getDecimal(buf[i*size : (i+1)*size]).String())
+
+		case v5.TypeFixedSizeBinary:
+			size := bitWidth / 8
+
+			value = strconv.Quote(strings.ToUpper(hex.EncodeToString(buf[i*size : (i+1)*size])))
+
+		case flatbuf.TypeFloatingPoint:
+			var f float64
+			var size int
+
+			switch bitWidth {
+			case 16:
+				f, size = float64(halfToFloat(binary.LittleEndian.Uint16(buf[i*2:]))), 32
+			case 32:
+				f, size = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))), 32
+			case 64:
+				f, size = math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:])), 64
+			default:
+				return nil, fmt.Errorf("unsupported floating point width %d", bitWidth)
+			}
+
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("floating point value %v at %d is not representable in JSON", f, i)
+			}
+
+			value = strconv.FormatFloat(f, 'g', -1, size)
+
+		default:
+			if bitWidth%8 != 0 || bitWidth > 64 {
+				return nil, fmt.Errorf("unsupported data width %d", bitWidth)
+			}
+
+			signed := true
+
+			if it, ok := t.(*schema.Int); ok {
+				signed = it.Signed
+			}
+
+			n := getInt(buf, bitWidth, i, signed)
+
+			if signed {
+				value = strconv.FormatInt(n, 10)
+			} else {
+				value = strconv.FormatUint(uint64(n), 10)
+			}
+
+			// 64 bit integers are written as strings, they do not fit in the doubles of JSON readers
+			if bitWidth == 64 {
+				value = strconv.Quote(value)
+			}
+		}
+
+		data[i] = json.RawMessage(value)
+	}
+
+	return data, nil
+}
+
+// Returns the single precision value of the half precision bits.
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	switch exp {
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+
+	case 0:
+		f := float32(frac) / (1 << 24) // subnormal, or zero
+
+		if sign != 0 {
+			f = -f
+		}
+
+		return f
+
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+	}
+}
+
+// Returns the half precision bits of the value, rounded to nearest even.
+func floatToHalf(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xff
+	frac := b & 0x7fffff
+
+	if exp == 0xff {
+		if frac != 0 {
+			return sign | 0x7e00
+		}
+
+		return sign | 0x7c00
+	}
+
+	e := exp - 127 + 15
+
+	if e >= 0x1f {
+		return sign | 0x7c00
+	}
+
+	if e <= 0 {
+		if e < -10 {
+			return sign
+		}
+
+		frac |= 0x800000
+
+		shift := uint(14 - e)
+		half := frac >> shift
+		rem := frac & (1<<shift - 1)
+		mid := uint32(1) << (shift - 1)
+
+		if rem > mid || (rem == mid && half&1 == 1) {
+			half++
+		}
+
+		return sign | uint16(half)
+	}
+
+	half := uint32(e)<<10 | frac>>13
+	rem := frac & 0x1fff
+
+	// a carry out of the fraction rounds up the exponent, up to infinity
+	if rem > 0x1000 || (rem == 0x1000 && half&1 == 1) {
+		half++
+	}
+
+	return sign | uint16(half)
+}
@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/flier/arrow/schema"
+)
+
+// Compare the schemas and the values of the batches and dictionaries of two files,
+// returns an error describing the first difference found.
+//
+// The layout of the bodies is not compared, only the values they hold.
+func Compare(expected, actual *File) error {
+	ef, err := marshalFile(expected)
+
+	if err != nil {
+		return fmt.Errorf("fail to encode expected file, %s", err)
+	}
+
+	af, err := marshalFile(actual)
+
+	if err != nil {
+		return fmt.Errorf("fail to encode actual file, %s", err)
+	}
+
+	if err := expected.Schema.Diff(actual.Schema, schema.EqualOptions{}); err != nil {
+		return fmt.Errorf("schema mismatch, %s", err)
+	}
+
+	// the layouts and dictionary ids are only compared in the encoding
+	if !reflect.DeepEqual(ef.Schema, af.Schema) {
+		return fmt.Errorf("schema mismatch")
+	}
+
+	if len(ef.Dictionaries) != len(af.Dictionaries) {
+		return fmt.Errorf("%d dictionaries, expected %d", len(af.Dictionaries), len(ef.Dictionaries))
+	}
+
+	for i, dict := range ef.Dictionaries {
+		if af.Dictionaries[i].ID != dict.ID {
+			return fmt.Errorf("dictionary %d, expected %d", af.Dictionaries[i].ID, dict.ID)
+		}
+
+		if err := compareBatch(dict.Data, af.Dictionaries[i].Data); err != nil {
+			return fmt.Errorf("dictionary %d mismatch, %s", dict.ID, err)
+		}
+	}
+
+	if len(ef.Batches) != len(af.Batches) {
+		return fmt.Errorf("%d batches, expected %d", len(af.Batches), len(ef.Batches))
+	}
+
+	for i, batch := range ef.Batches {
+		if err := compareBatch(batch, af.Batches[i]); err != nil {
+			return fmt.Errorf("batch %d mismatch, %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+func compareBatch(expected, actual *jsonBatch) error {
+	if expected.Count != actual.Count {
+		return fmt.Errorf("%d rows, expected %d", actual.Count, expected.Count)
+	}
+
+	if len(expected.Columns) != len(actual.Columns) {
+		return fmt.Errorf("%d columns, expected %d", len(actual.Columns), len(expected.Columns))
+	}
+
+	for i, col := range expected.Columns {
+		if err := compareColumn(col, actual.Columns[i], col.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compareColumn(expected, actual *jsonColumn, path string) error {
+	switch {
+	case expected.Count != actual.Count:
+		return fmt.Errorf("column %s has %d values, expected %d", path, actual.Count, expected.Count)
+	case !reflect.DeepEqual(expected.Validity, actual.Validity):
+		return fmt.Errorf("column %s has validity %v, expected %v", path, actual.Validity, expected.Validity)
+	case !reflect.DeepEqual(expected.Offset, actual.Offset):
+		return fmt.Errorf("column %s has offsets %s, expected %s", path, actual.Offset, expected.Offset)
+	case !reflect.DeepEqual(expected.TypeID, actual.TypeID):
+		return fmt.Errorf("column %s has type ids %s, expected %s", path, actual.TypeID, expected.TypeID)
+	case len(expected.Data) != len(actual.Data):
+		return fmt.Errorf("column %s has %d data values, expected %d", path, len(actual.Data), len(expected.Data))
+	case len(expected.Children) != len(actual.Children):
+		return fmt.Errorf("column %s has %d children, expected %d", path, len(actual.Children), len(expected.Children))
+	}
+
+	for i, value := range expected.Data {
+		if string(value) != string(actual.Data[i]) {
+			return fmt.Errorf("column %s has value %s at %d, expected %s", path, actual.Data[i], i, value)
+		}
+	}
+
+	for i, child := range expected.Children {
+		if err := compareColumn(child, actual.Children[i], path+"."+child.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package integration converts between the JSON format of the Arrow integration tests,
+// which describes a schema with its dictionaries and record batches, and schema.Schema
+// with vector.RecordBatch.
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// File is the content of an integration JSON file.
+type File struct {
+	Schema *schema.Schema
+
+	// The dictionaries referred to by dictionary encoded fields, keyed by their ids.
+	Dictionaries map[int64]*vector.RecordBatch
+
+	Batches []*vector.RecordBatch
+}
+
+type jsonFile struct {
+	Schema       *jsonSchema       `json:"schema"`
+	Dictionaries []*jsonDictionary `json:"dictionaries,omitempty"`
+	Batches      []*jsonBatch      `json:"batches"`
+}
+
+type jsonSchema struct {
+	Fields   []*jsonField    `json:"fields"`
+	Metadata []*jsonKeyValue `json:"metadata,omitempty"`
+}
+
+type jsonField struct {
+	Name       string          `json:"name"`
+	Nullable   bool            `json:"nullable"`
+	Type       *jsonType       `json:"type"`
+	Children   []*jsonField    `json:"children"`
+	Layout     *jsonTypeLayout `json:"typeLayout,omitempty"`
+	Dictionary *jsonEncoding   `json:"dictionary,omitempty"`
+	Metadata   []*jsonKeyValue `json:"metadata,omitempty"`
+}
+
+type jsonKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type jsonType struct {
+	Name      string          `json:"name"`
+	BitWidth  *int            `json:"bitWidth,omitempty"`
+	IsSigned  *bool           `json:"isSigned,omitempty"`
+	Precision json.RawMessage `json:"precision,omitempty"` // a name for floating points, a number for decimals
+	Scale     *int            `json:"scale,omitempty"`
+	Unit      string          `json:"unit,omitempty"`
+	Timezone  string          `json:"timezone,omitempty"`
+	Mode      string          `json:"mode,omitempty"`
+	TypeIDs   []int           `json:"typeIds,omitempty"`
+
+	ByteWidth  *int  `json:"byteWidth,omitempty"`
+	ListSize   *int  `json:"listSize,omitempty"`
+	KeysSorted *bool `json:"keysSorted,omitempty"`
+}
+
+type jsonTypeLayout struct {
+	Vectors []*jsonVectorLayout `json:"vectors"`
+}
+
+type jsonVectorLayout struct {
+	Type     string `json:"type"`
+	BitWidth int    `json:"typeBitWidth"`
+}
+
+type jsonEncoding struct {
+	ID        int64     `json:"id"`
+	IndexType *jsonType `json:"indexType,omitempty"`
+	IsOrdered bool      `json:"isOrdered,omitempty"`
+}
+
+type jsonDictionary struct {
+	ID   int64      `json:"id"`
+	Data *jsonBatch `json:"data"`
+}
+
+type jsonBatch struct {
+	Count   int           `json:"count"`
+	Columns []*jsonColumn `json:"columns"`
+}
+
+type jsonColumn struct {
+	Name     string            `json:"name"`
+	Count    int               `json:"count"`
+	Validity []int             `json:"VALIDITY,omitempty"`
+	Offset   []json.RawMessage `json:"OFFSET,omitempty"`
+	TypeID   []json.RawMessage `json:"TYPE_ID,omitempty"`
+	Data     []json.RawMessage `json:"DATA,omitempty"`
+	Children []*jsonColumn     `json:"children,omitempty"`
+}
+
+// Read an integration JSON file.
+//
+// The buffers of the batches are packed at 8 byte boundaries in the order of the field layouts,
+// a field without a typeLayout gets the layout of its type.
+func Read(r io.Reader) (*File, error) {
+	var jf jsonFile
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := dec.Decode(&jf); err != nil {
+		return nil, fmt.Errorf("fail to decode JSON, %s", err)
+	}
+
+	if jf.Schema == nil {
+		return nil, fmt.Errorf("missing schema")
+	}
+
+	s, err := unmarshalSchema(jf.Schema)
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse schema, %s", err)
+	}
+
+	f := &File{Schema: s}
+
+	if len(jf.Dictionaries) > 0 {
+		fields := dictionaryFields(s)
+
+		f.Dictionaries = make(map[int64]*vector.RecordBatch, len(jf.Dictionaries))
+
+		for _, dict := range jf.Dictionaries {
+			if dict.Data == nil {
+				return nil, fmt.Errorf("dictionary %d has no data", dict.ID)
+			}
+
+			ds, err := dictionarySchema(fields, dict.ID)
+
+			if err != nil {
+				return nil, err
+			}
+
+			batch, err := decodeBatch(ds, dict.Data)
+
+			if err != nil {
+				return nil, fmt.Errorf("fail to decode dictionary %d, %s", dict.ID, err)
+			}
+
+			f.Dictionaries[dict.ID] = batch
+		}
+	}
+
+	for i, b := range jf.Batches {
+		batch, err := decodeBatch(s, b)
+
+		if err != nil {
+			return nil, fmt.Errorf("fail to decode batch %d, %s", i, err)
+		}
+
+		f.Batches = append(f.Batches, batch)
+	}
+
+	return f, nil
+}
+
+// Write the file as integration JSON, the batches must be valid for the schema.
+func Write(w io.Writer, f *File) error {
+	jf, err := marshalFile(f)
+
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(jf)
+}
+
+func marshalFile(f *File) (*jsonFile, error) {
+	js, err := marshalSchema(f.Schema)
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal schema, %s", err)
+	}
+
+	jf := &jsonFile{Schema: js, Batches: []*jsonBatch{}}
+
+	if len(f.Dictionaries) > 0 {
+		fields := dictionaryFields(f.Schema)
+
+		var ids []int64
+
+		for id := range f.Dictionaries {
+			ids = append(ids, id)
+		}
+
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for _, id := range ids {
+			ds, err := dictionarySchema(fields, id)
+
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := encodeBatch(ds, f.Dictionaries[id])
+
+			if err != nil {
+				return nil, fmt.Errorf("fail to encode dictionary %d, %s", id, err)
+			}
+
+			jf.Dictionaries = append(jf.Dictionaries, &jsonDictionary{id, data})
+		}
+	}
+
+	for i, batch := range f.Batches {
+		b, err := encodeBatch(f.Schema, batch)
+
+		if err != nil {
+			return nil, fmt.Errorf("fail to encode batch %d, %s", i, err)
+		}
+
+		jf.Batches = append(jf.Batches, b)
+	}
+
+	return jf, nil
+}
+
+// Returns the dictionary encoded fields of the schema, keyed by their dictionary ids.
+func dictionaryFields(s *schema.Schema) map[int64]*schema.Field {
+	fields := make(map[int64]*schema.Field)
+
+	var walk func([]*schema.Field)
+
+	walk = func(fs []*schema.Field) {
+		for _, field := range fs {
+			if field.Dictionary != nil {
+				fields[field.Dictionary.ID] = field
+			}
+
+			walk(field.Children)
+		}
+	}
+
+	walk(s.Fields)
+
+	return fields
+}
+
+// Returns the schema of the values of a dictionary, a single column of the encoded field.
+func dictionarySchema(fields map[int64]*schema.Field, id int64) (*schema.Schema, error) {
+	field, found := fields[id]
+
+	if !found {
+		return nil, fmt.Errorf("dictionary %d is not referred to by any field", id)
+	}
+
+	layout, err := schema.NewTypeLayout(field.Type)
+
+	if err != nil {
+		return nil, fmt.Errorf("dictionary %d has no layout, %s", id, err)
+	}
+
+	f := *field
+	f.Dictionary = nil
+	f.Layout = layout
+
+	return &schema.Schema{Fields: []*schema.Field{&f}}, nil
+}
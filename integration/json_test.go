@@ -0,0 +1,144 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, path string) ([]byte, *File) {
+	buf, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Read(bytes.NewReader(buf))
+
+	if err != nil {
+		t.Fatalf("fail to read %s, %s", path, err)
+	}
+
+	return buf, f
+}
+
+func TestReadWrite(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.json")
+
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no fixtures, %v", err)
+	}
+
+	for _, path := range paths {
+		expected, f := readFixture(t, path)
+
+		var buf bytes.Buffer
+
+		if err := Write(&buf, f); err != nil {
+			t.Fatalf("fail to write %s, %s", path, err)
+		}
+
+		// the fixtures are written as Write does
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("write %s as\n%s", path, buf.String())
+		}
+
+		actual, err := Read(&buf)
+
+		if err != nil {
+			t.Fatalf("fail to read written %s, %s", path, err)
+		}
+
+		if err := Compare(f, actual); err != nil {
+			t.Errorf("%s changed by a round trip, %s", path, err)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	_, expected := readFixture(t, "testdata/nested.json")
+
+	tests := []struct {
+		edit func(f *File)
+		err  string
+	}{
+		{func(f *File) { f.Batches = f.Batches[:0] }, "0 batches, expected 1"},
+		{func(f *File) { f.Schema.Fields[0].Name = "other" }, "schema mismatch"},
+		{func(f *File) { f.Schema.Metadata = map[string]string{"origin": "other"} }, "the other has map[origin:other]"},
+		{func(f *File) { f.Batches[0].Buffers[3].Bytes()[4]++ }, "column list.item has value 1 at 1, expected 0"},
+		{func(f *File) { f.Batches[0].Buffers[1].Bytes()[4] = 1 }, "column list has offsets [0 1 2 3], expected [0 2 2 3]"},
+	}
+
+	for _, test := range tests {
+		_, actual := readFixture(t, "testdata/nested.json")
+
+		test.edit(actual)
+
+		if err := Compare(expected, actual); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("compare with error %v, expected %s", err, test.err)
+		}
+	}
+
+	_, other := readFixture(t, "testdata/dictionary.json")
+	_, actual := readFixture(t, "testdata/dictionary.json")
+
+	actual.Dictionaries[0].Buffers[2].Bytes()[0] = 'R'
+
+	if err := Compare(other, actual); err == nil || !strings.Contains(err.Error(), "dictionary 0 mismatch") {
+		t.Errorf("compare with error %v, expected a dictionary mismatch", err)
+	}
+}
+
+func TestCompareBatch(t *testing.T) {
+	expected := &jsonBatch{Count: 1, Columns: []*jsonColumn{
+		{Name: "a", Count: 1, Data: []json.RawMessage{json.RawMessage("1")}},
+		{Name: "b", Count: 1, Children: []*jsonColumn{{Name: "c", Count: 1}}},
+	}}
+
+	tests := []struct {
+		actual *jsonBatch
+		err    string
+	}{
+		{&jsonBatch{Count: 1}, "0 columns, expected 2"},
+		{&jsonBatch{Count: 1, Columns: []*jsonColumn{
+			{Name: "a", Count: 1},
+			{Name: "b", Count: 1, Children: []*jsonColumn{{Name: "c", Count: 1}}},
+		}}, "column a has 0 data values, expected 1"},
+		{&jsonBatch{Count: 1, Columns: []*jsonColumn{
+			{Name: "a", Count: 1, Data: []json.RawMessage{json.RawMessage("1")}},
+			{Name: "b", Count: 1},
+		}}, "column b has 0 children, expected 1"},
+	}
+
+	for _, test := range tests {
+		if err := compareBatch(expected, test.actual); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("compare with error %v, expected %s", err, test.err)
+		}
+	}
+}
+
+func TestReadError(t *testing.T) {
+	tests := []struct {
+		json, err string
+	}{
+		{`{}`, "missing schema"},
+		{`{"schema": {"fields": [{"name": "a", "type": {"name": "decimal", "precision": 5}}]},
+			"batches": [{"count": 1, "columns": [{"name": "a", "count": 1, "VALIDITY": [1], "DATA": ["170141183460469231731687303715884105728"]}]}]}`,
+			"invalid decimal value"},
+		{`{"schema": {"fields": [{"name": "a", "type": {"name": "utf8"}, "dictionary": {"id": 1}}]},
+			"dictionaries": [{"id": 2, "data": {"count": 0, "columns": []}}], "batches": []}`,
+			"dictionary 2 is not referred to by any field"},
+		{`{"schema": {"fields": [{"name": "a", "type": {"name": "int", "bitWidth": 32}}]},
+			"batches": [{"count": 2, "columns": [{"name": "a", "count": 2, "VALIDITY": [1, 1], "DATA": [1]}]}]}`,
+			"1 data values, expected 2"},
+	}
+
+	for _, test := range tests {
+		if _, err := Read(strings.NewReader(test.json)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("read with error %v, expected %s", err, test.err)
+		}
+	}
+}
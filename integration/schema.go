@@ -0,0 +1,606 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+var (
+	precisions = map[string]schema.Precision{
+		"HALF":   schema.Half,
+		"SINGLE": schema.Single,
+		"DOUBLE": schema.Double,
+	}
+
+	timeUnits = map[string]schema.TimeUnit{
+		"SECOND":      schema.Second,
+		"MILLISECOND": schema.Millisecond,
+		"MICROSECOND": schema.Microsecond,
+		"NANOSECOND":  schema.Nanosecond,
+	}
+
+	dateUnits = map[string]schema.DateUnit{
+		"DAY":         schema.DateDay,
+		"MILLISECOND": schema.DateMillisecond,
+	}
+
+	intervalUnits = map[string]schema.IntervalUnit{
+		"YEAR_MONTH": schema.YearMonth,
+		"DAY_TIME":   schema.DayTime,
+	}
+
+	unionModes = map[string]schema.UnionMode{
+		"SPARSE": schema.Sparse,
+		"DENSE":  schema.Dense,
+	}
+
+	vectorTypes = map[string]vector.VectorType{
+		"VALIDITY": vector.Validity,
+		"OFFSET":   vector.Offset,
+		"TYPE":     vector.Type,
+		"DATA":     vector.Data,
+	}
+)
+
+func unmarshalSchema(js *jsonSchema) (*schema.Schema, error) {
+	s := &schema.Schema{}
+
+	for _, jf := range js.Fields {
+		field, err := unmarshalField(jf)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Fields = append(s.Fields, field)
+	}
+
+	s.Metadata = unmarshalMetadata(js.Metadata)
+
+	return s, nil
+}
+
+func unmarshalMetadata(kvs []*jsonKeyValue) map[string]string {
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]string, len(kvs))
+
+	for _, kv := range kvs {
+		metadata[kv.Key] = kv.Value
+	}
+
+	return metadata
+}
+
+func unmarshalField(jf *jsonField) (*schema.Field, error) {
+	if jf.Type == nil {
+		return nil, fmt.Errorf("field %s has no type", jf.Name)
+	}
+
+	tp, err := unmarshalType(jf.Type)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s has invalid type, %s", jf.Name, err)
+	}
+
+	metadata := unmarshalMetadata(jf.Metadata)
+
+	if tp, metadata, err = schema.UnmarshalExtensionType(tp, metadata); err != nil {
+		return nil, fmt.Errorf("field %s has invalid extension type, %s", jf.Name, err)
+	}
+
+	field := &schema.Field{
+		Name:     jf.Name,
+		Nullable: jf.Nullable,
+		Type:     tp,
+		Metadata: metadata,
+	}
+
+	for _, child := range jf.Children {
+		f, err := unmarshalField(child)
+
+		if err != nil {
+			return nil, err
+		}
+
+		field.Children = append(field.Children, f)
+	}
+
+	if jf.Dictionary != nil {
+		if field.Dictionary, err = unmarshalEncoding(jf.Dictionary); err != nil {
+			return nil, fmt.Errorf("field %s has invalid dictionary, %s", jf.Name, err)
+		}
+	}
+
+	switch {
+	case jf.Layout != nil:
+		field.Layout, err = unmarshalLayout(jf.Layout)
+	case field.Dictionary != nil:
+		field.Layout, err = schema.NewTypeLayout(field.Dictionary.Index())
+	default:
+		field.Layout, err = schema.NewTypeLayout(tp)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s has invalid layout, %s", jf.Name, err)
+	}
+
+	return field, nil
+}
+
+func unmarshalEncoding(je *jsonEncoding) (*schema.DictionaryEncoding, error) {
+	encoding := &schema.DictionaryEncoding{ID: je.ID, Ordered: je.IsOrdered}
+
+	if je.IndexType != nil {
+		tp, err := unmarshalType(je.IndexType)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid index type, %s", err)
+		}
+
+		index, ok := tp.(*schema.Int)
+
+		if !ok {
+			return nil, fmt.Errorf("index type %s is not an integer", tp)
+		}
+
+		encoding.IndexType = index
+	}
+
+	return encoding, nil
+}
+
+func unmarshalType(jt *jsonType) (schema.Type, error) {
+	switch jt.Name {
+	case "null":
+		return schema.Null, nil
+
+	case "int":
+		if jt.BitWidth == nil {
+			return nil, fmt.Errorf("missing bitWidth")
+		}
+
+		signed := true
+
+		if jt.IsSigned != nil {
+			signed = *jt.IsSigned
+		}
+
+		return schema.NewInt(*jt.BitWidth, signed), nil
+
+	case "floatingpoint":
+		var name string
+
+		if err := json.Unmarshal(jt.Precision, &name); err != nil {
+			return nil, fmt.Errorf("invalid precision, %s", err)
+		}
+
+		precision, found := precisions[name]
+
+		if !found {
+			return nil, fmt.Errorf("unknown precision %s", name)
+		}
+
+		return schema.NewFloatingPoint(precision), nil
+
+	case "binary":
+		return schema.Binary, nil
+
+	case "utf8":
+		return schema.Utf8, nil
+
+	case "bool":
+		return schema.Bool, nil
+
+	case "decimal":
+		var precision int
+
+		if err := json.Unmarshal(jt.Precision, &precision); err != nil {
+			return nil, fmt.Errorf("invalid precision, %s", err)
+		}
+
+		scale := 0
+
+		if jt.Scale != nil {
+			scale = *jt.Scale
+		}
+
+		d := schema.NewDecimal(schema.Precision(precision), scale)
+
+		if jt.BitWidth != nil {
+			if *jt.BitWidth != 128 && *jt.BitWidth != 256 {
+				return nil, fmt.Errorf("unsupported decimal of %d bits", *jt.BitWidth)
+			}
+
+			d.BitWidth = *jt.BitWidth
+		}
+
+		return d, nil
+
+	case "date":
+		if jt.Unit == "" {
+			return schema.Date, nil
+		}
+
+		unit, found := dateUnits[jt.Unit]
+
+		if !found {
+			return nil, fmt.Errorf("unknown date unit %s", jt.Unit)
+		}
+
+		return schema.NewDate(unit), nil
+
+	case "time":
+		if jt.Unit == "" {
+			return schema.Time, nil
+		}
+
+		unit, found := timeUnits[jt.Unit]
+
+		if !found {
+			return nil, fmt.Errorf("unknown time unit %s", jt.Unit)
+		}
+
+		t := schema.NewTime(unit)
+
+		if jt.BitWidth != nil && *jt.BitWidth != t.BitWidth {
+			return nil, fmt.Errorf("time of %d bits in %s", *jt.BitWidth, unit)
+		}
+
+		return t, nil
+
+	case "duration":
+		unit, found := timeUnits[jt.Unit]
+
+		if !found {
+			return nil, fmt.Errorf("unknown time unit %s", jt.Unit)
+		}
+
+		return schema.NewDuration(unit), nil
+
+	case "largebinary":
+		return schema.LargeBinary, nil
+
+	case "largeutf8":
+		return schema.LargeUtf8, nil
+
+	case "fixedsizebinary":
+		if jt.ByteWidth == nil || *jt.ByteWidth <= 0 {
+			return nil, fmt.Errorf("missing byteWidth")
+		}
+
+		return schema.NewFixedSizeBinary(*jt.ByteWidth), nil
+
+	case "largelist":
+		return schema.LargeList, nil
+
+	case "fixedsizelist":
+		if jt.ListSize == nil || *jt.ListSize < 0 {
+			return nil, fmt.Errorf("missing listSize")
+		}
+
+		return schema.NewFixedSizeList(*jt.ListSize), nil
+
+	case "map":
+		return schema.NewMap(jt.KeysSorted != nil && *jt.KeysSorted), nil
+
+	case "timestamp":
+		unit, found := timeUnits[jt.Unit]
+
+		if !found {
+			return nil, fmt.Errorf("unknown time unit %s", jt.Unit)
+		}
+
+		ts := schema.NewTimeStamp(unit)
+		ts.Timezone = jt.Timezone
+
+		return ts, nil
+
+	case "interval":
+		unit, found := intervalUnits[jt.Unit]
+
+		if !found {
+			return nil, fmt.Errorf("unknown interval unit %s", jt.Unit)
+		}
+
+		return schema.NewInterval(unit), nil
+
+	case "list":
+		return schema.List, nil
+
+	case "struct":
+		return schema.Struct, nil
+
+	case "union":
+		mode, found := unionModes[jt.Mode]
+
+		if !found {
+			return nil, fmt.Errorf("unknown union mode %s", jt.Mode)
+		}
+
+		return schema.NewUnion(mode, jt.TypeIDs), nil
+
+	default:
+		return nil, fmt.Errorf("unknown type %s", jt.Name)
+	}
+}
+
+func unmarshalLayout(jl *jsonTypeLayout) (*vector.TypeLayout, error) {
+	var vectors []*vector.VectorLayout
+
+	for _, jv := range jl.Vectors {
+		tp, found := vectorTypes[jv.Type]
+
+		if !found {
+			return nil, fmt.Errorf("unknown vector type %s", jv.Type)
+		}
+
+		var layout *vector.VectorLayout
+
+		switch {
+		case tp == vector.Validity && jv.BitWidth == 1:
+			layout = vector.ValidityVector
+		case tp == vector.Offset && jv.BitWidth == 32:
+			layout = vector.OffsetVector
+		case tp == vector.Type && jv.BitWidth == 32:
+			layout = vector.TypeVector
+		case tp == vector.Type && jv.BitWidth == 8:
+			layout = vector.TypeIDVector
+		case tp == vector.Data:
+			var err error
+
+			// fixed size binaries have any number of bytes
+			if layout, err = vector.DataVector(jv.BitWidth); err != nil && (jv.BitWidth <= 0 || jv.BitWidth%8 != 0) {
+				return nil, err
+			} else if err != nil {
+				layout = &vector.VectorLayout{Type: tp, BitWidth: jv.BitWidth}
+			}
+		default:
+			layout = &vector.VectorLayout{Type: tp, BitWidth: jv.BitWidth}
+		}
+
+		vectors = append(vectors, layout)
+	}
+
+	return &vector.TypeLayout{Vectors: vectors}, nil
+}
+
+func marshalSchema(s *schema.Schema) (*jsonSchema, error) {
+	js := &jsonSchema{Fields: []*jsonField{}}
+
+	for _, field := range s.Fields {
+		jf, err := marshalField(field)
+
+		if err != nil {
+			return nil, err
+		}
+
+		js.Fields = append(js.Fields, jf)
+	}
+
+	js.Metadata = marshalMetadata(s.Metadata)
+
+	return js, nil
+}
+
+// The key values are sorted by key, so the same metadata is always written the same way.
+func marshalMetadata(metadata map[string]string) []*jsonKeyValue {
+	var kvs []*jsonKeyValue
+
+	for k, v := range metadata {
+		kvs = append(kvs, &jsonKeyValue{k, v})
+	}
+
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+	return kvs
+}
+
+func marshalField(field *schema.Field) (*jsonField, error) {
+	tp, err := marshalType(schema.StorageType(field.Type))
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s has invalid type, %s", field.Name, err)
+	}
+
+	jf := &jsonField{
+		Name:     field.Name,
+		Nullable: field.Nullable,
+		Type:     tp,
+		Children: []*jsonField{},
+	}
+
+	jf.Metadata = marshalMetadata(field.CustomMetadata())
+
+	for _, child := range field.Children {
+		c, err := marshalField(child)
+
+		if err != nil {
+			return nil, err
+		}
+
+		jf.Children = append(jf.Children, c)
+	}
+
+	if field.Layout != nil {
+		jf.Layout = &jsonTypeLayout{Vectors: []*jsonVectorLayout{}}
+
+		for _, layout := range field.Layout.Vectors {
+			jf.Layout.Vectors = append(jf.Layout.Vectors, &jsonVectorLayout{layout.Type.String(), layout.BitWidth})
+		}
+	}
+
+	if field.Dictionary != nil {
+		jf.Dictionary = &jsonEncoding{ID: field.Dictionary.ID, IsOrdered: field.Dictionary.Ordered}
+
+		if field.Dictionary.IndexType != nil {
+			if jf.Dictionary.IndexType, err = marshalType(field.Dictionary.IndexType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return jf, nil
+}
+
+func marshalType(t schema.Type) (*jsonType, error) {
+	switch t.Value() {
+	case flatbuf.TypeNull:
+		return &jsonType{Name: "null"}, nil
+
+	case flatbuf.TypeInt:
+		i, ok := t.(*schema.Int)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "int", BitWidth: &i.BitWidth, IsSigned: &i.Signed}, nil
+
+	case flatbuf.TypeFloatingPoint:
+		f, ok := t.(*schema.FloatingPoint)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "floatingpoint", Precision: json.RawMessage(strconv.Quote(f.Precision.String()))}, nil
+
+	case flatbuf.TypeBinary:
+		return &jsonType{Name: "binary"}, nil
+
+	case flatbuf.TypeUtf8:
+		return &jsonType{Name: "utf8"}, nil
+
+	case flatbuf.TypeBool:
+		return &jsonType{Name: "bool"}, nil
+
+	case flatbuf.TypeDecimal:
+		d, ok := t.(*schema.Decimal)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "decimal", Precision: json.RawMessage(strconv.Itoa(int(d.Precision))), Scale: &d.Scale, BitWidth: &d.BitWidth}, nil
+
+	case flatbuf.TypeDate:
+		d, ok := t.(*schema.DateType)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "date", Unit: d.Unit.String()}, nil
+
+	case flatbuf.TypeTime:
+		tm, ok := t.(*schema.TimeType)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "time", Unit: tm.Unit.String(), BitWidth: &tm.BitWidth}, nil
+
+	case v5.TypeDuration:
+		d, ok := t.(*schema.Duration)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "duration", Unit: d.Unit.String()}, nil
+
+	case v5.TypeLargeBinary:
+		return &jsonType{Name: "largebinary"}, nil
+
+	case v5.TypeLargeUtf8:
+		return &jsonType{Name: "largeutf8"}, nil
+
+	case v5.TypeFixedSizeBinary:
+		b, ok := t.(*schema.FixedSizeBinary)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "fixedsizebinary", ByteWidth: &b.ByteWidth}, nil
+
+	case v5.TypeLargeList:
+		return &jsonType{Name: "largelist"}, nil
+
+	case v5.TypeFixedSizeList:
+		l, ok := t.(*schema.FixedSizeList)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "fixedsizelist", ListSize: &l.ListSize}, nil
+
+	case v5.TypeMap:
+		m, ok := t.(*schema.Map)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "map", KeysSorted: &m.KeysSorted}, nil
+
+	case flatbuf.TypeTimestamp:
+		ts, ok := t.(*schema.Timestamp)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "timestamp", Unit: ts.Unit.String(), Timezone: ts.Timezone}, nil
+
+	case flatbuf.TypeInterval:
+		i, ok := t.(*schema.Interval)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		return &jsonType{Name: "interval", Unit: i.Unit.String()}, nil
+
+	case flatbuf.TypeList:
+		return &jsonType{Name: "list"}, nil
+
+	case flatbuf.TypeStruct_:
+		return &jsonType{Name: "struct"}, nil
+
+	case flatbuf.TypeUnion:
+		u, ok := t.(*schema.Union)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		var mode string
+
+		switch u.Mode {
+		case schema.Sparse:
+			mode = "SPARSE"
+		case schema.Dense:
+			mode = "DENSE"
+		default:
+			return nil, fmt.Errorf("unknown union mode %s", u.Mode)
+		}
+
+		return &jsonType{Name: "union", Mode: mode, TypeIDs: u.TypeIDs}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
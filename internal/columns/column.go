@@ -0,0 +1,589 @@
+// Package columns reads the values of the fields of record batches, shared by arrow-gen and the file formats.
+package columns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Column reads the values of a field of a record batch without reflection, for the code generated by
+// arrow-gen and the writers of text formats. The accessor methods must match the storage type of the field,
+// the indices of dictionary encoded fields are read through Dictionary, and the index must be within the column.
+type Column struct {
+	field *schema.Field
+	tp    schema.Type // the storage type of the field
+
+	length                         int
+	validity, offsets, types, data *memory.Buffer
+	offsetWidth                    int
+	children                       []*Column
+	dictionary                     *Column        // the values of a dictionary encoded field, indexed by data
+	location                       *time.Location // the time zone of Timestamp fields
+}
+
+// Returns the columns of the fields of the schema in the record batch, which is validated against it.
+//
+// The fields may have children and be dictionary encoded, but interval fields are not supported.
+func New(s *schema.Schema, batch *vector.RecordBatch) ([]*Column, error) {
+	if err := s.Validate(batch); err != nil {
+		return nil, err
+	}
+
+	r := &columnReader{batch: batch, dictionaries: make(map[int64]*Column)}
+
+	return r.columns(s.Fields, "")
+}
+
+type columnReader struct {
+	batch        *vector.RecordBatch
+	node, buffer int
+	dictionaries map[int64]*Column
+}
+
+func (r *columnReader) columns(fields []*schema.Field, prefix string) ([]*Column, error) {
+	columns := make([]*Column, len(fields))
+
+	for i, field := range fields {
+		c, err := r.column(field, prefix+field.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		columns[i] = c
+	}
+
+	return columns, nil
+}
+
+func (r *columnReader) column(field *schema.Field, path string) (*Column, error) {
+	node := r.batch.Nodes[r.node]
+	r.node++
+
+	c := &Column{field: field, tp: schema.StorageType(field.Type), length: node.Length}
+
+	for _, layout := range field.Layout.Vectors {
+		buf := r.batch.Buffers[r.buffer]
+		r.buffer++
+
+		switch layout.Type {
+		case vector.Validity:
+			if node.NullCount > 0 {
+				c.validity = buf
+			}
+
+		case vector.Offset:
+			c.offsets, c.offsetWidth = buf, layout.BitWidth
+
+		case vector.Type:
+			c.types = buf
+
+		default:
+			c.data = buf
+		}
+	}
+
+	if field.Dictionary != nil {
+		dictionary, err := r.dictionary(field, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.dictionary = dictionary
+
+		for i := 0; i < c.length; i++ {
+			if c.valid(i) {
+				if index := c.Index(i); index < 0 || index >= dictionary.length {
+					return nil, fmt.Errorf("field %s has index %d at %d out of the dictionary of %d values", path, index, i, dictionary.length)
+				}
+			}
+		}
+
+		return c, nil
+	}
+
+	switch tp := c.tp.(type) {
+	case *schema.Int, *schema.FloatingPoint, *schema.Decimal, *schema.DateType, *schema.TimeType,
+		*schema.Duration, *schema.FixedSizeBinary, *schema.FixedSizeList, *schema.Map, *schema.Union:
+
+	case *schema.Timestamp:
+		loc, err := Location(tp.Timezone)
+
+		if err != nil {
+			return nil, fmt.Errorf("field %s has invalid time zone, %s", path, err)
+		}
+
+		c.location = loc
+
+	default:
+		switch c.tp.Value() {
+		case flatbuf.TypeNull, flatbuf.TypeBool, flatbuf.TypeUtf8, flatbuf.TypeBinary, v5.TypeLargeUtf8, v5.TypeLargeBinary,
+			flatbuf.TypeList, v5.TypeLargeList, flatbuf.TypeStruct_:
+
+		default:
+			return nil, fmt.Errorf("field %s of type %s is not supported", path, field.Type)
+		}
+	}
+
+	children, err := r.columns(field.Children, path+".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.children = children
+
+	return c, nil
+}
+
+// Returns the column of the values of the dictionary, read once for all the fields referring to it.
+func (r *columnReader) dictionary(field *schema.Field, path string) (*Column, error) {
+	id := field.Dictionary.ID
+
+	if c, found := r.dictionaries[id]; found {
+		return c, nil
+	}
+
+	batch, found := r.batch.Dictionaries[id]
+
+	if !found {
+		return nil, fmt.Errorf("field %s refers to missing dictionary %d", path, id)
+	}
+
+	layout, err := schema.NewTypeLayout(field.Type)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s has no dictionary layout, %s", path, err)
+	}
+
+	f := *field
+	f.Dictionary = nil
+	f.Layout = layout
+
+	if err := (&schema.Schema{Fields: []*schema.Field{&f}}).Validate(batch); err != nil {
+		return nil, fmt.Errorf("invalid dictionary %d, %s", id, err)
+	}
+
+	dr := &columnReader{batch: batch, dictionaries: r.dictionaries}
+
+	c, err := dr.column(&f, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.dictionaries[id] = c
+
+	return c, nil
+}
+
+func (c *Column) Field() *schema.Field { return c.field }
+
+// Returns the number of values of the column.
+func (c *Column) Len() int { return c.length }
+
+// Returns the columns of the children of the field.
+func (c *Column) Children() []*Column { return c.children }
+
+// Returns the column of the values of a dictionary encoded field, or nil.
+func (c *Column) Dictionary() *Column { return c.dictionary }
+
+// Returns the index of the value of a dictionary encoded field.
+func (c *Column) Index(i int) int { return int(ReadInt64(c.data, c.field.Dictionary.Index(), i)) }
+
+// Returns true if the value has children, or is a dictionary value with children.
+func (c *Column) Nested() bool {
+	if c.dictionary != nil {
+		return c.dictionary.Nested()
+	}
+
+	return len(c.children) > 0
+}
+
+// Returns true if the value is null, the values of unions are null if those of their children are.
+func (c *Column) IsNull(i int) bool {
+	switch {
+	case c.tp.Value() == flatbuf.TypeNull:
+		return true
+
+	case !c.valid(i):
+		return true
+
+	case c.dictionary != nil:
+		return c.dictionary.IsNull(c.Index(i))
+
+	case c.types != nil:
+		child, j := c.unionValue(i)
+
+		return child.IsNull(j)
+	}
+
+	return false
+}
+
+func (c *Column) valid(i int) bool {
+	return c.validity == nil || c.validity.Bytes()[i>>3]&(1<<uint(i&7)) != 0
+}
+
+// Returns the column and index of the child holding the value of a union.
+func (c *Column) unionValue(i int) (*Column, int) {
+	union := c.tp.(*schema.Union)
+	id := int(c.types.TinyInt(i))
+	child := id
+
+	for j, typeID := range union.TypeIDs {
+		if typeID == id {
+			child = j
+			break
+		}
+	}
+
+	if union.Mode == schema.Dense {
+		return c.children[child], int(c.offsets.Int(i))
+	}
+
+	return c.children[child], i
+}
+
+// Returns the range of the values of a variable width field, or of the children of a list or map.
+func (c *Column) Span(i int) (int, int) {
+	if c.offsetWidth == 64 {
+		return int(c.offsets.BigInt(i)), int(c.offsets.BigInt(i + 1))
+	}
+
+	return int(c.offsets.Int(i)), int(c.offsets.Int(i + 1))
+}
+
+// Returns the bytes of a binary or string value, without copying them.
+func (c *Column) bytes(i int) []byte {
+	if b, ok := c.tp.(*schema.FixedSizeBinary); ok {
+		return c.data.Bytes()[i*b.ByteWidth : (i+1)*b.ByteWidth]
+	}
+
+	start, end := c.Span(i)
+
+	return c.data.Bytes()[start:end]
+}
+
+func (c *Column) Bool(i int) bool { return c.data.Bytes()[i>>3]&(1<<uint(i&7)) != 0 }
+
+func (c *Column) Int8(i int) int8   { return c.data.TinyInt(i) }
+func (c *Column) Int16(i int) int16 { return c.data.SmallInt(i) }
+func (c *Column) Int32(i int) int32 { return c.data.Int(i) }
+func (c *Column) Int64(i int) int64 { return c.data.BigInt(i) }
+
+func (c *Column) Uint8(i int) uint8   { return c.data.UInt1(i) }
+func (c *Column) Uint16(i int) uint16 { return c.data.UInt2(i) }
+func (c *Column) Uint32(i int) uint32 { return c.data.UInt4(i) }
+func (c *Column) Uint64(i int) uint64 { return c.data.UInt8(i) }
+
+func (c *Column) Float32(i int) float32 { return c.data.Float4(i) }
+func (c *Column) Float64(i int) float64 { return c.data.Float8(i) }
+
+// Returns the string of a Utf8 or LargeUtf8 field.
+func (c *Column) String(i int) string { return string(c.bytes(i)) }
+
+// Returns a copy of the bytes of a Binary, LargeBinary or FixedSizeBinary field.
+func (c *Column) Bytes(i int) []byte { return append([]byte{}, c.bytes(i)...) }
+
+// Returns the unscaled value of a Decimal field.
+func (c *Column) Decimal(i int) *big.Int {
+	size := c.tp.(*schema.Decimal).BitWidth / 8
+
+	return decimalInt(c.data.Bytes()[i*size : (i+1)*size])
+}
+
+// Returns the time of a Timestamp field, in its time zone or UTC.
+func (c *Column) Time(i int) time.Time {
+	return FromUnit(c.data.BigInt(i), c.tp.(*schema.Timestamp).Unit).In(c.location)
+}
+
+// Returns the date of a Date field, at midnight in UTC.
+func (c *Column) Date(i int) time.Time {
+	if c.tp.(*schema.DateType).Unit == schema.DateDay {
+		return time.Unix(int64(c.data.Int(i))*86400, 0).UTC()
+	}
+
+	return time.Unix(0, c.data.BigInt(i)*int64(time.Millisecond)).UTC()
+}
+
+// Returns the duration of a Duration field.
+func (c *Column) Duration(i int) time.Duration {
+	return time.Duration(c.data.BigInt(i) * UnitNanos(c.tp.(*schema.Duration).Unit))
+}
+
+// Format the valid value as text, the values of unions are formatted as those of their children,
+// and nested values as JSON.
+//
+// Times are formatted in UTC, dates as 2006-01-02 and times of day as 15:04:05.999999999.
+// Binary values are formatted as their bytes.
+func (c *Column) Format(i int, timestampFormat string) string {
+	if c.dictionary != nil {
+		return c.dictionary.Format(c.Index(i), timestampFormat)
+	}
+
+	if c.types != nil {
+		child, j := c.unionValue(i)
+
+		return child.Format(j, timestampFormat)
+	}
+
+	if len(c.children) > 0 {
+		var buf bytes.Buffer
+
+		c.AppendJSON(&buf, i, timestampFormat)
+
+		return buf.String()
+	}
+
+	switch tp := c.tp.(type) {
+	case *schema.Int:
+		if tp.Signed {
+			return strconv.FormatInt(ReadInt64(c.data, tp, i), 10)
+		}
+
+		return strconv.FormatUint(uint64(ReadInt64(c.data, tp, i)), 10)
+
+	case *schema.FloatingPoint:
+		switch tp.Precision {
+		case schema.Half:
+			return strconv.FormatFloat(float64(float16(c.data.UInt2(i))), 'g', -1, 32)
+		case schema.Single:
+			return strconv.FormatFloat(float64(c.data.Float4(i)), 'g', -1, 32)
+		default:
+			return strconv.FormatFloat(c.data.Float8(i), 'g', -1, 64)
+		}
+
+	case *schema.Decimal:
+		return formatDecimal(c.data.Bytes()[i*tp.BitWidth/8:(i+1)*tp.BitWidth/8], tp.Scale)
+
+	case *schema.DateType:
+		return c.Date(i).Format("2006-01-02")
+
+	case *schema.TimeType:
+		v := int64(c.data.Int(i))
+
+		if tp.BitWidth == 64 {
+			v = c.data.BigInt(i)
+		}
+
+		return time.Unix(0, v*UnitNanos(tp.Unit)).UTC().Format("15:04:05.999999999")
+
+	case *schema.Timestamp:
+		return FromUnit(c.data.BigInt(i), tp.Unit).UTC().Format(timestampFormat)
+
+	case *schema.Duration:
+		return c.Duration(i).String()
+	}
+
+	if c.tp == schema.Bool {
+		return strconv.FormatBool(c.Bool(i))
+	}
+
+	return string(c.bytes(i))
+}
+
+// Append the value as JSON, structs are objects, lists are arrays and maps are arrays of key and value objects.
+//
+// Integers and finite floating points are numbers, booleans are booleans,
+// and the other values are strings formatted by Format.
+func (c *Column) AppendJSON(buf *bytes.Buffer, i int, timestampFormat string) {
+	c.appendJSON(buf, i, timestampFormat, false)
+}
+
+// Append the value as JSON, the null values of objects are omitted if omitNulls.
+func (c *Column) appendJSON(buf *bytes.Buffer, i int, timestampFormat string, omitNulls bool) {
+	if c.IsNull(i) {
+		buf.WriteString("null")
+
+		return
+	}
+
+	if c.dictionary != nil {
+		c.dictionary.appendJSON(buf, c.Index(i), timestampFormat, omitNulls)
+
+		return
+	}
+
+	switch tp := c.tp.(type) {
+	case *schema.Int:
+		buf.WriteString(c.Format(i, timestampFormat))
+
+	case *schema.FloatingPoint:
+		if s := c.Format(i, timestampFormat); isFinite(s) {
+			buf.WriteString(s)
+		} else {
+			appendJSONString(buf, s)
+		}
+
+	case *schema.Union:
+		child, j := c.unionValue(i)
+
+		child.appendJSON(buf, j, timestampFormat, omitNulls)
+
+	case *schema.FixedSizeList:
+		c.appendJSONArray(buf, i*tp.ListSize, (i+1)*tp.ListSize, timestampFormat, omitNulls)
+
+	case *schema.Map:
+		start, end := c.Span(i)
+
+		c.appendJSONArray(buf, start, end, timestampFormat, omitNulls)
+
+	default:
+		switch c.tp.Value() {
+		case flatbuf.TypeBool:
+			buf.WriteString(c.Format(i, timestampFormat))
+
+		case flatbuf.TypeList, v5.TypeLargeList:
+			start, end := c.Span(i)
+
+			c.appendJSONArray(buf, start, end, timestampFormat, omitNulls)
+
+		case flatbuf.TypeStruct_:
+			appendJSONObject(buf, c.children, i, timestampFormat, omitNulls)
+
+		default:
+			appendJSONString(buf, c.Format(i, timestampFormat))
+		}
+	}
+}
+
+// Append a JSON object of the values of the columns of a record batch at row i,
+// the null values of the objects are omitted if omitNulls.
+func AppendJSONRow(buf *bytes.Buffer, columns []*Column, i int, timestampFormat string, omitNulls bool) {
+	appendJSONObject(buf, columns, i, timestampFormat, omitNulls)
+}
+
+func appendJSONObject(buf *bytes.Buffer, columns []*Column, i int, timestampFormat string, omitNulls bool) {
+	buf.WriteByte('{')
+
+	first := true
+
+	for _, c := range columns {
+		if omitNulls && c.IsNull(i) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+
+		first = false
+
+		appendJSONString(buf, c.field.Name)
+		buf.WriteByte(':')
+		c.appendJSON(buf, i, timestampFormat, omitNulls)
+	}
+
+	buf.WriteByte('}')
+}
+
+func (c *Column) appendJSONArray(buf *bytes.Buffer, start, end int, timestampFormat string, omitNulls bool) {
+	buf.WriteByte('[')
+
+	for j := start; j < end; j++ {
+		if j > start {
+			buf.WriteByte(',')
+		}
+
+		c.children[0].appendJSON(buf, j, timestampFormat, omitNulls)
+	}
+
+	buf.WriteByte(']')
+}
+
+func appendJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+
+	buf.Truncate(buf.Len() - 1) // the newline of Encode
+}
+
+func isFinite(s string) bool {
+	v, err := strconv.ParseFloat(s, 64)
+
+	return err == nil && !math.IsInf(v, 0) && !math.IsNaN(v)
+}
+
+// Returns the little endian two's complement integer.
+func decimalInt(le []byte) *big.Int {
+	be := make([]byte, len(le))
+
+	for i, b := range le {
+		be[len(le)-1-i] = b
+	}
+
+	v := new(big.Int).SetBytes(be)
+
+	if len(be) > 0 && be[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(be)*8)))
+	}
+
+	return v
+}
+
+// Format the little endian two's complement integer, scaled by 10^-scale.
+func formatDecimal(le []byte, scale int) string {
+	v := decimalInt(le)
+	s := v.String()
+
+	if scale <= 0 {
+		if v.Sign() == 0 {
+			return s
+		}
+
+		return s + strings.Repeat("0", -scale)
+	}
+
+	sign := ""
+
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+
+	for len(s) <= scale {
+		s = "0" + s
+	}
+
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// Convert the bits of a half precision float.
+func float16(bits uint16) float32 {
+	sign := uint32(bits>>15) << 31
+	exp := uint32(bits>>10) & 0x1f
+	frac := uint32(bits) & 0x3ff
+
+	switch {
+	case exp == 0x1f: // infinity or NaN
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+
+	case exp == 0 && frac == 0:
+		return math.Float32frombits(sign)
+
+	case exp == 0: // subnormal
+		v := float32(frac) / (1 << 24)
+
+		if sign != 0 {
+			v = -v
+		}
+
+		return v
+	}
+
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
@@ -0,0 +1,23 @@
+package columns
+
+import "testing"
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		le    []byte
+		scale int
+		s     string
+	}{
+		{[]byte{0x39, 0x30, 0, 0}, 2, "123.45"},
+		{[]byte{0xc7, 0xcf, 0xff, 0xff}, 2, "-123.45"},
+		{[]byte{5, 0}, 3, "0.005"},
+		{[]byte{0xfb, 0xff}, 3, "-0.005"},
+		{[]byte{7, 0}, -2, "700"},
+	}
+
+	for _, test := range tests {
+		if s := formatDecimal(test.le, test.scale); s != test.s {
+			t.Errorf("decimal %v of scale %d formatted as %s, expected %s", test.le, test.scale, s, test.s)
+		}
+	}
+}
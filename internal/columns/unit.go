@@ -0,0 +1,82 @@
+package columns
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+)
+
+// Returns the integer at the index of the buffer, of the width and signedness of the type.
+func ReadInt64(buf *memory.Buffer, t *schema.Int, i int) int64 {
+	switch {
+	case t.BitWidth == 8 && t.Signed:
+		return int64(buf.TinyInt(i))
+	case t.BitWidth == 8:
+		return int64(buf.UInt1(i))
+	case t.BitWidth == 16 && t.Signed:
+		return int64(buf.SmallInt(i))
+	case t.BitWidth == 16:
+		return int64(buf.UInt2(i))
+	case t.BitWidth == 32 && t.Signed:
+		return int64(buf.Int(i))
+	case t.BitWidth == 32:
+		return int64(buf.UInt4(i))
+	default:
+		return buf.BigInt(i)
+	}
+}
+
+// Returns the nanoseconds of the time unit.
+func UnitNanos(unit schema.TimeUnit) int64 {
+	switch unit {
+	case schema.Second:
+		return int64(time.Second)
+	case schema.Millisecond:
+		return int64(time.Millisecond)
+	case schema.Microsecond:
+		return int64(time.Microsecond)
+	default:
+		return 1
+	}
+}
+
+// Returns the time of the value since the epoch in the unit.
+func FromUnit(v int64, unit schema.TimeUnit) time.Time {
+	n := UnitNanos(unit)
+	perSecond := int64(time.Second) / n
+
+	return time.Unix(v/perSecond, v%perSecond*n)
+}
+
+// Returns the location of a time zone, either a name of the tz database or a fixed offset as +07:00.
+func Location(tz string) (*time.Location, error) {
+	if len(tz) == 0 {
+		return time.UTC, nil
+	}
+
+	if (tz[0] == '+' || tz[0] == '-') && len(tz) == 6 && tz[3] == ':' {
+		hours, err := strconv.Atoi(tz[1:3])
+
+		if err != nil {
+			return nil, err
+		}
+
+		minutes, err := strconv.Atoi(tz[4:])
+
+		if err != nil {
+			return nil, err
+		}
+
+		offset := (hours*60 + minutes) * 60
+
+		if tz[0] == '-' {
+			offset = -offset
+		}
+
+		return time.FixedZone(tz, offset), nil
+	}
+
+	return time.LoadLocation(tz)
+}
@@ -0,0 +1,305 @@
+package jsonl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/flier/arrow/schema"
+)
+
+// A JSON object, whose keys are kept in the order they first appear.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Parse the line as a JSON object, whose values are nil, bool, json.Number, string,
+// []interface{} and *object.
+func parseLine(text []byte) (*object, error) {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	dec.UseNumber()
+
+	v, err := parseValue(dec)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON, %s", err)
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON, more than one value")
+	}
+
+	obj, ok := v.(*object)
+
+	if !ok {
+		return nil, fmt.Errorf("JSON %s is not an object", kindOf(v))
+	}
+
+	return obj, nil
+}
+
+func parseValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := &object{values: make(map[string]interface{})}
+
+		for dec.More() {
+			key, err := dec.Token()
+
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := parseValue(dec)
+
+			if err != nil {
+				return nil, err
+			}
+
+			name := key.(string)
+
+			if _, found := obj.values[name]; !found {
+				obj.keys = append(obj.keys, name)
+			}
+
+			obj.values[name] = v
+		}
+
+		_, err := dec.Token() // the closing brace
+
+		return obj, err
+
+	default:
+		items := []interface{}{}
+
+		for dec.More() {
+			v, err := parseValue(dec)
+
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, v)
+		}
+
+		_, err := dec.Token() // the closing bracket
+
+		return items, err
+	}
+}
+
+// The kinds of JSON values, in the order of the children of inferred unions.
+const (
+	kindBool = 1 << iota
+	kindInt
+	kindFloat
+	kindString
+	kindObject
+	kindArray
+)
+
+func kindOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number " + string(v)
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// The shape of the values of a field seen by the inference.
+type shape struct {
+	kinds    int
+	keys     []string // the keys of the objects, in the order they first appear
+	children map[string]*shape
+	items    *shape // the shape of the items of the arrays
+}
+
+func (s *shape) add(v interface{}) {
+	switch v := v.(type) {
+	case bool:
+		s.kinds |= kindBool
+
+	case json.Number:
+		// integers out of the range of int64 are inferred as floats
+		if _, err := strconv.ParseInt(string(v), 10, 64); err != nil {
+			s.kinds |= kindFloat
+		} else {
+			s.kinds |= kindInt
+		}
+
+	case string:
+		s.kinds |= kindString
+
+	case []interface{}:
+		s.kinds |= kindArray
+
+		if s.items == nil {
+			s.items = &shape{}
+		}
+
+		for _, item := range v {
+			s.items.add(item)
+		}
+
+	case *object:
+		s.kinds |= kindObject
+
+		if s.children == nil {
+			s.children = make(map[string]*shape)
+		}
+
+		for _, key := range v.keys {
+			child, found := s.children[key]
+
+			if !found {
+				child = &shape{}
+				s.children[key] = child
+				s.keys = append(s.keys, key)
+			}
+
+			child.add(v.values[key])
+		}
+	}
+}
+
+// Returns the nullable fields of the keys of the objects.
+func (s *shape) fields() ([]*schema.Field, error) {
+	fields := make([]*schema.Field, len(s.keys))
+
+	for i, key := range s.keys {
+		field, err := s.children[key].field(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = field
+	}
+
+	return fields, nil
+}
+
+// Returns the nullable field of the values, integers are widened to floating points if both are seen,
+// and the values of different kinds are held by a dense union of a child per kind.
+// The fields of only nulls, or of the items of empty arrays, are Utf8.
+func (s *shape) field(name string) (*schema.Field, error) {
+	kinds := s.kinds
+
+	if kinds&kindFloat != 0 {
+		kinds &^= kindInt
+	}
+
+	var children []*schema.Field
+
+	for kind := kindBool; kind <= kindArray; kind <<= 1 {
+		if kinds&kind == 0 {
+			continue
+		}
+
+		child, err := s.kindField(kind, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		children = append(children, child)
+	}
+
+	switch len(children) {
+	case 0:
+		return schema.NewField(name, true, schema.Utf8)
+
+	case 1:
+		return children[0], nil
+	}
+
+	for _, child := range children {
+		child.Name = kindName(child.Type)
+	}
+
+	return schema.NewField(name, true, schema.NewUnion(schema.Dense, nil), children...)
+}
+
+func (s *shape) kindField(kind int, name string) (*schema.Field, error) {
+	switch kind {
+	case kindBool:
+		return schema.NewField(name, true, schema.Bool)
+
+	case kindInt:
+		return schema.NewField(name, true, schema.NewInt(64, true))
+
+	case kindFloat:
+		return schema.NewField(name, true, schema.NewFloatingPoint(schema.Double))
+
+	case kindString:
+		return schema.NewField(name, true, schema.Utf8)
+
+	case kindObject:
+		children, err := s.fields()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return schema.NewField(name, true, schema.Struct, children...)
+
+	default:
+		items := s.items
+
+		if items == nil {
+			items = &shape{}
+		}
+
+		item, err := items.field("item")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return schema.NewField(name, true, schema.List, item)
+	}
+}
+
+// Returns the name of the child of an inferred union.
+func kindName(t schema.Type) string {
+	switch t.(type) {
+	case *schema.Int, *schema.FloatingPoint:
+		return "number"
+	}
+
+	switch t {
+	case schema.Bool:
+		return "boolean"
+	case schema.Utf8:
+		return "string"
+	case schema.Struct:
+		return "object"
+	default:
+		return "array"
+	}
+}
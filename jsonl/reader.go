@@ -0,0 +1,547 @@
+// Package jsonl reads and writes JSON Lines, one JSON object per line, as record batches.
+//
+// The fields of the objects are columns of the types Int, FloatingPoint in single or double precision,
+// Bool, Utf8, Binary, Timestamp, and the nested types Struct for objects, List for arrays
+// and Union for the fields holding values of different types.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const (
+	DefaultBatchSize = 1024
+	DefaultInferRows = 100
+)
+
+// ReadOptions controls how the JSON lines are read, the zero value infers the schema.
+type ReadOptions struct {
+	Schema    *schema.Schema // the schema of the objects, inferred from the first lines if nil
+	BatchSize int            // the number of rows of each batch, DefaultBatchSize if zero
+	InferRows int            // the number of lines the schema is inferred from, DefaultInferRows if zero
+}
+
+// ParseError is the error of a line which is not a JSON object, or of a value which doesn't match its field.
+type ParseError struct {
+	Line  int    // the line of the object in the input, from 1
+	Field string // the path of the field, empty for the errors of the line
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d field %s: %s", e.Line, e.Field, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Reader reads the JSON lines as record batches of the Schema.
+//
+// The keys of the objects which are not fields of the schema are ignored, and the missing fields are nulls.
+// Timestamps are read from strings in the RFC 3339 format, and binary values from the bytes of strings.
+type Reader struct {
+	Schema *schema.Schema
+
+	opts    ReadOptions
+	r       *bufio.Reader
+	line    int
+	builder *arrow.RecordBuilder
+
+	pending []*object // the objects read to infer the schema, and their lines
+	lines   []int
+	err     error
+}
+
+// Create a reader of the JSON lines, reading the lines to infer the schema.
+func NewReader(r io.Reader, opts ReadOptions) (*Reader, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	if opts.InferRows <= 0 {
+		opts.InferRows = DefaultInferRows
+	}
+
+	reader := &Reader{opts: opts, r: bufio.NewReader(r)}
+
+	if opts.Schema != nil {
+		for _, field := range opts.Schema.Fields {
+			if err := checkField(field, field.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		reader.Schema = opts.Schema
+	} else {
+		s, err := reader.infer()
+
+		if err != nil {
+			return nil, err
+		}
+
+		reader.Schema = s
+	}
+
+	builder, err := arrow.NewRecordBuilder(reader.Schema)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader.builder = builder
+
+	return reader, nil
+}
+
+// Check the field and its children are supported.
+func checkField(field *schema.Field, path string) error {
+	supported := field.Dictionary == nil
+
+	switch tp := field.Type.(type) {
+	case *schema.Int, *schema.Timestamp:
+	case *schema.FloatingPoint:
+		supported = supported && (tp.Precision == schema.Single || tp.Precision == schema.Double)
+	case *schema.Union:
+	default:
+		switch field.Type {
+		case schema.Bool, schema.Utf8, schema.Binary, schema.List, schema.Struct:
+		default:
+			supported = false
+		}
+	}
+
+	if !supported {
+		return fmt.Errorf("field %s of type %s is not supported by JSON lines", path, field.Type)
+	}
+
+	for _, child := range field.Children {
+		if err := checkField(child, path+"."+child.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Infer the schema from the first lines, which are kept to be read by Read.
+func (r *Reader) infer() (*schema.Schema, error) {
+	root := &shape{}
+
+	for len(r.pending) < r.opts.InferRows {
+		obj, line, err := r.read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		root.add(obj)
+
+		r.pending = append(r.pending, obj)
+		r.lines = append(r.lines, line)
+	}
+
+	fields, err := root.fields()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.Schema{Fields: fields}, nil
+}
+
+// Returns the next object and its line, the objects read to infer the schema first.
+func (r *Reader) next() (*object, int, error) {
+	if len(r.pending) > 0 {
+		obj, line := r.pending[0], r.lines[0]
+		r.pending, r.lines = r.pending[1:], r.lines[1:]
+
+		return obj, line, nil
+	}
+
+	return r.read()
+}
+
+// Returns the object of the next line which is not blank, and its line.
+func (r *Reader) read() (*object, int, error) {
+	for {
+		text, err := r.r.ReadBytes('\n')
+
+		if err != nil && err != io.EOF {
+			return nil, 0, fmt.Errorf("fail to read JSON lines, %s", err)
+		}
+
+		if len(text) == 0 && err == io.EOF {
+			return nil, 0, io.EOF
+		}
+
+		r.line++
+
+		if text = bytes.TrimSpace(text); len(text) == 0 {
+			continue
+		}
+
+		obj, perr := parseLine(text)
+
+		if perr != nil {
+			return nil, 0, &ParseError{Line: r.line, Err: perr}
+		}
+
+		return obj, r.line, nil
+	}
+}
+
+// Read the next batch of at most BatchSize rows, returns io.EOF after the last one.
+// The reader fails with the first error.
+func (r *Reader) Read() (*vector.RecordBatch, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	rows := 0
+
+	for ; rows < r.opts.BatchSize; rows++ {
+		obj, line, err := r.next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err == nil {
+			err = r.appendObject(obj, line)
+		}
+
+		if err != nil {
+			r.err = err
+
+			return nil, err
+		}
+	}
+
+	if rows == 0 {
+		r.err = io.EOF
+
+		return nil, io.EOF
+	}
+
+	batch, err := r.builder.NewRecordBatch()
+
+	if err != nil {
+		r.err = err
+
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *Reader) appendObject(obj *object, line int) error {
+	for i, field := range r.Schema.Fields {
+		if err := appendValue(r.builder.Columns[i], field, obj.values[field.Name]); err != nil {
+			var pe *ParseError
+
+			if errors.As(err, &pe) {
+				pe.Line, pe.Field = line, field.Name+pe.Field
+
+				return pe
+			}
+
+			return &ParseError{Line: line, Field: field.Name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Append the JSON value of the field, the errors of the children are ParseError of the path below the field.
+func appendValue(c *arrow.ColumnBuilder, field *schema.Field, v interface{}) error {
+	if union, ok := field.Type.(*schema.Union); ok {
+		return appendUnion(c, field, union, v)
+	}
+
+	if v == nil {
+		if !field.Nullable {
+			return errors.New("null value of a field which is not nullable")
+		}
+
+		c.AppendNull()
+
+		return nil
+	}
+
+	switch tp := field.Type.(type) {
+	case *schema.Int:
+		n, ok := v.(json.Number)
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		return appendInt(c, tp, string(n))
+
+	case *schema.FloatingPoint:
+		n, ok := v.(json.Number)
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		if tp.Precision == schema.Single {
+			f, err := strconv.ParseFloat(string(n), 32)
+
+			if err != nil {
+				return unwrap(err)
+			}
+
+			c.AppendFloat32(float32(f))
+		} else {
+			f, err := strconv.ParseFloat(string(n), 64)
+
+			if err != nil {
+				return unwrap(err)
+			}
+
+			c.AppendFloat64(f)
+		}
+
+		return nil
+
+	case *schema.Timestamp:
+		s, ok := v.(string)
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, s)
+
+		if err != nil {
+			return err
+		}
+
+		c.AppendTime(t)
+
+		return nil
+	}
+
+	switch field.Type {
+	case schema.Bool:
+		b, ok := v.(bool)
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		c.AppendBool(b)
+
+	case schema.Utf8, schema.Binary:
+		s, ok := v.(string)
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		if field.Type == schema.Utf8 {
+			c.AppendString(s)
+		} else {
+			c.AppendBytes([]byte(s))
+		}
+
+	case schema.List:
+		items, ok := v.([]interface{})
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		for i, item := range items {
+			if err := appendValue(c.Child(0), field.Children[0], item); err != nil {
+				return childError(fmt.Sprintf("[%d]", i), err)
+			}
+		}
+
+		c.AppendList()
+
+	case schema.Struct:
+		obj, ok := v.(*object)
+
+		if !ok {
+			return mismatch(field, v)
+		}
+
+		c.AppendStruct()
+
+		for i, child := range field.Children {
+			if err := appendValue(c.Child(i), child, obj.values[child.Name]); err != nil {
+				return childError("."+child.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Append the value to the first child of the union which holds its kind, and nulls to the first child.
+func appendUnion(c *arrow.ColumnBuilder, field *schema.Field, union *schema.Union, v interface{}) error {
+	if v == nil {
+		if len(field.Children) == 0 || !field.Children[0].Nullable {
+			return errors.New("null value of a union whose first child is not nullable")
+		}
+
+		c.AppendNull()
+
+		return nil
+	}
+
+	for i, child := range field.Children {
+		if holds(child, v) {
+			if err := appendValue(c.AppendUnion(i), child, v); err != nil {
+				return childError("."+child.Name, err)
+			}
+
+			return nil
+		}
+	}
+
+	return mismatch(field, v)
+}
+
+// Returns true if the field holds the kind of the value.
+func holds(field *schema.Field, v interface{}) bool {
+	switch tp := field.Type.(type) {
+	case *schema.Int:
+		n, ok := v.(json.Number)
+
+		if !ok {
+			return false
+		}
+
+		if tp.Signed {
+			_, err := strconv.ParseInt(string(n), 10, tp.BitWidth)
+
+			return err == nil
+		}
+
+		_, err := strconv.ParseUint(string(n), 10, tp.BitWidth)
+
+		return err == nil
+
+	case *schema.FloatingPoint:
+		_, ok := v.(json.Number)
+
+		return ok
+
+	case *schema.Timestamp:
+		s, ok := v.(string)
+
+		if !ok {
+			return false
+		}
+
+		_, err := time.Parse(time.RFC3339Nano, s)
+
+		return err == nil
+
+	case *schema.Union:
+		for _, child := range field.Children {
+			if holds(child, v) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch v.(type) {
+	case bool:
+		return field.Type == schema.Bool
+	case string:
+		return field.Type == schema.Utf8 || field.Type == schema.Binary
+	case []interface{}:
+		return field.Type == schema.List
+	case *object:
+		return field.Type == schema.Struct
+	}
+
+	return false
+}
+
+func appendInt(c *arrow.ColumnBuilder, tp *schema.Int, s string) error {
+	if tp.Signed {
+		v, err := strconv.ParseInt(s, 10, tp.BitWidth)
+
+		if err != nil {
+			return unwrap(err)
+		}
+
+		switch tp.BitWidth {
+		case 8:
+			c.AppendInt8(int8(v))
+		case 16:
+			c.AppendInt16(int16(v))
+		case 32:
+			c.AppendInt32(int32(v))
+		default:
+			c.AppendInt64(v)
+		}
+	} else {
+		v, err := strconv.ParseUint(s, 10, tp.BitWidth)
+
+		if err != nil {
+			return unwrap(err)
+		}
+
+		switch tp.BitWidth {
+		case 8:
+			c.AppendUint8(uint8(v))
+		case 16:
+			c.AppendUint16(uint16(v))
+		case 32:
+			c.AppendUint32(uint32(v))
+		default:
+			c.AppendUint64(v)
+		}
+	}
+
+	return nil
+}
+
+func mismatch(field *schema.Field, v interface{}) error {
+	return fmt.Errorf("JSON %s doesn't match type %s", kindOf(v), field.Type)
+}
+
+// Returns the error of a child, with its path prefixed by the name or index of the child.
+func childError(name string, err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		pe.Field = name + pe.Field
+
+		return pe
+	}
+
+	return &ParseError{Field: name, Err: err}
+}
+
+func unwrap(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return fmt.Errorf("fail to parse %s, %w", ne.Num, ne.Err)
+	}
+
+	return err
+}
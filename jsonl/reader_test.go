@@ -0,0 +1,211 @@
+package jsonl
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/flier/arrow/schema"
+)
+
+func TestReadInfer(t *testing.T) {
+	text := `{"id": 1, "score": 1, "tags": ["a"], "user": {"name": "alice"}, "value": true}
+{"id": 2, "score": 2.5, "tags": [], "user": {"name": "bob", "age": 7}, "value": "x"}
+
+{"id": 3, "tags": null, "user": null, "value": [1, 2.5], "extra": null}
+{"id": 4, "score": -1e3, "value": {"k": 1}}
+`
+
+	r, err := NewReader(strings.NewReader(text), ReadOptions{BatchSize: 3})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "struct<id: int64, score: float64, tags: list<utf8>, user: struct<name: utf8, age: int64>, " +
+		"value: dense_union<boolean: bool, string: utf8, object: struct<k: int64>, array: list<float64>>, extra: utf8>"
+
+	if r.Schema.String() != expected {
+		t.Fatalf("inferred %s, expected %s", r.Schema, expected)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Schema.Validate(batch); err != nil {
+		t.Fatalf("invalid batch, %s", err)
+	}
+
+	if batch.Length != 3 || batch.Nodes[0].NullCount != 0 || batch.Nodes[1].NullCount != 1 || batch.Nodes[2].NullCount != 1 {
+		t.Errorf("batch of %d rows and nodes %v, expected 3 rows", batch.Length, batch.Nodes)
+	}
+
+	if score := batch.Buffers[3].Float8(1); score != 2.5 {
+		t.Errorf("score %f at 1, expected 2.5", score)
+	}
+
+	batch, err = r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Schema.Validate(batch); err != nil {
+		t.Fatalf("invalid batch, %s", err)
+	}
+
+	if batch.Length != 1 {
+		t.Errorf("batch of %d rows, expected 1", batch.Length)
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("got error %v, expected EOF", err)
+	}
+}
+
+func TestReadInferLargeInteger(t *testing.T) {
+	r, err := NewReader(strings.NewReader(`{"a": 18446744073709551615}`+"\n"+`{"a": 1}`), ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "struct<a: float64>"; r.Schema.String() != expected {
+		t.Fatalf("inferred %s, expected %s", r.Schema, expected)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a := batch.Buffers[1].Float8(0); a != 18446744073709551615 {
+		t.Errorf("a %f at 0, expected 18446744073709551615", a)
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	s, err := schema.Parse("struct<code: uint8 not null, ratio: float32, at: timestamp[ms], points: list<item: struct<x: int16, y: int16>>>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := `{"code": 7, "ratio": 0.25, "at": "2024-01-02T03:04:05.006Z", "points": [{"x": 1, "y": -2}, {"x": 3}], "other": "ignored"}
+{"code": 255}
+`
+
+	r, err := NewReader(strings.NewReader(text), ReadOptions{Schema: s})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Validate(batch); err != nil {
+		t.Fatalf("invalid batch, %s", err)
+	}
+
+	if code := batch.Buffers[1].UInt1(1); code != 255 {
+		t.Errorf("code %d at 1, expected 255", code)
+	}
+
+	if at := batch.Buffers[5].BigInt(0); at != 1704164645006 {
+		t.Errorf("at %d at 0, expected 1704164645006", at)
+	}
+
+	if points := batch.Nodes[4]; points.Length != 2 || points.NullCount != 0 {
+		t.Errorf("points node %v, expected 2 points", points)
+	}
+
+	if y := batch.Nodes[6]; y.NullCount != 1 {
+		t.Errorf("y node %v, expected a null", y)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	s, err := schema.Parse("struct<id: int8 not null, user: struct<tags: list<item: utf8>>>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text  string
+		line  int
+		field string
+		err   string
+	}{
+		{"{\"id\": 1}\n\n{\"id\": 300}\n", 3, "id", "value out of range"},
+		{`{"id": null}`, 1, "id", "not nullable"},
+		{`{"id": 1, "user": {"tags": ["a", 2]}}`, 1, "user.tags[1]", "JSON number 2 doesn't match type utf8"},
+		{`{"id": 1} {}`, 1, "", "more than one value"},
+		{`[1]`, 1, "", "JSON array is not an object"},
+		{`{"id": `, 1, "", "invalid JSON"},
+	}
+
+	for _, test := range tests {
+		r, err := NewReader(strings.NewReader(test.text), ReadOptions{Schema: s})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = r.Read()
+
+		var pe *ParseError
+
+		if !errors.As(err, &pe) || pe.Line != test.line || pe.Field != test.field || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("read %q with error %v, expected %s of %s at line %d", test.text, err, test.err, test.field, test.line)
+		}
+
+		if _, again := r.Read(); again != err {
+			t.Errorf("got error %v after %v", again, err)
+		}
+	}
+
+	r, err := NewReader(strings.NewReader(`{"id": 300}`), ReadOptions{Schema: s})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Read(); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("got error %v, expected out of range", err)
+	}
+
+	r, err = NewReader(strings.NewReader("{\"n\": 1}\n{\"n\": \"x\"}\n"), ReadOptions{InferRows: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Read(); err == nil || !strings.Contains(err.Error(), "line 2 field n: JSON string doesn't match type int64") {
+		t.Errorf("got error %v, expected a mismatch after the inferred lines", err)
+	}
+
+	if _, err := NewReader(strings.NewReader(""), ReadOptions{Schema: &schema.Schema{Fields: []*schema.Field{mustField(t, "f", schema.NewFloatingPoint(schema.Half))}}}); err == nil {
+		t.Errorf("expected half precision floats to be unsupported")
+	}
+}
+
+func mustField(t *testing.T, name string, tp schema.Type) *schema.Field {
+	field, err := schema.NewField(name, true, tp)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return field
+}
@@ -0,0 +1,74 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// WriteOptions controls how the JSON lines are written, the zero value writes nulls.
+type WriteOptions struct {
+	TimestampFormat string // the format of timestamps, time.RFC3339Nano if empty
+	OmitNulls       bool   // the null fields are omitted from the objects
+}
+
+// Writer writes the record batches of the Schema as JSON lines, one object per row.
+//
+// Structs are objects, lists are arrays, maps are arrays of key and value objects, and unions are
+// the values of their children. Integers and finite floating points are numbers, and the other
+// values are strings: times in UTC, dates as 2006-01-02, times of day as 15:04:05.999999999,
+// and binary values as their bytes. Dictionary encoded fields are written as their values.
+type Writer struct {
+	Schema *schema.Schema
+
+	opts WriteOptions
+	w    *bufio.Writer
+	buf  bytes.Buffer
+}
+
+// Create a writer of the record batches of the schema.
+func NewWriter(w io.Writer, s *schema.Schema, opts WriteOptions) *Writer {
+	if opts.TimestampFormat == "" {
+		opts.TimestampFormat = time.RFC3339Nano
+	}
+
+	return &Writer{Schema: s, opts: opts, w: bufio.NewWriter(w)}
+}
+
+// Write the rows of the record batch, which is validated against the schema.
+func (w *Writer) Write(batch *vector.RecordBatch) error {
+	cols, err := columns.New(w.Schema, batch)
+
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < batch.Length; i++ {
+		w.buf.Reset()
+
+		columns.AppendJSONRow(&w.buf, cols, i, w.opts.TimestampFormat, w.opts.OmitNulls)
+
+		w.buf.WriteByte('\n')
+
+		if _, err := w.w.Write(w.buf.Bytes()); err != nil {
+			return fmt.Errorf("fail to write JSON lines, %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Flush the written rows.
+func (w *Writer) Flush() error {
+	if err := w.w.Flush(); err != nil {
+		return fmt.Errorf("fail to write JSON lines, %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package jsonl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow"
+)
+
+type event struct {
+	ID    int64
+	Kind  *string `arrow:"kind,dict"`
+	Score float64
+	At    time.Time `arrow:"at,unit=ms"`
+	Tags  []string
+	Attrs map[string]int8
+}
+
+func TestWrite(t *testing.T) {
+	kind := `say "hi", <b>`
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	rows := []event{
+		{ID: 1, Kind: &kind, Score: 1.5, At: at, Tags: []string{"a", "b"}, Attrs: map[string]int8{"k": 1}},
+		{ID: -2, Score: -0.25, At: at.Add(time.Hour)},
+	}
+
+	batch, s, err := arrow.Marshal(rows)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, s, WriteOptions{OmitNulls: true})
+
+	if err := w.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"ID":1,"kind":"say \"hi\", <b>","Score":1.5,"at":"2024-01-02T03:04:05.006Z","Tags":["a","b"],"Attrs":[{"key":"k","value":1}]}
+{"ID":-2,"Score":-0.25,"at":"2024-01-02T04:04:05.006Z","Tags":[],"Attrs":[]}
+`
+
+	if buf.String() != expected {
+		t.Errorf("written\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	text := `{"id":1,"score":1.5,"user":{"name":"alice","tags":["a"]},"value":true}
+{"id":2,"score":null,"user":null,"value":"x"}
+{"id":3,"score":-2,"user":{"name":null,"tags":[]},"value":[1,{"k":2}]}
+{"id":null,"score":null,"user":null,"value":null}
+`
+
+	r, err := NewReader(strings.NewReader(text), ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	w := NewWriter(&buf, r.Schema, WriteOptions{})
+
+	if err := w.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != text {
+		t.Errorf("written\n%s\nexpected\n%s", buf.String(), text)
+	}
+}
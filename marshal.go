@@ -0,0 +1,503 @@
+// Package arrow converts between slices of Go structs and record batches.
+package arrow
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// The schema of a Go struct type and the Go struct field of each field, cached by codecOf.
+type codec struct {
+	schema *schema.Schema
+	index  schema.StructIndex
+}
+
+var codecs sync.Map // reflect.Type -> *codec
+
+// Returns the codec of the struct type, or a pointer to it, derived once by schema.FromStructIndex.
+func codecOf(t reflect.Type) (*codec, error) {
+	if c, found := codecs.Load(t); found {
+		return c.(*codec), nil
+	}
+
+	s, index, err := schema.FromStructIndex(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, _ := codecs.LoadOrStore(t, &codec{s, index})
+
+	return c.(*codec), nil
+}
+
+// Marshal the slice of structs, or pointers to them, into a record batch.
+//
+// The schema of the batch is derived from the element type by schema.FromStruct,
+// and shared by the calls of the same type, it must not be modified.
+// The dictionaries of the dictionary encoded fields are built from the distinct values
+// of the slice, and returned in the Dictionaries of the batch.
+func Marshal(v interface{}) (*vector.RecordBatch, *schema.Schema, error) {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("fail to marshal %T, expected a slice of structs", v)
+	}
+
+	c, err := codecOf(rv.Type().Elem())
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to marshal %T, %s", v, err)
+	}
+
+	rows := make([]reflect.Value, rv.Len())
+
+	for i := range rows {
+		row := rv.Index(i)
+
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				return nil, nil, fmt.Errorf("fail to marshal %T, nil row at %d", v, i)
+			}
+
+			row = row.Elem()
+		}
+
+		rows[i] = row
+	}
+
+	e := &encoder{batch: &vector.RecordBatch{Length: len(rows)}, index: c.index}
+
+	for _, field := range c.schema.Fields {
+		if err := e.encode(field, fieldValues(rows, c.index[field]), field.Name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return e.batch, c.schema, nil
+}
+
+type encoder struct {
+	batch *vector.RecordBatch
+	size  int64
+	index schema.StructIndex
+}
+
+// Append a buffer to the body of the batch, at the next 8 byte boundary.
+func (e *encoder) addBuffer(buf []byte) {
+	e.size = (e.size + 7) &^ 7
+
+	e.batch.Buffers = append(e.batch.Buffers, memory.NewBuffer(buf))
+	e.batch.Layouts = append(e.batch.Layouts, &vector.Buffer{Offset: e.size, Size: int64(len(buf))})
+
+	e.size += int64(len(buf))
+}
+
+func (e *encoder) addNode(length, nullCount int) {
+	e.batch.Nodes = append(e.batch.Nodes, &vector.FieldNode{Length: length, NullCount: nullCount})
+}
+
+// Append the validity bitmap, omitted without nulls.
+func (e *encoder) addValidity(valid []bool, nullCount int) {
+	if nullCount == 0 {
+		e.addBuffer(nil)
+
+		return
+	}
+
+	e.addBuffer(bitmap(valid))
+}
+
+// Encode the values of the field and its children, nil pointers are nulls.
+func (e *encoder) encode(field *schema.Field, values []reflect.Value, path string) error {
+	valid, nullCount := deref(values)
+
+	if field.Dictionary != nil {
+		return e.encodeDictionary(field, values, valid, nullCount, path)
+	}
+
+	e.addNode(len(values), nullCount)
+	e.addValidity(valid, nullCount)
+
+	switch tp := field.Type.(type) {
+	case *schema.Int:
+		data := memory.NewBuffer(make([]byte, len(values)*tp.BitWidth/8))
+
+		for i, v := range values {
+			putInt(data, tp.BitWidth, i, intBits(v))
+		}
+
+		e.addBuffer(data.Bytes())
+
+	case *schema.FloatingPoint:
+		if tp.Precision == schema.Single {
+			data := memory.NewBuffer(make([]byte, len(values)*4))
+
+			for i, v := range values {
+				data.PutFloat4(i, float32(v.Float()))
+			}
+
+			e.addBuffer(data.Bytes())
+		} else {
+			data := memory.NewBuffer(make([]byte, len(values)*8))
+
+			for i, v := range values {
+				data.PutFloat8(i, v.Float())
+			}
+
+			e.addBuffer(data.Bytes())
+		}
+
+	case *schema.Timestamp:
+		data := memory.NewBuffer(make([]byte, len(values)*8))
+
+		for i, v := range values {
+			t := v.Interface().(time.Time)
+
+			if valid[i] && !inUnitRange(t, tp.Unit) {
+				return fmt.Errorf("field %s has time %s at %d out of the range of %s", path, t, i, field.Type)
+			}
+
+			data.PutBigInt(i, toUnit(t, tp.Unit))
+		}
+
+		e.addBuffer(data.Bytes())
+
+	case *schema.Duration:
+		data := memory.NewBuffer(make([]byte, len(values)*8))
+
+		for i, v := range values {
+			data.PutBigInt(i, v.Int()/columns.UnitNanos(tp.Unit))
+		}
+
+		e.addBuffer(data.Bytes())
+
+	case *schema.FixedSizeBinary:
+		data := make([]byte, len(values)*tp.ByteWidth)
+
+		for i, v := range values {
+			reflect.Copy(reflect.ValueOf(data[i*tp.ByteWidth:(i+1)*tp.ByteWidth]), v)
+		}
+
+		e.addBuffer(data)
+
+	case *schema.FixedSizeList:
+		var items []reflect.Value
+
+		for _, v := range values {
+			for j := 0; j < tp.ListSize; j++ {
+				items = append(items, v.Index(j))
+			}
+		}
+
+		return e.encode(field.Children[0], items, path+"."+field.Children[0].Name)
+
+	case *schema.Map:
+		return e.encodeMap(field, values, path)
+
+	default:
+		switch field.Type {
+		case schema.Bool:
+			data := make([]bool, len(values))
+
+			for i, v := range values {
+				data[i] = v.Bool()
+			}
+
+			e.addBuffer(bitmap(data))
+
+		case schema.Utf8, schema.Binary:
+			offsets := memory.NewBuffer(make([]byte, (len(values)+1)*4))
+
+			var data []byte
+
+			for i, v := range values {
+				if v.Kind() == reflect.String {
+					data = append(data, v.String()...)
+				} else {
+					data = append(data, v.Bytes()...)
+				}
+
+				if len(data) > math.MaxInt32 {
+					return fmt.Errorf("field %s has more than %d bytes", path, math.MaxInt32)
+				}
+
+				offsets.PutInt(i+1, int32(len(data)))
+			}
+
+			e.addBuffer(offsets.Bytes())
+			e.addBuffer(data)
+
+		case schema.List:
+			offsets := memory.NewBuffer(make([]byte, (len(values)+1)*4))
+
+			var items []reflect.Value
+
+			for i, v := range values {
+				for j := 0; j < v.Len(); j++ {
+					items = append(items, v.Index(j))
+				}
+
+				if len(items) > math.MaxInt32 {
+					return fmt.Errorf("field %s has more than %d items", path, math.MaxInt32)
+				}
+
+				offsets.PutInt(i+1, int32(len(items)))
+			}
+
+			e.addBuffer(offsets.Bytes())
+
+			return e.encode(field.Children[0], items, path+"."+field.Children[0].Name)
+
+		case schema.Struct:
+			for _, child := range field.Children {
+				if err := e.encode(child, fieldValues(values, e.index[child]), path+"."+child.Name); err != nil {
+					return err
+				}
+			}
+
+		default:
+			return fmt.Errorf("field %s of type %s can't be marshaled", path, field.Type)
+		}
+	}
+
+	return nil
+}
+
+// Encode the entries of the maps sorted by their keys.
+func (e *encoder) encodeMap(field *schema.Field, values []reflect.Value, path string) error {
+	entries := field.Children[0]
+	offsets := memory.NewBuffer(make([]byte, (len(values)+1)*4))
+
+	var keys, items []reflect.Value
+
+	for i, v := range values {
+		mapKeys := v.MapKeys()
+
+		sort.Slice(mapKeys, func(a, b int) bool { return keyLess(mapKeys[a], mapKeys[b]) })
+
+		for _, key := range mapKeys {
+			keys = append(keys, key)
+			items = append(items, v.MapIndex(key))
+		}
+
+		if len(keys) > math.MaxInt32 {
+			return fmt.Errorf("field %s has more than %d entries", path, math.MaxInt32)
+		}
+
+		offsets.PutInt(i+1, int32(len(keys)))
+	}
+
+	e.addBuffer(offsets.Bytes())
+
+	path += "." + entries.Name
+
+	e.addNode(len(keys), 0)
+	e.addBuffer(nil)
+
+	if err := e.encode(entries.Children[0], keys, path+"."+entries.Children[0].Name); err != nil {
+		return err
+	}
+
+	return e.encode(entries.Children[1], items, path+"."+entries.Children[1].Name)
+}
+
+// Encode the indices of the distinct values, in the order they first appear,
+// and the dictionary of the values as a batch of a single column.
+func (e *encoder) encodeDictionary(field *schema.Field, values []reflect.Value, valid []bool, nullCount int, path string) error {
+	index := field.Dictionary.Index()
+	indices := memory.NewBuffer(make([]byte, len(values)*index.BitWidth/8))
+	positions := make(map[interface{}]int)
+
+	var distinct []reflect.Value
+
+	for i, v := range values {
+		if !valid[i] {
+			continue
+		}
+
+		key := v.Interface()
+
+		if v.Kind() == reflect.Slice {
+			key = string(v.Bytes())
+		}
+
+		pos, found := positions[key]
+
+		if !found {
+			pos = len(distinct)
+			positions[key] = pos
+			distinct = append(distinct, v)
+		}
+
+		putInt(indices, index.BitWidth, i, int64(pos))
+	}
+
+	if int64(len(distinct)) > maxIndex(index)+1 {
+		return fmt.Errorf("field %s has %d distinct values, more than its index type %s holds", path, len(distinct), index)
+	}
+
+	e.addNode(len(values), nullCount)
+	e.addValidity(valid, nullCount)
+	e.addBuffer(indices.Bytes())
+
+	layout, err := schema.NewTypeLayout(field.Type)
+
+	if err != nil {
+		return fmt.Errorf("field %s has no dictionary layout, %s", path, err)
+	}
+
+	f := *field
+	f.Dictionary = nil
+	f.Layout = layout
+
+	d := &encoder{batch: &vector.RecordBatch{Length: len(distinct)}, index: e.index}
+
+	if err := d.encode(&f, distinct, path); err != nil {
+		return err
+	}
+
+	if e.batch.Dictionaries == nil {
+		e.batch.Dictionaries = make(map[int64]*vector.RecordBatch)
+	}
+
+	e.batch.Dictionaries[field.Dictionary.ID] = d.batch
+
+	return nil
+}
+
+// Returns the Go struct field of the index in each value,
+// the zero value if it is promoted through a nil embedded pointer.
+func fieldValues(values []reflect.Value, index []int) []reflect.Value {
+	fields := make([]reflect.Value, len(values))
+
+	for i, v := range values {
+		fields[i] = fieldByIndex(v, index)
+	}
+
+	return fields
+}
+
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Zero(v.Type().Elem().FieldByIndex(index[i:]).Type)
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// Replace the pointers by the values they point to, nil pointers by zero values.
+// Returns which values are valid and the number of nulls.
+func deref(values []reflect.Value) ([]bool, int) {
+	valid := make([]bool, len(values))
+	nullCount := 0
+
+	for i, v := range values {
+		valid[i] = true
+
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				valid[i] = false
+				nullCount++
+				values[i] = reflect.Zero(v.Type().Elem())
+			} else {
+				values[i] = v.Elem()
+			}
+		}
+	}
+
+	return valid, nullCount
+}
+
+func bitmap(bits []bool) []byte {
+	buf := make([]byte, (len(bits)+7)/8)
+
+	for i, bit := range bits {
+		if bit {
+			buf[i>>3] |= 1 << uint(i&7)
+		}
+	}
+
+	return buf
+}
+
+// Returns the bits of a Go integer, unsigned integers are reinterpreted.
+func intBits(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	default:
+		return int64(v.Uint())
+	}
+}
+
+func putInt(buf *memory.Buffer, bitWidth, i int, v int64) {
+	switch bitWidth {
+	case 8:
+		buf.PutTinyInt(i, int8(v))
+	case 16:
+		buf.PutSmallInt(i, int16(v))
+	case 32:
+		buf.PutInt(i, int32(v))
+	default:
+		buf.PutBigInt(i, v)
+	}
+}
+
+// Returns the largest index held by the index type of a dictionary.
+func maxIndex(t *schema.Int) int64 {
+	bits := uint(t.BitWidth)
+
+	if t.Signed || bits >= 64 {
+		bits--
+	}
+
+	return 1<<bits - 1
+}
+
+// Returns the time since the epoch in the unit, truncated toward zero.
+func toUnit(t time.Time, unit schema.TimeUnit) int64 {
+	n := columns.UnitNanos(unit)
+
+	return t.Unix()*(int64(time.Second)/n) + int64(t.Nanosecond())/n
+}
+
+func inUnitRange(t time.Time, unit schema.TimeUnit) bool {
+	perSecond := int64(time.Second) / columns.UnitNanos(unit)
+
+	return t.Unix() > math.MinInt64/perSecond && t.Unix() < math.MaxInt64/perSecond
+}
+
+func keyLess(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	default:
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+}
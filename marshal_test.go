@@ -0,0 +1,165 @@
+package arrow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow/schema/vector"
+)
+
+type Point struct {
+	X, Y float64
+}
+
+type Base struct {
+	ID      int64
+	Created time.Time `arrow:"created,unit=us,tz=UTC"`
+}
+
+type Row struct {
+	Base
+
+	Name    string `arrow:"name,dict=int8"`
+	Code    *string
+	Score   float32
+	Flags   uint16
+	Active  bool
+	Digest  [4]byte
+	Data    []byte
+	Timeout time.Duration `arrow:"timeout,unit=ms"`
+	Tags    []string
+	Points  []*Point
+	Origin  *Point
+	Range   [2]int32
+	Attrs   map[string]*int16
+	Skipped int `arrow:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	code := "x1"
+	level := int16(3)
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123456000, time.UTC)
+
+	rows := []Row{
+		{
+			Base:    Base{ID: 1, Created: now},
+			Name:    "alice",
+			Code:    &code,
+			Score:   1.5,
+			Flags:   0xffff,
+			Active:  true,
+			Digest:  [4]byte{1, 2, 3, 4},
+			Data:    []byte("data"),
+			Timeout: 3 * time.Second,
+			Tags:    []string{"a", "b"},
+			Points:  []*Point{{1, 2}, nil, {3, 4}},
+			Origin:  &Point{5, 6},
+			Range:   [2]int32{-1, 1},
+			Attrs:   map[string]*int16{"level": &level, "none": nil},
+		},
+		{
+			Base: Base{ID: 2, Created: now.Add(-time.Hour)},
+			Name: "bob",
+			Tags: []string{},
+		},
+		{
+			Base:  Base{ID: 3, Created: now},
+			Name:  "alice",
+			Attrs: map[string]*int16{},
+		},
+	}
+
+	batch, s, err := Marshal(rows)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Validate(batch); err != nil {
+		t.Fatalf("invalid batch, %s", err)
+	}
+
+	if dict := batch.Dictionaries[1]; dict == nil || dict.Length != 2 {
+		t.Errorf("dictionary of names %v, expected 2 distinct values", dict)
+	}
+
+	var decoded []Row
+
+	if err := Unmarshal(batch, s, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	// nil and empty slices and maps are not distinguished
+	rows[0].Data = []byte("data")
+	rows[1].Data, rows[2].Data = []byte{}, []byte{}
+	rows[1].Points, rows[2].Points = []*Point{}, []*Point{}
+	rows[2].Tags = []string{}
+	rows[1].Attrs = map[string]*int16{}
+
+	if !reflect.DeepEqual(decoded, rows) {
+		t.Errorf("decoded %+v, expected %+v", decoded, rows)
+	}
+
+	var pointers []*Row
+
+	if err := Unmarshal(batch, s, &pointers); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pointers) != 3 || pointers[2].ID != 3 || pointers[2].Name != "alice" {
+		t.Errorf("decoded %+v, expected the rows", pointers)
+	}
+}
+
+func TestMarshalError(t *testing.T) {
+	if _, _, err := Marshal(Row{}); err == nil || !strings.Contains(err.Error(), "expected a slice of structs") {
+		t.Errorf("got error %v, expected a slice of structs", err)
+	}
+
+	if _, _, err := Marshal([]*Row{nil}); err == nil || !strings.Contains(err.Error(), "nil row at 0") {
+		t.Errorf("got error %v, expected nil row", err)
+	}
+
+	type Event struct {
+		At time.Time
+	}
+
+	if _, _, err := Marshal([]Event{{}}); err == nil || !strings.Contains(err.Error(), "field At has time") {
+		t.Errorf("got error %v, expected time out of range", err)
+	}
+
+	batch, s, err := Marshal([]Point{{1, 2}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []Row
+
+	if err := Unmarshal(batch, s, &rows); err == nil {
+		t.Errorf("unmarshal points to rows, expected error")
+	}
+
+	if err := Unmarshal(batch, s, rows); err == nil || !strings.Contains(err.Error(), "expected a pointer to a slice") {
+		t.Errorf("got error %v, expected a pointer to a slice", err)
+	}
+
+	ints, s, err := Marshal([]struct{ A int32 }{{7}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var floats []struct{ A float32 }
+
+	if err := Unmarshal(ints, s, &floats); err == nil || !strings.Contains(err.Error(), "field A has type int32") {
+		t.Errorf("got error %v, expected field A has type int32", err)
+	}
+
+	// the schema is checked before any buffer is read
+	if err := Unmarshal(&vector.RecordBatch{Length: 1}, s, &floats); err == nil || !strings.Contains(err.Error(), "field A has type int32") {
+		t.Errorf("got error %v, expected field A has type int32", err)
+	}
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	minSizeClass = 6  // 64 bytes
+	maxSizeClass = 28 // 256 MiB
+)
+
+// An abstraction that is used to obtain and recycle byte slices.
+type Allocator interface {
+	// Returns a slice of the given length, its contents are unspecified.
+	Allocate(size int) []byte
+
+	// Returns a slice obtained from Allocate to the allocator.
+	Free(buf []byte)
+}
+
+// The allocator used when none was specified.
+var DefaultAllocator Allocator = GoAllocator{}
+
+// GoAllocator allocates from the Go heap and leaves freed memory to the GC.
+type GoAllocator struct{}
+
+func (GoAllocator) Allocate(size int) []byte { return make([]byte, size) }
+
+func (GoAllocator) Free(buf []byte) {}
+
+// PoolAllocator recycles memory through sync.Pools of power-of-two size classes.
+//
+// Requests larger than the biggest size class are served from the Go heap.
+type PoolAllocator struct {
+	pools [maxSizeClass - minSizeClass + 1]sync.Pool
+}
+
+func NewPoolAllocator() *PoolAllocator {
+	return &PoolAllocator{}
+}
+
+func sizeClass(size int) int {
+	if size <= 1<<minSizeClass {
+		return 0
+	}
+
+	c := bits.Len(uint(size-1)) - minSizeClass
+
+	if c > maxSizeClass-minSizeClass {
+		return -1
+	}
+
+	return c
+}
+
+func (a *PoolAllocator) Allocate(size int) []byte {
+	c := sizeClass(size)
+
+	if c < 0 {
+		return make([]byte, size)
+	}
+
+	if p, ok := a.pools[c].Get().(*[]byte); ok {
+		return (*p)[:size]
+	}
+
+	return make([]byte, size, 1<<uint(c+minSizeClass))
+}
+
+func (a *PoolAllocator) Free(buf []byte) {
+	c := sizeClass(cap(buf))
+
+	if c < 0 || cap(buf) != 1<<uint(c+minSizeClass) {
+		return
+	}
+
+	buf = buf[:0]
+
+	a.pools[c].Put(&buf)
+}
+
+// Memory is a reference counted region of bytes.
+//
+// The region is handed back to its owner once the last reference is released.
+type Memory struct {
+	buf  []byte
+	refs int64
+	free func([]byte)
+}
+
+// Allocate a region of the given size with a single reference.
+func NewMemory(allocator Allocator, size int) *Memory {
+	return WrapMemory(allocator.Allocate(size), allocator.Free)
+}
+
+// Wrap an existing region with a single reference, free is called on the last release.
+func WrapMemory(buf []byte, free func([]byte)) *Memory {
+	return &Memory{
+		buf:  buf,
+		refs: 1,
+		free: free,
+	}
+}
+
+func (m *Memory) Bytes() []byte { return m.buf }
+
+func (m *Memory) Len() int { return len(m.buf) }
+
+// Increment the reference count.
+func (m *Memory) Retain() {
+	atomic.AddInt64(&m.refs, 1)
+}
+
+// Decrement the reference count, the region is freed when it drops to zero.
+func (m *Memory) Release() {
+	refs := atomic.AddInt64(&m.refs, -1)
+
+	if refs == 0 {
+		if m.free != nil {
+			m.free(m.buf)
+		}
+
+		m.buf = nil
+	} else if refs < 0 {
+		panic("memory: release of freed memory")
+	}
+}
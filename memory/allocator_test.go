@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSizeClass(t *testing.T) {
+	tests := []struct {
+		size, class, capacity int
+	}{
+		{0, 0, 64},
+		{1, 0, 64},
+		{64, 0, 64},
+		{65, 1, 128},
+		{128, 1, 128},
+		{129, 2, 256},
+		{1000, 4, 1024},
+		{1 << 20, 14, 1 << 20},
+		{1<<20 + 1, 15, 1 << 21},
+		{1 << maxSizeClass, maxSizeClass - minSizeClass, 1 << maxSizeClass},
+		{1<<maxSizeClass + 1, -1, 1<<maxSizeClass + 1},
+	}
+
+	a := NewPoolAllocator()
+
+	for _, test := range tests {
+		if c := sizeClass(test.size); c != test.class {
+			t.Errorf("size %d has class %d, expected %d", test.size, c, test.class)
+		}
+
+		if test.size > 1<<24 {
+			continue
+		}
+
+		buf := a.Allocate(test.size)
+
+		if len(buf) != test.size || cap(buf) != test.capacity {
+			t.Errorf("allocate %d bytes with length %d and capacity %d, expected capacity %d",
+				test.size, len(buf), cap(buf), test.capacity)
+		}
+
+		a.Free(buf)
+	}
+}
+
+// sync.Pool may drop what is put into it, under the race detector on purpose, so recycling is retried.
+func recycled(a *PoolAllocator, size int, release func(buf []byte)) bool {
+	for i := 0; i < 100; i++ {
+		buf := a.Allocate(size)
+		release(buf)
+
+		if next := a.Allocate(size); &next[:1][0] == &buf[:1][0] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestPoolAllocatorRecycle(t *testing.T) {
+	a := NewPoolAllocator()
+
+	if !recycled(a, 100, a.Free) {
+		t.Error("freed buffer is never recycled")
+	}
+
+	release := func(buf []byte) {
+		m := WrapMemory(buf, a.Free)
+		m.Retain()
+		m.Release()
+		m.Release()
+	}
+
+	if !recycled(a, 1000, release) {
+		t.Error("released memory is never recycled")
+	}
+
+	// a buffer of another capacity does not belong to any size class
+	a.Free(make([]byte, 100))
+
+	if buf := a.Allocate(100); cap(buf) != 128 {
+		t.Errorf("allocate from a pool with capacity %d, expected 128", cap(buf))
+	}
+}
+
+func TestMemoryRelease(t *testing.T) {
+	var freed int64
+
+	m := WrapMemory(make([]byte, 10), func([]byte) { atomic.AddInt64(&freed, 1) })
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				m.Retain()
+				m.Release()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt64(&freed); n != 0 {
+		t.Fatalf("freed %d times while referenced", n)
+	}
+
+	m.Release()
+
+	if freed != 1 || m.Bytes() != nil {
+		t.Errorf("freed %d times, expected once", freed)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("release freed memory without panic")
+		}
+	}()
+
+	m.Release()
+}
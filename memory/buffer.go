@@ -83,6 +83,28 @@ func (b *Buffer) TimeStamp(index int) time.Time {
 	return time.Unix(ts/1000, (ts%1000)*int64(time.Millisecond))
 }
 
+func (b *Buffer) DateDay(index int) time.Time {
+	return time.Unix(int64(b.Int(index))*int64(Day/time.Second), 0)
+}
+
+func (b *Buffer) TimeSecond(index int) time.Time {
+	return time.Unix(int64(b.Int(index)), 0)
+}
+
+func (b *Buffer) TimeMicro(index int) time.Time {
+	ts := b.BigInt(index)
+
+	return time.Unix(ts/1000000, (ts%1000000)*int64(time.Microsecond))
+}
+
+func (b *Buffer) TimeNano(index int) time.Time {
+	return time.Unix(0, b.BigInt(index))
+}
+
+func (b *Buffer) Duration(index int) time.Duration {
+	return time.Duration(b.BigInt(index))
+}
+
 func (b *Buffer) IntervalDay(index int) time.Duration {
 	days := b.Int(index * 2)
 	milliseconds := b.Int(index*2 + 1)
@@ -98,6 +120,19 @@ func (b *Buffer) VarChar(index int) string { return "" }
 
 func (b *Buffer) VarBinary(index int) []byte { return nil }
 
+// The offsets hold the 64-bit start of each value, followed by the end of the last one.
+func (b *Buffer) LargeVarChar(offsets *Buffer, index int) string {
+	return string(b.LargeVarBinary(offsets, index))
+}
+
+func (b *Buffer) LargeVarBinary(offsets *Buffer, index int) []byte {
+	return b.Bytes()[offsets.BigInt(index):offsets.BigInt(index+1)]
+}
+
+func (b *Buffer) FixedBinary(width, index int) []byte {
+	return b.Bytes()[index*width : (index+1)*width]
+}
+
 func (b *Buffer) PutTinyInt(index int, v int8) {
 	b.PutUInt1(index, uint8(v))
 }
@@ -150,6 +185,34 @@ func (b *Buffer) PutTimeStamp(index int, v time.Time) {
 	b.PutBigInt(index, v.UnixNano()/int64(time.Millisecond))
 }
 
+func (b *Buffer) PutDateDay(index int, v time.Time) {
+	s := v.Unix()
+	days := s / int64(Day/time.Second)
+
+	// round the days before the epoch down instead of toward zero
+	if s%int64(Day/time.Second) < 0 {
+		days--
+	}
+
+	b.PutInt(index, int32(days))
+}
+
+func (b *Buffer) PutTimeSecond(index int, v time.Time) {
+	b.PutInt(index, int32(v.Unix()))
+}
+
+func (b *Buffer) PutTimeMicro(index int, v time.Time) {
+	b.PutBigInt(index, v.UnixNano()/int64(time.Microsecond))
+}
+
+func (b *Buffer) PutTimeNano(index int, v time.Time) {
+	b.PutBigInt(index, v.UnixNano())
+}
+
+func (b *Buffer) PutDuration(index int, v time.Duration) {
+	b.PutBigInt(index, int64(v))
+}
+
 func (b *Buffer) PutIntervalDay(index int, v time.Duration) {
 	b.PutInt(index*2, int32(v/Day))
 	b.PutInt(index*2+1, int32((v%Day)/time.Millisecond))
@@ -162,3 +225,21 @@ func (b *Buffer) PutIntervalYear(index int, v time.Duration) {
 func (b *Buffer) PutVarChar(index int, v string) {}
 
 func (b *Buffer) PutVarBinary(index int, v []byte) {}
+
+// Write the value at the start in the offsets, the values after it are dropped.
+func (b *Buffer) PutLargeVarChar(offsets *Buffer, index int, v string) {
+	b.PutLargeVarBinary(offsets, index, []byte(v))
+}
+
+func (b *Buffer) PutLargeVarBinary(offsets *Buffer, index int, v []byte) {
+	start := offsets.BigInt(index)
+
+	b.Truncate(int(start))
+	b.Write(v)
+
+	offsets.PutBigInt(index+1, start+int64(len(v)))
+}
+
+func (b *Buffer) PutFixedBinary(width, index int, v []byte) {
+	copy(b.Bytes()[index*width:(index+1)*width], v)
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBufferTime(t *testing.T) {
+	b := NewBuffer(make([]byte, 16))
+	day := time.Unix(19000*86400, 0)
+	beforeEpoch := time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC)
+	second := time.Unix(1234567890, 0)
+	micro := time.Unix(1234567890, 123456000)
+	nano := time.Unix(1234567890, 123456789)
+
+	tests := []struct {
+		name     string
+		put      func()
+		get      func() time.Time
+		expected time.Time
+	}{
+		{"DateDay", func() { b.PutDateDay(1, day) }, func() time.Time { return b.DateDay(1) }, day},
+		{"DateDay before epoch", func() { b.PutDateDay(1, beforeEpoch) }, func() time.Time { return b.DateDay(1) }, time.Unix(-86400, 0)},
+		{"TimeSecond", func() { b.PutTimeSecond(1, second) }, func() time.Time { return b.TimeSecond(1) }, second},
+		{"TimeMicro", func() { b.PutTimeMicro(1, micro) }, func() time.Time { return b.TimeMicro(1) }, micro},
+		{"TimeNano", func() { b.PutTimeNano(1, nano) }, func() time.Time { return b.TimeNano(1) }, nano},
+	}
+
+	for _, test := range tests {
+		test.put()
+
+		if v := test.get(); !v.Equal(test.expected) {
+			t.Errorf("%s is %s, expected %s", test.name, v, test.expected)
+		}
+	}
+
+	b.PutDateDay(1, beforeEpoch)
+
+	if b.Int(1) != -1 {
+		t.Errorf("DateDay of %s is %d, expected -1", beforeEpoch, b.Int(1))
+	}
+
+	b.PutDuration(1, -90*time.Second)
+
+	if v := b.Duration(1); v != -90*time.Second || b.BigInt(1) != int64(-90*time.Second) {
+		t.Errorf("Duration is %s, expected -1m30s", v)
+	}
+}
+
+func TestBufferBinary(t *testing.T) {
+	offsets := NewBuffer(make([]byte, 24))
+	b := NewBuffer(nil)
+
+	b.PutLargeVarChar(offsets, 0, "hello")
+	b.PutLargeVarBinary(offsets, 1, []byte("world"))
+
+	if v := b.LargeVarChar(offsets, 0); v != "hello" {
+		t.Errorf("LargeVarChar is %s, expected hello", v)
+	}
+
+	if v := b.LargeVarBinary(offsets, 1); string(v) != "world" || offsets.BigInt(2) != 10 {
+		t.Errorf("LargeVarBinary is %s ending at %d, expected world ending at 10", v, offsets.BigInt(2))
+	}
+
+	// rewrite the first value and drop the second
+	b.PutLargeVarChar(offsets, 0, "hi")
+
+	if v := b.LargeVarChar(offsets, 0); v != "hi" || b.Len() != 2 {
+		t.Errorf("LargeVarChar is %s with %d bytes, expected hi with 2 bytes", v, b.Len())
+	}
+
+	b = NewBuffer(make([]byte, 6))
+	b.PutFixedBinary(3, 1, []byte("abc"))
+
+	if v := b.FixedBinary(3, 1); string(v) != "abc" {
+		t.Errorf("FixedBinary is %s, expected abc", v)
+	}
+}
@@ -0,0 +1,488 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/flier/arrow"
+)
+
+var errShortPage = errors.New("unexpected end of page")
+
+// values is the decoded values of a column, of the fixed width, the booleans or the byte arrays.
+type values struct {
+	width int
+	fixed []byte
+	bools []bool
+	bytes [][]byte
+}
+
+func (v *values) len() int {
+	switch {
+	case v.width > 0:
+		return len(v.fixed) / v.width
+	case v.bools != nil:
+		return len(v.bools)
+	default:
+		return len(v.bytes)
+	}
+}
+
+func (v *values) append(o *values) {
+	v.fixed = append(v.fixed, o.fixed...)
+	v.bools = append(v.bools, o.bools...)
+	v.bytes = append(v.bytes, o.bytes...)
+}
+
+// Returns the values of the dictionary at the indices.
+func (v *values) gather(indices []int) (*values, error) {
+	n := v.len()
+	o := &values{width: v.width}
+
+	if v.bools != nil {
+		o.bools = make([]bool, 0, len(indices))
+	}
+
+	for _, i := range indices {
+		if i >= n {
+			return nil, fmt.Errorf("dictionary index %d out of %d values", i, n)
+		}
+
+		switch {
+		case v.width > 0:
+			o.fixed = append(o.fixed, v.fixed[i*v.width:(i+1)*v.width]...)
+		case v.bools != nil:
+			o.bools = append(o.bools, v.bools[i])
+		default:
+			o.bytes = append(o.bytes, v.bytes[i])
+		}
+	}
+
+	return o, nil
+}
+
+// Returns the width of the values of the fixed width types, zero for the others.
+func valueWidth(e *schemaElement) int {
+	switch e.Type {
+	case typeInt32, typeFloat:
+		return 4
+	case typeInt64, typeDouble:
+		return 8
+	case typeInt96:
+		return 12
+	case typeFixedLenByteArray:
+		return int(e.TypeLength)
+	}
+
+	return 0
+}
+
+// Decode n values in the plain encoding.
+func decodePlain(e *schemaElement, buf []byte, n int) (*values, error) {
+	if e.Type == typeBoolean {
+		if len(buf)*8 < n {
+			return nil, errShortPage
+		}
+
+		v := &values{bools: make([]bool, n)}
+
+		for i := range v.bools {
+			v.bools[i] = buf[i>>3]&(1<<uint(i&7)) != 0
+		}
+
+		return v, nil
+	}
+
+	if width := valueWidth(e); width > 0 {
+		if len(buf)/width < n {
+			return nil, errShortPage
+		}
+
+		return &values{width: width, fixed: buf[:n*width]}, nil
+	}
+
+	v := &values{bytes: make([][]byte, n)}
+
+	for i := range v.bytes {
+		if len(buf) < 4 {
+			return nil, errShortPage
+		}
+
+		size := binary.LittleEndian.Uint32(buf)
+		buf = buf[4:]
+
+		if uint64(size) > uint64(len(buf)) {
+			return nil, errShortPage
+		}
+
+		v.bytes[i], buf = buf[:size], buf[size:]
+	}
+
+	return v, nil
+}
+
+// Decode n values of the bit width in the RLE/bit-packed hybrid encoding, without the length prefix.
+func decodeRLE(buf []byte, bitWidth, n int) ([]int, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", bitWidth)
+	}
+
+	out := make([]int, 0, n)
+	byteWidth := (bitWidth + 7) / 8
+
+	for len(out) < n {
+		header, size := binary.Uvarint(buf)
+
+		if size <= 0 {
+			return nil, errShortPage
+		}
+
+		buf = buf[size:]
+
+		if header&1 == 0 {
+			// a run of the same value
+			count := header >> 1
+
+			if len(buf) < byteWidth {
+				return nil, errShortPage
+			}
+
+			var v int
+
+			for i := 0; i < byteWidth; i++ {
+				v |= int(buf[i]) << uint(8*i)
+			}
+
+			buf = buf[byteWidth:]
+
+			if count > uint64(n-len(out)) {
+				count = uint64(n - len(out))
+			}
+
+			for i := uint64(0); i < count; i++ {
+				out = append(out, v)
+			}
+		} else {
+			// groups of 8 bit-packed values
+			groups := header >> 1
+
+			if groups > uint64(len(buf)) || int(groups)*bitWidth > len(buf) {
+				return nil, errShortPage
+			}
+
+			count := int(groups) * 8
+			packed := buf[:int(groups)*bitWidth]
+			buf = buf[len(packed):]
+
+			for i := 0; i < count && len(out) < n; i++ {
+				out = append(out, unpack(packed, i, bitWidth))
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// Returns the i-th value of the bit width packed from the least significant bit.
+func unpack(buf []byte, i, bitWidth int) int {
+	v := 0
+
+	for b := 0; b < bitWidth; b++ {
+		bit := i*bitWidth + b
+
+		if buf[bit>>3]&(1<<uint(bit&7)) != 0 {
+			v |= 1 << uint(b)
+		}
+	}
+
+	return v
+}
+
+// Decode the levels with the length prefix of the data pages V1, returns the rest of the page.
+func decodeLevels(buf []byte, encoding int32, bitWidth, n int) ([]int, []byte, error) {
+	switch encoding {
+	case encodingRLE:
+		if len(buf) < 4 {
+			return nil, nil, errShortPage
+		}
+
+		size := binary.LittleEndian.Uint32(buf)
+
+		if uint64(size) > uint64(len(buf)-4) {
+			return nil, nil, errShortPage
+		}
+
+		levels, err := decodeRLE(buf[4:4+size], bitWidth, n)
+
+		return levels, buf[4+size:], err
+
+	case encodingBitPacked:
+		// the deprecated encoding packs the levels from the most significant bit
+		size := (n*bitWidth + 7) / 8
+
+		if len(buf) < size {
+			return nil, nil, errShortPage
+		}
+
+		levels := make([]int, n)
+
+		for i := range levels {
+			for b := 0; b < bitWidth; b++ {
+				bit := i*bitWidth + b
+
+				levels[i] <<= 1
+
+				if buf[bit>>3]&(0x80>>uint(bit&7)) != 0 {
+					levels[i] |= 1
+				}
+			}
+		}
+
+		return levels, buf[size:], nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported encoding %s of levels", encodingName(encoding))
+}
+
+// Decode n values of the page in the encoding, the dictionary encoded values are looked up in the dictionary.
+func decodeValues(e *schemaElement, encoding int32, buf []byte, n int, dict *values) (*values, error) {
+	switch encoding {
+	case encodingPlain:
+		return decodePlain(e, buf, n)
+
+	case encodingPlainDictionary, encodingRLEDictionary:
+		if dict == nil {
+			return nil, errors.New("dictionary encoded page without dictionary")
+		}
+
+		if n == 0 {
+			return &values{width: dict.width}, nil
+		}
+
+		if len(buf) == 0 {
+			return nil, errShortPage
+		}
+
+		indices, err := decodeRLE(buf[1:], int(buf[0]), n)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return dict.gather(indices)
+
+	case encodingRLE:
+		if e.Type != typeBoolean {
+			break
+		}
+
+		if len(buf) < 4 {
+			return nil, errShortPage
+		}
+
+		size := binary.LittleEndian.Uint32(buf)
+
+		if uint64(size) > uint64(len(buf)-4) {
+			return nil, errShortPage
+		}
+
+		bits, err := decodeRLE(buf[4:4+size], 1, n)
+
+		if err != nil {
+			return nil, err
+		}
+
+		v := &values{bools: make([]bool, n)}
+
+		for i, b := range bits {
+			v.bools[i] = b != 0
+		}
+
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unsupported encoding %s of values", encodingName(encoding))
+}
+
+func encodingName(encoding int32) string {
+	if name, found := encodingNames[encoding]; found {
+		return name
+	}
+
+	return fmt.Sprintf("%d", encoding)
+}
+
+// appendFunc appends the i-th value to the builder of the column.
+type appendFunc func(c *arrow.ColumnBuilder, v *values, i int)
+
+func appendBool(c *arrow.ColumnBuilder, v *values, i int) { c.AppendBool(v.bools[i]) }
+
+func appendBytes(c *arrow.ColumnBuilder, v *values, i int) {
+	if v.width > 0 {
+		c.AppendBytes(v.fixed[i*v.width : (i+1)*v.width])
+	} else {
+		c.AppendBytes(v.bytes[i])
+	}
+}
+
+// Append the fixed width value in little endian, whose width matches the Arrow type.
+func appendFixed(c *arrow.ColumnBuilder, v *values, i int) {
+	b := v.fixed[i*v.width : (i+1)*v.width]
+
+	switch v.width {
+	case 2:
+		c.AppendUint16(binary.LittleEndian.Uint16(b))
+	case 4:
+		c.AppendUint32(binary.LittleEndian.Uint32(b))
+	default:
+		c.AppendUint64(binary.LittleEndian.Uint64(b))
+	}
+}
+
+// Returns the function appending the integers stored in 32 bits to a narrower Int type.
+func appendNarrow(bitWidth int) appendFunc {
+	return func(c *arrow.ColumnBuilder, v *values, i int) {
+		n := binary.LittleEndian.Uint32(v.fixed[i*4:])
+
+		if bitWidth == 8 {
+			c.AppendUint8(uint8(n))
+		} else {
+			c.AppendUint16(uint16(n))
+		}
+	}
+}
+
+// Append the timestamp of the nanoseconds of the day and the Julian day.
+func appendInt96(c *arrow.ColumnBuilder, v *values, i int) {
+	b := v.fixed[i*12 : (i+1)*12]
+	nanos := int64(binary.LittleEndian.Uint64(b))
+	days := int64(binary.LittleEndian.Uint32(b[8:])) - 2440588
+
+	c.AppendInt64(days*86400e9 + nanos)
+}
+
+// Append the unscaled decimal of an integer, or of the bytes of the two's complement in big endian.
+func appendDecimal(c *arrow.ColumnBuilder, v *values, i int) {
+	var b []byte
+
+	switch {
+	case v.width == 4:
+		c.AppendDecimal(big.NewInt(int64(int32(binary.LittleEndian.Uint32(v.fixed[i*4:])))))
+
+		return
+	case v.width == 8:
+		c.AppendDecimal(big.NewInt(int64(binary.LittleEndian.Uint64(v.fixed[i*8:]))))
+
+		return
+	case v.width > 0:
+		b = v.fixed[i*v.width : (i+1)*v.width]
+	default:
+		b = v.bytes[i]
+	}
+
+	n := new(big.Int).SetBytes(b)
+
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+
+	c.AppendDecimal(n)
+}
+
+// Returns the i-th value in the plain encoding, without the length of the byte arrays.
+func (v *values) value(i int) []byte {
+	switch {
+	case v.width > 0:
+		return v.fixed[i*v.width : (i+1)*v.width]
+	case v.bools != nil:
+		if v.bools[i] {
+			return []byte{1}
+		}
+
+		return []byte{0}
+	default:
+		return v.bytes[i]
+	}
+}
+
+// Append the values in [start, end) in the plain encoding.
+func (v *values) encodePlain(buf []byte, start, end int) []byte {
+	switch {
+	case v.width > 0:
+		return append(buf, v.fixed[start*v.width:end*v.width]...)
+
+	case v.bools != nil:
+		packed := make([]byte, (end-start+7)/8)
+
+		for i, b := range v.bools[start:end] {
+			if b {
+				packed[i>>3] |= 1 << uint(i&7)
+			}
+		}
+
+		return append(buf, packed...)
+	}
+
+	for _, b := range v.bytes[start:end] {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(b)))
+		buf = append(buf, b...)
+	}
+
+	return buf
+}
+
+// Append the values of the bit width in the RLE/bit-packed hybrid encoding, the runs of 8 or more
+// repeated values are run length encoded, and the others are bit-packed in groups of 8.
+func encodeRLE(buf []byte, vs []int, bitWidth int) []byte {
+	run := func(i int) int {
+		j := i + 1
+
+		for j < len(vs) && vs[j] == vs[i] {
+			j++
+		}
+
+		return j - i
+	}
+
+	for i := 0; i < len(vs); {
+		if n := run(i); n >= 8 {
+			buf = binary.AppendUvarint(buf, uint64(n)<<1)
+
+			for b := 0; b < (bitWidth+7)/8; b++ {
+				buf = append(buf, byte(vs[i]>>uint(8*b)))
+			}
+
+			i += n
+
+			continue
+		}
+
+		start := i
+
+		for i < len(vs) && (i == start || run(i) < 8) {
+			i += 8
+		}
+
+		if i > len(vs) {
+			i = len(vs)
+		}
+
+		groups := (i - start + 7) / 8
+		packed := make([]byte, groups*bitWidth)
+
+		for j, v := range vs[start:i] {
+			for b := 0; b < bitWidth; b++ {
+				if bit := j*bitWidth + b; v&(1<<uint(b)) != 0 {
+					packed[bit>>3] |= 1 << uint(bit&7)
+				}
+			}
+		}
+
+		buf = binary.AppendUvarint(buf, uint64(groups)<<1|1)
+		buf = append(buf, packed...)
+	}
+
+	return buf
+}
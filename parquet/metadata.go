@@ -0,0 +1,789 @@
+package parquet
+
+// The physical types of the values.
+const (
+	typeBoolean           = 0
+	typeInt32             = 1
+	typeInt64             = 2
+	typeInt96             = 3
+	typeFloat             = 4
+	typeDouble            = 5
+	typeByteArray         = 6
+	typeFixedLenByteArray = 7
+)
+
+// The repetitions of the fields.
+const (
+	required = 0
+	optional = 1
+	repeated = 2
+)
+
+// The converted types, which annotate the physical types in the older files.
+const (
+	convertedUTF8            = 0
+	convertedMap             = 1
+	convertedMapKeyValue     = 2
+	convertedList            = 3
+	convertedEnum            = 4
+	convertedDecimal         = 5
+	convertedDate            = 6
+	convertedTimeMillis      = 7
+	convertedTimeMicros      = 8
+	convertedTimestampMillis = 9
+	convertedTimestampMicros = 10
+	convertedUint8           = 11
+	convertedUint16          = 12
+	convertedUint32          = 13
+	convertedUint64          = 14
+	convertedInt8            = 15
+	convertedInt16           = 16
+	convertedInt32           = 17
+	convertedInt64           = 18
+	convertedJSON            = 19
+	convertedBSON            = 20
+	convertedInterval        = 21
+	convertedNone            = -1
+)
+
+// The encodings of the values and levels.
+const (
+	encodingPlain                = 0
+	encodingPlainDictionary      = 2
+	encodingRLE                  = 3
+	encodingBitPacked            = 4
+	encodingDeltaBinaryPacked    = 5
+	encodingDeltaLengthByteArray = 6
+	encodingDeltaByteArray       = 7
+	encodingRLEDictionary        = 8
+	encodingByteStreamSplit      = 9
+)
+
+// The compression codecs of the pages.
+const (
+	codecUncompressed = 0
+	codecSnappy       = 1
+	codecGzip         = 2
+	codecLZO          = 3
+	codecBrotli       = 4
+	codecLZ4          = 5
+	codecZstd         = 6
+	codecLZ4Raw       = 7
+)
+
+// The types of the pages.
+const (
+	pageData       = 0
+	pageIndex      = 1
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+var codecNames = map[int32]string{
+	codecUncompressed: "UNCOMPRESSED",
+	codecSnappy:       "SNAPPY",
+	codecGzip:         "GZIP",
+	codecLZO:          "LZO",
+	codecBrotli:       "BROTLI",
+	codecLZ4:          "LZ4",
+	codecZstd:         "ZSTD",
+	codecLZ4Raw:       "LZ4_RAW",
+}
+
+var encodingNames = map[int32]string{
+	encodingPlain:                "PLAIN",
+	encodingPlainDictionary:      "PLAIN_DICTIONARY",
+	encodingRLE:                  "RLE",
+	encodingBitPacked:            "BIT_PACKED",
+	encodingDeltaBinaryPacked:    "DELTA_BINARY_PACKED",
+	encodingDeltaLengthByteArray: "DELTA_LENGTH_BYTE_ARRAY",
+	encodingDeltaByteArray:       "DELTA_BYTE_ARRAY",
+	encodingRLEDictionary:        "RLE_DICTIONARY",
+	encodingByteStreamSplit:      "BYTE_STREAM_SPLIT",
+}
+
+type fileMetaData struct {
+	Version   int32
+	Schema    []*schemaElement
+	NumRows   int64
+	RowGroups []*rowGroup
+	KeyValues []*keyValue
+	CreatedBy string
+}
+
+type schemaElement struct {
+	Type          int32 // -1 for groups
+	TypeLength    int32
+	Repetition    int32
+	Name          string
+	NumChildren   int32
+	ConvertedType int32 // convertedNone if not annotated
+	Scale         int32
+	Precision     int32
+	FieldID       int32
+	LogicalType   *logicalType
+}
+
+// logicalType is the union of the logical types, which annotate the physical types in the newer files.
+type logicalType struct {
+	String, Map, List, Enum, Date, JSON, BSON, UUID, Float16, Unknown bool
+
+	Decimal   *decimalType
+	Time      *timeType
+	Timestamp *timeType
+	Integer   *intType
+}
+
+type decimalType struct {
+	Scale, Precision int32
+}
+
+// The time and timestamp types, whose unit is 1 for milliseconds, 2 for microseconds and 3 for nanoseconds.
+type timeType struct {
+	AdjustedToUTC bool
+	Unit          int
+}
+
+type intType struct {
+	BitWidth int8
+	Signed   bool
+}
+
+type rowGroup struct {
+	Columns       []*columnChunk
+	TotalByteSize int64
+	NumRows       int64
+}
+
+type columnChunk struct {
+	FilePath   string
+	FileOffset int64
+	MetaData   *columnMetaData
+}
+
+type columnMetaData struct {
+	Type                  int32
+	Encodings             []int32
+	Path                  []string
+	Codec                 int32
+	NumValues             int64
+	TotalUncompressedSize int64
+	TotalCompressedSize   int64
+	DataPageOffset        int64
+	DictionaryPageOffset  int64 // zero if there is no dictionary page
+	Statistics            *statistics
+}
+
+// statistics of the values of a column chunk, whose min and max values are in the plain encoding.
+type statistics struct {
+	NullCount          int64
+	MinValue, MaxValue []byte // nil if unknown
+}
+
+type keyValue struct {
+	Key, Value string
+}
+
+type pageHeader struct {
+	Type                 int32
+	UncompressedPageSize int32
+	CompressedPageSize   int32
+	DataPage             *dataPageHeader
+	DictionaryPage       *dictionaryPageHeader
+	DataPageV2           *dataPageHeaderV2
+}
+
+type dataPageHeader struct {
+	NumValues               int32
+	Encoding                int32
+	DefinitionLevelEncoding int32
+	RepetitionLevelEncoding int32
+}
+
+type dictionaryPageHeader struct {
+	NumValues int32
+	Encoding  int32
+	IsSorted  bool
+}
+
+type dataPageHeaderV2 struct {
+	NumValues                  int32
+	NumNulls                   int32
+	NumRows                    int32
+	Encoding                   int32
+	DefinitionLevelsByteLength int32
+	RepetitionLevelsByteLength int32
+	IsCompressed               bool
+}
+
+func (m *fileMetaData) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 1:
+			return r.readI32(tp, &m.Version)
+
+		case id == 2 && tp == thriftList:
+			return r.readList(func(tp byte) error {
+				e := &schemaElement{}
+				m.Schema = append(m.Schema, e)
+
+				return e.read(r, tp)
+			})
+
+		case id == 3:
+			return r.readI64(tp, &m.NumRows)
+
+		case id == 4 && tp == thriftList:
+			return r.readList(func(tp byte) error {
+				g := &rowGroup{}
+				m.RowGroups = append(m.RowGroups, g)
+
+				return g.read(r, tp)
+			})
+
+		case id == 5 && tp == thriftList:
+			return r.readList(func(tp byte) error {
+				kv := &keyValue{}
+				m.KeyValues = append(m.KeyValues, kv)
+
+				return kv.read(r, tp)
+			})
+
+		case id == 6:
+			return r.readString(tp, &m.CreatedBy)
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (e *schemaElement) read(r *thriftReader, tp byte) error {
+	if tp != thriftStruct {
+		return r.skip(tp)
+	}
+
+	e.Type, e.ConvertedType = -1, convertedNone
+
+	return r.readStruct(func(id int16, tp byte) error {
+		switch id {
+		case 1:
+			return r.readI32(tp, &e.Type)
+		case 2:
+			return r.readI32(tp, &e.TypeLength)
+		case 3:
+			return r.readI32(tp, &e.Repetition)
+		case 4:
+			return r.readString(tp, &e.Name)
+		case 5:
+			return r.readI32(tp, &e.NumChildren)
+		case 6:
+			return r.readI32(tp, &e.ConvertedType)
+		case 7:
+			return r.readI32(tp, &e.Scale)
+		case 8:
+			return r.readI32(tp, &e.Precision)
+		case 9:
+			return r.readI32(tp, &e.FieldID)
+		case 10:
+			if tp == thriftStruct {
+				e.LogicalType = &logicalType{}
+
+				return e.LogicalType.read(r)
+			}
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (t *logicalType) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, tp byte) error {
+		if tp != thriftStruct {
+			return r.skip(tp)
+		}
+
+		switch id {
+		case 1:
+			t.String = true
+		case 2:
+			t.Map = true
+		case 3:
+			t.List = true
+		case 4:
+			t.Enum = true
+		case 5:
+			t.Decimal = &decimalType{}
+
+			return r.readStruct(func(id int16, tp byte) error {
+				switch id {
+				case 1:
+					return r.readI32(tp, &t.Decimal.Scale)
+				case 2:
+					return r.readI32(tp, &t.Decimal.Precision)
+				}
+
+				return r.skip(tp)
+			})
+		case 6:
+			t.Date = true
+		case 7, 8:
+			tt := &timeType{}
+
+			if id == 7 {
+				t.Time = tt
+			} else {
+				t.Timestamp = tt
+			}
+
+			return tt.read(r)
+		case 10:
+			t.Integer = &intType{}
+
+			return r.readStruct(func(id int16, tp byte) error {
+				switch {
+				case id == 1 && tp == thriftByte:
+					b, err := r.byte()
+					t.Integer.BitWidth = int8(b)
+
+					return err
+				case id == 2:
+					return r.readBool(tp, &t.Integer.Signed)
+				}
+
+				return r.skip(tp)
+			})
+		case 11:
+			t.Unknown = true
+		case 12:
+			t.JSON = true
+		case 13:
+			t.BSON = true
+		case 14:
+			t.UUID = true
+		case 15:
+			t.Float16 = true
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (t *timeType) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 1:
+			return r.readBool(tp, &t.AdjustedToUTC)
+
+		case id == 2 && tp == thriftStruct:
+			// the union of the units, whose field id is the unit
+			return r.readStruct(func(id int16, tp byte) error {
+				t.Unit = int(id)
+
+				return r.skip(tp)
+			})
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (g *rowGroup) read(r *thriftReader, tp byte) error {
+	if tp != thriftStruct {
+		return r.skip(tp)
+	}
+
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 1 && tp == thriftList:
+			return r.readList(func(tp byte) error {
+				c := &columnChunk{}
+				g.Columns = append(g.Columns, c)
+
+				return c.read(r, tp)
+			})
+		case id == 2:
+			return r.readI64(tp, &g.TotalByteSize)
+		case id == 3:
+			return r.readI64(tp, &g.NumRows)
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (c *columnChunk) read(r *thriftReader, tp byte) error {
+	if tp != thriftStruct {
+		return r.skip(tp)
+	}
+
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 1:
+			return r.readString(tp, &c.FilePath)
+		case id == 2:
+			return r.readI64(tp, &c.FileOffset)
+		case id == 3 && tp == thriftStruct:
+			c.MetaData = &columnMetaData{}
+
+			return c.MetaData.read(r)
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (m *columnMetaData) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 1:
+			return r.readI32(tp, &m.Type)
+
+		case id == 2 && tp == thriftList:
+			return r.readList(func(tp byte) error {
+				var encoding int32
+				err := r.readI32(tp, &encoding)
+				m.Encodings = append(m.Encodings, encoding)
+
+				return err
+			})
+
+		case id == 3 && tp == thriftList:
+			return r.readList(func(tp byte) error {
+				var name string
+				err := r.readString(tp, &name)
+				m.Path = append(m.Path, name)
+
+				return err
+			})
+
+		case id == 4:
+			return r.readI32(tp, &m.Codec)
+		case id == 5:
+			return r.readI64(tp, &m.NumValues)
+		case id == 6:
+			return r.readI64(tp, &m.TotalUncompressedSize)
+		case id == 7:
+			return r.readI64(tp, &m.TotalCompressedSize)
+		case id == 9:
+			return r.readI64(tp, &m.DataPageOffset)
+		case id == 11:
+			return r.readI64(tp, &m.DictionaryPageOffset)
+
+		case id == 12 && tp == thriftStruct:
+			m.Statistics = &statistics{}
+
+			return m.Statistics.read(r)
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (s *statistics) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 3:
+			return r.readI64(tp, &s.NullCount)
+
+		case (id == 5 || id == 6) && tp == thriftBinary:
+			b, err := r.binary()
+
+			if id == 5 {
+				s.MaxValue = b
+			} else {
+				s.MinValue = b
+			}
+
+			return err
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (kv *keyValue) read(r *thriftReader, tp byte) error {
+	if tp != thriftStruct {
+		return r.skip(tp)
+	}
+
+	return r.readStruct(func(id int16, tp byte) error {
+		switch id {
+		case 1:
+			return r.readString(tp, &kv.Key)
+		case 2:
+			return r.readString(tp, &kv.Value)
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (h *pageHeader) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, tp byte) error {
+		switch {
+		case id == 1:
+			return r.readI32(tp, &h.Type)
+		case id == 2:
+			return r.readI32(tp, &h.UncompressedPageSize)
+		case id == 3:
+			return r.readI32(tp, &h.CompressedPageSize)
+
+		case id == 5 && tp == thriftStruct:
+			p := &dataPageHeader{}
+			h.DataPage = p
+
+			return r.readStruct(func(id int16, tp byte) error {
+				switch id {
+				case 1:
+					return r.readI32(tp, &p.NumValues)
+				case 2:
+					return r.readI32(tp, &p.Encoding)
+				case 3:
+					return r.readI32(tp, &p.DefinitionLevelEncoding)
+				case 4:
+					return r.readI32(tp, &p.RepetitionLevelEncoding)
+				}
+
+				return r.skip(tp)
+			})
+
+		case id == 7 && tp == thriftStruct:
+			p := &dictionaryPageHeader{}
+			h.DictionaryPage = p
+
+			return r.readStruct(func(id int16, tp byte) error {
+				switch id {
+				case 1:
+					return r.readI32(tp, &p.NumValues)
+				case 2:
+					return r.readI32(tp, &p.Encoding)
+				case 3:
+					return r.readBool(tp, &p.IsSorted)
+				}
+
+				return r.skip(tp)
+			})
+
+		case id == 8 && tp == thriftStruct:
+			p := &dataPageHeaderV2{IsCompressed: true}
+			h.DataPageV2 = p
+
+			return r.readStruct(func(id int16, tp byte) error {
+				switch id {
+				case 1:
+					return r.readI32(tp, &p.NumValues)
+				case 2:
+					return r.readI32(tp, &p.NumNulls)
+				case 3:
+					return r.readI32(tp, &p.NumRows)
+				case 4:
+					return r.readI32(tp, &p.Encoding)
+				case 5:
+					return r.readI32(tp, &p.DefinitionLevelsByteLength)
+				case 6:
+					return r.readI32(tp, &p.RepetitionLevelsByteLength)
+				case 7:
+					return r.readBool(tp, &p.IsCompressed)
+				}
+
+				return r.skip(tp)
+			})
+		}
+
+		return r.skip(tp)
+	})
+}
+
+func (m *fileMetaData) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.writeI32(1, m.Version)
+		w.writeList(2, thriftStruct, len(m.Schema), func(i int) { m.Schema[i].write(w) })
+		w.writeI64(3, m.NumRows)
+		w.writeList(4, thriftStruct, len(m.RowGroups), func(i int) { m.RowGroups[i].write(w) })
+
+		if len(m.KeyValues) > 0 {
+			w.writeList(5, thriftStruct, len(m.KeyValues), func(i int) {
+				w.writeStruct(func() {
+					w.writeString(1, m.KeyValues[i].Key)
+					w.writeString(2, m.KeyValues[i].Value)
+				})
+			})
+		}
+
+		if m.CreatedBy != "" {
+			w.writeString(6, m.CreatedBy)
+		}
+
+		// the type defined orders of the leaves, for the min and max values of the statistics
+		leaves := 0
+
+		for _, e := range m.Schema {
+			if e.NumChildren == 0 {
+				leaves++
+			}
+		}
+
+		w.writeList(7, thriftStruct, leaves, func(i int) {
+			w.writeStruct(func() { w.writeStructField(1, func() {}) })
+		})
+	})
+}
+
+func (e *schemaElement) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		if e.Type >= 0 {
+			w.writeI32(1, e.Type)
+		}
+
+		if e.Type == typeFixedLenByteArray {
+			w.writeI32(2, e.TypeLength)
+		}
+
+		if e.Repetition >= 0 {
+			w.writeI32(3, e.Repetition)
+		}
+
+		w.writeString(4, e.Name)
+
+		if e.NumChildren > 0 {
+			w.writeI32(5, e.NumChildren)
+		}
+
+		if e.ConvertedType != convertedNone {
+			w.writeI32(6, e.ConvertedType)
+		}
+
+		if e.ConvertedType == convertedDecimal {
+			w.writeI32(7, e.Scale)
+			w.writeI32(8, e.Precision)
+		}
+
+		if e.LogicalType != nil {
+			w.fieldHeader(10, thriftStruct)
+			e.LogicalType.write(w)
+		}
+	})
+}
+
+func (t *logicalType) write(w *thriftWriter) {
+	w.writeStruct(func() { t.writeUnion(w) })
+}
+
+func (t *logicalType) writeUnion(w *thriftWriter) {
+	empty := func() {}
+
+	switch {
+	case t.String:
+		w.writeStructField(1, empty)
+	case t.Map:
+		w.writeStructField(2, empty)
+	case t.List:
+		w.writeStructField(3, empty)
+	case t.Enum:
+		w.writeStructField(4, empty)
+	case t.Decimal != nil:
+		w.writeStructField(5, func() {
+			w.writeI32(1, t.Decimal.Scale)
+			w.writeI32(2, t.Decimal.Precision)
+		})
+	case t.Date:
+		w.writeStructField(6, empty)
+	case t.Time != nil:
+		w.fieldHeader(7, thriftStruct)
+		t.Time.write(w)
+	case t.Timestamp != nil:
+		w.fieldHeader(8, thriftStruct)
+		t.Timestamp.write(w)
+	case t.Integer != nil:
+		w.writeStructField(10, func() {
+			w.fieldHeader(1, thriftByte)
+			w.buf = append(w.buf, byte(t.Integer.BitWidth))
+			w.writeBool(2, t.Integer.Signed)
+		})
+	case t.JSON:
+		w.writeStructField(12, empty)
+	case t.BSON:
+		w.writeStructField(13, empty)
+	case t.UUID:
+		w.writeStructField(14, empty)
+	case t.Float16:
+		w.writeStructField(15, empty)
+	}
+}
+
+func (t *timeType) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.writeBool(1, t.AdjustedToUTC)
+		w.writeStructField(2, func() { w.writeStructField(int16(t.Unit), func() {}) })
+	})
+}
+
+func (g *rowGroup) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.writeList(1, thriftStruct, len(g.Columns), func(i int) { g.Columns[i].write(w) })
+		w.writeI64(2, g.TotalByteSize)
+		w.writeI64(3, g.NumRows)
+	})
+}
+
+func (c *columnChunk) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.writeI64(2, c.FileOffset)
+		w.fieldHeader(3, thriftStruct)
+		c.MetaData.write(w)
+	})
+}
+
+func (m *columnMetaData) write(w *thriftWriter) {
+	w.writeStruct(func() { m.writeFields(w) })
+}
+
+func (m *columnMetaData) writeFields(w *thriftWriter) {
+	w.writeI32(1, m.Type)
+	w.writeList(2, thriftI32, len(m.Encodings), func(i int) { w.varint(int64(m.Encodings[i])) })
+	w.writeList(3, thriftBinary, len(m.Path), func(i int) { w.binary([]byte(m.Path[i])) })
+	w.writeI32(4, m.Codec)
+	w.writeI64(5, m.NumValues)
+	w.writeI64(6, m.TotalUncompressedSize)
+	w.writeI64(7, m.TotalCompressedSize)
+	w.writeI64(9, m.DataPageOffset)
+
+	if m.DictionaryPageOffset > 0 {
+		w.writeI64(11, m.DictionaryPageOffset)
+	}
+
+	if s := m.Statistics; s != nil {
+		w.writeStructField(12, func() {
+			w.writeI64(3, s.NullCount)
+
+			if s.MinValue != nil && s.MaxValue != nil {
+				w.writeBinary(5, s.MaxValue)
+				w.writeBinary(6, s.MinValue)
+			}
+		})
+	}
+}
+
+func (h *pageHeader) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.writeI32(1, h.Type)
+		w.writeI32(2, h.UncompressedPageSize)
+		w.writeI32(3, h.CompressedPageSize)
+
+		if p := h.DataPage; p != nil {
+			w.writeStructField(5, func() {
+				w.writeI32(1, p.NumValues)
+				w.writeI32(2, p.Encoding)
+				w.writeI32(3, p.DefinitionLevelEncoding)
+				w.writeI32(4, p.RepetitionLevelEncoding)
+			})
+		}
+
+		if p := h.DictionaryPage; p != nil {
+			w.writeStructField(7, func() {
+				w.writeI32(1, p.NumValues)
+				w.writeI32(2, p.Encoding)
+			})
+		}
+	})
+}
@@ -0,0 +1,540 @@
+// Package parquet reads Parquet files as record batches, a batch per row group, and writes record batches as Parquet files.
+//
+// The Parquet schema is mapped to the Arrow fields: the optional fields are nullable, the groups are Struct,
+// the annotated lists are List and the maps are Map, and the repeated fields out of them are non-nullable
+// lists of their elements. The primitive types are mapped by their logical or converted types,
+// and the INT96 timestamps are Timestamp in nanoseconds.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const magic = "PAR1"
+
+// ReadOptions controls how the Parquet file is read.
+type ReadOptions struct {
+	Columns []string // the names of the root fields to read, all the fields if empty
+}
+
+// Reader reads the row groups of a Parquet file as record batches of the Schema.
+type Reader struct {
+	Schema *schema.Schema
+
+	r       io.ReaderAt
+	meta    *fileMetaData
+	columns []*column // the selected root fields
+	leaves  int       // the number of leaves of the Parquet schema
+	builder *arrow.RecordBuilder
+	next    int
+}
+
+// Create a reader of the Parquet file of the size, reading its metadata.
+func NewReader(r io.ReaderAt, size int64, opts ReadOptions) (*Reader, error) {
+	if size < int64(2*len(magic)+4) {
+		return nil, errors.New("Parquet file too small")
+	}
+
+	footer := make([]byte, 4+len(magic))
+
+	if _, err := r.ReadAt(footer, size-int64(len(footer))); err != nil {
+		return nil, fmt.Errorf("fail to read Parquet footer, %s", err)
+	}
+
+	if string(footer[4:]) != magic {
+		return nil, errors.New("invalid Parquet file, missing magic")
+	}
+
+	metaSize := int64(binary.LittleEndian.Uint32(footer))
+
+	if metaSize > size-int64(len(footer)+len(magic)) {
+		return nil, fmt.Errorf("invalid Parquet metadata of %d bytes", metaSize)
+	}
+
+	buf := make([]byte, metaSize)
+
+	if _, err := r.ReadAt(buf, size-int64(len(footer))-metaSize); err != nil {
+		return nil, fmt.Errorf("fail to read Parquet metadata, %s", err)
+	}
+
+	meta := &fileMetaData{}
+
+	if err := meta.read(&thriftReader{buf: buf}); err != nil {
+		return nil, fmt.Errorf("fail to decode Parquet metadata, %s", err)
+	}
+
+	columns, err := parseSchema(meta.Schema)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{r: r, meta: meta}
+
+	for _, c := range columns {
+		reader.leaves += len(c.leaves)
+	}
+
+	if len(opts.Columns) == 0 {
+		reader.columns = columns
+	} else {
+		for _, name := range opts.Columns {
+			found := false
+
+			for _, c := range columns {
+				if c.field.Name == name {
+					reader.columns, found = append(reader.columns, c), true
+
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("field %s not found", name)
+			}
+		}
+	}
+
+	s := &schema.Schema{}
+
+	for _, c := range reader.columns {
+		s.Fields = append(s.Fields, c.field)
+	}
+
+	builder, err := arrow.NewRecordBuilder(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader.Schema, reader.builder = s, builder
+
+	return reader, nil
+}
+
+// Returns the number of the row groups.
+func (r *Reader) NumRowGroups() int { return len(r.meta.RowGroups) }
+
+// Returns the number of the rows of the file.
+func (r *Reader) NumRows() int64 { return r.meta.NumRows }
+
+// Returns the key value metadata of the file.
+func (r *Reader) Metadata() map[string]string {
+	m := make(map[string]string)
+
+	for _, kv := range r.meta.KeyValues {
+		m[kv.Key] = kv.Value
+	}
+
+	return m
+}
+
+// Read the next row group, returns io.EOF after the last one.
+func (r *Reader) Read() (*vector.RecordBatch, error) {
+	if r.next >= len(r.meta.RowGroups) {
+		return nil, io.EOF
+	}
+
+	r.next++
+
+	return r.ReadRowGroup(r.next - 1)
+}
+
+// Read the i-th row group as a record batch.
+func (r *Reader) ReadRowGroup(i int) (*vector.RecordBatch, error) {
+	if i < 0 || i >= len(r.meta.RowGroups) {
+		return nil, fmt.Errorf("row group %d out of %d", i, len(r.meta.RowGroups))
+	}
+
+	g := r.meta.RowGroups[i]
+
+	if len(g.Columns) != r.leaves {
+		return nil, fmt.Errorf("row group %d has %d columns, expected %d", i, len(g.Columns), r.leaves)
+	}
+
+	for _, c := range r.columns {
+		for _, l := range c.leaves {
+			if err := r.readChunk(l, g.Columns[l.index]); err != nil {
+				return nil, fmt.Errorf("fail to read column %s of row group %d, %s", joinPath(l.path), i, err)
+			}
+		}
+	}
+
+	for row := int64(0); row < g.NumRows; row++ {
+		for j, c := range r.columns {
+			if err := c.assemble(r.builder.Columns[j]); err != nil {
+				r.builder.NewRecordBatch() // drop the appended values
+
+				return nil, fmt.Errorf("field %s of row group %d at row %d, %s", c.field.Name, i, row, err)
+			}
+		}
+	}
+
+	for _, c := range r.columns {
+		for _, l := range c.leaves {
+			if l.pos != len(l.defs) || l.vpos != l.values.len() {
+				r.builder.NewRecordBatch() // drop the appended values
+
+				return nil, fmt.Errorf("column %s has levels or values out of %d rows", joinPath(l.path), g.NumRows)
+			}
+
+			l.defs, l.reps, l.values = nil, nil, nil
+		}
+	}
+
+	batch, err := r.builder.NewRecordBatch()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Schema.Validate(batch); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// Read the levels and values of the leaf from the column chunk.
+func (r *Reader) readChunk(l *leaf, chunk *columnChunk) error {
+	meta := chunk.MetaData
+
+	if meta == nil {
+		return errors.New("missing column metadata")
+	}
+
+	if chunk.FilePath != "" {
+		return fmt.Errorf("column stored in the file %s", chunk.FilePath)
+	}
+
+	if joinPath(meta.Path) != joinPath(l.path) || meta.Type != l.element.Type {
+		return fmt.Errorf("column %s of type %d doesn't match the schema", joinPath(meta.Path), meta.Type)
+	}
+
+	offset := meta.DataPageOffset
+
+	if meta.DictionaryPageOffset > 0 && meta.DictionaryPageOffset < offset {
+		offset = meta.DictionaryPageOffset
+	}
+
+	if offset < 0 || meta.TotalCompressedSize < 0 || meta.TotalCompressedSize > 1<<31 {
+		return fmt.Errorf("invalid column chunk of %d bytes at %d", meta.TotalCompressedSize, offset)
+	}
+
+	buf := make([]byte, meta.TotalCompressedSize)
+
+	if _, err := r.r.ReadAt(buf, offset); err != nil {
+		return err
+	}
+
+	l.defs, l.reps, l.values, l.pos, l.vpos = nil, nil, &values{width: valueWidth(l.element)}, 0, 0
+
+	var dict *values
+
+	for len(buf) > 0 && int64(len(l.defs)) < meta.NumValues {
+		h := &pageHeader{}
+		tr := &thriftReader{buf: buf}
+
+		if err := h.read(tr); err != nil {
+			return fmt.Errorf("fail to decode page header, %s", err)
+		}
+
+		buf = buf[tr.pos:]
+
+		if h.CompressedPageSize < 0 || int(h.CompressedPageSize) > len(buf) || h.UncompressedPageSize < 0 {
+			return fmt.Errorf("invalid page of %d bytes", h.CompressedPageSize)
+		}
+
+		page := buf[:h.CompressedPageSize]
+		buf = buf[h.CompressedPageSize:]
+
+		var err error
+
+		switch h.Type {
+		case pageDictionary:
+			if h.DictionaryPage == nil {
+				return errors.New("missing dictionary page header")
+			}
+
+			if page, err = decompress(meta.Codec, page, int(h.UncompressedPageSize)); err != nil {
+				return err
+			}
+
+			if dict, err = decodePlain(l.element, page, int(h.DictionaryPage.NumValues)); err != nil {
+				return fmt.Errorf("fail to decode dictionary, %s", err)
+			}
+
+		case pageData:
+			err = l.readPage(h, meta.Codec, page, dict)
+
+		case pageDataV2:
+			err = l.readPageV2(h, meta.Codec, page, dict)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if int64(len(l.defs)) != meta.NumValues {
+		return fmt.Errorf("column has %d values, expected %d", len(l.defs), meta.NumValues)
+	}
+
+	return nil
+}
+
+// Read a data page, whose levels and values are compressed together.
+func (l *leaf) readPage(h *pageHeader, codec int32, page []byte, dict *values) error {
+	p := h.DataPage
+
+	if p == nil {
+		return errors.New("missing data page header")
+	}
+
+	page, err := decompress(codec, page, int(h.UncompressedPageSize))
+
+	if err != nil {
+		return err
+	}
+
+	n := int(p.NumValues)
+
+	if n < 0 {
+		return fmt.Errorf("invalid data page of %d values", n)
+	}
+
+	reps, defs := make([]int, n), make([]int, n)
+
+	if l.maxRep > 0 {
+		if reps, page, err = decodeLevels(page, p.RepetitionLevelEncoding, bits.Len(uint(l.maxRep)), n); err != nil {
+			return fmt.Errorf("fail to decode repetition levels, %s", err)
+		}
+	}
+
+	if l.maxDef > 0 {
+		if defs, page, err = decodeLevels(page, p.DefinitionLevelEncoding, bits.Len(uint(l.maxDef)), n); err != nil {
+			return fmt.Errorf("fail to decode definition levels, %s", err)
+		}
+	}
+
+	return l.readValues(p.Encoding, page, reps, defs, dict)
+}
+
+// Read a data page V2, whose levels are not compressed, nor prefixed by their lengths.
+func (l *leaf) readPageV2(h *pageHeader, codec int32, page []byte, dict *values) error {
+	p := h.DataPageV2
+
+	if p == nil {
+		return errors.New("missing data page V2 header")
+	}
+
+	n, repLen, defLen := int(p.NumValues), int(p.RepetitionLevelsByteLength), int(p.DefinitionLevelsByteLength)
+
+	if n < 0 || repLen < 0 || defLen < 0 || repLen+defLen > len(page) {
+		return fmt.Errorf("invalid data page of %d values", n)
+	}
+
+	reps, defs := make([]int, n), make([]int, n)
+
+	var err error
+
+	if l.maxRep > 0 {
+		if reps, err = decodeRLE(page[:repLen], bits.Len(uint(l.maxRep)), n); err != nil {
+			return fmt.Errorf("fail to decode repetition levels, %s", err)
+		}
+	}
+
+	if l.maxDef > 0 {
+		if defs, err = decodeRLE(page[repLen:repLen+defLen], bits.Len(uint(l.maxDef)), n); err != nil {
+			return fmt.Errorf("fail to decode definition levels, %s", err)
+		}
+	}
+
+	page = page[repLen+defLen:]
+
+	if size := int(h.UncompressedPageSize) - repLen - defLen; size < 0 {
+		return fmt.Errorf("invalid data page of %d bytes", h.UncompressedPageSize)
+	}
+
+	if p.IsCompressed {
+		if page, err = decompress(codec, page, int(h.UncompressedPageSize)-repLen-defLen); err != nil {
+			return err
+		}
+	}
+
+	return l.readValues(p.Encoding, page, reps, defs, dict)
+}
+
+// Decode the values of the page, one for each definition level of the valid values.
+func (l *leaf) readValues(encoding int32, page []byte, reps, defs []int, dict *values) error {
+	n := 0
+
+	for i, def := range defs {
+		if def > l.maxDef || reps[i] > l.maxRep {
+			return fmt.Errorf("levels %d and %d out of %d and %d", def, reps[i], l.maxDef, l.maxRep)
+		}
+
+		if def == l.maxDef {
+			n++
+		}
+	}
+
+	if len(l.defs) == 0 && len(reps) > 0 && reps[0] != 0 {
+		return errors.New("column starts in a row")
+	}
+
+	v, err := decodeValues(l.element, encoding, page, n, dict)
+
+	if err != nil {
+		return fmt.Errorf("fail to decode values, %s", err)
+	}
+
+	l.defs, l.reps = append(l.defs, defs...), append(l.reps, reps...)
+	l.values.append(v)
+
+	return nil
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// Decompress the page of the codec, whose size is known.
+func decompress(codec int32, page []byte, size int) ([]byte, error) {
+	var buf []byte
+	var err error
+
+	switch codec {
+	case codecUncompressed:
+		return page, nil
+
+	case codecSnappy:
+		buf, err = snappy.Decode(nil, page)
+
+	case codecGzip:
+		var r *gzip.Reader
+
+		if r, err = gzip.NewReader(bytes.NewReader(page)); err == nil {
+			buf, err = io.ReadAll(io.LimitReader(r, int64(size)+1))
+		}
+
+	case codecZstd:
+		zstdOnce.Do(func() {
+			zstdDecoder, zstdErr = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		})
+
+		if err = zstdErr; err == nil {
+			buf, err = zstdDecoder.DecodeAll(page, make([]byte, 0, size))
+		}
+
+	default:
+		name, found := codecNames[codec]
+
+		if !found {
+			name = fmt.Sprintf("%d", codec)
+		}
+
+		return nil, fmt.Errorf("unsupported compression codec %s", name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to decompress page, %s", err)
+	}
+
+	if len(buf) != size {
+		return nil, fmt.Errorf("page decompressed to %d bytes, expected %d", len(buf), size)
+	}
+
+	return buf, nil
+}
+
+// Append the value of the column at the levels of its first leaf, and move the leaves to the next.
+func (c *column) assemble(b *arrow.ColumnBuilder) error {
+	first := c.leaves[0]
+
+	if first.pos >= len(first.defs) {
+		return errors.New("missing levels")
+	}
+
+	def := first.defs[first.pos]
+
+	if def < c.def {
+		// the column or its parent is null
+		if c.field.Nullable {
+			b.AppendNull()
+		}
+
+		c.skip()
+
+		return nil
+	}
+
+	switch c.kind {
+	case kindLeaf:
+		if def == first.maxDef && first.vpos < first.values.len() {
+			first.append(b, first.values, first.vpos)
+			first.vpos++
+		} else {
+			b.AppendNull()
+		}
+
+		first.pos++
+
+	case kindStruct:
+		b.AppendStruct()
+
+		for i, child := range c.children {
+			if err := child.assemble(b.Child(i)); err != nil {
+				return err
+			}
+		}
+
+	case kindList:
+		if def < c.elemDef {
+			// an empty list
+			c.skip()
+		} else {
+			item := c.children[0]
+
+			for {
+				if err := item.assemble(b.Child(0)); err != nil {
+					return err
+				}
+
+				if first.pos >= len(first.defs) || first.reps[first.pos] != c.rep {
+					break
+				}
+			}
+		}
+
+		b.AppendList()
+	}
+
+	return nil
+}
+
+// Move the leaves to the next levels, which have no value.
+func (c *column) skip() {
+	for _, l := range c.leaves {
+		l.pos++
+	}
+}
+
+func joinPath(path []string) string { return strings.Join(path, ".") }
@@ -0,0 +1,629 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/flier/arrow/internal/columns"
+)
+
+// The Thrift values of the test files, encoded in the compact protocol.
+type (
+	tfield struct {
+		id int16
+		v  interface{} // int32, int64, string, bool, tstruct or tlist
+	}
+	tstruct []tfield
+	tlist   []interface{}
+)
+
+func thriftType(v interface{}) byte {
+	switch v := v.(type) {
+	case int32:
+		return thriftI32
+	case int64:
+		return thriftI64
+	case string:
+		return thriftBinary
+	case bool:
+		if v {
+			return thriftTrue
+		}
+
+		return thriftFalse
+	case tlist:
+		return thriftList
+	default:
+		return thriftStruct
+	}
+}
+
+func encodeThrift(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case int32:
+		return binary.AppendUvarint(buf, uint64(int64(v)<<1^int64(v)>>63))
+	case int64:
+		return binary.AppendUvarint(buf, uint64(v<<1^v>>63))
+	case string:
+		return append(binary.AppendUvarint(buf, uint64(len(v))), v...)
+	case bool:
+		return buf
+	case tlist:
+		tp := byte(thriftI32)
+
+		if len(v) > 0 {
+			tp = thriftType(v[0])
+		}
+
+		if len(v) < 15 {
+			buf = append(buf, byte(len(v))<<4|tp)
+		} else {
+			buf = binary.AppendUvarint(append(buf, 0xf0|tp), uint64(len(v)))
+		}
+
+		for _, item := range v {
+			buf = encodeThrift(buf, item)
+		}
+
+		return buf
+	default:
+		last := int16(0)
+
+		for _, f := range v.(tstruct) {
+			tp := thriftType(f.v)
+
+			if delta := f.id - last; delta > 0 && delta <= 15 {
+				buf = append(buf, byte(delta)<<4|tp)
+			} else {
+				buf = binary.AppendUvarint(append(buf, tp), uint64(int64(f.id)<<1^int64(f.id)>>63))
+			}
+
+			buf, last = encodeThrift(buf, f.v), f.id
+		}
+
+		return append(buf, thriftStop)
+	}
+}
+
+// The encodings of the test pages.
+
+func plainInt32(vs ...int32) []byte {
+	var buf []byte
+
+	for _, v := range vs {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(v))
+	}
+
+	return buf
+}
+
+func plainInt64(vs ...int64) []byte {
+	var buf []byte
+
+	for _, v := range vs {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(v))
+	}
+
+	return buf
+}
+
+func plainDouble(vs ...float64) []byte {
+	var buf []byte
+
+	for _, v := range vs {
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+
+	return buf
+}
+
+func plainBytes(vs ...string) []byte {
+	var buf []byte
+
+	for _, v := range vs {
+		buf = append(binary.LittleEndian.AppendUint32(buf, uint32(len(v))), v...)
+	}
+
+	return buf
+}
+
+// Returns the values bit-packed in the RLE/bit-packed hybrid encoding.
+func bitPacked(bitWidth int, vs ...int) []byte {
+	groups := (len(vs) + 7) / 8
+	packed := make([]byte, groups*bitWidth)
+
+	for i, v := range vs {
+		for b := 0; b < bitWidth; b++ {
+			if bit := i*bitWidth + b; v&(1<<uint(b)) != 0 {
+				packed[bit>>3] |= 1 << uint(bit&7)
+			}
+		}
+	}
+
+	return append(binary.AppendUvarint(nil, uint64(groups<<1|1)), packed...)
+}
+
+// Returns a run of the value in the RLE/bit-packed hybrid encoding.
+func rleRun(bitWidth, v, count int) []byte {
+	buf := binary.AppendUvarint(nil, uint64(count<<1))
+
+	for i := 0; i < (bitWidth+7)/8; i++ {
+		buf = append(buf, byte(v>>uint(8*i)))
+	}
+
+	return buf
+}
+
+func lengthPrefixed(buf []byte) []byte {
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(buf))), buf...)
+}
+
+func compress(t *testing.T, codec int32, buf []byte) []byte {
+	switch codec {
+	case codecSnappy:
+		return snappy.Encode(nil, buf)
+
+	case codecGzip:
+		var out bytes.Buffer
+
+		w := gzip.NewWriter(&out)
+
+		if _, err := w.Write(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		return out.Bytes()
+
+	case codecZstd:
+		w, err := zstd.NewWriter(nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return w.EncodeAll(buf, nil)
+	}
+
+	return buf
+}
+
+// testPage is a page of a test column, whose levels are encoded if the column has them.
+type testPage struct {
+	dict     int // the number of the values of a dictionary page
+	v2       bool
+	encoding int32
+	reps     []int
+	defs     []int
+	values   []byte
+}
+
+type testColumn struct {
+	path    []string
+	tp      int32
+	codec   int32
+	maxDef  int
+	maxRep  int
+	pages   []testPage
+	numRows int64
+}
+
+func (p *testPage) encode(t *testing.T, c *testColumn) []byte {
+	n := int32(len(p.defs))
+
+	if c.maxDef == 0 {
+		n = int32(len(p.reps))
+	}
+
+	if p.dict > 0 {
+		body := compress(t, c.codec, p.values)
+		header := tstruct{
+			{1, int32(pageDictionary)},
+			{2, int32(len(p.values))},
+			{3, int32(len(body))},
+			{7, tstruct{{1, int32(p.dict)}, {2, int32(encodingPlain)}}},
+		}
+
+		return append(encodeThrift(nil, header), body...)
+	}
+
+	var reps, defs []byte
+
+	if c.maxRep > 0 {
+		reps = bitPacked(bitWidth(c.maxRep), p.reps...)
+	}
+
+	if c.maxDef > 0 {
+		defs = bitPacked(bitWidth(c.maxDef), p.defs...)
+	}
+
+	if p.v2 {
+		body := compress(t, c.codec, p.values)
+		header := tstruct{
+			{1, int32(pageDataV2)},
+			{2, int32(len(reps) + len(defs) + len(p.values))},
+			{3, int32(len(reps) + len(defs) + len(body))},
+			{8, tstruct{
+				{1, n},
+				{2, int32(0)},
+				{3, n},
+				{4, p.encoding},
+				{5, int32(len(defs))},
+				{6, int32(len(reps))},
+			}},
+		}
+
+		return append(append(append(encodeThrift(nil, header), reps...), defs...), body...)
+	}
+
+	var page []byte
+
+	if c.maxRep > 0 {
+		page = lengthPrefixed(reps)
+	}
+
+	if c.maxDef > 0 {
+		page = append(page, lengthPrefixed(defs)...)
+	}
+
+	page = append(page, p.values...)
+	body := compress(t, c.codec, page)
+	header := tstruct{
+		{1, int32(pageData)},
+		{2, int32(len(page))},
+		{3, int32(len(body))},
+		{5, tstruct{{1, n}, {2, p.encoding}, {3, int32(encodingRLE)}, {4, int32(encodingRLE)}}},
+	}
+
+	return append(encodeThrift(nil, header), body...)
+}
+
+func bitWidth(level int) int {
+	n := 0
+
+	for ; level > 0; level >>= 1 {
+		n++
+	}
+
+	return n
+}
+
+// Returns a Parquet file of the schema elements and the row groups of the columns.
+func testFile(t *testing.T, elements []tstruct, groups ...[]testColumn) []byte {
+	buf := []byte(magic)
+
+	var rowGroups tlist
+	var numRows int64
+
+	for _, columns := range groups {
+		var chunks tlist
+
+		for _, c := range columns {
+			offset := int64(len(buf))
+			dictOffset := int64(0)
+			numValues := 0
+
+			for _, p := range c.pages {
+				if p.dict > 0 {
+					dictOffset = offset
+				} else if c.maxDef > 0 {
+					numValues += len(p.defs)
+				} else {
+					numValues += len(p.reps)
+				}
+
+				buf = append(buf, p.encode(t, &c)...)
+			}
+
+			var path tlist
+
+			for _, name := range c.path {
+				path = append(path, name)
+			}
+
+			meta := tstruct{
+				{1, c.tp},
+				{2, tlist{int32(encodingPlain), int32(encodingRLE)}},
+				{3, path},
+				{4, c.codec},
+				{5, int64(numValues)},
+				{6, int64(len(buf)) - offset},
+				{7, int64(len(buf)) - offset},
+				{9, offset},
+			}
+
+			if dictOffset > 0 {
+				meta = append(meta, tfield{11, dictOffset})
+			}
+
+			chunks = append(chunks, tstruct{{2, offset}, {3, meta}})
+		}
+
+		rowGroups = append(rowGroups, tstruct{{1, chunks}, {2, int64(0)}, {3, columns[0].numRows}})
+		numRows += columns[0].numRows
+	}
+
+	var schema tlist
+
+	for _, e := range elements {
+		schema = append(schema, e)
+	}
+
+	meta := encodeThrift(nil, tstruct{
+		{1, int32(1)},
+		{2, schema},
+		{3, numRows},
+		{4, rowGroups},
+		{5, tlist{tstruct{{1, "origin"}, {2, "test"}}}},
+		{6, "arrow test"},
+	})
+
+	buf = append(buf, meta...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(meta)))
+
+	return append(buf, magic...)
+}
+
+// Returns the rows of the batches in JSON.
+func readRows(t *testing.T, r *Reader) []string {
+	var rows []string
+
+	for {
+		batch, err := r.Read()
+
+		if err == io.EOF {
+			return rows
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cols, err := columns.New(r.Schema, batch)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < batch.Length; i++ {
+			var buf bytes.Buffer
+
+			columns.AppendJSONRow(&buf, cols, i, time.RFC3339Nano, false)
+
+			rows = append(rows, buf.String())
+		}
+	}
+}
+
+func flatFile(t *testing.T) []byte {
+	elements := []tstruct{
+		{{4, "schema"}, {5, int32(5)}},
+		{{1, int32(typeInt32)}, {3, int32(required)}, {4, "id"}},
+		{{1, int32(typeByteArray)}, {3, int32(optional)}, {4, "name"}, {6, int32(convertedUTF8)}},
+		{{1, int32(typeDouble)}, {3, int32(optional)}, {4, "score"}},
+		{{1, int32(typeBoolean)}, {3, int32(optional)}, {4, "flag"}},
+		{{1, int32(typeInt64)}, {3, int32(optional)}, {4, "ts"},
+			{10, tstruct{{8, tstruct{{1, true}, {2, tstruct{{2, tstruct{}}}}}}}}},
+	}
+
+	first := []testColumn{
+		{path: []string{"id"}, tp: typeInt32, numRows: 3, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0, 0}, values: plainInt32(1, 2)},
+			{encoding: encodingPlain, reps: []int{0}, values: plainInt32(3)},
+		}},
+		{path: []string{"name"}, tp: typeByteArray, codec: codecSnappy, maxDef: 1, pages: []testPage{
+			{dict: 2, values: plainBytes("alice", "bob")},
+			{encoding: encodingRLEDictionary, defs: []int{1, 0, 1}, values: append([]byte{1}, bitPacked(1, 1, 0)...)},
+		}},
+		{path: []string{"score"}, tp: typeDouble, codec: codecZstd, maxDef: 1, pages: []testPage{
+			{v2: true, encoding: encodingPlain, defs: []int{1, 1, 0}, values: plainDouble(1.5, -2)},
+		}},
+		{path: []string{"flag"}, tp: typeBoolean, codec: codecGzip, maxDef: 1, pages: []testPage{
+			{encoding: encodingRLE, defs: []int{1, 1, 1}, values: lengthPrefixed(append(rleRun(1, 1, 2), rleRun(1, 0, 1)...))},
+		}},
+		{path: []string{"ts"}, tp: typeInt64, maxDef: 1, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{0, 1, 0}, values: plainInt64(1500000)},
+		}},
+	}
+
+	second := []testColumn{
+		{path: []string{"id"}, tp: typeInt32, numRows: 1, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0}, values: plainInt32(4)},
+		}},
+		{path: []string{"name"}, tp: typeByteArray, maxDef: 1, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{1}, values: plainBytes("carol")},
+		}},
+		{path: []string{"score"}, tp: typeDouble, maxDef: 1, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{0}},
+		}},
+		{path: []string{"flag"}, tp: typeBoolean, maxDef: 1, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{1}, values: []byte{1}},
+		}},
+		{path: []string{"ts"}, tp: typeInt64, maxDef: 1, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{0}},
+		}},
+	}
+
+	return testFile(t, elements, first, second)
+}
+
+func TestRead(t *testing.T) {
+	buf := flatFile(t)
+	r, err := NewReader(bytes.NewReader(buf), int64(len(buf)), ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `struct<id: int32 not null, name: utf8, score: float64, flag: bool, ts: timestamp[us, tz=UTC]>`
+
+	if r.Schema.String() != expected {
+		t.Errorf("schema %s, expected %s", r.Schema, expected)
+	}
+
+	if r.NumRowGroups() != 2 || r.NumRows() != 4 || r.Metadata()["origin"] != "test" {
+		t.Errorf("%d row groups of %d rows and metadata %v", r.NumRowGroups(), r.NumRows(), r.Metadata())
+	}
+
+	rows := readRows(t, r)
+	expectedRows := []string{
+		`{"id":1,"name":"bob","score":1.5,"flag":true,"ts":null}`,
+		`{"id":2,"name":null,"score":-2,"flag":true,"ts":"1970-01-01T00:00:01.5Z"}`,
+		`{"id":3,"name":"alice","score":null,"flag":false,"ts":null}`,
+		`{"id":4,"name":"carol","score":null,"flag":true,"ts":null}`,
+	}
+
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("read %q, expected %q", rows, expectedRows)
+	}
+}
+
+func TestReadColumns(t *testing.T) {
+	buf := flatFile(t)
+	r, err := NewReader(bytes.NewReader(buf), int64(len(buf)), ReadOptions{Columns: []string{"flag", "id"}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "struct<flag: bool, id: int32 not null>"; r.Schema.String() != expected {
+		t.Errorf("schema %s, expected %s", r.Schema, expected)
+	}
+
+	batch, err := r.ReadRowGroup(1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := columns.New(r.Schema, batch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if batch.Length != 1 || !cols[0].Bool(0) || cols[1].Int32(0) != 4 {
+		t.Errorf("read %d rows of flag %v and id %d", batch.Length, cols[0].Bool(0), cols[1].Int32(0))
+	}
+
+	if _, err := NewReader(bytes.NewReader(buf), int64(len(buf)), ReadOptions{Columns: []string{"x"}}); err == nil {
+		t.Error("read unknown column x")
+	}
+}
+
+func TestReadNested(t *testing.T) {
+	elements := []tstruct{
+		{{4, "schema"}, {5, int32(4)}},
+		{{3, int32(optional)}, {4, "l"}, {5, int32(1)}, {6, int32(convertedList)}},
+		{{3, int32(repeated)}, {4, "list"}, {5, int32(1)}},
+		{{1, int32(typeInt32)}, {3, int32(optional)}, {4, "element"}},
+		{{3, int32(optional)}, {4, "s"}, {5, int32(2)}},
+		{{1, int32(typeInt32)}, {3, int32(required)}, {4, "a"}},
+		{{1, int32(typeByteArray)}, {3, int32(optional)}, {4, "b"}, {10, tstruct{{1, tstruct{}}}}},
+		{{3, int32(optional)}, {4, "m"}, {5, int32(1)}, {10, tstruct{{2, tstruct{}}}}},
+		{{3, int32(repeated)}, {4, "key_value"}, {5, int32(2)}},
+		{{1, int32(typeByteArray)}, {3, int32(required)}, {4, "key"}, {6, int32(convertedUTF8)}},
+		{{1, int32(typeInt32)}, {3, int32(optional)}, {4, "value"}},
+		{{1, int32(typeInt64)}, {3, int32(repeated)}, {4, "ids"}},
+	}
+
+	columns := []testColumn{
+		{path: []string{"l", "list", "element"}, tp: typeInt32, maxDef: 3, maxRep: 1, numRows: 3, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0, 1, 1, 0, 0}, defs: []int{3, 2, 3, 0, 1}, values: plainInt32(1, 2)},
+		}},
+		{path: []string{"s", "a"}, tp: typeInt32, maxDef: 1, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{1, 0, 1}, values: plainInt32(1, 2)},
+		}},
+		{path: []string{"s", "b"}, tp: typeByteArray, maxDef: 2, pages: []testPage{
+			{encoding: encodingPlain, defs: []int{2, 0, 1}, values: plainBytes("x")},
+		}},
+		{path: []string{"m", "key_value", "key"}, tp: typeByteArray, maxDef: 2, maxRep: 1, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0, 1, 0, 0}, defs: []int{2, 2, 1, 0}, values: plainBytes("k", "j")},
+		}},
+		{path: []string{"m", "key_value", "value"}, tp: typeInt32, maxDef: 3, maxRep: 1, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0, 1, 0, 0}, defs: []int{3, 2, 1, 0}, values: plainInt32(1)},
+		}},
+		{path: []string{"ids"}, tp: typeInt64, maxDef: 1, maxRep: 1, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0, 1, 0, 0}, defs: []int{1, 1, 0, 1}, values: plainInt64(7, 8, 9)},
+		}},
+	}
+
+	buf := testFile(t, elements, columns)
+	r, err := NewReader(bytes.NewReader(buf), int64(len(buf)), ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "struct<l: list<element: int32>, s: struct<a: int32 not null, b: utf8>, " +
+		"m: map<key: utf8 not null, value: int32>, ids: list<ids: int64 not null> not null>"
+
+	if r.Schema.String() != expected {
+		t.Errorf("schema %s, expected %s", r.Schema, expected)
+	}
+
+	rows := readRows(t, r)
+	expectedRows := []string{
+		`{"l":[1,null,2],"s":{"a":1,"b":"x"},"m":[{"key":"k","value":1},{"key":"j","value":null}],"ids":[7,8]}`,
+		`{"l":null,"s":null,"m":[],"ids":[]}`,
+		`{"l":[],"s":{"a":2,"b":null},"m":null,"ids":[9]}`,
+	}
+
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("read %q, expected %q", rows, expectedRows)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	buf := flatFile(t)
+
+	if _, err := NewReader(bytes.NewReader(buf[:8]), 8, ReadOptions{}); err == nil {
+		t.Error("read a file without footer")
+	}
+
+	elements := []tstruct{
+		{{4, "schema"}, {5, int32(1)}},
+		{{1, int32(typeInt32)}, {3, int32(required)}, {4, "id"}},
+	}
+
+	tests := []struct {
+		column testColumn
+		err    string
+	}{
+		{testColumn{path: []string{"id"}, tp: typeInt32, numRows: 1, pages: []testPage{
+			{dict: 1, values: plainInt32(1)},
+			{encoding: encodingRLEDictionary, reps: []int{0}, values: append([]byte{1}, rleRun(1, 1, 1)...)},
+		}}, "fail to read column id of row group 0, fail to decode values, dictionary index 1 out of 1 values"},
+		{testColumn{path: []string{"id"}, tp: typeInt32, codec: codecLZO, numRows: 1, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0}, values: plainInt32(1)},
+		}}, "fail to read column id of row group 0, unsupported compression codec LZO"},
+		{testColumn{path: []string{"id"}, tp: typeInt32, numRows: 2, pages: []testPage{
+			{encoding: encodingPlain, reps: []int{0}, values: plainInt32(1)},
+		}}, "field id of row group 0 at row 1, missing levels"},
+	}
+
+	for _, test := range tests {
+		buf := testFile(t, elements, []testColumn{test.column})
+		r, err := NewReader(bytes.NewReader(buf), int64(len(buf)), ReadOptions{})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := r.Read(); err == nil || err.Error() != test.err {
+			t.Errorf("read with error %v, expected %s", err, test.err)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package parquet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flier/arrow/schema"
+)
+
+// The kinds of the columns assembled from the levels of the leaves.
+const (
+	kindLeaf = iota
+	kindStruct
+	kindList
+)
+
+// column is an Arrow field mapped from the Parquet schema, with the levels to assemble its values.
+type column struct {
+	field    *schema.Field
+	kind     int
+	def      int // the definition level of the valid values
+	elemDef  int // the definition level of the lists with an element
+	rep      int // the repetition level of the elements of the lists
+	children []*column
+	leaves   []*leaf // the leaves below the column, in the order of the Parquet schema
+}
+
+// leaf is a primitive column of the Parquet schema, whose values are stored in the column chunks.
+type leaf struct {
+	index   int // the index of the column chunk in the row groups
+	path    []string
+	element *schemaElement
+	maxDef  int
+	maxRep  int
+	append  appendFunc
+
+	// the levels and values of the current row group
+	defs, reps []int
+	values     *values
+	pos, vpos  int
+}
+
+// The parser of the schema, whose elements are flattened in depth first order.
+type schemaParser struct {
+	elements []*schemaElement
+	pos      int
+	leaves   int
+}
+
+// Map the Parquet schema to the Arrow columns of the root fields.
+func parseSchema(elements []*schemaElement) ([]*column, error) {
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("Parquet schema has no root")
+	}
+
+	p := &schemaParser{elements: elements, pos: 1}
+
+	var columns []*column
+
+	for i := 0; i < int(elements[0].NumChildren); i++ {
+		c, err := p.parse(nil, 0, 0, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, c)
+	}
+
+	if p.pos != len(elements) {
+		return nil, fmt.Errorf("Parquet schema has %d elements out of the root", len(elements)-p.pos)
+	}
+
+	return columns, nil
+}
+
+// Returns the next element, whose children follow it.
+func (p *schemaParser) next() (*schemaElement, error) {
+	if p.pos >= len(p.elements) {
+		return nil, fmt.Errorf("Parquet schema has %d elements, expected more", len(p.elements))
+	}
+
+	e := p.elements[p.pos]
+	p.pos++
+
+	if e.NumChildren < 0 || int(e.NumChildren) > len(p.elements)-p.pos {
+		return nil, fmt.Errorf("field %s has %d children out of the schema", e.Name, e.NumChildren)
+	}
+
+	return e, nil
+}
+
+// Parse the next element below the path, at the levels of its parent. The element is treated as required
+// if it is the repeated element of a list.
+func (p *schemaParser) parse(path []string, def, rep int, element bool) (*column, error) {
+	e, err := p.next()
+
+	if err != nil {
+		return nil, err
+	}
+
+	path = append(path[:len(path):len(path)], e.Name)
+
+	if e.Repetition == repeated && !element {
+		// a repeated field out of an annotated list is a list of required elements
+		item, err := p.parseElement(e, path, def+1, rep+1, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return newList(e.Name, false, schema.List, def, def+1, rep+1, item)
+	}
+
+	nullable := e.Repetition == optional && !element
+
+	if nullable {
+		def++
+	}
+
+	return p.parseElement(e, path, def, rep, nullable)
+}
+
+func (p *schemaParser) parseElement(e *schemaElement, path []string, def, rep int, nullable bool) (*column, error) {
+	name := strings.Join(path, ".")
+
+	if e.NumChildren == 0 && e.Type < 0 {
+		return nil, fmt.Errorf("field %s has no type", name)
+	}
+
+	if e.NumChildren == 0 {
+		return p.parseLeaf(e, path, def, rep, nullable)
+	}
+
+	lt := e.LogicalType
+
+	switch {
+	case (lt != nil && lt.List) || e.ConvertedType == convertedList:
+		return p.parseList(e, path, def, rep, nullable, false)
+
+	case (lt != nil && lt.Map) || e.ConvertedType == convertedMap || e.ConvertedType == convertedMapKeyValue:
+		return p.parseList(e, path, def, rep, nullable, true)
+	}
+
+	c := &column{kind: kindStruct, def: def}
+
+	var children []*schema.Field
+
+	for i := 0; i < int(e.NumChildren); i++ {
+		child, err := p.parse(path, def, rep, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.children = append(c.children, child)
+		c.leaves = append(c.leaves, child.leaves...)
+		children = append(children, child.field)
+	}
+
+	field, err := schema.NewField(e.Name, nullable, schema.Struct, children...)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s of struct, %s", name, err)
+	}
+
+	c.field = field
+
+	return c, nil
+}
+
+// Parse the only child of a list or map, which is repeated. The child of the standard lists is a group
+// of the element, and the legacy lists repeat their element directly.
+func (p *schemaParser) parseList(e *schemaElement, path []string, def, rep int, nullable, isMap bool) (*column, error) {
+	name := strings.Join(path, ".")
+
+	if e.NumChildren != 1 {
+		return nil, fmt.Errorf("field %s of list or map has %d children, expected 1", name, e.NumChildren)
+	}
+
+	if p.elements[p.pos].Repetition != repeated {
+		return nil, fmt.Errorf("field %s of list or map has no repeated child", name)
+	}
+
+	child := p.elements[p.pos]
+	elemDef, elemRep := def+1, rep+1
+
+	if isMap {
+		if child.NumChildren != 2 {
+			return nil, fmt.Errorf("field %s of map has no key and value", name)
+		}
+
+		p.pos++
+		path = append(path[:len(path):len(path)], child.Name)
+
+		key, err := p.parse(path, elemDef, elemRep, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if key.field.Nullable {
+			return nil, fmt.Errorf("field %s of map has nullable keys", name)
+		}
+
+		value, err := p.parse(path, elemDef, elemRep, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := schema.NewField(child.Name, false, schema.Struct, key.field, value.field)
+
+		if err != nil {
+			return nil, fmt.Errorf("field %s of map, %s", name, err)
+		}
+
+		item := &column{
+			field:    entries,
+			kind:     kindStruct,
+			def:      elemDef,
+			children: []*column{key, value},
+			leaves:   append(key.leaves[:len(key.leaves):len(key.leaves)], value.leaves...),
+		}
+
+		return newList(e.Name, nullable, schema.NewMap(false), def, elemDef, elemRep, item)
+	}
+
+	var item *column
+	var err error
+
+	if child.NumChildren == 1 && child.Name != "array" && child.Name != e.Name+"_tuple" {
+		p.pos++
+
+		item, err = p.parse(append(path[:len(path):len(path)], child.Name), elemDef, elemRep, false)
+	} else {
+		item, err = p.parse(path, elemDef, elemRep, true)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newList(e.Name, nullable, schema.List, def, elemDef, elemRep, item)
+}
+
+func newList(name string, nullable bool, tp schema.Type, def, elemDef, rep int, item *column) (*column, error) {
+	field, err := schema.NewField(name, nullable, tp, item.field)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s of list, %s", name, err)
+	}
+
+	return &column{
+		field:    field,
+		kind:     kindList,
+		def:      def,
+		elemDef:  elemDef,
+		rep:      rep,
+		children: []*column{item},
+		leaves:   item.leaves,
+	}, nil
+}
+
+func (p *schemaParser) parseLeaf(e *schemaElement, path []string, def, rep int, nullable bool) (*column, error) {
+	name := strings.Join(path, ".")
+	tp, fn, err := leafType(e)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s, %s", name, err)
+	}
+
+	field, err := schema.NewField(e.Name, nullable, tp)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s of type %s, %s", name, tp, err)
+	}
+
+	l := &leaf{index: p.leaves, path: path, element: e, maxDef: def, maxRep: rep, append: fn}
+	p.leaves++
+
+	return &column{field: field, kind: kindLeaf, def: def, leaves: []*leaf{l}}, nil
+}
+
+// Returns the Arrow type of the primitive element, from its logical type, converted type and physical type,
+// and the function appending its values.
+func leafType(e *schemaElement) (schema.Type, appendFunc, error) {
+	lt := e.LogicalType
+
+	if lt == nil {
+		lt = convertedLogicalType(e)
+	}
+
+	switch {
+	case lt.Decimal != nil:
+		if e.Type != typeInt32 && e.Type != typeInt64 && e.Type != typeByteArray && e.Type != typeFixedLenByteArray {
+			break
+		}
+
+		precision := lt.Decimal.Precision
+
+		if precision <= 0 || precision > 76 || lt.Decimal.Scale < 0 || lt.Decimal.Scale > precision {
+			return nil, nil, fmt.Errorf("invalid decimal of precision %d and scale %d", precision, lt.Decimal.Scale)
+		}
+
+		tp := schema.NewDecimal(schema.Precision(precision), int(lt.Decimal.Scale))
+
+		if precision > 38 {
+			tp.BitWidth = 256
+		}
+
+		return tp, appendDecimal, nil
+
+	case lt.Integer != nil:
+		width := int(lt.Integer.BitWidth)
+
+		if width != 8 && width != 16 && width != 32 && width != 64 {
+			return nil, nil, fmt.Errorf("invalid integer of %d bits", width)
+		}
+
+		switch {
+		case e.Type == typeInt32 && width < 32:
+			return schema.NewInt(width, lt.Integer.Signed), appendNarrow(width), nil
+		case (e.Type == typeInt32 && width == 32) || (e.Type == typeInt64 && width == 64):
+			return schema.NewInt(width, lt.Integer.Signed), appendFixed, nil
+		}
+
+	case lt.Date:
+		if e.Type == typeInt32 {
+			return schema.NewDate(schema.DateDay), appendFixed, nil
+		}
+
+	case lt.Time != nil:
+		switch {
+		case lt.Time.Unit == 1 && e.Type == typeInt32:
+			return schema.NewTime(schema.Millisecond), appendFixed, nil
+		case lt.Time.Unit == 2 && e.Type == typeInt64:
+			return schema.NewTime(schema.Microsecond), appendFixed, nil
+		case lt.Time.Unit == 3 && e.Type == typeInt64:
+			return schema.NewTime(schema.Nanosecond), appendFixed, nil
+		}
+
+	case lt.Timestamp != nil:
+		if e.Type != typeInt64 || lt.Timestamp.Unit < 1 || lt.Timestamp.Unit > 3 {
+			break
+		}
+
+		tp := schema.NewTimeStamp([]schema.TimeUnit{schema.Millisecond, schema.Microsecond, schema.Nanosecond}[lt.Timestamp.Unit-1])
+
+		if lt.Timestamp.AdjustedToUTC {
+			tp.Timezone = "UTC"
+		}
+
+		return tp, appendFixed, nil
+
+	case lt.String, lt.Enum, lt.JSON:
+		if e.Type == typeByteArray {
+			return schema.Utf8, appendBytes, nil
+		}
+
+	case lt.Float16:
+		if e.Type == typeFixedLenByteArray && e.TypeLength == 2 {
+			return schema.NewFloatingPoint(schema.Half), appendFixed, nil
+		}
+
+	case lt.UUID:
+		if e.Type == typeFixedLenByteArray && e.TypeLength == 16 {
+			return schema.NewFixedSizeBinary(16), appendBytes, nil
+		}
+	}
+
+	switch e.Type {
+	case typeBoolean:
+		return schema.Bool, appendBool, nil
+	case typeInt32:
+		return schema.NewInt(32, true), appendFixed, nil
+	case typeInt64:
+		return schema.NewInt(64, true), appendFixed, nil
+	case typeInt96:
+		return schema.NewTimeStamp(schema.Nanosecond), appendInt96, nil
+	case typeFloat:
+		return schema.NewFloatingPoint(schema.Single), appendFixed, nil
+	case typeDouble:
+		return schema.NewFloatingPoint(schema.Double), appendFixed, nil
+	case typeByteArray:
+		return schema.Binary, appendBytes, nil
+	case typeFixedLenByteArray:
+		if e.TypeLength <= 0 {
+			return nil, nil, fmt.Errorf("invalid fixed length %d", e.TypeLength)
+		}
+
+		return schema.NewFixedSizeBinary(int(e.TypeLength)), appendBytes, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown Parquet type %d", e.Type)
+}
+
+// Returns the logical type of the converted type of the older files.
+func convertedLogicalType(e *schemaElement) *logicalType {
+	lt := &logicalType{}
+
+	switch tp := e.ConvertedType; tp {
+	case convertedUTF8:
+		lt.String = true
+	case convertedEnum:
+		lt.Enum = true
+	case convertedJSON:
+		lt.JSON = true
+	case convertedDecimal:
+		lt.Decimal = &decimalType{Scale: e.Scale, Precision: e.Precision}
+	case convertedDate:
+		lt.Date = true
+	case convertedTimeMillis:
+		lt.Time = &timeType{AdjustedToUTC: true, Unit: 1}
+	case convertedTimeMicros:
+		lt.Time = &timeType{AdjustedToUTC: true, Unit: 2}
+	case convertedTimestampMillis:
+		lt.Timestamp = &timeType{AdjustedToUTC: true, Unit: 1}
+	case convertedTimestampMicros:
+		lt.Timestamp = &timeType{AdjustedToUTC: true, Unit: 2}
+	case convertedUint8, convertedUint16, convertedUint32, convertedUint64:
+		lt.Integer = &intType{BitWidth: int8(8 << uint(tp-convertedUint8))}
+	case convertedInt8, convertedInt16, convertedInt32, convertedInt64:
+		lt.Integer = &intType{BitWidth: int8(8 << uint(tp-convertedInt8)), Signed: true}
+	}
+
+	return lt
+}
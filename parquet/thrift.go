@@ -0,0 +1,378 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The types of the Thrift compact protocol.
+const (
+	thriftStop    = 0
+	thriftTrue    = 1
+	thriftFalse   = 2
+	thriftByte    = 3
+	thriftI16     = 4
+	thriftI32     = 5
+	thriftI64     = 6
+	thriftDouble  = 7
+	thriftBinary  = 8
+	thriftList    = 9
+	thriftSet     = 10
+	thriftMap     = 11
+	thriftStruct  = 12
+	maxThriftNest = 64
+)
+
+var errThriftEOF = errors.New("unexpected end of Thrift data")
+
+// thriftReader decodes the Thrift compact protocol of the Parquet metadata.
+type thriftReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThriftEOF
+	}
+
+	b := r.buf[r.pos]
+	r.pos++
+
+	return b, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+
+	if n <= 0 {
+		return 0, errThriftEOF
+	}
+
+	r.pos += n
+
+	return v, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	v, err := r.uvarint()
+
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (r *thriftReader) i32() (int32, error) {
+	v, err := r.varint()
+
+	if err == nil && (v < math.MinInt32 || v > math.MaxInt32) {
+		return 0, fmt.Errorf("Thrift i32 %d out of range", v)
+	}
+
+	return int32(v), err
+}
+
+func (r *thriftReader) binary() ([]byte, error) {
+	n, err := r.uvarint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(len(r.buf)-r.pos) {
+		return nil, errThriftEOF
+	}
+
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+
+	return b, nil
+}
+
+func (r *thriftReader) string() (string, error) {
+	b, err := r.binary()
+
+	return string(b), err
+}
+
+// Read the field into v if it is of the type, or skip it.
+func (r *thriftReader) readI32(tp byte, v *int32) (err error) {
+	if tp != thriftI32 {
+		return r.skip(tp)
+	}
+
+	*v, err = r.i32()
+
+	return err
+}
+
+func (r *thriftReader) readI64(tp byte, v *int64) (err error) {
+	if tp != thriftI64 {
+		return r.skip(tp)
+	}
+
+	*v, err = r.varint()
+
+	return err
+}
+
+func (r *thriftReader) readString(tp byte, v *string) (err error) {
+	if tp != thriftBinary {
+		return r.skip(tp)
+	}
+
+	*v, err = r.string()
+
+	return err
+}
+
+// Read a bool field, whose value is its type.
+func (r *thriftReader) readBool(tp byte, v *bool) error {
+	if tp != thriftTrue && tp != thriftFalse {
+		return r.skip(tp)
+	}
+
+	*v = tp == thriftTrue
+
+	return nil
+}
+
+// Read a struct, calling field with the id and type of each field, which must read or skip its value.
+func (r *thriftReader) readStruct(field func(id int16, tp byte) error) error {
+	if r.depth++; r.depth > maxThriftNest {
+		return errors.New("Thrift structs nested too deeply")
+	}
+
+	defer func() { r.depth-- }()
+
+	var id int16
+
+	for {
+		b, err := r.byte()
+
+		if err != nil {
+			return err
+		}
+
+		tp := b & 0x0f
+
+		if tp == thriftStop {
+			return nil
+		}
+
+		if delta := b >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			v, err := r.varint()
+
+			if err != nil {
+				return err
+			}
+
+			id = int16(v)
+		}
+
+		if err := field(id, tp); err != nil {
+			return err
+		}
+	}
+}
+
+// Read the header of a list or set, returns the type and the number of elements.
+func (r *thriftReader) listHeader() (byte, int, error) {
+	b, err := r.byte()
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n := int(b >> 4)
+
+	if n == 15 {
+		size, err := r.uvarint()
+
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if size > uint64(len(r.buf)-r.pos) { // every element has at least a byte
+			return 0, 0, errThriftEOF
+		}
+
+		n = int(size)
+	}
+
+	return b & 0x0f, n, nil
+}
+
+// Read a list, calling elem with the type of each element, which must read or skip it.
+func (r *thriftReader) readList(elem func(tp byte) error) error {
+	tp, n, err := r.listHeader()
+
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		if err := elem(tp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Skip a value of the type.
+func (r *thriftReader) skip(tp byte) error {
+	var err error
+
+	switch tp {
+	case thriftTrue, thriftFalse:
+	case thriftByte:
+		_, err = r.byte()
+	case thriftI16, thriftI32, thriftI64:
+		_, err = r.uvarint()
+	case thriftDouble:
+		if r.pos += 8; r.pos > len(r.buf) {
+			err = errThriftEOF
+		}
+	case thriftBinary:
+		_, err = r.binary()
+	case thriftList, thriftSet:
+		err = r.readList(func(tp byte) error {
+			if tp == thriftTrue || tp == thriftFalse {
+				_, err := r.byte()
+
+				return err
+			}
+
+			return r.skip(tp)
+		})
+	case thriftMap:
+		err = r.skipMap()
+	case thriftStruct:
+		err = r.readStruct(func(id int16, tp byte) error { return r.skip(tp) })
+	default:
+		err = fmt.Errorf("unknown Thrift type %d", tp)
+	}
+
+	return err
+}
+
+func (r *thriftReader) skipMap() error {
+	n, err := r.uvarint()
+
+	if err != nil || n == 0 {
+		return err
+	}
+
+	if n > uint64(len(r.buf)-r.pos) {
+		return errThriftEOF
+	}
+
+	types, err := r.byte()
+
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < int(n); i++ {
+		for _, tp := range []byte{types >> 4, types & 0x0f} {
+			if tp == thriftTrue || tp == thriftFalse {
+				tp = thriftByte
+			}
+
+			if err := r.skip(tp); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// thriftWriter encodes the Thrift compact protocol of the Parquet metadata.
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // the ids of the last fields of the structs being written
+}
+
+func (w *thriftWriter) uvarint(v uint64) { w.buf = binary.AppendUvarint(w.buf, v) }
+
+func (w *thriftWriter) varint(v int64) { w.uvarint(uint64(v<<1 ^ v>>63)) }
+
+func (w *thriftWriter) binary(b []byte) {
+	w.uvarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+// Write a struct, whose fields are written by the function in the order of their ids.
+func (w *thriftWriter) writeStruct(fields func()) {
+	w.last = append(w.last, 0)
+
+	fields()
+
+	w.buf = append(w.buf, thriftStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) fieldHeader(id int16, tp byte) {
+	last := &w.last[len(w.last)-1]
+
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|tp)
+	} else {
+		w.buf = append(w.buf, tp)
+		w.varint(int64(id))
+	}
+
+	*last = id
+}
+
+func (w *thriftWriter) writeI32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) writeI64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) writeBinary(id int16, b []byte) {
+	w.fieldHeader(id, thriftBinary)
+	w.binary(b)
+}
+
+func (w *thriftWriter) writeString(id int16, s string) { w.writeBinary(id, []byte(s)) }
+
+// Write a bool field, whose value is its type.
+func (w *thriftWriter) writeBool(id int16, v bool) {
+	if v {
+		w.fieldHeader(id, thriftTrue)
+	} else {
+		w.fieldHeader(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) writeStructField(id int16, fields func()) {
+	w.fieldHeader(id, thriftStruct)
+	w.writeStruct(fields)
+}
+
+// Write a list of n elements of the type, each written by the function.
+func (w *thriftWriter) writeList(id int16, tp byte, n int, elem func(i int)) {
+	w.fieldHeader(id, thriftList)
+
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|tp)
+	} else {
+		w.buf = append(w.buf, 0xf0|tp)
+		w.uvarint(uint64(n))
+	}
+
+	for i := 0; i < n; i++ {
+		elem(i)
+	}
+}
@@ -0,0 +1,945 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const (
+	DefaultRowGroupSize   = 64 * 1024
+	DefaultPageSize       = 1024 * 1024
+	DefaultDictionarySize = 1024 * 1024
+
+	createdBy = "github.com/flier/arrow"
+)
+
+// Codec is the compression codec of the pages.
+type Codec int32
+
+const (
+	Uncompressed Codec = codecUncompressed
+	Snappy       Codec = codecSnappy
+	Gzip         Codec = codecGzip
+	Zstd         Codec = codecZstd
+)
+
+func (c Codec) String() string {
+	if name, found := codecNames[int32(c)]; found {
+		return name
+	}
+
+	return fmt.Sprintf("%d", int32(c))
+}
+
+// WriteOptions controls how the record batches are written, the zero value writes uncompressed plain pages.
+type WriteOptions struct {
+	RowGroupSize   int               // the number of rows of each row group, DefaultRowGroupSize if zero
+	PageSize       int               // the size of the values of each page, DefaultPageSize if zero
+	Dictionary     bool              // dictionary encode the values, except booleans
+	DictionarySize int               // the size of the largest dictionary of a column chunk, DefaultDictionarySize if zero
+	Compression    Codec             // the codec of the pages
+	Metadata       map[string]string // the key value metadata of the file
+}
+
+// Writer writes the record batches of the Schema as the row groups of a Parquet file, which is completed by Close.
+//
+// The fields are mapped to the Parquet schema as the reader maps them back, but the dates are days,
+// and the times and timestamps in seconds are in milliseconds. Dictionary encoded fields are written
+// as their values, and the duration, interval, union and fixed size list fields are not supported.
+// The column chunks record the null count and the min and max values of their types.
+type Writer struct {
+	Schema *schema.Schema
+
+	w       io.Writer
+	opts    WriteOptions
+	offset  int64
+	columns []*column
+	leaves  []*leaf
+	meta    *fileMetaData
+	rows    int // the number of rows of the current row group
+	err     error
+}
+
+// Create a writer of the record batches of the schema, writing the header of the file.
+func NewWriter(w io.Writer, s *schema.Schema, opts WriteOptions) (*Writer, error) {
+	if opts.RowGroupSize <= 0 {
+		opts.RowGroupSize = DefaultRowGroupSize
+	}
+
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+
+	if opts.DictionarySize <= 0 {
+		opts.DictionarySize = DefaultDictionarySize
+	}
+
+	switch opts.Compression {
+	case Uncompressed, Snappy, Gzip, Zstd:
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %s", opts.Compression)
+	}
+
+	elements := []*schemaElement{{
+		Type: -1, Repetition: -1, Name: "schema", NumChildren: int32(len(s.Fields)), ConvertedType: convertedNone,
+	}}
+
+	for _, field := range s.Fields {
+		es, err := fieldElements(field, field.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, es...)
+	}
+
+	columns, err := parseSchema(elements)
+
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &Writer{
+		Schema:  s,
+		w:       w,
+		opts:    opts,
+		columns: columns,
+		meta:    &fileMetaData{Version: 1, Schema: elements, CreatedBy: createdBy},
+	}
+
+	for _, c := range columns {
+		for _, l := range c.leaves {
+			l.values = newValues(l.element)
+			writer.leaves = append(writer.leaves, l)
+		}
+	}
+
+	keys := make([]string, 0, len(opts.Metadata))
+
+	for key := range opts.Metadata {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		writer.meta.KeyValues = append(writer.meta.KeyValues, &keyValue{key, opts.Metadata[key]})
+	}
+
+	if err := writer.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// Returns the Parquet elements of the field and its children.
+func fieldElements(field *schema.Field, path string) ([]*schemaElement, error) {
+	e := &schemaElement{Type: -1, Repetition: required, Name: field.Name, ConvertedType: convertedNone}
+
+	if field.Nullable {
+		e.Repetition = optional
+	}
+
+	tp := schema.StorageType(field.Type)
+
+	var children []*schema.Field
+
+	switch {
+	case tp == schema.Struct:
+		children = field.Children
+
+	case tp == schema.List || tp == schema.LargeList:
+		e.ConvertedType, e.LogicalType = convertedList, &logicalType{List: true}
+		children = field.Children
+
+		if len(children) != 1 {
+			return nil, fmt.Errorf("field %s of type %s has %d children, expected 1", path, field.Type, len(children))
+		}
+
+	default:
+		if _, ok := tp.(*schema.Map); !ok {
+			if err := leafElement(e, tp); err != nil {
+				return nil, fmt.Errorf("field %s %s", path, err)
+			}
+
+			return []*schemaElement{e}, nil
+		}
+
+		e.ConvertedType, e.LogicalType = convertedMap, &logicalType{Map: true}
+
+		if len(field.Children) != 1 || len(field.Children[0].Children) != 2 {
+			return nil, fmt.Errorf("field %s of type Map has no struct of keys and values", path)
+		}
+
+		if field.Children[0].Children[0].Nullable {
+			return nil, fmt.Errorf("field %s of type Map has nullable keys", path)
+		}
+	}
+
+	elements := []*schemaElement{e}
+
+	if tp != schema.Struct {
+		// the repeated group of the elements of a list, or of the keys and values of a map
+		group := &schemaElement{Type: -1, Repetition: repeated, Name: "list", NumChildren: 1, ConvertedType: convertedNone}
+
+		if _, ok := tp.(*schema.Map); ok {
+			entries := field.Children[0]
+			group.Name, group.NumChildren, children = entries.Name, 2, entries.Children
+		}
+
+		e.NumChildren = 1
+		elements = append(elements, group)
+	} else {
+		e.NumChildren = int32(len(children))
+	}
+
+	for _, child := range children {
+		es, err := fieldElements(child, path+"."+child.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, es...)
+	}
+
+	return elements, nil
+}
+
+// Set the physical type and annotations of the primitive type.
+func leafElement(e *schemaElement, t schema.Type) error {
+	switch tp := t.(type) {
+	case *schema.Int:
+		e.Type = typeInt32
+
+		if tp.BitWidth == 64 {
+			e.Type = typeInt64
+		}
+
+		e.LogicalType = &logicalType{Integer: &intType{BitWidth: int8(tp.BitWidth), Signed: tp.Signed}}
+		e.ConvertedType = int32(convertedUint8 + bits.TrailingZeros(uint(tp.BitWidth/8)))
+
+		if tp.Signed {
+			e.ConvertedType += convertedInt8 - convertedUint8
+		}
+
+		return nil
+
+	case *schema.FloatingPoint:
+		switch tp.Precision {
+		case schema.Half:
+			e.Type, e.TypeLength, e.LogicalType = typeFixedLenByteArray, 2, &logicalType{Float16: true}
+		case schema.Single:
+			e.Type = typeFloat
+		default:
+			e.Type = typeDouble
+		}
+
+		return nil
+
+	case *schema.Decimal:
+		e.Type, e.TypeLength = typeFixedLenByteArray, int32(tp.BitWidth/8)
+		e.ConvertedType, e.Scale, e.Precision = convertedDecimal, int32(tp.Scale), int32(tp.Precision)
+		e.LogicalType = &logicalType{Decimal: &decimalType{Scale: e.Scale, Precision: e.Precision}}
+
+		return nil
+
+	case *schema.DateType:
+		e.Type, e.ConvertedType, e.LogicalType = typeInt32, convertedDate, &logicalType{Date: true}
+
+		return nil
+
+	case *schema.TimeType:
+		switch tp.Unit {
+		case schema.Second, schema.Millisecond:
+			e.Type, e.ConvertedType = typeInt32, convertedTimeMillis
+			e.LogicalType = &logicalType{Time: &timeType{AdjustedToUTC: true, Unit: 1}}
+		case schema.Microsecond:
+			e.Type, e.ConvertedType = typeInt64, convertedTimeMicros
+			e.LogicalType = &logicalType{Time: &timeType{AdjustedToUTC: true, Unit: 2}}
+		default:
+			e.Type, e.LogicalType = typeInt64, &logicalType{Time: &timeType{AdjustedToUTC: true, Unit: 3}}
+		}
+
+		return nil
+
+	case *schema.Timestamp:
+		ts := &timeType{AdjustedToUTC: tp.Timezone != "", Unit: 3}
+
+		switch tp.Unit {
+		case schema.Second, schema.Millisecond:
+			ts.Unit = 1
+		case schema.Microsecond:
+			ts.Unit = 2
+		}
+
+		e.Type, e.LogicalType = typeInt64, &logicalType{Timestamp: ts}
+
+		if ts.AdjustedToUTC && ts.Unit < 3 {
+			e.ConvertedType = int32(convertedTimestampMillis + ts.Unit - 1)
+		}
+
+		return nil
+
+	case *schema.FixedSizeBinary:
+		e.Type, e.TypeLength = typeFixedLenByteArray, int32(tp.ByteWidth)
+
+		return nil
+	}
+
+	switch t {
+	case schema.Bool:
+		e.Type = typeBoolean
+	case schema.Utf8, schema.LargeUtf8:
+		e.Type, e.ConvertedType, e.LogicalType = typeByteArray, convertedUTF8, &logicalType{String: true}
+	case schema.Binary, schema.LargeBinary:
+		e.Type = typeByteArray
+	default:
+		return fmt.Errorf("of type %s is not supported by Parquet", t)
+	}
+
+	return nil
+}
+
+func newValues(e *schemaElement) *values {
+	v := &values{width: valueWidth(e)}
+
+	if e.Type == typeBoolean {
+		v.bools = []bool{}
+	}
+
+	return v
+}
+
+// Write the rows of the batch, the row groups are written when they are full.
+func (w *Writer) Write(batch *vector.RecordBatch) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	cols, err := columns.New(w.Schema, batch)
+
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < batch.Length; i++ {
+		for j, c := range w.columns {
+			c.shred(cols[j], i, 0)
+		}
+
+		if w.rows++; w.rows == w.opts.RowGroupSize {
+			if err := w.flush(); err != nil {
+				w.err = err
+
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Write the last row group and the footer of the file, the writer fails after it.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.err = errors.New("Parquet writer closed")
+
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+
+	tw := &thriftWriter{}
+	w.meta.write(tw)
+
+	footer := binary.LittleEndian.AppendUint32(tw.buf, uint32(len(tw.buf)))
+
+	return w.write(append(footer, magic...))
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+
+	if err != nil {
+		return fmt.Errorf("fail to write Parquet, %s", err)
+	}
+
+	return nil
+}
+
+// Append the levels and values of the value at i to the leaves of the column,
+// the first of them at the repetition level.
+func (c *column) shred(col *columns.Column, i, rep int) {
+	if c.field.Nullable && col.IsNull(i) {
+		for _, l := range c.leaves {
+			l.defs, l.reps = append(l.defs, c.def-1), append(l.reps, rep)
+		}
+
+		return
+	}
+
+	if dictionary := col.Dictionary(); dictionary != nil {
+		c.shred(dictionary, col.Index(i), rep)
+
+		return
+	}
+
+	switch c.kind {
+	case kindLeaf:
+		l := c.leaves[0]
+		l.defs, l.reps = append(l.defs, c.def), append(l.reps, rep)
+
+		appendValue(l.values, col, i)
+
+	case kindStruct:
+		for k, child := range c.children {
+			child.shred(col.Children()[k], i, rep)
+		}
+
+	case kindList:
+		start, end := col.Span(i)
+
+		if start == end {
+			for _, l := range c.leaves {
+				l.defs, l.reps = append(l.defs, c.def), append(l.reps, rep)
+			}
+		}
+
+		for j := start; j < end; j++ {
+			c.children[0].shred(col.Children()[0], j, rep)
+
+			rep = c.rep
+		}
+	}
+}
+
+// Append the value of the column at i in its Parquet type.
+func appendValue(v *values, col *columns.Column, i int) {
+	switch tp := schema.StorageType(col.Field().Type).(type) {
+	case *schema.Int:
+		switch {
+		case tp.BitWidth == 8 && tp.Signed:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, uint32(col.Int8(i)))
+		case tp.BitWidth == 16 && tp.Signed:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, uint32(col.Int16(i)))
+		case tp.BitWidth == 8:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, uint32(col.Uint8(i)))
+		case tp.BitWidth == 16:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, uint32(col.Uint16(i)))
+		case tp.BitWidth == 32:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, col.Uint32(i))
+		default:
+			v.fixed = binary.LittleEndian.AppendUint64(v.fixed, col.Uint64(i))
+		}
+
+	case *schema.FloatingPoint:
+		switch tp.Precision {
+		case schema.Half:
+			v.fixed = binary.LittleEndian.AppendUint16(v.fixed, col.Uint16(i))
+		case schema.Single:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, col.Uint32(i))
+		default:
+			v.fixed = binary.LittleEndian.AppendUint64(v.fixed, col.Uint64(i))
+		}
+
+	case *schema.Decimal:
+		// the two's complement in big endian
+		size := tp.BitWidth / 8
+		n := col.Decimal(i)
+
+		if n.Sign() < 0 {
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+		}
+
+		v.fixed = append(v.fixed, n.FillBytes(make([]byte, size))...)
+
+	case *schema.DateType:
+		if tp.Unit == schema.DateDay {
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, col.Uint32(i))
+		} else {
+			ms := col.Int64(i)
+			days := ms / 86400000
+
+			if ms%86400000 < 0 {
+				days--
+			}
+
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, uint32(days))
+		}
+
+	case *schema.TimeType:
+		switch tp.Unit {
+		case schema.Second:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, uint32(col.Int32(i)*1000))
+		case schema.Millisecond:
+			v.fixed = binary.LittleEndian.AppendUint32(v.fixed, col.Uint32(i))
+		default:
+			v.fixed = binary.LittleEndian.AppendUint64(v.fixed, col.Uint64(i))
+		}
+
+	case *schema.Timestamp:
+		if tp.Unit == schema.Second {
+			v.fixed = binary.LittleEndian.AppendUint64(v.fixed, uint64(col.Int64(i)*1000))
+		} else {
+			v.fixed = binary.LittleEndian.AppendUint64(v.fixed, col.Uint64(i))
+		}
+
+	case *schema.FixedSizeBinary:
+		v.fixed = append(v.fixed, col.Bytes(i)...)
+
+	default:
+		if tp == schema.Bool {
+			v.bools = append(v.bools, col.Bool(i))
+		} else {
+			v.bytes = append(v.bytes, col.Bytes(i))
+		}
+	}
+}
+
+// Write the column chunks of the leaves as a row group.
+func (w *Writer) flush() error {
+	g := &rowGroup{NumRows: int64(w.rows)}
+
+	for _, l := range w.leaves {
+		chunk, err := w.writeChunk(l)
+
+		if err != nil {
+			return fmt.Errorf("fail to write column %s, %s", joinPath(l.path), err)
+		}
+
+		g.Columns = append(g.Columns, chunk)
+		g.TotalByteSize += chunk.MetaData.TotalUncompressedSize
+
+		l.defs, l.reps, l.values = l.defs[:0], l.reps[:0], newValues(l.element)
+	}
+
+	w.meta.RowGroups = append(w.meta.RowGroups, g)
+	w.meta.NumRows += int64(w.rows)
+	w.rows = 0
+
+	return nil
+}
+
+// Write the pages of the leaf, the values are dictionary encoded if the dictionary is small enough.
+func (w *Writer) writeChunk(l *leaf) (*columnChunk, error) {
+	meta := &columnMetaData{
+		Type:       l.element.Type,
+		Encodings:  []int32{encodingPlain, encodingRLE},
+		Path:       l.path,
+		Codec:      int32(w.opts.Compression),
+		NumValues:  int64(len(l.defs)),
+		Statistics: l.statistics(),
+	}
+
+	chunk := &columnChunk{FileOffset: w.offset, MetaData: meta}
+	start := w.offset
+
+	var dict *values
+	var indices []int
+
+	if w.opts.Dictionary && l.element.Type != typeBoolean {
+		dict, indices = l.values.dictionary(w.opts.DictionarySize)
+	}
+
+	bitWidth := 0
+
+	if dict != nil {
+		meta.Encodings = append(meta.Encodings, encodingRLEDictionary)
+		meta.DictionaryPageOffset = w.offset
+
+		h := &pageHeader{
+			Type:           pageDictionary,
+			DictionaryPage: &dictionaryPageHeader{NumValues: int32(dict.len()), Encoding: encodingPlain},
+		}
+
+		size, err := w.writePage(h, dict.encodePlain(nil, 0, dict.len()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		meta.TotalUncompressedSize += size
+		bitWidth = bits.Len(uint(dict.len() - 1))
+
+		if bitWidth == 0 {
+			bitWidth = 1
+		}
+	}
+
+	meta.DataPageOffset = w.offset
+
+	// split the pages at the rows, after the values of the page size
+	first, value, size := 0, 0, 0
+
+	for i, def := range l.defs {
+		if def == l.maxDef {
+			switch {
+			case dict != nil:
+				size += (bitWidth + 7) / 8
+			case l.values.width > 0:
+				size += l.values.width
+			case l.values.bools != nil:
+				size++
+			default:
+				size += 4 + len(l.values.bytes[value])
+			}
+
+			value++
+		}
+
+		if i+1 < len(l.defs) && (size < w.opts.PageSize || l.reps[i+1] != 0) {
+			continue
+		}
+
+		var body []byte
+
+		if l.maxRep > 0 {
+			body = appendLevels(body, l.reps[first:i+1], l.maxRep)
+		}
+
+		if l.maxDef > 0 {
+			body = appendLevels(body, l.defs[first:i+1], l.maxDef)
+		}
+
+		start := value - countValues(l.defs[first:i+1], l.maxDef)
+		h := &pageHeader{
+			Type: pageData,
+			DataPage: &dataPageHeader{
+				NumValues:               int32(i + 1 - first),
+				Encoding:                encodingPlain,
+				DefinitionLevelEncoding: encodingRLE,
+				RepetitionLevelEncoding: encodingRLE,
+			},
+		}
+
+		if dict != nil {
+			h.DataPage.Encoding = encodingRLEDictionary
+			body = encodeRLE(append(body, byte(bitWidth)), indices[start:value], bitWidth)
+		} else {
+			body = l.values.encodePlain(body, start, value)
+		}
+
+		n, err := w.writePage(h, body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		meta.TotalUncompressedSize += n
+		first, size = i+1, 0
+	}
+
+	meta.TotalCompressedSize = w.offset - start
+
+	return chunk, nil
+}
+
+// Append the levels in the RLE/bit-packed hybrid encoding, prefixed by their length.
+func appendLevels(buf []byte, levels []int, maxLevel int) []byte {
+	encoded := encodeRLE(nil, levels, bits.Len(uint(maxLevel)))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(encoded)))
+
+	return append(buf, encoded...)
+}
+
+func countValues(defs []int, maxDef int) int {
+	n := 0
+
+	for _, def := range defs {
+		if def == maxDef {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Write the page compressed, returns the uncompressed size of the page and its header.
+func (w *Writer) writePage(h *pageHeader, body []byte) (int64, error) {
+	compressed, err := compressPage(int32(w.opts.Compression), body)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(body) > math.MaxInt32 || len(compressed) > math.MaxInt32 {
+		return 0, fmt.Errorf("page of %d bytes is too large", len(body))
+	}
+
+	h.UncompressedPageSize, h.CompressedPageSize = int32(len(body)), int32(len(compressed))
+
+	tw := &thriftWriter{}
+	h.write(tw)
+
+	if err := w.write(tw.buf); err != nil {
+		return 0, err
+	}
+
+	if err := w.write(compressed); err != nil {
+		return 0, err
+	}
+
+	return int64(len(tw.buf) + len(body)), nil
+}
+
+var (
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderErr  error
+)
+
+// Compress the page with the codec.
+func compressPage(codec int32, page []byte) ([]byte, error) {
+	switch codec {
+	case codecSnappy:
+		return snappy.Encode(nil, page), nil
+
+	case codecGzip:
+		var buf bytes.Buffer
+
+		w := gzip.NewWriter(&buf)
+
+		if _, err := w.Write(page); err != nil {
+			return nil, err
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+
+	case codecZstd:
+		zstdEncoderOnce.Do(func() {
+			zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		})
+
+		if zstdEncoderErr != nil {
+			return nil, zstdEncoderErr
+		}
+
+		return zstdEncoder.EncodeAll(page, nil), nil
+	}
+
+	return page, nil
+}
+
+// Returns the dictionary of the distinct values and the indices of the values,
+// or nil if the dictionary in the plain encoding is larger than the size.
+func (v *values) dictionary(size int) (*values, []int) {
+	dict := &values{width: v.width}
+	seen := make(map[string]int)
+	indices := make([]int, v.len())
+	total := 0
+
+	for i := range indices {
+		b := v.value(i)
+		index, found := seen[string(b)]
+
+		if !found {
+			index = len(seen)
+			seen[string(b)] = index
+
+			if v.width > 0 {
+				dict.fixed = append(dict.fixed, b...)
+				total += v.width
+			} else {
+				dict.bytes = append(dict.bytes, b)
+				total += 4 + len(b)
+			}
+
+			if total > size {
+				return nil, nil
+			}
+		}
+
+		indices[i] = index
+	}
+
+	return dict, indices
+}
+
+// Returns the statistics of the levels and values of the leaf, the min and max values are omitted
+// if the type has no order, or there is no value.
+func (l *leaf) statistics() *statistics {
+	s := &statistics{}
+
+	for _, def := range l.defs {
+		if def < l.maxDef {
+			s.NullCount++
+		}
+	}
+
+	compare := valueOrder(l.element)
+
+	if compare == nil {
+		return s
+	}
+
+	for i := 0; i < l.values.len(); i++ {
+		b := l.values.value(i)
+
+		if isNaN(l.element.Type, b) {
+			continue
+		}
+
+		if s.MinValue == nil || compare(b, s.MinValue) < 0 {
+			s.MinValue = b
+		}
+
+		if s.MaxValue == nil || compare(b, s.MaxValue) > 0 {
+			s.MaxValue = b
+		}
+	}
+
+	s.MinValue = append([]byte(nil), s.MinValue...)
+	s.MaxValue = append([]byte(nil), s.MaxValue...)
+
+	// a zero min or max may be either of the signed zeros, which compare equal,
+	// so the min is written as -0.0 and the max as +0.0 to bound both
+	if isZero(l.element.Type, s.MinValue) {
+		s.MinValue = signedZero(l.element.Type, true)
+	}
+
+	if isZero(l.element.Type, s.MaxValue) {
+		s.MaxValue = signedZero(l.element.Type, false)
+	}
+
+	return s
+}
+
+// Returns the comparison of the values of the element in the plain encoding, or nil if they have no order.
+func valueOrder(e *schemaElement) func(a, b []byte) int {
+	unsigned := e.LogicalType != nil && e.LogicalType.Integer != nil && !e.LogicalType.Integer.Signed
+
+	switch e.Type {
+	case typeBoolean, typeByteArray:
+		return bytes.Compare
+
+	case typeInt32:
+		if unsigned {
+			return func(a, b []byte) int {
+				return compareOrdered(binary.LittleEndian.Uint32(a), binary.LittleEndian.Uint32(b))
+			}
+		}
+
+		return func(a, b []byte) int {
+			return compareOrdered(int32(binary.LittleEndian.Uint32(a)), int32(binary.LittleEndian.Uint32(b)))
+		}
+
+	case typeInt64:
+		if unsigned {
+			return func(a, b []byte) int {
+				return compareOrdered(binary.LittleEndian.Uint64(a), binary.LittleEndian.Uint64(b))
+			}
+		}
+
+		return func(a, b []byte) int {
+			return compareOrdered(int64(binary.LittleEndian.Uint64(a)), int64(binary.LittleEndian.Uint64(b)))
+		}
+
+	case typeFloat:
+		return func(a, b []byte) int {
+			return compareOrdered(math.Float32frombits(binary.LittleEndian.Uint32(a)),
+				math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}
+
+	case typeDouble:
+		return func(a, b []byte) int {
+			return compareOrdered(math.Float64frombits(binary.LittleEndian.Uint64(a)),
+				math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		}
+
+	case typeFixedLenByteArray:
+		switch {
+		case e.LogicalType != nil && e.LogicalType.Float16:
+			return nil
+
+		case e.ConvertedType == convertedDecimal:
+			// the two's complements in big endian of the same width
+			return func(a, b []byte) int {
+				if c := compareOrdered(int8(a[0]), int8(b[0])); c != 0 {
+					return c
+				}
+
+				return bytes.Compare(a[1:], b[1:])
+			}
+		}
+
+		return bytes.Compare
+	}
+
+	return nil
+}
+
+func compareOrdered[T int8 | int32 | int64 | uint32 | uint64 | float32 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func isNaN(tp int32, b []byte) bool {
+	switch tp {
+	case typeFloat:
+		return math.IsNaN(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+	case typeDouble:
+		return math.IsNaN(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	}
+
+	return false
+}
+
+// Returns whether the floating point value is either of the signed zeros.
+func isZero(tp int32, b []byte) bool {
+	switch {
+	case tp == typeFloat && len(b) == 4:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)) == 0
+	case tp == typeDouble && len(b) == 8:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)) == 0
+	}
+
+	return false
+}
+
+func signedZero(tp int32, negative bool) []byte {
+	zero := 0.0
+
+	if negative {
+		zero = math.Copysign(0, -1)
+	}
+
+	if tp == typeFloat {
+		return binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(zero)))
+	}
+
+	return binary.LittleEndian.AppendUint64(nil, math.Float64bits(zero))
+}
@@ -0,0 +1,360 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const writeSchema = "struct<id: int32 not null, name: utf8, score: float64, flag: bool, small: uint16, " +
+	"price: decimal128[10, 2], day: date32, at: timestamp[us, tz=UTC], " +
+	"tags: list<item: utf8>, point: struct<x: int64 not null, y: int64>, attrs: map<key: utf8 not null, value: int32>>"
+
+func writeBatch(t *testing.T, s *schema.Schema) *vector.RecordBatch {
+	b, err := arrow.NewRecordBuilder(s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2020, 5, 17, 0, 0, 0, 0, time.UTC)
+	names := []string{"alice", "bob", "alice", "carol", "bob"}
+
+	for i := 0; i < 5; i++ {
+		c := b.Columns
+		c[0].AppendInt32(int32(i + 1))
+
+		if i == 3 {
+			for _, c := range c[1:] {
+				c.AppendNull()
+			}
+
+			continue
+		}
+
+		c[1].AppendString(names[i])
+		c[2].AppendFloat64(float64(i) - 1.5)
+		c[3].AppendBool(i%2 == 0)
+		c[4].AppendUint16(uint16(60000 + i))
+		c[5].AppendDecimal(big.NewInt(int64(i*1000 - 1234)))
+		c[6].AppendDate(day.AddDate(0, 0, i))
+		c[7].AppendTime(day.Add(time.Duration(i) * time.Millisecond))
+
+		for j := 0; j < i; j++ {
+			c[8].Child(0).AppendString(names[j])
+		}
+
+		c[8].AppendList()
+
+		c[9].AppendStruct()
+		c[9].Child(0).AppendInt64(int64(-i))
+		c[9].Child(1).AppendNull()
+
+		if i > 0 {
+			entries := c[10].Child(0)
+			entries.AppendStruct()
+			entries.Child(0).AppendString("k")
+			entries.Child(1).AppendInt32(int32(i))
+		}
+
+		c[10].AppendList()
+	}
+
+	batch, err := b.NewRecordBatch()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return batch
+}
+
+// Returns the rows of the batch in JSON.
+func batchRows(t *testing.T, s *schema.Schema, batch *vector.RecordBatch) []string {
+	cols, err := columns.New(s, batch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []string
+
+	for i := 0; i < batch.Length; i++ {
+		var buf bytes.Buffer
+
+		columns.AppendJSONRow(&buf, cols, i, time.RFC3339Nano, false)
+
+		rows = append(rows, buf.String())
+	}
+
+	return rows
+}
+
+func writeFile(t *testing.T, s *schema.Schema, opts WriteOptions, batches ...*vector.RecordBatch) *Reader {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, s, opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, batch := range batches {
+		if err := w.Write(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()), ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func TestWrite(t *testing.T) {
+	s, err := schema.Parse(writeSchema)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := writeBatch(t, s)
+	expected := batchRows(t, s, batch)
+
+	tests := []WriteOptions{
+		{},
+		{RowGroupSize: 2, PageSize: 8},
+		{Dictionary: true, Compression: Snappy},
+		{Dictionary: true, DictionarySize: 16, Compression: Gzip},
+		{RowGroupSize: 3, Dictionary: true, PageSize: 1, Compression: Zstd},
+	}
+
+	for _, opts := range tests {
+		r := writeFile(t, s, opts, batch, batch)
+
+		if r.Schema.String() != s.String() {
+			t.Errorf("schema %s, expected %s", r.Schema, s)
+		}
+
+		if r.NumRows() != 10 {
+			t.Errorf("wrote %d rows, expected 10", r.NumRows())
+		}
+
+		if opts.RowGroupSize == 2 && r.NumRowGroups() != 5 {
+			t.Errorf("wrote %d row groups, expected 5", r.NumRowGroups())
+		}
+
+		rows := readRows(t, r)
+
+		if !reflect.DeepEqual(rows, append(expected, expected...)) {
+			t.Errorf("read %q with %+v, expected %q", rows, opts, expected)
+		}
+	}
+}
+
+func TestWriteTypes(t *testing.T) {
+	s, err := schema.Parse("struct<a: time32[s], b: timestamp[s], c: date64, d: fixed_size_binary[2], e: binary not null>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := arrow.NewRecordBuilder(s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Columns[0].AppendTimeOfDay(90 * time.Second)
+	b.Columns[1].AppendTime(time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC))
+	b.Columns[2].AppendDate(time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC))
+	b.Columns[3].AppendBytes([]byte("xy"))
+	b.Columns[4].AppendBytes([]byte("z"))
+
+	batch, err := b.NewRecordBatch()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := writeFile(t, s, WriteOptions{}, batch)
+	expected := "struct<a: time32[ms], b: timestamp[ms], c: date32, d: fixed_size_binary[2], e: binary not null>"
+
+	if r.Schema.String() != expected {
+		t.Errorf("schema %s, expected %s", r.Schema, expected)
+	}
+
+	rows := readRows(t, r)
+	expectedRows := []string{
+		`{"a":"00:01:30","b":"1969-12-31T23:59:59Z","c":"1969-12-31","d":"xy","e":"z"}`,
+	}
+
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("read %q, expected %q", rows, expectedRows)
+	}
+}
+
+func TestWriteStatistics(t *testing.T) {
+	s, err := schema.Parse(writeSchema)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := writeFile(t, s, WriteOptions{Dictionary: true}, writeBatch(t, s))
+	columns := r.meta.RowGroups[0].Columns
+
+	int32s := func(v int32) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(v)) }
+	decimal := func(v int64) []byte {
+		b := make([]byte, 16)
+
+		for i := range b {
+			b[15-i] = byte(v >> uint(min(i*8, 63)))
+		}
+
+		return b
+	}
+
+	tests := []struct {
+		column    int
+		nullCount int64
+		min, max  []byte
+	}{
+		{0, 0, int32s(1), int32s(5)},
+		{1, 1, []byte("alice"), []byte("bob")},
+		{3, 1, []byte{0}, []byte{1}},
+		{4, 1, int32s(60000), int32s(60004)},
+		{5, 1, decimal(-1234), decimal(2766)},
+		{8, 2, []byte("alice"), []byte("carol")},
+		{10, 5, nil, nil},
+	}
+
+	for _, test := range tests {
+		meta := columns[test.column].MetaData
+		stats := meta.Statistics
+
+		if stats == nil {
+			t.Errorf("column %s has no statistics", strings.Join(meta.Path, "."))
+
+			continue
+		}
+
+		if stats.NullCount != test.nullCount || !bytes.Equal(stats.MinValue, test.min) || !bytes.Equal(stats.MaxValue, test.max) {
+			t.Errorf("column %s has statistics %+v, expected %d nulls in [%v, %v]",
+				strings.Join(meta.Path, "."), stats, test.nullCount, test.min, test.max)
+		}
+	}
+
+	if encodings := columns[1].MetaData.Encodings; len(encodings) != 3 || encodings[2] != encodingRLEDictionary {
+		t.Errorf("column name has encodings %v, expected a dictionary", encodings)
+	}
+}
+
+func TestWriteFloatStatistics(t *testing.T) {
+	s, err := schema.Parse("struct<a: float32, b: float64, c: float64, d: float64>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := arrow.NewRecordBuilder(s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nan := math.NaN()
+
+	for _, v := range []float64{nan, 0, 2} {
+		b.Columns[0].AppendFloat32(float32(v))
+		b.Columns[1].AppendFloat64(v)
+		b.Columns[2].AppendFloat64(-v)
+		b.Columns[3].AppendFloat64(nan)
+	}
+
+	batch, err := b.NewRecordBatch()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := writeFile(t, s, WriteOptions{}, batch)
+	columns := r.meta.RowGroups[0].Columns
+
+	float32s := func(v float32) []byte { return binary.LittleEndian.AppendUint32(nil, math.Float32bits(v)) }
+	float64s := func(v float64) []byte { return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)) }
+	negativeZero := math.Copysign(0, -1)
+
+	tests := []struct {
+		min, max []byte
+	}{
+		{float32s(float32(negativeZero)), float32s(2)},
+		{float64s(negativeZero), float64s(2)},
+		{float64s(-2), float64s(0)},
+		{nil, nil},
+	}
+
+	for i, test := range tests {
+		meta := columns[i].MetaData
+		stats := meta.Statistics
+
+		if stats == nil || !bytes.Equal(stats.MinValue, test.min) || !bytes.Equal(stats.MaxValue, test.max) {
+			t.Errorf("column %s has statistics %+v, expected [%v, %v]", strings.Join(meta.Path, "."), stats, test.min, test.max)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	s, err := schema.Parse("struct<a: int32, b: struct<c: duration[s]>>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "field b.c of type duration[s] is not supported by Parquet"
+
+	if _, err := NewWriter(&bytes.Buffer{}, s, WriteOptions{}); err == nil || err.Error() != expected {
+		t.Errorf("create writer with error %v, expected %s", err, expected)
+	}
+
+	s, err = schema.Parse("struct<a: int32>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, s, WriteOptions{Compression: Codec(codecLZO)}); err == nil {
+		t.Error("create writer with LZO compression")
+	}
+
+	w, err := NewWriter(&bytes.Buffer{}, s, WriteOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err == nil {
+		t.Error("close a closed writer")
+	}
+}
@@ -0,0 +1,466 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Adapter converts the record batches of a schema to an evolved one, usually merged by Merge.
+//
+// The fields of the evolved schema are matched by name, those missing from the batches are filled
+// with nulls, the widened ones are cast, and the fields missing from the evolved schema are dropped.
+type Adapter struct {
+	From *Schema
+	To   *Schema
+
+	fields  []*fieldAdapter
+	nodes   int
+	buffers int
+}
+
+type adaptKind int
+
+const (
+	adaptCopy   adaptKind = iota // share the nodes and buffers of the field and its children
+	adaptNulls                   // fill the field and its children with nulls
+	adaptCast                    // convert the values to the evolved type
+	adaptStruct                  // share the nodes and buffers of the field, adapt its children
+)
+
+type fieldAdapter struct {
+	kind  adaptKind
+	field *Field // the evolved field
+	from  *Field // the field of the batches, nil if it is missing
+
+	// the first field node and buffer of the field in the batches, and how many are spanned by its children
+	node, nodes     int
+	buffer, buffers int
+
+	children []*fieldAdapter
+}
+
+// Create an adapter of the record batches of the schema from to the schema to.
+func NewAdapter(from, to *Schema) (*Adapter, error) {
+	a := &Adapter{From: from, To: to}
+
+	fields, err := a.planFields(to.Fields, from.Fields, &a.nodes, &a.buffers, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.fields = fields
+
+	return a, nil
+}
+
+func (a *Adapter) planFields(to, from []*Field, node, buffer *int, prefix string) ([]*fieldAdapter, error) {
+	type position struct {
+		field        *Field
+		node, buffer int
+	}
+
+	positions := make(map[string]*position, len(from))
+
+	for _, field := range from {
+		positions[field.Name] = &position{field, *node, *buffer}
+
+		if err := skip(field, node, buffer); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields []*fieldAdapter
+
+	for _, field := range to {
+		path := prefix + field.Name
+		pos, found := positions[field.Name]
+
+		if !found {
+			if !field.Nullable {
+				return nil, fmt.Errorf("field %s is not nullable and missing from the batches", path)
+			}
+
+			if err := fillable(field, path); err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, &fieldAdapter{kind: adaptNulls, field: field})
+
+			continue
+		}
+
+		f, err := a.planField(field, pos.field, pos.node, pos.buffer, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+func (a *Adapter) planField(to, from *Field, node, buffer int, path string) (*fieldAdapter, error) {
+	if !to.Nullable && from.Nullable {
+		return nil, fmt.Errorf("field %s is not nullable, the field of the batches is", path)
+	}
+
+	if to.Layout == nil || from.Layout == nil {
+		return nil, fmt.Errorf("missing layout of field %s", path)
+	}
+
+	f := &fieldAdapter{field: to, from: from, node: node, buffer: buffer}
+
+	if err := skip(from, &f.nodes, &f.buffers); err != nil {
+		return nil, err
+	}
+
+	if err := to.compatible(from, path); err == nil && len(to.Layout.Vectors) == len(from.Layout.Vectors) {
+		f.kind = adaptCopy
+
+		return f, nil
+	}
+
+	switch {
+	case to.Dictionary != nil || from.Dictionary != nil:
+
+	case to.Type.Value() == flatbuf.TypeStruct_ && from.Type.Value() == flatbuf.TypeStruct_ &&
+		len(to.Layout.Vectors) == len(from.Layout.Vectors):
+		node, buffer = node+1, buffer+len(from.Layout.Vectors)
+
+		children, err := a.planFields(to.Children, from.Children, &node, &buffer, path+".")
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.kind = adaptStruct
+		f.children = children
+
+		return f, nil
+
+	case castable(from.Type, to.Type) && isPrimitiveLayout(from.Layout) && isPrimitiveLayout(to.Layout):
+		f.kind = adaptCast
+
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("field %s of type %s can't be adapted to %s", path, from.Type, to.Type)
+}
+
+// Check the field can be filled with nulls, unions have no nulls of their own since V5.
+func fillable(field *Field, path string) error {
+	if field.Layout == nil {
+		return fmt.Errorf("missing layout of field %s", path)
+	}
+
+	for _, layout := range field.Layout.Vectors {
+		if layout.Type == vector.Type {
+			return fmt.Errorf("field %s of type %s can't be filled with nulls", path, field.Type)
+		}
+	}
+
+	for _, child := range field.Children {
+		if err := fillable(child, path+"."+child.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Whether the values of a type are held by the other without loss, except for integers
+// beyond the 53 bits of double precision.
+func castable(from, to Type) bool {
+	switch to := to.(type) {
+	case *Int:
+		f, ok := from.(*Int)
+
+		if !ok || f.BitWidth%8 != 0 || to.BitWidth%8 != 0 || to.BitWidth > 64 {
+			return false
+		}
+
+		if f.Signed == to.Signed {
+			return to.BitWidth >= f.BitWidth
+		}
+
+		return to.Signed && to.BitWidth > f.BitWidth
+
+	case *FloatingPoint:
+		if to.Precision != Single && to.Precision != Double {
+			return false
+		}
+
+		switch f := from.(type) {
+		case *Int:
+			return f.BitWidth%8 == 0 && f.BitWidth <= 64
+
+		case *FloatingPoint:
+			return (f.Precision == Single || f.Precision == Double) && to.Precision >= f.Precision
+		}
+	}
+
+	return false
+}
+
+// Whether the layout is a validity bitmap followed by fixed width values.
+func isPrimitiveLayout(layout *vector.TypeLayout) bool {
+	return len(layout.Vectors) == 2 && layout.Vectors[0].Type == vector.Validity && layout.Vectors[1].Type == vector.Data
+}
+
+type batchAdapter struct {
+	from *vector.RecordBatch
+	out  *vector.RecordBatch
+	size int64
+}
+
+// Convert the record batch of the From schema to the To schema.
+//
+// The batch shares the buffers of the fields that are not converted,
+// and holds a reference to the memory backing them until it is released.
+func (a *Adapter) Adapt(batch *vector.RecordBatch) (*vector.RecordBatch, error) {
+	if len(batch.Nodes) != a.nodes {
+		return nil, fmt.Errorf("batch has %d field nodes but the schema describes %d", len(batch.Nodes), a.nodes)
+	}
+
+	if len(batch.Buffers) != a.buffers {
+		return nil, fmt.Errorf("batch has %d buffers but the schema describes %d", len(batch.Buffers), a.buffers)
+	}
+
+	b := &batchAdapter{
+		from: batch,
+		out: &vector.RecordBatch{
+			Length:       batch.Length,
+			Dictionaries: batch.Dictionaries,
+		},
+	}
+
+	for _, f := range a.fields {
+		if err := b.adapt(f, batch.Length); err != nil {
+			return nil, err
+		}
+	}
+
+	batch.Retain()
+
+	b.out.Memory = batch.Memory
+
+	return b.out, nil
+}
+
+// Append a buffer to the body of the batch, at the next 8 byte boundary.
+func (b *batchAdapter) addBuffer(buf []byte) {
+	b.size = (b.size + 7) &^ 7
+
+	b.out.Buffers = append(b.out.Buffers, memory.NewBuffer(buf))
+	b.out.Layouts = append(b.out.Layouts, &vector.Buffer{Offset: b.size, Size: int64(len(buf))})
+
+	b.size += int64(len(buf))
+}
+
+func (b *batchAdapter) addNode(length, nullCount int) {
+	b.out.Nodes = append(b.out.Nodes, &vector.FieldNode{Length: length, NullCount: nullCount})
+}
+
+func (b *batchAdapter) adapt(f *fieldAdapter, length int) error {
+	switch f.kind {
+	case adaptCopy:
+		for _, node := range b.from.Nodes[f.node : f.node+f.nodes] {
+			b.addNode(node.Length, node.NullCount)
+		}
+
+		for _, buffer := range b.from.Buffers[f.buffer : f.buffer+f.buffers] {
+			b.addBuffer(buffer.Bytes())
+		}
+
+	case adaptNulls:
+		b.fillNulls(f.field, length)
+
+	case adaptStruct:
+		node := b.from.Nodes[f.node]
+
+		b.addNode(node.Length, node.NullCount)
+
+		for _, buffer := range b.from.Buffers[f.buffer : f.buffer+len(f.from.Layout.Vectors)] {
+			b.addBuffer(buffer.Bytes())
+		}
+
+		for _, child := range f.children {
+			if err := b.adapt(child, node.Length); err != nil {
+				return err
+			}
+		}
+
+	case adaptCast:
+		node := b.from.Nodes[f.node]
+		values := b.from.Buffers[f.buffer+1]
+
+		if size := (node.Length*f.from.Layout.Vectors[1].BitWidth + 7) / 8; values.Len() < size {
+			return fmt.Errorf("field %s has %d bytes of data, needs %d", f.field.Name, values.Len(), size)
+		}
+
+		b.addNode(node.Length, node.NullCount)
+		b.addBuffer(b.from.Buffers[f.buffer].Bytes())
+		b.addBuffer(castValues(f.from.Type, f.field.Type, values, node.Length))
+	}
+
+	return nil
+}
+
+// Append the nodes and buffers of the field and its children holding only nulls,
+// fields without a validity bitmap hold zeros or empty values instead.
+func (b *batchAdapter) fillNulls(field *Field, length int) {
+	var validity, variable bool
+
+	for _, layout := range field.Layout.Vectors {
+		switch layout.Type {
+		case vector.Validity:
+			validity = true
+		case vector.Offset:
+			variable = true
+		}
+	}
+
+	var nullCount int
+
+	if validity && field.Nullable {
+		nullCount = length
+	}
+
+	b.addNode(length, nullCount)
+
+	for _, layout := range field.Layout.Vectors {
+		switch layout.Type {
+		case vector.Validity:
+			if nullCount > 0 {
+				b.addBuffer(make([]byte, (length+7)/8))
+			} else {
+				b.addBuffer(nil)
+			}
+
+		case vector.Offset:
+			b.addBuffer(make([]byte, (length+1)*layout.BitWidth/8))
+
+		default:
+			if variable {
+				b.addBuffer(nil)
+			} else {
+				b.addBuffer(make([]byte, (length*layout.BitWidth+7)/8))
+			}
+		}
+	}
+
+	childLength := length
+
+	switch field.Type.Value() {
+	case flatbuf.TypeList, v5.TypeLargeList, v5.TypeMap:
+		childLength = 0
+
+	case v5.TypeFixedSizeList:
+		if list, ok := StorageType(field.Type).(*FixedSizeList); ok {
+			childLength = length * list.ListSize
+		}
+	}
+
+	for _, child := range field.Children {
+		b.fillNulls(child, childLength)
+	}
+}
+
+// Convert the fixed width values as checked by castable.
+func castValues(from, to Type, values *memory.Buffer, length int) []byte {
+	var bitWidth int
+
+	switch to := to.(type) {
+	case *Int:
+		bitWidth = to.BitWidth
+	case *FloatingPoint:
+		bitWidth = 32
+
+		if to.Precision == Double {
+			bitWidth = 64
+		}
+	}
+
+	buf := memory.NewBuffer(make([]byte, length*bitWidth/8))
+
+	for i := 0; i < length; i++ {
+		switch to.(type) {
+		case *Int:
+			putInt(buf, bitWidth, i, readInt64(values, from.(*Int), i))
+
+		case *FloatingPoint:
+			var v float64
+
+			switch from := from.(type) {
+			case *Int:
+				if from.Signed {
+					v = float64(readInt64(values, from, i))
+				} else {
+					v = float64(uint64(readInt64(values, from, i)))
+				}
+
+			case *FloatingPoint:
+				if from.Precision == Double {
+					v = values.Float8(i)
+				} else {
+					v = float64(values.Float4(i))
+				}
+			}
+
+			if bitWidth == 64 {
+				buf.PutFloat8(i, v)
+			} else {
+				buf.PutFloat4(i, float32(v))
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// Returns the integer sign or zero extended to 64 bits.
+func readInt64(buf *memory.Buffer, t *Int, i int) int64 {
+	if t.Signed {
+		switch t.BitWidth {
+		case 8:
+			return int64(buf.TinyInt(i))
+		case 16:
+			return int64(buf.SmallInt(i))
+		case 32:
+			return int64(buf.Int(i))
+		default:
+			return buf.BigInt(i)
+		}
+	}
+
+	switch t.BitWidth {
+	case 8:
+		return int64(buf.UInt1(i))
+	case 16:
+		return int64(buf.UInt2(i))
+	case 32:
+		return int64(buf.UInt4(i))
+	default:
+		return int64(buf.UInt8(i))
+	}
+}
+
+func putInt(buf *memory.Buffer, bitWidth, i int, v int64) {
+	switch bitWidth {
+	case 8:
+		buf.PutUInt1(i, uint8(v))
+	case 16:
+		buf.PutUInt2(i, uint16(v))
+	case 32:
+		buf.PutUInt4(i, uint32(v))
+	default:
+		buf.PutUInt8(i, uint64(v))
+	}
+}
@@ -0,0 +1,301 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	fb "github.com/google/flatbuffers/go"
+)
+
+type EqualOptions struct {
+	// Ignore the custom metadata of the schema and its fields, the extension types are still compared.
+	IgnoreMetadata bool
+
+	// Ignore whether the fields are nullable.
+	IgnoreNullability bool
+}
+
+// Returns whether the types are the same, including their parameters.
+//
+// Extension types are the same when their names, serialized metadata and storage types are.
+func TypeEqual(a, b Type) bool {
+	ea, aok := a.(ExtensionType)
+	eb, bok := b.(ExtensionType)
+
+	if aok || bok {
+		return aok && bok &&
+			ea.ExtensionName() == eb.ExtensionName() &&
+			ea.Serialize() == eb.Serialize() &&
+			TypeEqual(ea.StorageType(), eb.StorageType())
+	}
+
+	if e, ok := a.(interface{ Equal(Type) bool }); ok {
+		return e.Equal(b)
+	}
+
+	return a == b
+}
+
+func (t arrowType) Equal(other Type) bool {
+	o, ok := other.(arrowType)
+
+	return ok && o == t
+}
+
+func (i *Int) Equal(other Type) bool {
+	o, ok := other.(*Int)
+
+	return ok && o.BitWidth == i.BitWidth && o.Signed == i.Signed
+}
+
+func (f *FloatingPoint) Equal(other Type) bool {
+	o, ok := other.(*FloatingPoint)
+
+	return ok && o.Precision == f.Precision
+}
+
+func (d *Decimal) Equal(other Type) bool {
+	o, ok := other.(*Decimal)
+
+	return ok && o.Precision == d.Precision && o.Scale == d.Scale && o.BitWidth == d.BitWidth
+}
+
+func (d *DateType) Equal(other Type) bool {
+	o, ok := other.(*DateType)
+
+	return ok && o.Unit == d.Unit
+}
+
+func (t *TimeType) Equal(other Type) bool {
+	o, ok := other.(*TimeType)
+
+	return ok && o.Unit == t.Unit && o.BitWidth == t.BitWidth
+}
+
+func (t *Timestamp) Equal(other Type) bool {
+	o, ok := other.(*Timestamp)
+
+	return ok && o.Unit == t.Unit && o.Timezone == t.Timezone
+}
+
+func (d *Duration) Equal(other Type) bool {
+	o, ok := other.(*Duration)
+
+	return ok && o.Unit == d.Unit
+}
+
+func (i *Interval) Equal(other Type) bool {
+	o, ok := other.(*Interval)
+
+	return ok && o.Unit == i.Unit
+}
+
+func (u *Union) Equal(other Type) bool {
+	o, ok := other.(*Union)
+
+	if !ok || o.Mode != u.Mode || len(o.TypeIDs) != len(u.TypeIDs) {
+		return false
+	}
+
+	for i, id := range u.TypeIDs {
+		if o.TypeIDs[i] != id {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *FixedSizeBinary) Equal(other Type) bool {
+	o, ok := other.(*FixedSizeBinary)
+
+	return ok && o.ByteWidth == b.ByteWidth
+}
+
+func (l *FixedSizeList) Equal(other Type) bool {
+	o, ok := other.(*FixedSizeList)
+
+	return ok && o.ListSize == l.ListSize
+}
+
+func (m *Map) Equal(other Type) bool {
+	o, ok := other.(*Map)
+
+	return ok && o.KeysSorted == m.KeysSorted
+}
+
+// Returns whether the schemas have the same fields and metadata.
+func (s *Schema) Equal(other *Schema, opts EqualOptions) bool {
+	return s.Diff(other, opts) == nil
+}
+
+// Returns the first difference between the fields or metadata of the schemas, nil if they are the same.
+func (s *Schema) Diff(other *Schema, opts EqualOptions) error {
+	if len(s.Fields) != len(other.Fields) {
+		return fmt.Errorf("schema has %d fields, the other has %d", len(s.Fields), len(other.Fields))
+	}
+
+	for i, field := range s.Fields {
+		if err := field.diff(other.Fields[i], field.Name, opts); err != nil {
+			return err
+		}
+	}
+
+	if !opts.IgnoreMetadata && !metadataEqual(s.Metadata, other.Metadata) {
+		return fmt.Errorf("schema has metadata %v, the other has %v", s.Metadata, other.Metadata)
+	}
+
+	return nil
+}
+
+// Returns whether the fields are the same.
+func (f *Field) Equal(other *Field, opts EqualOptions) bool {
+	return f.Diff(other, opts) == nil
+}
+
+// Returns the first difference between the fields or their children, nil if they are the same.
+//
+// The layouts are derived from the types, and the dictionary ids are local to a file,
+// so neither of them are compared.
+func (f *Field) Diff(other *Field, opts EqualOptions) error {
+	return f.diff(other, f.Name, opts)
+}
+
+func (f *Field) diff(other *Field, path string, opts EqualOptions) error {
+	if f.Name != other.Name {
+		return fmt.Errorf("field %s is named %s in the other", path, other.Name)
+	}
+
+	if !opts.IgnoreNullability && f.Nullable != other.Nullable {
+		return fmt.Errorf("field %s is nullable %t, the other is %t", path, f.Nullable, other.Nullable)
+	}
+
+	if !TypeEqual(f.Type, other.Type) {
+		return fmt.Errorf("field %s has type %s, the other has %s", path, f.Type, other.Type)
+	}
+
+	if err := f.diffDictionary(other, path); err != nil {
+		return err
+	}
+
+	if !opts.IgnoreMetadata && !metadataEqual(f.Metadata, other.Metadata) {
+		return fmt.Errorf("field %s has metadata %v, the other has %v", path, f.Metadata, other.Metadata)
+	}
+
+	if len(f.Children) != len(other.Children) {
+		return fmt.Errorf("field %s has %d children, the other has %d", path, len(f.Children), len(other.Children))
+	}
+
+	for i, child := range f.Children {
+		if err := child.diff(other.Children[i], path+"."+child.Name, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Field) diffDictionary(other *Field, path string) error {
+	switch {
+	case f.Dictionary == nil && other.Dictionary == nil:
+		return nil
+
+	case f.Dictionary == nil:
+		return fmt.Errorf("field %s is not dictionary encoded, the other is", path)
+
+	case other.Dictionary == nil:
+		return fmt.Errorf("field %s is dictionary encoded, the other is not", path)
+	}
+
+	if index, otherIndex := f.Dictionary.Index(), other.Dictionary.Index(); !index.Equal(otherIndex) {
+		return fmt.Errorf("field %s has dictionary indices of %s, the other has %s", path, index, otherIndex)
+	}
+
+	if f.Dictionary.Ordered != other.Dictionary.Ordered {
+		return fmt.Errorf("field %s has ordered dictionary %t, the other has %t", path, f.Dictionary.Ordered, other.Dictionary.Ordered)
+	}
+
+	return nil
+}
+
+func metadataEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if w, found := b[k]; !found || w != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns whether the record batches of the other schema could be appended to a file of the schema,
+// or why they could not.
+//
+// The fields must have the same types and dictionary encodings, and must not be nullable when
+// the fields of the schema are not; their names and metadata may differ.
+func (s *Schema) IsCompatible(other *Schema) error {
+	if len(s.Fields) != len(other.Fields) {
+		return fmt.Errorf("schema has %d fields, the other has %d", len(s.Fields), len(other.Fields))
+	}
+
+	for i, field := range s.Fields {
+		if err := field.compatible(other.Fields[i], field.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Field) compatible(other *Field, path string) error {
+	if !f.Nullable && other.Nullable {
+		return fmt.Errorf("field %s is not nullable, the other is", path)
+	}
+
+	if !TypeEqual(StorageType(f.Type), StorageType(other.Type)) {
+		return fmt.Errorf("field %s has type %s, the other has %s", path, f.Type, other.Type)
+	}
+
+	if err := f.diffDictionary(other, path); err != nil {
+		return err
+	}
+
+	if f.Dictionary != nil && f.Dictionary.ID != other.Dictionary.ID {
+		return fmt.Errorf("field %s has dictionary %d, the other has %d", path, f.Dictionary.ID, other.Dictionary.ID)
+	}
+
+	if len(f.Children) != len(other.Children) {
+		return fmt.Errorf("field %s has %d children, the other has %d", path, len(f.Children), len(other.Children))
+	}
+
+	for i, child := range f.Children {
+		if err := child.compatible(other.Children[i], path+"."+child.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the hex encoded SHA-256 hash of the schema in the V5 metadata encoding,
+// which is the same for schemas of the same fields, metadata and dictionary ids.
+func (s *Schema) Fingerprint() (string, error) {
+	builder := fb.NewBuilder(0)
+
+	off, err := s.MarshalV5(builder)
+
+	if err != nil {
+		return "", fmt.Errorf("fail to marshal schema, %s", err)
+	}
+
+	builder.Finish(off)
+
+	sum := sha256.Sum256(builder.FinishedBytes())
+
+	return hex.EncodeToString(sum[:]), nil
+}
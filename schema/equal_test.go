@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *Schema)
+		opts   EqualOptions
+		diff   string
+	}{
+		{"same", func(s *Schema) {}, EqualOptions{}, ""},
+		{"name", func(s *Schema) { s.Fields[0].Name = "key" }, EqualOptions{}, "field id is named key"},
+		{"nullable", func(s *Schema) { s.Fields[0].Nullable = true }, EqualOptions{}, "field id is nullable false"},
+		{"ignore nullable", func(s *Schema) { s.Fields[0].Nullable = true }, EqualOptions{IgnoreNullability: true}, ""},
+		{"type", func(s *Schema) { s.Fields[1].Type = NewFloatingPoint(Single) }, EqualOptions{}, "field score has type"},
+		{"child", func(s *Schema) { s.Fields[2].Children[0].Type = Binary }, EqualOptions{}, "field tags.item has type"},
+		{"union", func(s *Schema) { s.Fields[3].Type = NewUnion(Sparse, []int{1, 0}) }, EqualOptions{}, "field value has type"},
+		{"metadata", func(s *Schema) { s.Fields[0].Metadata = map[string]string{"k": "v"} }, EqualOptions{}, "field id has metadata"},
+		{"ignore metadata", func(s *Schema) { s.Fields[0].Metadata = map[string]string{"k": "v"} }, EqualOptions{IgnoreMetadata: true}, ""},
+		{"schema metadata", func(s *Schema) { s.Metadata = map[string]string{"k": "v"} }, EqualOptions{}, "schema has metadata"},
+		{"ignore schema metadata", func(s *Schema) { s.Metadata = map[string]string{"k": "v"} }, EqualOptions{IgnoreMetadata: true}, ""},
+		{"fields", func(s *Schema) { s.Fields = s.Fields[1:] }, EqualOptions{}, "schema has 4 fields, the other has 3"},
+	}
+
+	for _, test := range tests {
+		other := seedSchema()
+		test.modify(other)
+
+		err := seedSchema().Diff(other, test.opts)
+
+		switch {
+		case test.diff == "" && err != nil:
+			t.Errorf("%s: unexpected difference, %s", test.name, err)
+		case test.diff != "" && (err == nil || !strings.Contains(err.Error(), test.diff)):
+			t.Errorf("%s: got difference %v, expected %s", test.name, err, test.diff)
+		}
+	}
+}
+
+func TestSchemaIsCompatible(t *testing.T) {
+	s := seedSchema()
+	other := seedSchema()
+
+	other.Fields[0].Name = "key"
+	other.Fields[1].Nullable = false
+
+	if err := s.IsCompatible(other); err != nil {
+		t.Errorf("unexpected incompatibility, %s", err)
+	}
+
+	if err := other.IsCompatible(s); err == nil || !strings.Contains(err.Error(), "field score is not nullable") {
+		t.Errorf("got incompatibility %v, expected field score is not nullable", err)
+	}
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	a, err := seedSchema().Fingerprint()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := seedSchema().Fingerprint()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != b {
+		t.Errorf("fingerprints %s and %s of the same schema differ", a, b)
+	}
+
+	other := seedSchema()
+	other.Fields[0].Metadata = map[string]string{"k": "v"}
+
+	if c, err := other.Fingerprint(); err != nil || c == a {
+		t.Errorf("fingerprint %s of a different schema, %v", c, err)
+	}
+}
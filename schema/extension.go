@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"fmt"
+	"sync"
+
+	fb "github.com/google/flatbuffers/go"
+)
+
+// The keys of the field custom metadata recording its extension type.
+const (
+	ExtensionNameKey     = "ARROW:extension:name"
+	ExtensionMetadataKey = "ARROW:extension:metadata"
+)
+
+// ExtensionType is a user defined type stored as one of the builtin types.
+//
+// The name and serialized metadata of the extension type are recorded in the custom metadata
+// of the field, readers without the extension type registered see the storage type instead.
+type ExtensionType interface {
+	Type
+
+	// The unique name of the extension type.
+	ExtensionName() string
+
+	// The builtin type holding the values.
+	StorageType() Type
+
+	// Returns the parameters of the extension type, recorded in the field metadata.
+	Serialize() string
+
+	// Creates an extension type from its storage type and serialized parameters.
+	Deserialize(storage Type, metadata string) (ExtensionType, error)
+}
+
+// ExtensionBase implements the Type methods of an extension type with those of its storage type.
+type ExtensionBase struct {
+	Storage Type
+}
+
+func (b *ExtensionBase) Value() int { return b.Storage.Value() }
+
+func (b *ExtensionBase) StorageType() Type { return b.Storage }
+
+func (b *ExtensionBase) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	return b.Storage.Marshal(builder)
+}
+
+var (
+	extensionTypesLock sync.RWMutex
+	extensionTypes     = make(map[string]ExtensionType)
+)
+
+// Register the extension type, so it will be produced when reading fields of its name.
+func RegisterExtensionType(t ExtensionType) error {
+	extensionTypesLock.Lock()
+	defer extensionTypesLock.Unlock()
+
+	name := t.ExtensionName()
+
+	if _, found := extensionTypes[name]; found {
+		return fmt.Errorf("extension type %s is already registered", name)
+	}
+
+	extensionTypes[name] = t
+
+	return nil
+}
+
+// Unregister the extension type of the name.
+func UnregisterExtensionType(name string) error {
+	extensionTypesLock.Lock()
+	defer extensionTypesLock.Unlock()
+
+	if _, found := extensionTypes[name]; !found {
+		return fmt.Errorf("extension type %s is not registered", name)
+	}
+
+	delete(extensionTypes, name)
+
+	return nil
+}
+
+// Returns the registered extension type of the name, nil if not found.
+func GetExtensionType(name string) ExtensionType {
+	extensionTypesLock.RLock()
+	defer extensionTypesLock.RUnlock()
+
+	return extensionTypes[name]
+}
+
+// Returns the storage type of an extension type, or the type itself.
+func StorageType(t Type) Type {
+	if ext, ok := t.(ExtensionType); ok {
+		return ext.StorageType()
+	}
+
+	return t
+}
+
+// Returns the custom metadata of the field, including the name and metadata of its extension type.
+func (f *Field) CustomMetadata() map[string]string {
+	ext, ok := f.Type.(ExtensionType)
+
+	if !ok {
+		return f.Metadata
+	}
+
+	metadata := make(map[string]string, len(f.Metadata)+2)
+
+	for k, v := range f.Metadata {
+		metadata[k] = v
+	}
+
+	metadata[ExtensionNameKey] = ext.ExtensionName()
+	metadata[ExtensionMetadataKey] = ext.Serialize()
+
+	return metadata
+}
+
+// Returns the registered extension type named in the custom metadata with the storage type,
+// and the metadata without the extension keys.
+//
+// The storage type and the metadata are returned unchanged if the extension type is not registered.
+func UnmarshalExtensionType(storage Type, metadata map[string]string) (Type, map[string]string, error) {
+	name, found := metadata[ExtensionNameKey]
+
+	if !found {
+		return storage, metadata, nil
+	}
+
+	ext := GetExtensionType(name)
+
+	if ext == nil {
+		return storage, metadata, nil
+	}
+
+	t, err := ext.Deserialize(storage, metadata[ExtensionMetadataKey])
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to deserialize extension type %s, %s", name, err)
+	}
+
+	rest := make(map[string]string, len(metadata))
+
+	for k, v := range metadata {
+		if k != ExtensionNameKey && k != ExtensionMetadataKey {
+			rest[k] = v
+		}
+	}
+
+	if len(rest) == 0 {
+		rest = nil
+	}
+
+	return t, rest, nil
+}
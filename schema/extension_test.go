@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	fb "github.com/google/flatbuffers/go"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+)
+
+type jsonExtension struct {
+	ExtensionBase
+}
+
+func (t *jsonExtension) String() string { return "extension<json>" }
+
+func (t *jsonExtension) ExtensionName() string { return "arrow.json" }
+
+func (t *jsonExtension) Serialize() string { return "" }
+
+func (t *jsonExtension) Deserialize(storage Type, metadata string) (ExtensionType, error) {
+	return &jsonExtension{ExtensionBase{storage}}, nil
+}
+
+func unmarshalFieldVersion(t *testing.T, f *Field, version MetadataVersion) *Field {
+	if version == V1 {
+		field, err := UnmarshalField(flatbuf.GetRootAsField(marshal(t, f), 0))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return field
+	}
+
+	builder := fb.NewBuilder(0)
+
+	off, err := f.MarshalV5(builder)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder.Finish(off)
+
+	u := &unmarshaler{version: version}
+
+	field, err := u.unmarshalFieldV5(v5.GetRootAsField(builder.FinishedBytes(), 0), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return field
+}
+
+func TestExtensionType(t *testing.T) {
+	ext := &jsonExtension{ExtensionBase{Utf8}}
+
+	if err := RegisterExtensionType(ext); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterExtensionType(ext); err == nil {
+		t.Errorf("registered extension type %s twice", ext.ExtensionName())
+	}
+
+	f, err := NewField("doc", true, ext)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Metadata = map[string]string{"origin": "test"}
+
+	for _, version := range []MetadataVersion{V1, V5} {
+		if field := unmarshalFieldVersion(t, f, version); !reflect.DeepEqual(field, f) {
+			t.Errorf("%s field mismatch, got %+v, expected %+v", version, field, f)
+		}
+	}
+
+	if err := UnregisterExtensionType(ext.ExtensionName()); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := map[string]string{"origin": "test", ExtensionNameKey: "arrow.json", ExtensionMetadataKey: ""}
+
+	for _, version := range []MetadataVersion{V1, V5} {
+		field := unmarshalFieldVersion(t, f, version)
+
+		if field.Type != Utf8 {
+			t.Errorf("%s field has type %s, expected the storage type", version, field.Type)
+		}
+
+		if !reflect.DeepEqual(field.Metadata, metadata) {
+			t.Errorf("%s field has metadata %v, expected %v", version, field.Metadata, metadata)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"sort"
 	"unsafe"
 
 	fb "github.com/google/flatbuffers/go"
@@ -16,15 +17,75 @@ type Field struct {
 	Type     Type
 	Children []*Field
 	Layout   *vector.TypeLayout
+
+	// The dictionary encoding of the field, nil if it is not encoded.
+	Dictionary *DictionaryEncoding
+
+	// The custom metadata of the field, without the keys of its extension type.
+	Metadata map[string]string
+}
+
+// DictionaryEncoding describes the values of a field encoded as indices into a dictionary.
+type DictionaryEncoding struct {
+	// The id of the dictionary batch holding the values.
+	ID int64
+
+	// The type of the indices, a signed 32 bit integer if nil.
+	IndexType *Int
+
+	// Whether the order of the dictionary values is meaningful.
+	Ordered bool
+}
+
+// Returns the type of the indices.
+func (d *DictionaryEncoding) Index() *Int {
+	if d.IndexType != nil {
+		return d.IndexType
+	}
+
+	return NewInt(32, true)
+}
+
+const (
+	// limits of the field tree, guarding against malformed or cyclic metadata
+	maxFieldDepth = 64
+	maxFields     = 1 << 20
+)
+
+type unmarshaler struct {
+	version MetadataVersion
+	fields  int
+}
+
+func UnmarshalField(field *flatbuf.Field) (f *Field, err error) {
+	defer recoverMalformed(&err)
+
+	u := &unmarshaler{}
+
+	return u.unmarshalField(field, 0)
 }
 
-func UnmarshalField(field *flatbuf.Field) (*Field, error) {
+func (u *unmarshaler) unmarshalField(field *flatbuf.Field, depth int) (*Field, error) {
+	if depth > maxFieldDepth {
+		return nil, fmt.Errorf("fields nested deeper than %d", maxFieldDepth)
+	}
+
+	if u.fields++; u.fields > maxFields {
+		return nil, fmt.Errorf("more than %d fields", maxFields)
+	}
+
 	tp, err := getTypeForField(field)
 
 	if err != nil {
 		return nil, err
 	}
 
+	tp, metadata, err := UnmarshalExtensionType(tp, unmarshalMetadata(field))
+
+	if err != nil {
+		return nil, err
+	}
+
 	var layouts []*vector.VectorLayout
 	var layout flatbuf.VectorLayout
 
@@ -45,7 +106,7 @@ func UnmarshalField(field *flatbuf.Field) (*Field, error) {
 
 	for i := 0; i < field.ChildrenLength(); i++ {
 		if field.Children(&child, i) {
-			f, err := UnmarshalField(&child)
+			f, err := u.unmarshalField(&child, depth+1)
 
 			if err != nil {
 				return nil, err
@@ -55,15 +116,47 @@ func UnmarshalField(field *flatbuf.Field) (*Field, error) {
 		}
 	}
 
+	var dictionary *DictionaryEncoding
+
+	if id := field.Dictionary(); id != 0 {
+		dictionary = &DictionaryEncoding{ID: id}
+	}
+
 	return &Field{
-		Name:     string(field.Name()),
-		Nullable: field.Nullable() != 0,
-		Type:     tp,
-		Children: children,
-		Layout:   &vector.TypeLayout{layouts},
+		Name:       string(field.Name()),
+		Nullable:   field.Nullable() != 0,
+		Type:       tp,
+		Children:   children,
+		Layout:     &vector.TypeLayout{Vectors: layouts},
+		Dictionary: dictionary,
+		Metadata:   metadata,
 	}, nil
 }
 
+// The custom metadata of a field or a schema.
+type customMetadata interface {
+	CustomMetadataLength() int
+	CustomMetadata(obj *flatbuf.KeyValue, j int) bool
+}
+
+func unmarshalMetadata(table customMetadata) map[string]string {
+	if table.CustomMetadataLength() == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]string)
+
+	var kv flatbuf.KeyValue
+
+	for i := 0; i < table.CustomMetadataLength(); i++ {
+		if table.CustomMetadata(&kv, i) {
+			metadata[string(kv.Key())] = string(kv.ValueBytes())
+		}
+	}
+
+	return metadata
+}
+
 func getTypeForField(field *flatbuf.Field) (Type, error) {
 	switch field.TypeType() {
 	case flatbuf.TypeNull:
@@ -152,7 +245,9 @@ func (f *Field) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 		nameOffset = builder.CreateString(f.Name)
 	}
 
-	typeOffset, err := f.Type.Marshal(builder)
+	tp := StorageType(f.Type)
+
+	typeOffset, err := tp.Marshal(builder)
 
 	if err != nil {
 		return 0, fmt.Errorf("fail to marshal type, %s", err)
@@ -170,6 +265,18 @@ func (f *Field) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 		return 0, fmt.Errorf("fail to marshal layout, %s", err)
 	}
 
+	if f.Dictionary != nil && f.Dictionary.ID == 0 {
+		return 0, fmt.Errorf("dictionary id 0 of field %s is reserved in V1 metadata", f.Name)
+	}
+
+	metadata := f.CustomMetadata()
+
+	var metadataOffset fb.UOffsetT
+
+	if len(metadata) > 0 {
+		metadataOffset = marshalMetadata(builder, metadata)
+	}
+
 	flatbuf.FieldStart(builder)
 
 	if len(f.Name) > 0 {
@@ -183,14 +290,58 @@ func (f *Field) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	}
 
 	flatbuf.FieldAddNullable(builder, nullable)
-	flatbuf.FieldAddTypeType(builder, byte(f.Type.Value()))
+	flatbuf.FieldAddTypeType(builder, byte(tp.Value()))
 	flatbuf.FieldAddType(builder, typeOffset)
+
+	if f.Dictionary != nil {
+		flatbuf.FieldAddDictionary(builder, f.Dictionary.ID)
+	}
+
 	flatbuf.FieldAddChildren(builder, childrenOffset)
 	flatbuf.FieldAddLayout(builder, layoutOffset)
 
+	if len(metadata) > 0 {
+		flatbuf.FieldAddCustomMetadata(builder, metadataOffset)
+	}
+
 	return flatbuf.FieldEnd(builder), nil
 }
 
+// Returns the keys of the custom metadata in order, so the encoding is deterministic.
+func metadataKeys(metadata map[string]string) []string {
+	keys := make([]string, 0, len(metadata))
+
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func marshalMetadata(builder *fb.Builder, metadata map[string]string) fb.UOffsetT {
+	var offsets []fb.UOffsetT
+
+	for _, k := range metadataKeys(metadata) {
+		keyOffset := builder.CreateString(k)
+		valueOffset := builder.CreateByteVector([]byte(metadata[k]))
+
+		flatbuf.KeyValueStart(builder)
+		flatbuf.KeyValueAddKey(builder, keyOffset)
+		flatbuf.KeyValueAddValue(builder, valueOffset)
+		offsets = append(offsets, flatbuf.KeyValueEnd(builder))
+	}
+
+	flatbuf.FieldStartCustomMetadataVector(builder, len(offsets))
+
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+
+	return builder.EndVector(len(offsets))
+}
+
 func (f *Field) marshalChildren(builder *fb.Builder) (fb.UOffsetT, error) {
 	var childOffsets []fb.UOffsetT
 
@@ -206,8 +357,8 @@ func (f *Field) marshalChildren(builder *fb.Builder) (fb.UOffsetT, error) {
 
 	flatbuf.FieldStartChildrenVector(builder, len(childOffsets))
 
-	for _, off := range childOffsets {
-		builder.PrependUOffsetT(off)
+	for i := len(childOffsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(childOffsets[i])
 	}
 
 	return builder.EndVector(len(childOffsets)), nil
@@ -216,6 +367,10 @@ func (f *Field) marshalChildren(builder *fb.Builder) (fb.UOffsetT, error) {
 func (f *Field) marshalLayout(builder *fb.Builder) (fb.UOffsetT, error) {
 	var bufferOffsets []fb.UOffsetT
 
+	if f.Layout == nil {
+		return 0, fmt.Errorf("missing layout of field %s", f.Name)
+	}
+
 	for _, layout := range f.Layout.Vectors {
 		off, err := layout.Marshal(builder)
 
@@ -228,8 +383,8 @@ func (f *Field) marshalLayout(builder *fb.Builder) (fb.UOffsetT, error) {
 
 	flatbuf.FieldStartLayoutVector(builder, len(bufferOffsets))
 
-	for _, off := range bufferOffsets {
-		builder.PrependUOffsetT(off)
+	for i := len(bufferOffsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(bufferOffsets[i])
 	}
 
 	return builder.EndVector(len(bufferOffsets)), nil
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"testing"
+
+	fb "github.com/google/flatbuffers/go"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/schema/vector"
+)
+
+func newLayout(vectors ...*vector.VectorLayout) *vector.TypeLayout {
+	return &vector.TypeLayout{Vectors: vectors}
+}
+
+func seedSchema() *Schema {
+	return &Schema{Fields: []*Field{
+		{Name: "id", Type: NewInt(64, true), Layout: newLayout(vector.ValidityVector, vector.Value64Vector)},
+		{Name: "score", Nullable: true, Type: NewFloatingPoint(Double), Layout: newLayout(vector.ValidityVector, vector.Value64Vector)},
+		{Name: "tags", Nullable: true, Type: List, Layout: newLayout(vector.ValidityVector, vector.OffsetVector), Children: []*Field{
+			{Name: "item", Nullable: true, Type: Utf8, Layout: newLayout(vector.ValidityVector, vector.OffsetVector, vector.ByteVector)},
+		}},
+		{Name: "value", Nullable: true, Type: NewUnion(Sparse, []int{0, 1}), Layout: newLayout(vector.ValidityVector, vector.TypeVector), Children: []*Field{
+			{Name: "i", Nullable: true, Type: NewInt(32, true), Layout: newLayout(vector.ValidityVector, vector.Value32Vector)},
+			{Name: "ts", Nullable: true, Type: NewTimeStamp(Millisecond), Layout: newLayout(vector.ValidityVector, vector.Value64Vector)},
+		}},
+	}}
+}
+
+// The seed schema with the types and dictionary encoding added by V4 and V5 metadata.
+func seedSchemaV5() *Schema {
+	s := seedSchema()
+
+	s.Fields = append(s.Fields,
+		&Field{Name: "name", Nullable: true, Type: LargeUtf8, Dictionary: &DictionaryEncoding{ID: 1, IndexType: NewInt(16, false)}},
+		&Field{Name: "elapsed", Type: NewDuration(Microsecond)},
+		&Field{Name: "point", Type: NewFixedSizeList(2), Children: []*Field{{Name: "item", Type: NewFloatingPoint(Single)}}},
+		&Field{Name: "attrs", Nullable: true, Type: NewMap(true), Children: []*Field{
+			{Name: "entries", Type: Struct, Children: []*Field{
+				{Name: "key", Type: Utf8},
+				{Name: "value", Nullable: true, Type: NewFixedSizeBinary(4)},
+			}},
+		}},
+	)
+
+	return s
+}
+
+func marshal(tb testing.TB, obj Marshaler) []byte {
+	builder := fb.NewBuilder(0)
+
+	off, err := obj.Marshal(builder)
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	builder.Finish(off)
+
+	return builder.FinishedBytes()
+}
+
+func FuzzUnmarshalSchema(f *testing.F) {
+	f.Add(marshal(f, seedSchema()))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 4 {
+			return // GetRootAsSchema needs the root offset
+		}
+
+		UnmarshalSchema(flatbuf.GetRootAsSchema(data, 0))
+	})
+}
+
+func FuzzUnmarshalSchemaV5(f *testing.F) {
+	builder := fb.NewBuilder(0)
+	off, err := seedSchemaV5().MarshalV5(builder)
+
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	builder.Finish(off)
+
+	if _, err := UnmarshalSchemaV5(v5.GetRootAsSchema(builder.FinishedBytes(), 0), V5); err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(builder.FinishedBytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 4 {
+			return // GetRootAsSchema needs the root offset
+		}
+
+		for _, version := range []MetadataVersion{V4, V5} {
+			UnmarshalSchemaV5(v5.GetRootAsSchema(data, 0), version)
+		}
+	})
+}
+
+func FuzzUnmarshalField(f *testing.F) {
+	for _, field := range seedSchema().Fields {
+		f.Add(marshal(f, field))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 4 {
+			return // GetRootAsField needs the root offset
+		}
+
+		UnmarshalField(flatbuf.GetRootAsField(data, 0))
+	})
+}
@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Returns the layout of the buffers of a type, not including its children.
+func NewTypeLayout(t Type) (*vector.TypeLayout, error) {
+	var vectors []*vector.VectorLayout
+
+	t = StorageType(t)
+
+	switch t.Value() {
+	case flatbuf.TypeNull:
+
+	case flatbuf.TypeInt:
+		i, ok := t.(*Int)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		data, err := vector.DataVector(i.BitWidth)
+
+		if err != nil {
+			return nil, err
+		}
+
+		vectors = []*vector.VectorLayout{vector.ValidityVector, data}
+
+	case flatbuf.TypeFloatingPoint:
+		f, ok := t.(*FloatingPoint)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		var data *vector.VectorLayout
+
+		switch f.Precision {
+		case Half:
+			data = vector.Value16Vector
+		case Single:
+			data = vector.Value32Vector
+		case Double:
+			data = vector.Value64Vector
+		default:
+			return nil, fmt.Errorf("unsupported precision, %s", f.Precision)
+		}
+
+		vectors = []*vector.VectorLayout{vector.ValidityVector, data}
+
+	case flatbuf.TypeDecimal:
+		d, ok := t.(*Decimal)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		data, err := vector.DataVector(d.BitWidth)
+
+		if err != nil {
+			return nil, err
+		}
+
+		vectors = []*vector.VectorLayout{vector.ValidityVector, data}
+
+	case flatbuf.TypeDate:
+		d, ok := t.(*DateType)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		switch d.Unit {
+		case DateDay:
+			vectors = []*vector.VectorLayout{vector.ValidityVector, vector.Value32Vector}
+		case DateMillisecond:
+			vectors = []*vector.VectorLayout{vector.ValidityVector, vector.Value64Vector}
+		default:
+			return nil, fmt.Errorf("unsupported date unit, %s", d.Unit)
+		}
+
+	case flatbuf.TypeTime:
+		tm, ok := t.(*TimeType)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		if tm.BitWidth != 32 && tm.BitWidth != 64 {
+			return nil, fmt.Errorf("unsupported time of %d bits", tm.BitWidth)
+		}
+
+		data, err := vector.DataVector(tm.BitWidth)
+
+		if err != nil {
+			return nil, err
+		}
+
+		vectors = []*vector.VectorLayout{vector.ValidityVector, data}
+
+	case flatbuf.TypeTimestamp, v5.TypeDuration:
+		vectors = []*vector.VectorLayout{vector.ValidityVector, vector.Value64Vector}
+
+	case flatbuf.TypeInterval:
+		i, ok := t.(*Interval)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		switch i.Unit {
+		case DayTime:
+			vectors = []*vector.VectorLayout{vector.ValidityVector, vector.Value64Vector}
+		case YearMonth:
+			vectors = []*vector.VectorLayout{vector.ValidityVector, vector.Value32Vector}
+		default:
+			return nil, fmt.Errorf("unsupported interval unit, %s", i.Unit)
+		}
+
+	case flatbuf.TypeBinary, flatbuf.TypeUtf8:
+		vectors = []*vector.VectorLayout{vector.ValidityVector, vector.OffsetVector, vector.ByteVector}
+
+	case flatbuf.TypeBool:
+		vectors = []*vector.VectorLayout{vector.ValidityVector, vector.BooleanVector}
+
+	case v5.TypeLargeBinary, v5.TypeLargeUtf8:
+		vectors = []*vector.VectorLayout{vector.ValidityVector, vector.LargeOffsetVector, vector.ByteVector}
+
+	case v5.TypeFixedSizeBinary:
+		b, ok := t.(*FixedSizeBinary)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		if b.ByteWidth <= 0 {
+			return nil, fmt.Errorf("invalid byte width %d", b.ByteWidth)
+		}
+
+		vectors = []*vector.VectorLayout{vector.ValidityVector, {Type: vector.Data, BitWidth: b.ByteWidth * 8}}
+
+	case flatbuf.TypeList, v5.TypeMap:
+		vectors = []*vector.VectorLayout{vector.ValidityVector, vector.OffsetVector}
+
+	case v5.TypeLargeList:
+		vectors = []*vector.VectorLayout{vector.ValidityVector, vector.LargeOffsetVector}
+
+	case flatbuf.TypeStruct_, v5.TypeFixedSizeList:
+		vectors = []*vector.VectorLayout{vector.ValidityVector}
+
+	case flatbuf.TypeUnion:
+		u, ok := t.(*Union)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T", t)
+		}
+
+		// unions have no validity bitmap since V5, their nulls are the nulls of the children
+		switch u.Mode {
+		case Sparse:
+			vectors = []*vector.VectorLayout{vector.TypeIDVector}
+		case Dense:
+			vectors = []*vector.VectorLayout{vector.TypeIDVector, vector.OffsetVector}
+		default:
+			return nil, fmt.Errorf("unsupported union mode, %s", u.Mode)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported type, %s", t)
+	}
+
+	return &vector.TypeLayout{Vectors: vectors}, nil
+}
+
+// Returns the layout of the buffers of the field in the metadata version.
+//
+// Since V2 the layouts are not part of the metadata, they are derived from the types,
+// or from the index type of dictionary encoded fields.
+func fieldLayout(f *Field, version MetadataVersion) (*vector.TypeLayout, error) {
+	if f.Dictionary != nil {
+		return NewTypeLayout(f.Dictionary.Index())
+	}
+
+	layout, err := NewTypeLayout(f.Type)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Type.Value() == flatbuf.TypeUnion && version < V5 {
+		layout.Vectors = append([]*vector.VectorLayout{vector.ValidityVector}, layout.Vectors...)
+	}
+
+	return layout, nil
+}
+
+// Create a field with the layout derived from its type.
+func NewField(name string, nullable bool, t Type, children ...*Field) (*Field, error) {
+	layout, err := NewTypeLayout(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Field{
+		Name:     name,
+		Nullable: nullable,
+		Type:     t,
+		Children: children,
+		Layout:   layout,
+	}, nil
+}
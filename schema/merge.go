@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/flier/arrow/flatbuf"
+)
+
+// Merge the schemas of a dataset evolved over time into a schema that holds both.
+//
+// The fields are matched by name, those of a keep their order and the fields only in b
+// are appended, they must be nullable since the batches of the other schema have no values for them.
+// The types of the matched fields must be the same, or one of them is widened:
+//
+//   - integers of the same signedness to the wider one
+//   - an unsigned integer to a wider signed one
+//   - integers to floating points, in double precision for integers of more than 16 bits
+//   - single precision floating points to double precision
+//
+// The children of Struct fields are merged the same way, the other nested fields must be the same.
+// The merged fields are nullable if either of them is, and hold the custom metadata of both,
+// those of a win for the same key, as the merged schema does.
+func Merge(a, b *Schema) (*Schema, error) {
+	fields, err := mergeFields(a.Fields, b.Fields, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{Fields: fields, Metadata: mergeMetadata(a.Metadata, b.Metadata)}, nil
+}
+
+func mergeFields(a, b []*Field, prefix string) ([]*Field, error) {
+	others := make(map[string]*Field, len(b))
+
+	for _, field := range b {
+		if _, found := others[field.Name]; found {
+			return nil, fmt.Errorf("duplicate field %s%s", prefix, field.Name)
+		}
+
+		others[field.Name] = field
+	}
+
+	var merged []*Field
+
+	found := make(map[string]bool, len(a))
+
+	for _, field := range a {
+		if found[field.Name] {
+			return nil, fmt.Errorf("duplicate field %s%s", prefix, field.Name)
+		}
+
+		found[field.Name] = true
+
+		other, exists := others[field.Name]
+
+		if !exists {
+			if !field.Nullable {
+				return nil, fmt.Errorf("field %s%s is not nullable and missing from the other schema", prefix, field.Name)
+			}
+
+			merged = append(merged, field)
+
+			continue
+		}
+
+		f, err := mergeField(field, other, prefix+field.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		merged = append(merged, f)
+	}
+
+	for _, field := range b {
+		if found[field.Name] {
+			continue
+		}
+
+		if !field.Nullable {
+			return nil, fmt.Errorf("field %s%s is not nullable and missing from the schema", prefix, field.Name)
+		}
+
+		merged = append(merged, field)
+	}
+
+	return merged, nil
+}
+
+func mergeField(a, b *Field, path string) (*Field, error) {
+	f := *a
+	f.Nullable = a.Nullable || b.Nullable
+	f.Metadata = mergeMetadata(a.Metadata, b.Metadata)
+
+	if a.Dictionary != nil || b.Dictionary != nil {
+		if err := a.diffDictionary(b, path); err != nil {
+			return nil, err
+		}
+
+		if !TypeEqual(a.Type, b.Type) {
+			return nil, fmt.Errorf("field %s has dictionary of %s, the other has %s", path, a.Type, b.Type)
+		}
+	} else {
+		t, err := mergeType(a.Type, b.Type)
+
+		if err != nil {
+			return nil, fmt.Errorf("field %s can't be merged, %s", path, err)
+		}
+
+		if !TypeEqual(t, a.Type) {
+			f.Type = t
+
+			if f.Layout, err = NewTypeLayout(t); err != nil {
+				return nil, fmt.Errorf("field %s has no layout, %s", path, err)
+			}
+		}
+	}
+
+	if a.Type.Value() == flatbuf.TypeStruct_ {
+		children, err := mergeFields(a.Children, b.Children, path+".")
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Children = children
+	} else if len(a.Children) > 0 || len(b.Children) > 0 {
+		for i := 0; i < len(a.Children) && i < len(b.Children); i++ {
+			if err := a.Children[i].diff(b.Children[i], path+"."+a.Children[i].Name, EqualOptions{IgnoreMetadata: true}); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(a.Children) != len(b.Children) {
+			return nil, fmt.Errorf("field %s has %d children, the other has %d", path, len(a.Children), len(b.Children))
+		}
+	}
+
+	return &f, nil
+}
+
+// Returns the type holding the values of both types.
+func mergeType(a, b Type) (Type, error) {
+	if TypeEqual(a, b) {
+		return a, nil
+	}
+
+	switch a := a.(type) {
+	case *Int:
+		switch b := b.(type) {
+		case *Int:
+			return mergeInt(a, b)
+
+		case *FloatingPoint:
+			return mergeFloatingPoint(b, intPrecision(a))
+		}
+
+	case *FloatingPoint:
+		switch b := b.(type) {
+		case *Int:
+			return mergeFloatingPoint(a, intPrecision(b))
+
+		case *FloatingPoint:
+			return mergeFloatingPoint(a, b.Precision)
+		}
+	}
+
+	return nil, fmt.Errorf("incompatible types %s and %s", a, b)
+}
+
+func mergeInt(a, b *Int) (Type, error) {
+	if a.Signed == b.Signed {
+		if a.BitWidth >= b.BitWidth {
+			return a, nil
+		}
+
+		return b, nil
+	}
+
+	signed, unsigned := a, b
+
+	if b.Signed {
+		signed, unsigned = b, a
+	}
+
+	if signed.BitWidth > unsigned.BitWidth {
+		return signed, nil
+	}
+
+	if unsigned.BitWidth >= 64 {
+		return nil, fmt.Errorf("no signed integer holds uint%d", unsigned.BitWidth)
+	}
+
+	return NewInt(unsigned.BitWidth*2, true), nil
+}
+
+// Returns the precision of the floating points holding the integers,
+// double precision holds only the integers of 53 bits exactly.
+func intPrecision(i *Int) Precision {
+	if i.BitWidth <= 16 {
+		return Single
+	}
+
+	return Double
+}
+
+func mergeFloatingPoint(f *FloatingPoint, precision Precision) (Type, error) {
+	if precision < Single {
+		precision = Single
+	}
+
+	if f.Precision >= precision {
+		return f, nil
+	}
+
+	return NewFloatingPoint(precision), nil
+}
+
+func mergeMetadata(a, b map[string]string) map[string]string {
+	if len(b) == 0 {
+		return a
+	}
+
+	if len(a) == 0 {
+		return b
+	}
+
+	metadata := make(map[string]string, len(a)+len(b))
+
+	for k, v := range b {
+		metadata[k] = v
+	}
+
+	for k, v := range a {
+		metadata[k] = v
+	}
+
+	return metadata
+}
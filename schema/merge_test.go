@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema/vector"
+)
+
+func mustField(t *testing.T, name string, nullable bool, tp Type, children ...*Field) *Field {
+	f, err := NewField(name, nullable, tp, children...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func TestMerge(t *testing.T) {
+	a := &Schema{Fields: []*Field{
+		mustField(t, "id", false, NewInt(32, true)),
+		mustField(t, "score", true, NewInt(16, false)),
+		mustField(t, "point", true, Struct, mustField(t, "x", false, NewFloatingPoint(Single))),
+	}, Metadata: map[string]string{"origin": "a"}}
+
+	b := &Schema{Fields: []*Field{
+		mustField(t, "id", false, NewInt(64, true)),
+		mustField(t, "name", true, Utf8),
+		mustField(t, "score", false, NewFloatingPoint(Single)),
+		mustField(t, "point", true, Struct,
+			mustField(t, "x", false, NewFloatingPoint(Double)),
+			mustField(t, "y", true, NewFloatingPoint(Double))),
+	}, Metadata: map[string]string{"origin": "b", "version": "2"}}
+
+	merged, err := Merge(a, b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Schema{Fields: []*Field{
+		mustField(t, "id", false, NewInt(64, true)),
+		mustField(t, "score", true, NewFloatingPoint(Single)),
+		mustField(t, "point", true, Struct,
+			mustField(t, "x", false, NewFloatingPoint(Double)),
+			mustField(t, "y", true, NewFloatingPoint(Double))),
+		mustField(t, "name", true, Utf8),
+	}, Metadata: map[string]string{"origin": "a", "version": "2"}}
+
+	if err := merged.Diff(expected, EqualOptions{}); err != nil {
+		t.Errorf("merged schema mismatch, %s", err)
+	}
+
+	b.Fields[1].Nullable = false
+
+	if _, err := Merge(a, b); err == nil || !strings.Contains(err.Error(), "field name is not nullable") {
+		t.Errorf("got error %v, expected field name is not nullable", err)
+	}
+
+	b.Fields[1] = mustField(t, "id", true, Utf8)
+
+	if _, err := Merge(a, &Schema{Fields: b.Fields[1:2]}); err == nil || !strings.Contains(err.Error(), "incompatible types") {
+		t.Errorf("got error %v, expected incompatible types", err)
+	}
+}
+
+func TestAdapter(t *testing.T) {
+	from := &Schema{Fields: []*Field{
+		mustField(t, "id", false, NewInt(32, true)),
+		mustField(t, "score", true, NewFloatingPoint(Single)),
+	}}
+
+	to := &Schema{Fields: []*Field{
+		mustField(t, "id", false, NewInt(64, true)),
+		mustField(t, "score", true, NewFloatingPoint(Single)),
+		mustField(t, "name", true, Utf8),
+	}}
+
+	ids := memory.NewBuffer(make([]byte, 12))
+	scores := memory.NewBuffer(make([]byte, 12))
+
+	for i := 0; i < 3; i++ {
+		ids.PutInt(i, int32(i-1))
+		scores.PutFloat4(i, float32(i)/2)
+	}
+
+	batch := &vector.RecordBatch{
+		Length:  3,
+		Nodes:   []*vector.FieldNode{{Length: 3}, {Length: 3, NullCount: 1}},
+		Buffers: []*memory.Buffer{memory.NewBuffer(nil), ids, memory.NewBuffer([]byte{0x05}), scores},
+	}
+
+	a, err := NewAdapter(from, to)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adapted, err := a.Adapt(batch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := to.Validate(adapted); err != nil {
+		t.Fatalf("invalid adapted batch, %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if id := adapted.Buffers[1].BigInt(i); id != int64(i-1) {
+			t.Errorf("id %d at %d, expected %d", id, i, i-1)
+		}
+
+		if score := adapted.Buffers[3].Float4(i); score != float32(i)/2 {
+			t.Errorf("score %f at %d, expected %f", score, i, float32(i)/2)
+		}
+	}
+
+	if name := adapted.Nodes[2]; name.Length != 3 || name.NullCount != 3 {
+		t.Errorf("name has %d nulls of %d, expected all nulls", name.NullCount, name.Length)
+	}
+
+	if _, err := NewAdapter(to, from); err == nil || !strings.Contains(err.Error(), "field id of type int64 can't be adapted") {
+		t.Errorf("got error %v, expected field id can't be adapted", err)
+	}
+}
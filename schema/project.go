@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flier/arrow/flatbuf"
+)
+
+// Projection is a subset of the fields of a schema.
+//
+// Nodes and Buffers are the indices of the field nodes and buffers of a record batch
+// of the full schema that make up a record batch of the projected schema.
+type Projection struct {
+	Schema  *Schema
+	Nodes   []int
+	Buffers []int
+}
+
+// The fields selected by a set of paths, a nil selection keeps the whole subtree.
+type selection map[string]selection
+
+// Project the schema onto the fields of the given paths.
+//
+// A path is a field name, or the names of nested Struct fields separated by dots,
+// the ancestors of a nested field are kept with only the selected children.
+// The projected fields keep the order of the schema.
+func (s *Schema) Project(paths ...string) (*Projection, error) {
+	sel := make(selection)
+
+	for _, path := range paths {
+		if len(path) == 0 {
+			return nil, fmt.Errorf("empty field path")
+		}
+
+		cur := sel
+		names := strings.Split(path, ".")
+
+		for i, name := range names {
+			child, exists := cur[name]
+
+			if i == len(names)-1 {
+				cur[name] = nil
+				break
+			}
+
+			if exists && child == nil {
+				break
+			}
+
+			if !exists {
+				child = make(selection)
+				cur[name] = child
+			}
+
+			cur = child
+		}
+	}
+
+	p := &Projection{Schema: &Schema{}}
+
+	var nodes, buffers int
+
+	fields, err := p.project(s.Fields, sel, &nodes, &buffers, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.Schema.Fields = fields
+
+	return p, nil
+}
+
+func (p *Projection) project(fields []*Field, sel selection, nodes, buffers *int, prefix string) ([]*Field, error) {
+	var projected []*Field
+
+	found := make(map[string]bool)
+
+	for _, field := range fields {
+		child, selected := sel[field.Name]
+
+		if !selected {
+			if err := skip(field, nodes, buffers); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		found[field.Name] = true
+
+		if child == nil {
+			if err := p.keep(field, nodes, buffers); err != nil {
+				return nil, err
+			}
+
+			projected = append(projected, field)
+
+			continue
+		}
+
+		if field.Type.Value() != flatbuf.TypeStruct_ {
+			return nil, fmt.Errorf("field %s%s of type %s has no children to project", prefix, field.Name, field.Type)
+		}
+
+		if field.Layout == nil {
+			return nil, fmt.Errorf("missing layout of field %s%s", prefix, field.Name)
+		}
+
+		p.Nodes = append(p.Nodes, *nodes)
+		*nodes++
+
+		for range field.Layout.Vectors {
+			p.Buffers = append(p.Buffers, *buffers)
+			*buffers++
+		}
+
+		children, err := p.project(field.Children, child, nodes, buffers, prefix+field.Name+".")
+
+		if err != nil {
+			return nil, err
+		}
+
+		f := *field
+		f.Children = children
+
+		projected = append(projected, &f)
+	}
+
+	for name := range sel {
+		if !found[name] {
+			return nil, fmt.Errorf("field %s%s not found", prefix, name)
+		}
+	}
+
+	return projected, nil
+}
+
+// Keep the nodes and buffers of the field and its children.
+func (p *Projection) keep(field *Field, nodes, buffers *int) error {
+	if field.Layout == nil {
+		return fmt.Errorf("missing layout of field %s", field.Name)
+	}
+
+	p.Nodes = append(p.Nodes, *nodes)
+	*nodes++
+
+	for range field.Layout.Vectors {
+		p.Buffers = append(p.Buffers, *buffers)
+		*buffers++
+	}
+
+	for _, child := range field.Children {
+		if err := p.keep(child, nodes, buffers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Skip the nodes and buffers of the field and its children.
+func skip(field *Field, nodes, buffers *int) error {
+	if field.Layout == nil {
+		return fmt.Errorf("missing layout of field %s", field.Name)
+	}
+
+	*nodes++
+	*buffers += len(field.Layout.Vectors)
+
+	for _, child := range field.Children {
+		if err := skip(child, nodes, buffers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
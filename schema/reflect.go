@@ -0,0 +1,339 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// The options of a field in the arrow struct tag.
+type tagOptions struct {
+	nullable bool
+	unit     *TimeUnit
+	timezone string
+	dict     bool
+	index    *Int // the index type of a dictionary encoded field, nil for the default
+}
+
+type reflector struct {
+	visiting map[reflect.Type]bool
+	nextID   int64
+	index    StructIndex
+}
+
+// StructIndex is the index sequence of the Go struct field of each struct child created by FromStruct,
+// as used by reflect.Value.FieldByIndex.
+type StructIndex map[*Field][]int
+
+// Create the schema of the exported fields of a Go struct type, or a pointer to it.
+//
+// The Go types are mapped to
+//
+//	bool                          Bool
+//	int8, int16, int32, int64     Int, signed of the same width, int as 64 bits
+//	uint8, uint16, uint32, uint64 Int, unsigned of the same width, uint as 64 bits
+//	float32, float64              FloatingPoint in single or double precision
+//	string, []byte, [N]byte       Utf8, Binary, FixedSizeBinary of N bytes
+//	time.Time, time.Duration      Timestamp and Duration in nanoseconds
+//	[]T, [N]T                     List and FixedSizeList of N items
+//	map[K]V                       Map of the keys and values
+//	struct                        Struct, the fields of embedded structs are promoted
+//
+// Pointers are nullable, the other fields are not. The arrow struct tag controls the fields:
+//
+//	Name string `arrow:"name,nullable,unit=us,tz=UTC,dict=int16"`
+//
+// The name replaces the Go name, and a name of "-" skips the field. The options are
+//
+//	nullable  the field is nullable without being a pointer
+//	unit      the time unit of Timestamp and Duration fields, one of s, ms, us and ns
+//	tz        the time zone of Timestamp fields
+//	dict      the field is dictionary encoded, with indices of the type, int32 if omitted
+//
+// The options of list and map fields apply to their items and values.
+// Dictionary ids are numbered from 1 in the order of the fields.
+func FromStruct(t reflect.Type) (*Schema, error) {
+	s, _, err := FromStructIndex(t)
+
+	return s, err
+}
+
+// Create the schema of a Go struct type as FromStruct, and the Go struct field of each field.
+func FromStructIndex(t reflect.Type) (*Schema, StructIndex, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	r := &reflector{visiting: make(map[reflect.Type]bool), index: make(StructIndex)}
+
+	fields, err := r.structFields(t, "", nil)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Schema{Fields: fields}, r.index, nil
+}
+
+// Returns the fields of the struct, the index of the embedding struct field precedes their own.
+func (r *reflector) structFields(t reflect.Type, prefix string, embedding []int) ([]*Field, error) {
+	if r.visiting[t] {
+		return nil, fmt.Errorf("recursive type %s", t)
+	}
+
+	r.visiting[t] = true
+
+	defer delete(r.visiting, t)
+
+	var fields []*Field
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("arrow")
+
+		if tag == "-" {
+			continue
+		}
+
+		if sf.Anonymous && !tagged {
+			embedded := sf.Type
+
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct && embedded != timeType {
+				promoted, err := r.structFields(embedded, prefix, append(embedding[:len(embedding):len(embedding)], i))
+
+				if err != nil {
+					return nil, err
+				}
+
+				fields = append(fields, promoted...)
+
+				continue
+			}
+		}
+
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, opts, err := parseTag(tag)
+
+		if err != nil {
+			return nil, fmt.Errorf("field %s%s has invalid tag, %s", prefix, sf.Name, err)
+		}
+
+		if len(name) == 0 {
+			name = sf.Name
+		}
+
+		field, err := r.field(name, sf.Type, opts, prefix+name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.index[field] = append(embedding[:len(embedding):len(embedding)], i)
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func parseTag(tag string) (string, *tagOptions, error) {
+	parts := strings.Split(tag, ",")
+	opts := &tagOptions{}
+
+	for _, part := range parts[1:] {
+		key, value := part, ""
+
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			key, value = part[:i], part[i+1:]
+		}
+
+		switch key {
+		case "nullable":
+			opts.nullable = true
+
+		case "unit":
+			unit, found := timeUnits[value]
+
+			if !found {
+				return "", nil, fmt.Errorf("unknown time unit %s", value)
+			}
+
+			opts.unit = &unit
+
+		case "tz":
+			opts.timezone = value
+
+		case "dict":
+			opts.dict = true
+
+			if len(value) > 0 {
+				newType, found := simpleTypes[value]
+
+				if !found {
+					return "", nil, fmt.Errorf("unknown index type %s", value)
+				}
+
+				index, ok := newType().(*Int)
+
+				if !ok {
+					return "", nil, fmt.Errorf("index type %s is not an integer", value)
+				}
+
+				opts.index = index
+			}
+
+		default:
+			return "", nil, fmt.Errorf("unknown option %s", key)
+		}
+	}
+
+	return parts[0], opts, nil
+}
+
+// Create the field of the Go type, its path is only used by errors.
+func (r *reflector) field(name string, t reflect.Type, opts *tagOptions, path string) (*Field, error) {
+	f := &Field{Name: name, Nullable: opts.nullable}
+
+	if t.Kind() == reflect.Ptr {
+		f.Nullable = true
+		t = t.Elem()
+	}
+
+	// the options except nullable apply to the items of lists and the values of maps
+	items := *opts
+	items.nullable = false
+
+	switch {
+	case t == timeType:
+		ts := NewTimeStamp(Nanosecond)
+
+		if opts.unit != nil {
+			ts.Unit = *opts.unit
+		}
+
+		ts.Timezone = opts.timezone
+		f.Type = ts
+
+	case t == durationType:
+		d := NewDuration(Nanosecond)
+
+		if opts.unit != nil {
+			d.Unit = *opts.unit
+		}
+
+		f.Type = d
+
+	case t.Kind() == reflect.Bool:
+		f.Type = Bool
+
+	case t.Kind() == reflect.Int8, t.Kind() == reflect.Int16, t.Kind() == reflect.Int32, t.Kind() == reflect.Int64, t.Kind() == reflect.Int:
+		f.Type = NewInt(t.Bits(), true)
+
+	case t.Kind() == reflect.Uint8, t.Kind() == reflect.Uint16, t.Kind() == reflect.Uint32, t.Kind() == reflect.Uint64, t.Kind() == reflect.Uint:
+		f.Type = NewInt(t.Bits(), false)
+
+	case t.Kind() == reflect.Float32:
+		f.Type = NewFloatingPoint(Single)
+
+	case t.Kind() == reflect.Float64:
+		f.Type = NewFloatingPoint(Double)
+
+	case t.Kind() == reflect.String:
+		f.Type = Utf8
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		f.Type = Binary
+
+	case t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8:
+		f.Type = NewFixedSizeBinary(t.Len())
+
+	case t.Kind() == reflect.Slice, t.Kind() == reflect.Array:
+		item, err := r.field("item", t.Elem(), &items, path+".item")
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Type = List
+
+		if t.Kind() == reflect.Array {
+			f.Type = NewFixedSizeList(t.Len())
+		}
+
+		f.Children = []*Field{item}
+
+	case t.Kind() == reflect.Map:
+		key, err := r.field("key", t.Key(), &tagOptions{}, path+".key")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Nullable {
+			return nil, fmt.Errorf("field %s has nullable keys", path)
+		}
+
+		value, err := r.field("value", t.Elem(), &items, path+".value")
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := NewField("entries", false, Struct, key, value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Type = NewMap(false)
+		f.Children = []*Field{entries}
+
+	case t.Kind() == reflect.Struct:
+		if opts.dict {
+			return nil, fmt.Errorf("field %s of struct can't be dictionary encoded", path)
+		}
+
+		children, err := r.structFields(t, path+".", nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Type = Struct
+		f.Children = children
+
+	default:
+		return nil, fmt.Errorf("field %s has unsupported type %s", path, t)
+	}
+
+	if opts.dict && len(f.Children) == 0 {
+		r.nextID++
+
+		f.Dictionary = &DictionaryEncoding{ID: r.nextID, IndexType: opts.index}
+	}
+
+	var err error
+
+	if f.Layout, err = fieldLayout(f, V5); err != nil {
+		return nil, fmt.Errorf("field %s has no layout, %s", path, err)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type reflectBase struct {
+	ID      int64
+	Created time.Time `arrow:"created_at,unit=us,tz=UTC"`
+}
+
+type reflectPoint struct {
+	X, Y float32
+}
+
+type reflectRecord struct {
+	reflectBase
+
+	Name     string   `arrow:"name,dict"`
+	Nickname *string  `arrow:"nickname"`
+	Tags     []string `arrow:"tags,nullable,dict=int16"`
+	Score    float64
+	Count    uint16
+	Flag     bool `arrow:"flag,nullable"`
+	Payload  []byte
+	Digest   [16]byte
+	Timeout  time.Duration `arrow:"timeout,unit=ms"`
+	Path     []reflectPoint
+	Corners  [4]*reflectPoint
+	Labels   map[string]*int32
+	Internal string `arrow:"-"`
+	hidden   int
+}
+
+func TestFromStruct(t *testing.T) {
+	s, err := FromStruct(reflect.TypeOf(&reflectRecord{}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "struct<ID: int64 not null, created_at: timestamp[us, tz=UTC] not null, " +
+		"name: dictionary[1]<utf8> not null, nickname: utf8, tags: list<dictionary[2, int16]<utf8> not null>, " +
+		"Score: float64 not null, Count: uint16 not null, flag: bool, Payload: binary not null, " +
+		"Digest: fixed_size_binary[16] not null, timeout: duration[ms] not null, " +
+		"Path: list<struct<X: float32 not null, Y: float32 not null> not null> not null, " +
+		"Corners: fixed_size_list[4]<struct<X: float32 not null, Y: float32 not null>> not null, " +
+		"Labels: map<key: utf8 not null, value: int32> not null>"
+
+	if s.String() != expected {
+		t.Errorf("got schema %s, expected %s", s, expected)
+	}
+
+	parsed, err := Parse(expected)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Diff(parsed, EqualOptions{}); err != nil {
+		t.Errorf("schema differs from the parsed one, %s", err)
+	}
+
+	for _, field := range s.Fields {
+		if field.Layout == nil {
+			t.Errorf("field %s has no layout", field.Name)
+		}
+	}
+}
+
+type reflectNode struct {
+	Children []*reflectNode
+}
+
+func TestFromStructError(t *testing.T) {
+	tests := []struct {
+		v   interface{}
+		err string
+	}{
+		{0, "int is not a struct"},
+		{reflectNode{}, "recursive type schema.reflectNode"},
+		{struct{ C chan int }{}, "field C has unsupported type chan int"},
+		{struct {
+			T time.Time `arrow:"t,unit=h"`
+		}{}, "field T has invalid tag, unknown time unit h"},
+		{struct {
+			P reflectPoint `arrow:"p,dict"`
+		}{}, "field p of struct can't be dictionary encoded"},
+		{struct{ M map[*string]int }{}, "field M has nullable keys"},
+	}
+
+	for _, test := range tests {
+		if _, err := FromStruct(reflect.TypeOf(test.v)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%T got error %v, expected %s", test.v, err, test.err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package schema
 
 import (
+	"fmt"
+	"runtime"
+
 	fb "github.com/google/flatbuffers/go"
 
 	"github.com/flier/arrow/flatbuf"
@@ -8,15 +11,22 @@ import (
 
 type Schema struct {
 	Fields []*Field
+
+	// The custom metadata of the schema, as those of its fields.
+	Metadata map[string]string
 }
 
-func UnmarshalSchema(schema *flatbuf.Schema) (*Schema, error) {
+func UnmarshalSchema(schema *flatbuf.Schema) (s *Schema, err error) {
+	defer recoverMalformed(&err)
+
 	var fields []*Field
 	var field flatbuf.Field
 
+	u := &unmarshaler{}
+
 	for i := 0; i < schema.FieldsLength(); i++ {
 		if schema.Fields(&field, i) {
-			f, err := UnmarshalField(&field)
+			f, err := u.unmarshalField(&field, 0)
 
 			if err != nil {
 				return nil, err
@@ -26,7 +36,7 @@ func UnmarshalSchema(schema *flatbuf.Schema) (*Schema, error) {
 		}
 	}
 
-	return &Schema{fields}, nil
+	return &Schema{Fields: fields, Metadata: unmarshalMetadata(schema)}, nil
 }
 
 func (s *Schema) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
@@ -44,13 +54,35 @@ func (s *Schema) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 
 	flatbuf.SchemaStartFieldsVector(builder, len(offsets))
 
-	for _, off := range offsets {
-		builder.PrependUOffsetT(off)
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
 	}
 
 	fieldsOffset := builder.EndVector(len(offsets))
 
+	var metadataOffset fb.UOffsetT
+
+	if len(s.Metadata) > 0 {
+		metadataOffset = marshalMetadata(builder, s.Metadata)
+	}
+
 	flatbuf.SchemaStart(builder)
 	flatbuf.SchemaAddFields(builder, fieldsOffset)
+
+	if len(s.Metadata) > 0 {
+		flatbuf.SchemaAddCustomMetadata(builder, metadataOffset)
+	}
+
 	return flatbuf.SchemaEnd(builder), nil
 }
+
+// Turn the runtime panic raised by accessing a malformed flatbuffer into an error.
+func recoverMalformed(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(runtime.Error); ok {
+			*err = fmt.Errorf("malformed schema, %s", e)
+		} else {
+			panic(r)
+		}
+	}
+}
@@ -0,0 +1,911 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+)
+
+// The text of a schema is the struct of its fields, for example
+//
+//	struct<id: int64 not null, tags: list<utf8>, ts: timestamp[us, tz=UTC]>
+//
+// A field is its name and type, followed by "not null" if it is not nullable.
+// The parameters of a type are written in brackets and its children in angle brackets:
+//
+//	null, bool, binary, utf8, large_binary, large_utf8
+//	int8, int16, int32, int64, uint8, uint16, uint32, uint64, float16, float32, float64
+//	decimal128[precision, scale], decimal256[precision, scale]
+//	date32, date64, time32[s|ms], time64[us|ns], timestamp[unit] or timestamp[unit, tz=zone]
+//	duration[unit], interval[year_month|day_time]
+//	fixed_size_binary[width]
+//	list<child>, large_list<child>, fixed_size_list[size]<child>
+//	map<key field, value field> or map[sorted]<key field, value field>
+//	struct<fields>, sparse_union<fields>, dense_union[type ids]<fields>
+//	dictionary[id, index type, ordered]<value type>, the index type and ordered being optional
+//	extension["name", "metadata"]<storage type>
+//
+// The child of a list is a nullable field named item unless written as a field.
+// Names which are not identifiers are quoted as Go strings. The custom metadata of the fields
+// other than their extension types is not written.
+
+// Returns the text of the schema.
+func (s *Schema) String() string {
+	var b strings.Builder
+
+	b.WriteString("struct<")
+	writeFields(&b, s.Fields)
+	b.WriteString(">")
+
+	return b.String()
+}
+
+// Returns the text of the field, its name followed by its type.
+func (f *Field) String() string {
+	var b strings.Builder
+
+	writeField(&b, f)
+
+	return b.String()
+}
+
+func writeFields(b *strings.Builder, fields []*Field) {
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		writeField(b, field)
+	}
+}
+
+func writeField(b *strings.Builder, f *Field) {
+	b.WriteString(quoteName(f.Name))
+	b.WriteString(": ")
+	writeFieldType(b, f)
+
+	if !f.Nullable {
+		b.WriteString(" not null")
+	}
+}
+
+// Write the child of a list, omitting the default name.
+func writeChild(b *strings.Builder, f *Field) {
+	if f.Name != "item" {
+		writeField(b, f)
+
+		return
+	}
+
+	writeFieldType(b, f)
+
+	if !f.Nullable {
+		b.WriteString(" not null")
+	}
+}
+
+func writeFieldType(b *strings.Builder, f *Field) {
+	if d := f.Dictionary; d != nil {
+		fmt.Fprintf(b, "dictionary[%d", d.ID)
+
+		if d.IndexType != nil {
+			fmt.Fprintf(b, ", %s", d.IndexType)
+		}
+
+		if d.Ordered {
+			b.WriteString(", ordered")
+		}
+
+		b.WriteString("]<")
+	}
+
+	if ext, ok := f.Type.(ExtensionType); ok {
+		fmt.Fprintf(b, "extension[%s, %s]<", strconv.Quote(ext.ExtensionName()), strconv.Quote(ext.Serialize()))
+		writeType(b, ext.StorageType(), f.Children)
+		b.WriteString(">")
+	} else if name, found := f.Metadata[ExtensionNameKey]; found {
+		// the extension type is not registered
+		fmt.Fprintf(b, "extension[%s, %s]<", strconv.Quote(name), strconv.Quote(f.Metadata[ExtensionMetadataKey]))
+		writeType(b, f.Type, f.Children)
+		b.WriteString(">")
+	} else {
+		writeType(b, f.Type, f.Children)
+	}
+
+	if f.Dictionary != nil {
+		b.WriteString(">")
+	}
+}
+
+func writeType(b *strings.Builder, t Type, children []*Field) {
+	b.WriteString(t.String())
+
+	switch t.Value() {
+	case flatbuf.TypeList, v5.TypeLargeList, v5.TypeFixedSizeList:
+		if len(children) == 1 {
+			b.WriteString("<")
+			writeChild(b, children[0])
+			b.WriteString(">")
+
+			return
+		}
+
+	case v5.TypeMap:
+		if len(children) == 1 {
+			b.WriteString("<")
+			writeFields(b, children[0].Children)
+			b.WriteString(">")
+
+			return
+		}
+
+	case flatbuf.TypeStruct_, flatbuf.TypeUnion:
+		b.WriteString("<")
+		writeFields(b, children)
+		b.WriteString(">")
+
+		return
+	}
+
+	if len(children) > 0 {
+		b.WriteString("<")
+		writeFields(b, children)
+		b.WriteString(">")
+	}
+}
+
+func isIdent(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+
+	return len(s) > 0
+}
+
+// Returns the name as is if it is an identifier, quoted otherwise.
+func quoteName(name string) string {
+	if isIdent(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+var (
+	simpleTypes = map[string]func() Type{
+		"null":         func() Type { return Null },
+		"bool":         func() Type { return Bool },
+		"binary":       func() Type { return Binary },
+		"utf8":         func() Type { return Utf8 },
+		"large_binary": func() Type { return LargeBinary },
+		"large_utf8":   func() Type { return LargeUtf8 },
+		"int8":         func() Type { return NewInt(8, true) },
+		"int16":        func() Type { return NewInt(16, true) },
+		"int32":        func() Type { return NewInt(32, true) },
+		"int64":        func() Type { return NewInt(64, true) },
+		"uint8":        func() Type { return NewInt(8, false) },
+		"uint16":       func() Type { return NewInt(16, false) },
+		"uint32":       func() Type { return NewInt(32, false) },
+		"uint64":       func() Type { return NewInt(64, false) },
+		"float16":      func() Type { return NewFloatingPoint(Half) },
+		"float32":      func() Type { return NewFloatingPoint(Single) },
+		"float64":      func() Type { return NewFloatingPoint(Double) },
+		"date32":       func() Type { return NewDate(DateDay) },
+		"date64":       func() Type { return NewDate(DateMillisecond) },
+	}
+
+	timeUnits = map[string]TimeUnit{
+		"s":  Second,
+		"ms": Millisecond,
+		"us": Microsecond,
+		"ns": Nanosecond,
+	}
+
+	intervalUnits = map[string]IntervalUnit{
+		"year_month": YearMonth,
+		"day_time":   DayTime,
+	}
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of text"
+	}
+
+	return strconv.Quote(t.text)
+}
+
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			start := i
+
+			for i < len(text) && (text[i] == '_' || 'a' <= text[i] && text[i] <= 'z' || 'A' <= text[i] && text[i] <= 'Z' || '0' <= text[i] && text[i] <= '9') {
+				i++
+			}
+
+			tokens = append(tokens, token{tokenIdent, text[start:i], start})
+
+		case '0' <= c && c <= '9':
+			start := i
+
+			for i < len(text) && '0' <= text[i] && text[i] <= '9' {
+				i++
+			}
+
+			tokens = append(tokens, token{tokenNumber, text[start:i], start})
+
+		case c == '"':
+			quoted, err := strconv.QuotedPrefix(text[i:])
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d, %s", i, err)
+			}
+
+			s, err := strconv.Unquote(quoted)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d, %s", i, err)
+			}
+
+			tokens = append(tokens, token{tokenString, s, i})
+
+			i += len(quoted)
+
+		case strings.IndexByte("<>[],:=", c) >= 0:
+			tokens = append(tokens, token{tokenPunct, text[i : i+1], i})
+
+			i++
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(text)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// Parse the text of a schema as written by Schema.String.
+//
+// The layouts of the fields are derived from their types, and the extension types are
+// produced if they are registered, or recorded in the custom metadata of the fields otherwise.
+func Parse(text string) (*Schema, error) {
+	tokens, err := tokenize(text)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	if err := p.expect("struct"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("<"); err != nil {
+		return nil, err
+	}
+
+	fields, err := p.parseFields(">")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at %d, expected end of text", tok, tok.pos)
+	}
+
+	return &Schema{Fields: fields}, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// Consume the identifier or punctuation if it is the next token.
+func (p *parser) accept(text string) bool {
+	if tok := p.peek(); (tok.kind == tokenIdent || tok.kind == tokenPunct) && tok.text == text {
+		p.pos++
+
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+
+		return fmt.Errorf("unexpected %s at %d, expected %q", tok, tok.pos, text)
+	}
+
+	return nil
+}
+
+func (p *parser) ident() (string, int, error) {
+	tok := p.next()
+
+	if tok.kind != tokenIdent {
+		return "", tok.pos, fmt.Errorf("unexpected %s at %d, expected a name", tok, tok.pos)
+	}
+
+	return tok.text, tok.pos, nil
+}
+
+func (p *parser) name() (string, error) {
+	tok := p.next()
+
+	if tok.kind != tokenIdent && tok.kind != tokenString {
+		return "", fmt.Errorf("unexpected %s at %d, expected a field name", tok, tok.pos)
+	}
+
+	return tok.text, nil
+}
+
+func (p *parser) str() (string, error) {
+	tok := p.next()
+
+	if tok.kind != tokenString {
+		return "", fmt.Errorf("unexpected %s at %d, expected a string", tok, tok.pos)
+	}
+
+	return tok.text, nil
+}
+
+func (p *parser) number() (int, error) {
+	tok := p.next()
+
+	if tok.kind != tokenNumber {
+		return 0, fmt.Errorf("unexpected %s at %d, expected a number", tok, tok.pos)
+	}
+
+	n, err := strconv.Atoi(tok.text)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %s at %d, %s", tok.text, tok.pos, err)
+	}
+
+	return n, nil
+}
+
+// Parse the comma separated fields up to the closing token.
+func (p *parser) parseFields(close string) ([]*Field, error) {
+	var fields []*Field
+
+	if p.accept(close) {
+		return fields, nil
+	}
+
+	for {
+		name, err := p.name()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		field, err := p.parseField(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect(close); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// Parse the child of a list, a field or a type of the default name.
+func (p *parser) parseChild() (*Field, error) {
+	if tok := p.peek(); tok.kind == tokenIdent || tok.kind == tokenString {
+		if next := p.tokens[p.pos+1]; next.kind == tokenPunct && next.text == ":" {
+			p.pos += 2
+
+			return p.parseField(tok.text)
+		}
+	}
+
+	return p.parseField("item")
+}
+
+// Parse the type of the field and whether it is nullable.
+func (p *parser) parseField(name string) (*Field, error) {
+	f, err := p.parseType(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("not") {
+		if err := p.expect("null"); err != nil {
+			return nil, err
+		}
+
+		f.Nullable = false
+	}
+
+	if f.Layout, err = fieldLayout(f, V5); err != nil {
+		return nil, fmt.Errorf("field %s has no layout, %s", name, err)
+	}
+
+	return f, nil
+}
+
+func (p *parser) parseType(name string) (*Field, error) {
+	keyword, pos, err := p.ident()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.depth++; p.depth > maxFieldDepth {
+		return nil, fmt.Errorf("types nested deeper than %d at %d", maxFieldDepth, pos)
+	}
+
+	defer func() { p.depth-- }()
+
+	f := &Field{Name: name, Nullable: true}
+
+	if newType, found := simpleTypes[keyword]; found {
+		f.Type = newType()
+
+		return f, nil
+	}
+
+	switch keyword {
+	case "decimal128", "decimal256":
+		var precision, scale int
+
+		if err := p.params(&precision, &scale); err != nil {
+			return nil, err
+		}
+
+		bitWidth, _ := strconv.Atoi(keyword[len("decimal"):])
+
+		f.Type = &Decimal{flatbuf.TypeDecimal, Precision(precision), scale, bitWidth}
+
+	case "time32", "time64":
+		unit, err := p.timeUnit()
+
+		if err != nil {
+			return nil, err
+		}
+
+		tm := NewTime(unit)
+
+		if keyword != fmt.Sprintf("time%d", tm.BitWidth) {
+			return nil, fmt.Errorf("%s at %d can't hold %s", keyword, pos, unit)
+		}
+
+		f.Type = tm
+
+	case "timestamp":
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+
+		unit, err := p.unit()
+
+		if err != nil {
+			return nil, err
+		}
+
+		ts := NewTimeStamp(unit)
+
+		if p.accept(",") {
+			if err := p.expect("tz"); err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+
+			if ts.Timezone, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+
+		f.Type = ts
+
+	case "duration":
+		unit, err := p.timeUnit()
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Type = NewDuration(unit)
+
+	case "interval":
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+
+		name, pos, err := p.ident()
+
+		if err != nil {
+			return nil, err
+		}
+
+		unit, found := intervalUnits[name]
+
+		if !found {
+			return nil, fmt.Errorf("unknown interval unit %s at %d", name, pos)
+		}
+
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+
+		f.Type = NewInterval(unit)
+
+	case "fixed_size_binary":
+		var width int
+
+		if err := p.params(&width); err != nil {
+			return nil, err
+		}
+
+		f.Type = NewFixedSizeBinary(width)
+
+	case "list", "large_list", "fixed_size_list":
+		f.Type = List
+
+		if keyword == "large_list" {
+			f.Type = LargeList
+		} else if keyword == "fixed_size_list" {
+			var size int
+
+			if err := p.params(&size); err != nil {
+				return nil, err
+			}
+
+			f.Type = NewFixedSizeList(size)
+		}
+
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+
+		child, err := p.parseChild()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+
+		f.Children = []*Field{child}
+
+	case "map":
+		var sorted bool
+
+		if p.accept("[") {
+			if err := p.expect("sorted"); err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			sorted = true
+		}
+
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+
+		fields, err := p.parseFields(">")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("map at %d has %d fields, expected the keys and values", pos, len(fields))
+		}
+
+		entries, err := NewField("entries", false, Struct, fields...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.Type = NewMap(sorted)
+		f.Children = []*Field{entries}
+
+	case "struct":
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+
+		if f.Children, err = p.parseFields(">"); err != nil {
+			return nil, err
+		}
+
+		f.Type = Struct
+
+	case "sparse_union", "dense_union":
+		var typeIDs []int
+
+		if p.accept("[") {
+			for {
+				id, err := p.number()
+
+				if err != nil {
+					return nil, err
+				}
+
+				typeIDs = append(typeIDs, id)
+
+				if !p.accept(",") {
+					break
+				}
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+
+		if f.Children, err = p.parseFields(">"); err != nil {
+			return nil, err
+		}
+
+		mode := Sparse
+
+		if keyword == "dense_union" {
+			mode = Dense
+		}
+
+		f.Type = NewUnion(mode, typeIDs)
+
+	case "dictionary":
+		return p.parseDictionary(name)
+
+	case "extension":
+		return p.parseExtension(name)
+
+	default:
+		return nil, fmt.Errorf("unknown type %s at %d", keyword, pos)
+	}
+
+	return f, nil
+}
+
+func (p *parser) parseDictionary(name string) (*Field, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+
+	id, err := p.number()
+
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary := &DictionaryEncoding{ID: int64(id)}
+
+	for p.accept(",") {
+		if p.accept("ordered") {
+			dictionary.Ordered = true
+
+			continue
+		}
+
+		tok := p.peek()
+
+		index, err := p.parseType("")
+
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := index.Type.(*Int)
+
+		if !ok {
+			return nil, fmt.Errorf("index type %s at %d is not an integer", index.Type, tok.pos)
+		}
+
+		dictionary.IndexType = i
+	}
+
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("<"); err != nil {
+		return nil, err
+	}
+
+	f, err := p.parseType(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Dictionary != nil {
+		return nil, fmt.Errorf("field %s has nested dictionaries", name)
+	}
+
+	if err := p.expect(">"); err != nil {
+		return nil, err
+	}
+
+	f.Dictionary = dictionary
+
+	return f, nil
+}
+
+func (p *parser) parseExtension(name string) (*Field, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+
+	extName, err := p.str()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+
+	extMetadata, err := p.str()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("<"); err != nil {
+		return nil, err
+	}
+
+	f, err := p.parseType(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(">"); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{ExtensionNameKey: extName, ExtensionMetadataKey: extMetadata}
+
+	if f.Type, f.Metadata, err = UnmarshalExtensionType(f.Type, metadata); err != nil {
+		return nil, fmt.Errorf("field %s has invalid extension type, %s", name, err)
+	}
+
+	return f, nil
+}
+
+// Parse the bracketed numbers.
+func (p *parser) params(values ...*int) error {
+	if err := p.expect("["); err != nil {
+		return err
+	}
+
+	for i, v := range values {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+
+		n, err := p.number()
+
+		if err != nil {
+			return err
+		}
+
+		*v = n
+	}
+
+	return p.expect("]")
+}
+
+// Parse the bracketed time unit.
+func (p *parser) timeUnit() (TimeUnit, error) {
+	if err := p.expect("["); err != nil {
+		return 0, err
+	}
+
+	unit, err := p.unit()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return unit, p.expect("]")
+}
+
+func (p *parser) unit() (TimeUnit, error) {
+	name, pos, err := p.ident()
+
+	if err != nil {
+		return 0, err
+	}
+
+	unit, found := timeUnits[name]
+
+	if !found {
+		return 0, fmt.Errorf("unknown time unit %s at %d", name, pos)
+	}
+
+	return unit, nil
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+var textSchemas = []string{
+	"struct<>",
+	"struct<id: int64 not null, tags: list<utf8>, ts: timestamp[us, tz=UTC]>",
+	`struct<"first name": utf8, price: decimal128[10, 2], born: date32, at: time64[ns], ts: timestamp[ms, tz="+07:00"]>`,
+	"struct<a: int8, b: uint16, c: float16, d: float32, e: float64, f: bool, g: null, h: binary, i: large_binary, j: large_utf8>",
+	"struct<wait: duration[s], span: interval[day_time], digest: fixed_size_binary[32], t: time32[ms], d: date64>",
+	"struct<points: fixed_size_list[3]<float64 not null>, rows: large_list<row: struct<x: int32, y: int32> not null>>",
+	"struct<attrs: map[sorted]<key: utf8 not null, value: list<int32>> not null, counts: map<key: int32 not null, value: int64>>",
+	"struct<value: sparse_union<i: int32, s: utf8>, other: dense_union[5, 7]<f: float32, b: bool>>",
+	"struct<name: dictionary[1]<utf8>, code: dictionary[2, int16, ordered]<large_utf8> not null>",
+	`struct<doc: extension["arrow.json", ""]<utf8>, id: extension["arrow.uuid", "v4"]<fixed_size_binary[16]> not null>`,
+}
+
+func TestParse(t *testing.T) {
+	for _, text := range textSchemas {
+		s, err := Parse(text)
+
+		if err != nil {
+			t.Errorf("fail to parse %s, %s", text, err)
+			continue
+		}
+
+		if s.String() != text {
+			t.Errorf("schema %s printed as %s", text, s)
+		}
+
+		for _, field := range s.Fields {
+			if _, err := NewTypeLayout(field.Type); err != nil {
+				t.Errorf("field %s has no layout, %s", field.Name, err)
+			}
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"", `unexpected end of text at 0, expected "struct"`},
+		{"struct<id int64>", `unexpected "int64" at 10, expected ":"`},
+		{"struct<id: int128>", "unknown type int128 at 11"},
+		{"struct<t: time32[us]>", "time32 at 10 can't hold MICROSECOND"},
+		{"struct<m: map<key: utf8>>", "map at 10 has 1 fields"},
+		{"struct<d: dictionary[1, utf8]<utf8>>", "index type utf8 at 24 is not an integer"},
+		{"struct<id: int64> x", `unexpected "x" at 18, expected end of text`},
+		{"struct<id: int64 not>", `unexpected ">" at 20, expected "null"`},
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(test.text); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("parse %s got error %v, expected %s", test.text, err, test.err)
+		}
+	}
+}
+
+func TestParseExtension(t *testing.T) {
+	ext := &jsonExtension{ExtensionBase{Utf8}}
+
+	if err := RegisterExtensionType(ext); err != nil {
+		t.Fatal(err)
+	}
+
+	defer UnregisterExtensionType(ext.ExtensionName())
+
+	text := `struct<doc: extension["arrow.json", ""]<utf8>>`
+
+	s, err := Parse(text)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.Fields[0].Type.(*jsonExtension); !ok || s.Fields[0].Metadata != nil {
+		t.Errorf("field doc has type %T and metadata %v, expected the extension type", s.Fields[0].Type, s.Fields[0].Metadata)
+	}
+
+	if s.String() != text {
+		t.Errorf("schema %s printed as %s", text, s)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, text := range textSchemas {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		s, err := Parse(text)
+
+		if err != nil {
+			return
+		}
+
+		again, err := Parse(s.String())
+
+		if err != nil {
+			t.Fatalf("fail to parse %s printed from %s, %s", s, text, err)
+		}
+
+		if again.String() != s.String() {
+			t.Fatalf("schema %s printed as %s", s, again)
+		}
+	})
+}
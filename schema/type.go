@@ -3,10 +3,12 @@ package schema
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	fb "github.com/google/flatbuffers/go"
 
 	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
 )
 
 type Marshaler interface {
@@ -26,21 +28,45 @@ var (
 	Binary = arrowType(flatbuf.TypeBinary)
 	Utf8   = arrowType(flatbuf.TypeUtf8)
 	Bool   = arrowType(flatbuf.TypeBool)
-	Date   = arrowType(flatbuf.TypeDate)
-	Time   = arrowType(flatbuf.TypeTime)
 	List   = arrowType(flatbuf.TypeList)
 	Struct = arrowType(flatbuf.TypeStruct_)
+
+	// The variable width types with 64 bit offsets, only V4 and later metadata support them.
+	LargeBinary = arrowType(v5.TypeLargeBinary)
+	LargeUtf8   = arrowType(v5.TypeLargeUtf8)
+	LargeList   = arrowType(v5.TypeLargeList)
+
+	// The date and time of day in milliseconds, the only units of V1 metadata.
+	Date = NewDate(DateMillisecond)
+	Time = NewTime(Millisecond)
 )
 
 type arrowType int
 
 func (t arrowType) Value() int { return int(t) }
 
+// The names of the types without parameters, as written by Schema.String.
+var typeNames = map[int]string{
+	flatbuf.TypeNull:    "null",
+	flatbuf.TypeBinary:  "binary",
+	flatbuf.TypeUtf8:    "utf8",
+	flatbuf.TypeBool:    "bool",
+	flatbuf.TypeList:    "list",
+	flatbuf.TypeStruct_: "struct",
+	v5.TypeLargeBinary:  "large_binary",
+	v5.TypeLargeUtf8:    "large_utf8",
+	v5.TypeLargeList:    "large_list",
+}
+
 func (t arrowType) String() string {
 	v := t.Value()
 
-	if 0 <= v && v < len(flatbuf.EnumNamesType) {
-		return flatbuf.EnumNamesType[v]
+	if name, found := typeNames[v]; found {
+		return name
+	}
+
+	if name, found := v5.EnumNamesType[v]; found {
+		return name
 	}
 
 	return strconv.FormatInt(int64(v), 10)
@@ -64,14 +90,6 @@ func (t arrowType) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 		flatbuf.BoolStart(builder)
 		return flatbuf.BoolEnd(builder), nil
 
-	case flatbuf.TypeDate:
-		flatbuf.DateStart(builder)
-		return flatbuf.DateEnd(builder), nil
-
-	case flatbuf.TypeTime:
-		flatbuf.TimeStart(builder)
-		return flatbuf.TimeEnd(builder), nil
-
 	case flatbuf.TypeList:
 		flatbuf.ListStart(builder)
 		return flatbuf.ListEnd(builder), nil
@@ -95,6 +113,14 @@ func NewInt(bitWidth int, signed bool) *Int {
 	return &Int{flatbuf.TypeInt, bitWidth, signed}
 }
 
+func (i *Int) String() string {
+	if i.Signed {
+		return fmt.Sprintf("int%d", i.BitWidth)
+	}
+
+	return fmt.Sprintf("uint%d", i.BitWidth)
+}
+
 func (i *Int) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	flatbuf.IntStart(builder)
 	flatbuf.IntAddBitWidth(builder, int32(i.BitWidth))
@@ -140,6 +166,19 @@ func NewFloatingPoint(precision Precision) *FloatingPoint {
 	return &FloatingPoint{flatbuf.TypeFloatingPoint, precision}
 }
 
+func (f *FloatingPoint) String() string {
+	switch f.Precision {
+	case Half:
+		return "float16"
+	case Single:
+		return "float32"
+	case Double:
+		return "float64"
+	default:
+		return fmt.Sprintf("float[%s]", f.Precision)
+	}
+}
+
 func (f *FloatingPoint) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	flatbuf.FloatingPointStart(builder)
 	flatbuf.FloatingPointAddPrecision(builder, int16(f.Precision))
@@ -151,13 +190,24 @@ type Decimal struct {
 
 	Precision Precision
 	Scale     int
+
+	// The width of the values, only V4 and later metadata record it.
+	BitWidth int
 }
 
 func NewDecimal(precision Precision, scale int) *Decimal {
-	return &Decimal{flatbuf.TypeDecimal, precision, scale}
+	return &Decimal{flatbuf.TypeDecimal, precision, scale, 128}
+}
+
+func (d *Decimal) String() string {
+	return fmt.Sprintf("decimal%d[%d, %d]", d.BitWidth, d.Precision, d.Scale)
 }
 
 func (d *Decimal) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	if d.BitWidth != 128 {
+		return 0, fmt.Errorf("decimal of %d bits is not supported in V1 metadata", d.BitWidth)
+	}
+
 	flatbuf.DecimalStart(builder)
 	flatbuf.DecimalAddPrecision(builder, int32(d.Precision))
 	flatbuf.DecimalAddScale(builder, int32(d.Scale))
@@ -188,22 +238,149 @@ func (u TimeUnit) String() string {
 	}
 }
 
+// Returns the abbreviation of the unit, as written by Schema.String.
+func (u TimeUnit) Abbrev() string {
+	switch u {
+	case Nanosecond:
+		return "ns"
+	case Microsecond:
+		return "us"
+	case Millisecond:
+		return "ms"
+	case Second:
+		return "s"
+	default:
+		return strconv.FormatInt(int64(u), 10)
+	}
+}
+
+type DateUnit int16
+
+const (
+	DateDay         DateUnit = v5.DateUnitDAY
+	DateMillisecond DateUnit = v5.DateUnitMILLISECOND
+)
+
+func (u DateUnit) String() string {
+	switch u {
+	case DateDay:
+		return "DAY"
+	case DateMillisecond:
+		return "MILLISECOND"
+	default:
+		return strconv.FormatInt(int64(u), 10)
+	}
+}
+
+// DateType is the date since the UNIX epoch, in 32 bit days or 64 bit milliseconds.
+type DateType struct {
+	arrowType
+
+	Unit DateUnit
+}
+
+func NewDate(unit DateUnit) *DateType {
+	return &DateType{flatbuf.TypeDate, unit}
+}
+
+func (d *DateType) String() string {
+	switch d.Unit {
+	case DateDay:
+		return "date32"
+	case DateMillisecond:
+		return "date64"
+	default:
+		return fmt.Sprintf("date[%s]", d.Unit)
+	}
+}
+
+func (d *DateType) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	if d.Unit != DateMillisecond {
+		return 0, fmt.Errorf("date in %s is not supported in V1 metadata", d.Unit)
+	}
+
+	flatbuf.DateStart(builder)
+	return flatbuf.DateEnd(builder), nil
+}
+
+// TimeType is the time since midnight, in 32 bits for seconds and milliseconds,
+// or in 64 bits for microseconds and nanoseconds.
+type TimeType struct {
+	arrowType
+
+	Unit     TimeUnit
+	BitWidth int
+}
+
+func NewTime(unit TimeUnit) *TimeType {
+	bitWidth := 64
+
+	if unit == Second || unit == Millisecond {
+		bitWidth = 32
+	}
+
+	return &TimeType{flatbuf.TypeTime, unit, bitWidth}
+}
+
+func (t *TimeType) String() string {
+	return fmt.Sprintf("time%d[%s]", t.BitWidth, t.Unit.Abbrev())
+}
+
+func (t *TimeType) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	if t.Unit != Millisecond || t.BitWidth != 32 {
+		return 0, fmt.Errorf("time of %d bits in %s is not supported in V1 metadata", t.BitWidth, t.Unit)
+	}
+
+	flatbuf.TimeStart(builder)
+	return flatbuf.TimeEnd(builder), nil
+}
+
 type Timestamp struct {
 	arrowType
 
 	Unit TimeUnit
+
+	// The name or offset of the time zone, only V4 and later metadata record it.
+	Timezone string
 }
 
 func NewTimeStamp(unit TimeUnit) *Timestamp {
-	return &Timestamp{flatbuf.TypeTimestamp, unit}
+	return &Timestamp{flatbuf.TypeTimestamp, unit, ""}
+}
+
+func (t *Timestamp) String() string {
+	if len(t.Timezone) > 0 {
+		return fmt.Sprintf("timestamp[%s, tz=%s]", t.Unit.Abbrev(), quoteName(t.Timezone))
+	}
+
+	return fmt.Sprintf("timestamp[%s]", t.Unit.Abbrev())
 }
 
 func (t *Timestamp) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	if len(t.Timezone) > 0 {
+		return 0, fmt.Errorf("timestamp with time zone %s is not supported in V1 metadata", t.Timezone)
+	}
+
 	flatbuf.TimestampStart(builder)
 	flatbuf.TimestampAddUnit(builder, int16(t.Unit))
 	return flatbuf.TimestampEnd(builder), nil
 }
 
+// Duration is the elapsed time in 64 bits of the unit, only V4 and later metadata support it.
+type Duration struct {
+	arrowType
+
+	Unit TimeUnit
+}
+
+func NewDuration(unit TimeUnit) *Duration {
+	return &Duration{v5.TypeDuration, unit}
+}
+
+func (d *Duration) String() string {
+	return fmt.Sprintf("duration[%s]", d.Unit.Abbrev())
+}
+
 type IntervalUnit int16
 
 const (
@@ -232,6 +409,10 @@ func NewInterval(unit IntervalUnit) *Interval {
 	return &Interval{flatbuf.TypeInterval, unit}
 }
 
+func (i *Interval) String() string {
+	return fmt.Sprintf("interval[%s]", strings.ToLower(i.Unit.String()))
+}
+
 func (i *Interval) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	flatbuf.IntervalStart(builder)
 	flatbuf.IntervalAddUnit(builder, int16(i.Unit))
@@ -267,14 +448,30 @@ func NewUnion(mode UnionMode, typeIDs []int) *Union {
 	return &Union{flatbuf.TypeUnion, mode, typeIDs}
 }
 
+func (u *Union) String() string {
+	name := strings.ToLower(u.Mode.String()) + "_union"
+
+	if len(u.TypeIDs) == 0 {
+		return name
+	}
+
+	ids := make([]string, len(u.TypeIDs))
+
+	for i, id := range u.TypeIDs {
+		ids[i] = strconv.Itoa(id)
+	}
+
+	return fmt.Sprintf("%s[%s]", name, strings.Join(ids, ", "))
+}
+
 func (u *Union) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 	var typeIdOffset fb.UOffsetT
 
 	if len(u.TypeIDs) > 0 {
 		flatbuf.UnionStartTypeIdsVector(builder, len(u.TypeIDs))
 
-		for _, typeID := range u.TypeIDs {
-			builder.PrependInt32(int32(typeID))
+		for i := len(u.TypeIDs) - 1; i >= 0; i-- {
+			builder.PrependInt32(int32(u.TypeIDs[i]))
 		}
 
 		typeIdOffset = builder.EndVector(len(u.TypeIDs))
@@ -289,3 +486,55 @@ func (u *Union) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 
 	return flatbuf.UnionEnd(builder), nil
 }
+
+// FixedSizeBinary is the binary values of the same width, only V4 and later metadata support it.
+type FixedSizeBinary struct {
+	arrowType
+
+	ByteWidth int
+}
+
+func NewFixedSizeBinary(byteWidth int) *FixedSizeBinary {
+	return &FixedSizeBinary{v5.TypeFixedSizeBinary, byteWidth}
+}
+
+func (b *FixedSizeBinary) String() string {
+	return fmt.Sprintf("fixed_size_binary[%d]", b.ByteWidth)
+}
+
+// FixedSizeList is the lists of the same size, their values are held by the only child.
+// Only V4 and later metadata support it.
+type FixedSizeList struct {
+	arrowType
+
+	ListSize int
+}
+
+func NewFixedSizeList(listSize int) *FixedSizeList {
+	return &FixedSizeList{v5.TypeFixedSizeList, listSize}
+}
+
+func (l *FixedSizeList) String() string {
+	return fmt.Sprintf("fixed_size_list[%d]", l.ListSize)
+}
+
+// Map is the list of entries held by the only child, a non-nullable struct of the keys and values.
+// Only V4 and later metadata support it.
+type Map struct {
+	arrowType
+
+	// Whether the keys of every map are sorted.
+	KeysSorted bool
+}
+
+func NewMap(keysSorted bool) *Map {
+	return &Map{v5.TypeMap, keysSorted}
+}
+
+func (m *Map) String() string {
+	if m.KeysSorted {
+		return "map[sorted]"
+	}
+
+	return "map"
+}
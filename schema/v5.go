@@ -0,0 +1,592 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	fb "github.com/google/flatbuffers/go"
+
+	v5 "github.com/flier/arrow/flatbuf/v5"
+)
+
+// Unmarshal the schema of V4 or V5 metadata, the layouts of the fields are derived from their types.
+func UnmarshalSchemaV5(schema *v5.Schema, version MetadataVersion) (s *Schema, err error) {
+	defer recoverMalformed(&err)
+
+	if schema.Endianness() != v5.EndiannessLittle {
+		return nil, errors.New("big endian data is not supported")
+	}
+
+	var fields []*Field
+	var field v5.Field
+
+	u := &unmarshaler{version: version}
+
+	for i := 0; i < schema.FieldsLength(); i++ {
+		if schema.Fields(&field, i) {
+			f, err := u.unmarshalFieldV5(&field, 0)
+
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, f)
+		}
+	}
+
+	return &Schema{Fields: fields, Metadata: unmarshalMetadataV5(schema)}, nil
+}
+
+func (u *unmarshaler) unmarshalFieldV5(field *v5.Field, depth int) (*Field, error) {
+	if depth > maxFieldDepth {
+		return nil, fmt.Errorf("fields nested deeper than %d", maxFieldDepth)
+	}
+
+	if u.fields++; u.fields > maxFields {
+		return nil, fmt.Errorf("more than %d fields", maxFields)
+	}
+
+	tp, err := getTypeForFieldV5(field)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tp, metadata, err := UnmarshalExtensionType(tp, unmarshalMetadataV5(field))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*Field
+	var child v5.Field
+
+	for i := 0; i < field.ChildrenLength(); i++ {
+		if field.Children(&child, i) {
+			f, err := u.unmarshalFieldV5(&child, depth+1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			children = append(children, f)
+		}
+	}
+
+	f := &Field{
+		Name:     string(field.Name()),
+		Nullable: field.Nullable() != 0,
+		Type:     tp,
+		Children: children,
+		Metadata: metadata,
+	}
+
+	if d := field.Dictionary(nil); d != nil {
+		f.Dictionary = &DictionaryEncoding{ID: d.Id(), Ordered: d.IsOrdered() != 0}
+
+		if index := d.IndexType(nil); index != nil {
+			f.Dictionary.IndexType = NewInt(int(index.BitWidth()), index.IsSigned() != 0)
+		}
+	}
+
+	if f.Layout, err = fieldLayout(f, u.version); err != nil {
+		return nil, fmt.Errorf("field %s has no layout, %s", f.Name, err)
+	}
+
+	return f, nil
+}
+
+// The custom metadata of a field or a schema.
+type customMetadataV5 interface {
+	CustomMetadataLength() int
+	CustomMetadata(obj *v5.KeyValue, j int) bool
+}
+
+func unmarshalMetadataV5(table customMetadataV5) map[string]string {
+	if table.CustomMetadataLength() == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]string)
+
+	var kv v5.KeyValue
+
+	for i := 0; i < table.CustomMetadataLength(); i++ {
+		if table.CustomMetadata(&kv, i) {
+			metadata[string(kv.Key())] = string(kv.Value())
+		}
+	}
+
+	return metadata
+}
+
+func getTypeForFieldV5(field *v5.Field) (Type, error) {
+	var table fb.Table
+
+	if field.TypeType() != v5.TypeNONE && !field.Type(&table) {
+		return nil, fmt.Errorf("fail to parse type, %s", v5.EnumNamesType[int(field.TypeType())])
+	}
+
+	switch field.TypeType() {
+	case v5.TypeNull:
+		return Null, nil
+
+	case v5.TypeInt:
+		var i v5.Int
+		i.Init(table.Bytes, table.Pos)
+
+		return NewInt(int(i.BitWidth()), i.IsSigned() != 0), nil
+
+	case v5.TypeFloatingPoint:
+		var f v5.FloatingPoint
+		f.Init(table.Bytes, table.Pos)
+
+		return NewFloatingPoint(Precision(f.Precision())), nil
+
+	case v5.TypeDecimal:
+		var d v5.Decimal
+		d.Init(table.Bytes, table.Pos)
+
+		return &Decimal{arrowType(v5.TypeDecimal), Precision(d.Precision()), int(d.Scale()), int(d.BitWidth())}, nil
+
+	case v5.TypeBinary:
+		return Binary, nil
+
+	case v5.TypeUtf8:
+		return Utf8, nil
+
+	case v5.TypeBool:
+		return Bool, nil
+
+	case v5.TypeDate:
+		var d v5.Date
+		d.Init(table.Bytes, table.Pos)
+
+		if d.Unit() != v5.DateUnitDAY && d.Unit() != v5.DateUnitMILLISECOND {
+			return nil, fmt.Errorf("unsupported date unit, %s", DateUnit(d.Unit()))
+		}
+
+		return NewDate(DateUnit(d.Unit())), nil
+
+	case v5.TypeTime:
+		var t v5.Time
+		t.Init(table.Bytes, table.Pos)
+
+		tm := NewTime(TimeUnit(t.Unit()))
+
+		if t.Unit() < v5.TimeUnitSECOND || t.Unit() > v5.TimeUnitNANOSECOND || int(t.BitWidth()) != tm.BitWidth {
+			return nil, fmt.Errorf("unsupported time of %d bits in %s", t.BitWidth(), tm.Unit)
+		}
+
+		return tm, nil
+
+	case v5.TypeTimestamp:
+		var ts v5.Timestamp
+		ts.Init(table.Bytes, table.Pos)
+
+		if ts.Unit() < v5.TimeUnitSECOND || ts.Unit() > v5.TimeUnitNANOSECOND {
+			return nil, fmt.Errorf("unsupported timestamp unit, %d", ts.Unit())
+		}
+
+		return &Timestamp{arrowType(v5.TypeTimestamp), TimeUnit(ts.Unit()), string(ts.Timezone())}, nil
+
+	case v5.TypeInterval:
+		var i v5.Interval
+		i.Init(table.Bytes, table.Pos)
+
+		if i.Unit() != v5.IntervalUnitYEAR_MONTH && i.Unit() != v5.IntervalUnitDAY_TIME {
+			return nil, fmt.Errorf("unsupported interval unit, %s", v5.EnumNamesIntervalUnit[int(i.Unit())])
+		}
+
+		return NewInterval(IntervalUnit(i.Unit())), nil
+
+	case v5.TypeDuration:
+		var d v5.Duration
+		d.Init(table.Bytes, table.Pos)
+
+		if d.Unit() < v5.TimeUnitSECOND || d.Unit() > v5.TimeUnitNANOSECOND {
+			return nil, fmt.Errorf("unsupported duration unit, %d", d.Unit())
+		}
+
+		return NewDuration(TimeUnit(d.Unit())), nil
+
+	case v5.TypeList:
+		return List, nil
+
+	case v5.TypeLargeList:
+		return LargeList, nil
+
+	case v5.TypeLargeBinary:
+		return LargeBinary, nil
+
+	case v5.TypeLargeUtf8:
+		return LargeUtf8, nil
+
+	case v5.TypeFixedSizeBinary:
+		var b v5.FixedSizeBinary
+		b.Init(table.Bytes, table.Pos)
+
+		if b.ByteWidth() <= 0 {
+			return nil, fmt.Errorf("invalid byte width %d", b.ByteWidth())
+		}
+
+		return NewFixedSizeBinary(int(b.ByteWidth())), nil
+
+	case v5.TypeFixedSizeList:
+		var l v5.FixedSizeList
+		l.Init(table.Bytes, table.Pos)
+
+		if l.ListSize() < 0 {
+			return nil, fmt.Errorf("invalid list size %d", l.ListSize())
+		}
+
+		return NewFixedSizeList(int(l.ListSize())), nil
+
+	case v5.TypeMap:
+		var m v5.Map
+		m.Init(table.Bytes, table.Pos)
+
+		return NewMap(m.KeysSorted() != 0), nil
+
+	case v5.TypeStruct_:
+		return Struct, nil
+
+	case v5.TypeUnion:
+		var u v5.Union
+		u.Init(table.Bytes, table.Pos)
+
+		if u.Mode() != v5.UnionModeSparse && u.Mode() != v5.UnionModeDense {
+			return nil, fmt.Errorf("unsupported union mode, %d", u.Mode())
+		}
+
+		var typeIDs []int
+
+		for i := 0; i < u.TypeIdsLength(); i++ {
+			typeIDs = append(typeIDs, int(u.TypeIds(i)))
+		}
+
+		return NewUnion(UnionMode(u.Mode()), typeIDs), nil
+
+	default:
+		if name, found := v5.EnumNamesType[int(field.TypeType())]; found {
+			return nil, fmt.Errorf("unsupported type, %s", name)
+		}
+
+		return nil, fmt.Errorf("unsupported type, %d", field.TypeType())
+	}
+}
+
+// Marshal the schema as V4 or V5 metadata, which have the same encoding.
+func (s *Schema) MarshalV5(builder *fb.Builder) (fb.UOffsetT, error) {
+	var offsets []fb.UOffsetT
+
+	for _, field := range s.Fields {
+		off, err := field.MarshalV5(builder)
+
+		if err != nil {
+			return off, err
+		}
+
+		offsets = append(offsets, off)
+	}
+
+	v5.SchemaStartFieldsVector(builder, len(offsets))
+
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+
+	fieldsOffset := builder.EndVector(len(offsets))
+
+	var metadataOffset fb.UOffsetT
+
+	if len(s.Metadata) > 0 {
+		metadataOffset = marshalMetadataV5(builder, s.Metadata)
+	}
+
+	v5.SchemaStart(builder)
+	v5.SchemaAddFields(builder, fieldsOffset)
+
+	if len(s.Metadata) > 0 {
+		v5.SchemaAddCustomMetadata(builder, metadataOffset)
+	}
+
+	return v5.SchemaEnd(builder), nil
+}
+
+func (f *Field) MarshalV5(builder *fb.Builder) (fb.UOffsetT, error) {
+	var nameOffset fb.UOffsetT
+
+	if len(f.Name) > 0 {
+		nameOffset = builder.CreateString(f.Name)
+	}
+
+	tp := StorageType(f.Type)
+
+	typeOffset, err := marshalTypeV5(builder, tp)
+
+	if err != nil {
+		return 0, fmt.Errorf("fail to marshal type, %s", err)
+	}
+
+	var childOffsets []fb.UOffsetT
+
+	for _, child := range f.Children {
+		off, err := child.MarshalV5(builder)
+
+		if err != nil {
+			return 0, fmt.Errorf("fail to marshal children, %s", err)
+		}
+
+		childOffsets = append(childOffsets, off)
+	}
+
+	v5.FieldStartChildrenVector(builder, len(childOffsets))
+
+	for i := len(childOffsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(childOffsets[i])
+	}
+
+	childrenOffset := builder.EndVector(len(childOffsets))
+
+	var dictionaryOffset fb.UOffsetT
+
+	if f.Dictionary != nil {
+		dictionaryOffset = f.Dictionary.marshalV5(builder)
+	}
+
+	metadata := f.CustomMetadata()
+
+	var metadataOffset fb.UOffsetT
+
+	if len(metadata) > 0 {
+		metadataOffset = marshalMetadataV5(builder, metadata)
+	}
+
+	v5.FieldStart(builder)
+
+	if len(f.Name) > 0 {
+		v5.FieldAddName(builder, nameOffset)
+	}
+
+	var nullable byte
+
+	if f.Nullable {
+		nullable = 1
+	}
+
+	v5.FieldAddNullable(builder, nullable)
+	v5.FieldAddTypeType(builder, byte(tp.Value()))
+	v5.FieldAddType(builder, typeOffset)
+
+	if f.Dictionary != nil {
+		v5.FieldAddDictionary(builder, dictionaryOffset)
+	}
+
+	v5.FieldAddChildren(builder, childrenOffset)
+
+	if len(metadata) > 0 {
+		v5.FieldAddCustomMetadata(builder, metadataOffset)
+	}
+
+	return v5.FieldEnd(builder), nil
+}
+
+func marshalMetadataV5(builder *fb.Builder, metadata map[string]string) fb.UOffsetT {
+	var offsets []fb.UOffsetT
+
+	for _, k := range metadataKeys(metadata) {
+		keyOffset := builder.CreateString(k)
+		valueOffset := builder.CreateString(metadata[k])
+
+		v5.KeyValueStart(builder)
+		v5.KeyValueAddKey(builder, keyOffset)
+		v5.KeyValueAddValue(builder, valueOffset)
+		offsets = append(offsets, v5.KeyValueEnd(builder))
+	}
+
+	v5.FieldStartCustomMetadataVector(builder, len(offsets))
+
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+
+	return builder.EndVector(len(offsets))
+}
+
+func (d *DictionaryEncoding) marshalV5(builder *fb.Builder) fb.UOffsetT {
+	var indexOffset fb.UOffsetT
+
+	if d.IndexType != nil {
+		indexOffset = marshalIntV5(builder, d.IndexType)
+	}
+
+	var ordered byte
+
+	if d.Ordered {
+		ordered = 1
+	}
+
+	v5.DictionaryEncodingStart(builder)
+	v5.DictionaryEncodingAddId(builder, d.ID)
+
+	if d.IndexType != nil {
+		v5.DictionaryEncodingAddIndexType(builder, indexOffset)
+	}
+
+	v5.DictionaryEncodingAddIsOrdered(builder, ordered)
+	return v5.DictionaryEncodingEnd(builder)
+}
+
+func marshalIntV5(builder *fb.Builder, i *Int) fb.UOffsetT {
+	var signed byte
+
+	if i.Signed {
+		signed = 1
+	}
+
+	v5.IntStart(builder)
+	v5.IntAddBitWidth(builder, int32(i.BitWidth))
+	v5.IntAddIsSigned(builder, signed)
+	return v5.IntEnd(builder)
+}
+
+func marshalTypeV5(builder *fb.Builder, t Type) (fb.UOffsetT, error) {
+	switch t := t.(type) {
+	case *Int:
+		return marshalIntV5(builder, t), nil
+
+	case *FloatingPoint:
+		v5.FloatingPointStart(builder)
+		v5.FloatingPointAddPrecision(builder, int16(t.Precision))
+		return v5.FloatingPointEnd(builder), nil
+
+	case *Decimal:
+		v5.DecimalStart(builder)
+		v5.DecimalAddPrecision(builder, int32(t.Precision))
+		v5.DecimalAddScale(builder, int32(t.Scale))
+		v5.DecimalAddBitWidth(builder, int32(t.BitWidth))
+		return v5.DecimalEnd(builder), nil
+
+	case *Timestamp:
+		var timezoneOffset fb.UOffsetT
+
+		if len(t.Timezone) > 0 {
+			timezoneOffset = builder.CreateString(t.Timezone)
+		}
+
+		v5.TimestampStart(builder)
+		v5.TimestampAddUnit(builder, int16(t.Unit))
+
+		if len(t.Timezone) > 0 {
+			v5.TimestampAddTimezone(builder, timezoneOffset)
+		}
+
+		return v5.TimestampEnd(builder), nil
+
+	case *Interval:
+		v5.IntervalStart(builder)
+		v5.IntervalAddUnit(builder, int16(t.Unit))
+		return v5.IntervalEnd(builder), nil
+
+	case *DateType:
+		v5.DateStart(builder)
+		v5.DateAddUnit(builder, int16(t.Unit))
+		return v5.DateEnd(builder), nil
+
+	case *TimeType:
+		v5.TimeStart(builder)
+		v5.TimeAddUnit(builder, int16(t.Unit))
+		v5.TimeAddBitWidth(builder, int32(t.BitWidth))
+		return v5.TimeEnd(builder), nil
+
+	case *Duration:
+		v5.DurationStart(builder)
+		v5.DurationAddUnit(builder, int16(t.Unit))
+		return v5.DurationEnd(builder), nil
+
+	case *FixedSizeBinary:
+		v5.FixedSizeBinaryStart(builder)
+		v5.FixedSizeBinaryAddByteWidth(builder, int32(t.ByteWidth))
+		return v5.FixedSizeBinaryEnd(builder), nil
+
+	case *FixedSizeList:
+		v5.FixedSizeListStart(builder)
+		v5.FixedSizeListAddListSize(builder, int32(t.ListSize))
+		return v5.FixedSizeListEnd(builder), nil
+
+	case *Map:
+		var keysSorted byte
+
+		if t.KeysSorted {
+			keysSorted = 1
+		}
+
+		v5.MapStart(builder)
+		v5.MapAddKeysSorted(builder, keysSorted)
+		return v5.MapEnd(builder), nil
+
+	case *Union:
+		var typeIdOffset fb.UOffsetT
+
+		if len(t.TypeIDs) > 0 {
+			v5.UnionStartTypeIdsVector(builder, len(t.TypeIDs))
+
+			for i := len(t.TypeIDs) - 1; i >= 0; i-- {
+				builder.PrependInt32(int32(t.TypeIDs[i]))
+			}
+
+			typeIdOffset = builder.EndVector(len(t.TypeIDs))
+		}
+
+		v5.UnionStart(builder)
+		v5.UnionAddMode(builder, int16(t.Mode))
+
+		if len(t.TypeIDs) > 0 {
+			v5.UnionAddTypeIds(builder, typeIdOffset)
+		}
+
+		return v5.UnionEnd(builder), nil
+	}
+
+	switch t.Value() {
+	case v5.TypeNull:
+		v5.NullStart(builder)
+		return v5.NullEnd(builder), nil
+
+	case v5.TypeBinary:
+		v5.BinaryStart(builder)
+		return v5.BinaryEnd(builder), nil
+
+	case v5.TypeUtf8:
+		v5.Utf8Start(builder)
+		return v5.Utf8End(builder), nil
+
+	case v5.TypeBool:
+		v5.BoolStart(builder)
+		return v5.BoolEnd(builder), nil
+
+	case v5.TypeList:
+		v5.ListStart(builder)
+		return v5.ListEnd(builder), nil
+
+	case v5.TypeLargeList:
+		v5.LargeListStart(builder)
+		return v5.LargeListEnd(builder), nil
+
+	case v5.TypeLargeBinary:
+		v5.LargeBinaryStart(builder)
+		return v5.LargeBinaryEnd(builder), nil
+
+	case v5.TypeLargeUtf8:
+		v5.LargeUtf8Start(builder)
+		return v5.LargeUtf8End(builder), nil
+
+	case v5.TypeStruct_:
+		v5.Struct_Start(builder)
+		return v5.Struct_End(builder), nil
+
+	default:
+		return 0, fmt.Errorf("unsupported type, %s", t)
+	}
+}
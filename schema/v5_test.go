@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	fb "github.com/google/flatbuffers/go"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+)
+
+func TestSchemaMetadata(t *testing.T) {
+	s := seedSchemaV5()
+	s.Metadata = map[string]string{"origin": "test", "rows": "42"}
+
+	builder := fb.NewBuilder(0)
+	off, err := s.MarshalV5(builder)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder.Finish(off)
+
+	actual, err := UnmarshalSchemaV5(v5.GetRootAsSchema(builder.FinishedBytes(), 0), V5)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Diff(actual, EqualOptions{}); err != nil {
+		t.Errorf("V5 schema changed by a round trip, %s", err)
+	}
+
+	s = seedSchema()
+	s.Metadata = map[string]string{"origin": "test"}
+
+	actual, err = UnmarshalSchema(flatbuf.GetRootAsSchema(marshal(t, s), 0))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Diff(actual, EqualOptions{}); err != nil {
+		t.Errorf("V1 schema changed by a round trip, %s", err)
+	}
+}
+
+// Build a V5 field of the type, which is written by fn.
+func fieldV5(tp byte, fn func(builder *fb.Builder) fb.UOffsetT) *v5.Field {
+	builder := fb.NewBuilder(0)
+	name := builder.CreateString("f")
+	typeOffset := fn(builder)
+
+	v5.FieldStart(builder)
+	v5.FieldAddName(builder, name)
+	v5.FieldAddTypeType(builder, tp)
+	v5.FieldAddType(builder, typeOffset)
+	builder.Finish(v5.FieldEnd(builder))
+
+	return v5.GetRootAsField(builder.FinishedBytes(), 0)
+}
+
+func TestGetTypeForFieldV5(t *testing.T) {
+	tests := []struct {
+		field *v5.Field
+		err   string // empty if the type is valid
+	}{
+		{fieldV5(v5.TypeTimestamp, func(builder *fb.Builder) fb.UOffsetT {
+			v5.TimestampStart(builder)
+			v5.TimestampAddUnit(builder, v5.TimeUnitNANOSECOND)
+			return v5.TimestampEnd(builder)
+		}), ""},
+		{fieldV5(v5.TypeTimestamp, func(builder *fb.Builder) fb.UOffsetT {
+			v5.TimestampStart(builder)
+			v5.TimestampAddUnit(builder, 7)
+			return v5.TimestampEnd(builder)
+		}), "unsupported timestamp unit, 7"},
+		{fieldV5(v5.TypeDuration, func(builder *fb.Builder) fb.UOffsetT {
+			v5.DurationStart(builder)
+			v5.DurationAddUnit(builder, -1)
+			return v5.DurationEnd(builder)
+		}), "unsupported duration unit, -1"},
+		{fieldV5(v5.TypeUnion, func(builder *fb.Builder) fb.UOffsetT {
+			v5.UnionStart(builder)
+			v5.UnionAddMode(builder, v5.UnionModeDense)
+			return v5.UnionEnd(builder)
+		}), ""},
+		{fieldV5(v5.TypeUnion, func(builder *fb.Builder) fb.UOffsetT {
+			v5.UnionStart(builder)
+			v5.UnionAddMode(builder, 2)
+			return v5.UnionEnd(builder)
+		}), "unsupported union mode, 2"},
+	}
+
+	for _, test := range tests {
+		tp, err := getTypeForFieldV5(test.field)
+
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("parse %s with error %s", v5.EnumNamesType[int(test.field.TypeType())], err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("parse %s as %v with error %v, expected %s", v5.EnumNamesType[int(test.field.TypeType())], tp, err, test.err)
+		}
+	}
+}
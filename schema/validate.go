@@ -0,0 +1,397 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"unicode/utf8"
+
+	"github.com/flier/arrow/flatbuf"
+	v5 "github.com/flier/arrow/flatbuf/v5"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema/vector"
+)
+
+type validator struct {
+	batch  *vector.RecordBatch
+	node   int
+	buffer int
+}
+
+// Check the structure of the record batch against the schema.
+//
+// The field nodes and buffers must match the layouts of the fields, the null counts must
+// match the validity bitmaps, offsets must be monotonic and within their values, and Utf8
+// values must be valid UTF-8. The error describes the first problem found.
+func (s *Schema) Validate(batch *vector.RecordBatch) error {
+	if err := batch.Validate(); err != nil {
+		return err
+	}
+
+	v := &validator{batch: batch}
+
+	for _, field := range s.Fields {
+		if _, err := v.validate(field, field.Name, batch.Length); err != nil {
+			return err
+		}
+	}
+
+	if v.node != len(batch.Nodes) {
+		return fmt.Errorf("batch has %d field nodes but the schema describes %d", len(batch.Nodes), v.node)
+	}
+
+	if v.buffer != len(batch.Buffers) {
+		return fmt.Errorf("batch has %d buffers but the schema describes %d", len(batch.Buffers), v.buffer)
+	}
+
+	return nil
+}
+
+// Validate the field and its children, a negative length is not checked against the field node.
+// Returns the length of the field node.
+func (v *validator) validate(field *Field, path string, length int) (int, error) {
+	if field.Layout == nil {
+		return 0, fmt.Errorf("field %s has no layout", path)
+	}
+
+	if v.node >= len(v.batch.Nodes) {
+		return 0, fmt.Errorf("field %s has no field node", path)
+	}
+
+	node := v.batch.Nodes[v.node]
+	v.node++
+
+	if length >= 0 && node.Length != length {
+		return 0, fmt.Errorf("field %s has length %d, expected %d", path, node.Length, length)
+	}
+
+	if !field.Nullable && node.NullCount > 0 {
+		return 0, fmt.Errorf("field %s is not nullable but has %d nulls", path, node.NullCount)
+	}
+
+	vectors := field.Layout.Vectors
+
+	if v.buffer+len(vectors) > len(v.batch.Buffers) {
+		return 0, fmt.Errorf("field %s needs %d buffers, only %d left", path, len(vectors), len(v.batch.Buffers)-v.buffer)
+	}
+
+	buffers := v.batch.Buffers[v.buffer : v.buffer+len(vectors)]
+	v.buffer += len(vectors)
+
+	tp := field.Type.Value()
+
+	if field.Dictionary != nil {
+		tp = flatbuf.TypeInt // the buffers hold the indices
+	}
+
+	var validity, offsets, types, data *memory.Buffer
+	var offsetWidth, typeWidth, dataWidth int
+
+	for i, layout := range vectors {
+		switch layout.Type {
+		case vector.Validity:
+			validity = buffers[i]
+		case vector.Offset:
+			offsets, offsetWidth = buffers[i], layout.BitWidth
+		case vector.Type:
+			types, typeWidth = buffers[i], layout.BitWidth
+		case vector.Data:
+			data, dataWidth = buffers[i], layout.BitWidth
+		default:
+			return 0, fmt.Errorf("field %s has unknown vector type %s", path, layout.Type)
+		}
+	}
+
+	if validity != nil {
+		if err := validateValidity(validity, node); err != nil {
+			return 0, fmt.Errorf("field %s: %s", path, err)
+		}
+	} else if node.NullCount > 0 {
+		return 0, fmt.Errorf("field %s has %d nulls but no validity bitmap", path, node.NullCount)
+	}
+
+	var values = -1 // the number of child values or bytes referred to by the offsets
+
+	if offsets != nil && tp != flatbuf.TypeUnion {
+		last, err := validateOffsets(offsets, offsetWidth, node.Length)
+
+		if err != nil {
+			return 0, fmt.Errorf("field %s: %s", path, err)
+		}
+
+		values = last
+	}
+
+	if data != nil {
+		n := node.Length
+
+		if values >= 0 {
+			n = values
+		}
+
+		if !holds(data, dataWidth, n) {
+			return 0, fmt.Errorf("field %s has %d bytes of data for %d values of %d bits", path, data.Len(), n, dataWidth)
+		}
+	}
+
+	if (tp == flatbuf.TypeUtf8 || tp == v5.TypeLargeUtf8) && offsets != nil && data != nil {
+		if err := validateUtf8(validity, offsets, offsetWidth, data, node); err != nil {
+			return 0, fmt.Errorf("field %s: %s", path, err)
+		}
+	}
+
+	switch tp {
+	case flatbuf.TypeStruct_:
+		for _, child := range field.Children {
+			if _, err := v.validate(child, path+"."+child.Name, node.Length); err != nil {
+				return 0, err
+			}
+		}
+
+	case flatbuf.TypeList, v5.TypeLargeList, v5.TypeMap:
+		if len(field.Children) != 1 {
+			return 0, fmt.Errorf("field %s of type %s has %d children", path, field.Type, len(field.Children))
+		}
+
+		if entries := field.Children[0]; tp == v5.TypeMap && (entries.Type.Value() != flatbuf.TypeStruct_ || len(entries.Children) != 2) {
+			return 0, fmt.Errorf("field %s of type Map has no struct of keys and values", path)
+		}
+
+		if _, err := v.validate(field.Children[0], path+"."+field.Children[0].Name, values); err != nil {
+			return 0, err
+		}
+
+	case v5.TypeFixedSizeList:
+		list, ok := StorageType(field.Type).(*FixedSizeList)
+
+		if !ok {
+			return 0, fmt.Errorf("field %s has invalid fixed size list type %T", path, field.Type)
+		}
+
+		if len(field.Children) != 1 {
+			return 0, fmt.Errorf("field %s of type %s has %d children", path, field.Type, len(field.Children))
+		}
+
+		if list.ListSize < 0 || list.ListSize > 0 && node.Length > math.MaxInt/list.ListSize {
+			return 0, fmt.Errorf("field %s of %d lists of size %d overflows", path, node.Length, list.ListSize)
+		}
+
+		if _, err := v.validate(field.Children[0], path+"."+field.Children[0].Name, node.Length*list.ListSize); err != nil {
+			return 0, err
+		}
+
+	case flatbuf.TypeUnion:
+		if err := v.validateUnion(field, path, node, types, typeWidth, offsets, offsetWidth); err != nil {
+			return 0, err
+		}
+
+	default:
+		for _, child := range field.Children {
+			if _, err := v.validate(child, path+"."+child.Name, -1); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return node.Length, nil
+}
+
+func (v *validator) validateUnion(field *Field, path string, node *vector.FieldNode, types *memory.Buffer, typeWidth int, offsets *memory.Buffer, offsetWidth int) error {
+	union, ok := StorageType(field.Type).(*Union)
+
+	if !ok {
+		return fmt.Errorf("field %s has invalid union type %T", path, field.Type)
+	}
+
+	childLength := -1
+
+	if union.Mode == Sparse {
+		childLength = node.Length
+	}
+
+	lengths := make([]int, len(field.Children))
+
+	for i, child := range field.Children {
+		n, err := v.validate(child, path+"."+child.Name, childLength)
+
+		if err != nil {
+			return err
+		}
+
+		lengths[i] = n
+	}
+
+	if types == nil {
+		return fmt.Errorf("field %s of type Union has no type ids", path)
+	}
+
+	if typeWidth != 8 && typeWidth != 16 && typeWidth != 32 && typeWidth != 64 {
+		return fmt.Errorf("field %s has unsupported type id width %d", path, typeWidth)
+	}
+
+	if !holds(types, typeWidth, node.Length) {
+		return fmt.Errorf("field %s has %d bytes of type ids for %d values", path, types.Len(), node.Length)
+	}
+
+	if union.Mode == Dense {
+		if offsets == nil {
+			return fmt.Errorf("field %s of dense Union has no offsets", path)
+		}
+
+		if offsetWidth != 32 && offsetWidth != 64 {
+			return fmt.Errorf("field %s has unsupported offset width %d", path, offsetWidth)
+		}
+
+		if !holds(offsets, offsetWidth, node.Length) {
+			return fmt.Errorf("field %s has %d bytes of offsets for %d values", path, offsets.Len(), node.Length)
+		}
+	}
+
+	for i := 0; i < node.Length; i++ {
+		typeID := readInt(types, typeWidth, i)
+		child := typeID
+
+		if len(union.TypeIDs) > 0 {
+			child = -1
+
+			for j, id := range union.TypeIDs {
+				if id == typeID {
+					child = j
+					break
+				}
+			}
+		}
+
+		if child < 0 || child >= len(lengths) {
+			return fmt.Errorf("field %s has unknown type id %d at %d", path, typeID, i)
+		}
+
+		if union.Mode == Dense {
+			if off := readInt(offsets, offsetWidth, i); off < 0 || off >= lengths[child] {
+				return fmt.Errorf("field %s has offset %d at %d out of child range [0, %d)", path, off, i, lengths[child])
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateValidity(validity *memory.Buffer, node *vector.FieldNode) error {
+	if validity.Len() == 0 {
+		if node.NullCount > 0 {
+			return fmt.Errorf("validity bitmap omitted with %d nulls", node.NullCount)
+		}
+
+		return nil
+	}
+
+	if !holds(validity, 1, node.Length) {
+		return fmt.Errorf("validity bitmap has %d bytes for %d values", validity.Len(), node.Length)
+	}
+
+	nulls := node.Length - countBits(validity.Bytes(), node.Length)
+
+	if nulls != node.NullCount {
+		return fmt.Errorf("validity bitmap has %d nulls, null count is %d", nulls, node.NullCount)
+	}
+
+	return nil
+}
+
+// Returns the number of set bits in the first n bits of the bitmap.
+func countBits(bitmap []byte, n int) int {
+	count := 0
+
+	for _, b := range bitmap[:n/8] {
+		count += bits.OnesCount8(b)
+	}
+
+	if rem := n % 8; rem > 0 {
+		count += bits.OnesCount8(bitmap[n/8] & byte(1<<uint(rem)-1))
+	}
+
+	return count
+}
+
+func isValid(validity *memory.Buffer, i int) bool {
+	if validity == nil || validity.Len() == 0 {
+		return true
+	}
+
+	return validity.Bytes()[i>>3]&(1<<uint(i&7)) != 0
+}
+
+// Check the length+1 offsets are monotonic, returns the last one.
+func validateOffsets(offsets *memory.Buffer, bitWidth, length int) (int, error) {
+	if bitWidth != 32 && bitWidth != 64 {
+		return 0, fmt.Errorf("unsupported offset width %d", bitWidth)
+	}
+
+	if length == 0 && offsets.Len() == 0 {
+		return 0, nil
+	}
+
+	// length+1 offsets, the length is not incremented as it may be the largest int
+	if length >= offsets.Len()*8/bitWidth {
+		return 0, fmt.Errorf("offsets have %d bytes for %d values", offsets.Len(), length)
+	}
+
+	prev := readInt(offsets, bitWidth, 0)
+
+	if prev < 0 {
+		return 0, fmt.Errorf("negative first offset %d", prev)
+	}
+
+	for i := 1; i <= length; i++ {
+		off := readInt(offsets, bitWidth, i)
+
+		if off < prev {
+			return 0, fmt.Errorf("offset %d at %d is less than the previous offset %d", off, i, prev)
+		}
+
+		prev = off
+	}
+
+	return prev, nil
+}
+
+func validateUtf8(validity, offsets *memory.Buffer, offsetWidth int, data *memory.Buffer, node *vector.FieldNode) error {
+	if node.Length == 0 {
+		return nil
+	}
+
+	values := data.Bytes()
+
+	for i := 0; i < node.Length; i++ {
+		if !isValid(validity, i) {
+			continue
+		}
+
+		start, end := readInt(offsets, offsetWidth, i), readInt(offsets, offsetWidth, i+1)
+
+		if !utf8.Valid(values[start:end]) {
+			return fmt.Errorf("invalid UTF-8 value at %d", i)
+		}
+	}
+
+	return nil
+}
+
+// Returns whether the buffer holds n values of bitWidth bits, the size of the buffer is divided
+// rather than the length multiplied, which overflows with the lengths of a corrupt batch.
+func holds(buf *memory.Buffer, bitWidth, n int) bool {
+	return bitWidth == 0 || bitWidth > 0 && n <= buf.Len()*8/bitWidth
+}
+
+func readInt(buf *memory.Buffer, bitWidth, i int) int {
+	switch bitWidth {
+	case 8:
+		return int(buf.TinyInt(i))
+	case 16:
+		return int(buf.SmallInt(i))
+	case 32:
+		return int(buf.Int(i))
+	default:
+		return int(buf.BigInt(i))
+	}
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema/vector"
+)
+
+func validateField(t *testing.T, name string, tp Type, children ...*Field) *Field {
+	layout, err := NewTypeLayout(tp)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Field{Name: name, Nullable: true, Type: tp, Layout: layout, Children: children}
+}
+
+func buffers(bufs ...[]byte) []*memory.Buffer {
+	var buffers []*memory.Buffer
+
+	for _, buf := range bufs {
+		buffers = append(buffers, memory.NewBuffer(buf))
+	}
+
+	return buffers
+}
+
+func TestValidate(t *testing.T) {
+	utf8 := validateField(t, "s", Utf8)
+	int32s := validateField(t, "i", NewInt(32, true))
+	bools := validateField(t, "b", Bool)
+	list := validateField(t, "l", NewFixedSizeList(4), validateField(t, "item", NewInt(32, true)))
+	union := validateField(t, "u", NewUnion(Dense, nil), validateField(t, "i", NewInt(32, true)))
+
+	tests := []struct {
+		field  *Field
+		length int
+		nodes  []*vector.FieldNode
+		bufs   []*memory.Buffer
+		err    string // empty if the batch is valid
+	}{
+		{
+			int32s, 2, []*vector.FieldNode{{Length: 2, NullCount: 1}},
+			buffers([]byte{1}, make([]byte, 8)), "",
+		},
+		{
+			utf8, 2, []*vector.FieldNode{{Length: 2}},
+			buffers(nil, []byte{0, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}, []byte("abc")), "",
+		},
+		{
+			utf8, 1 << 62, []*vector.FieldNode{{Length: 1 << 62}},
+			buffers(nil, make([]byte, 8), nil), "offsets have 8 bytes for 4611686018427387904 values",
+		},
+		{
+			utf8, math.MaxInt, []*vector.FieldNode{{Length: math.MaxInt}},
+			buffers(nil, make([]byte, 16), nil), "offsets have 16 bytes",
+		},
+		{
+			utf8, 2, []*vector.FieldNode{{Length: 2}},
+			buffers(nil, []byte{0, 0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0}, []byte("abc")), "offset 1 at 2 is less than the previous offset 2",
+		},
+		{
+			utf8, 2, []*vector.FieldNode{{Length: 2}},
+			buffers(nil, []byte{0, 0, 0, 0, 2, 0, 0, 0, 4, 0, 0, 0}, []byte("abc")), "3 bytes of data for 4 values",
+		},
+		{
+			utf8, 1, []*vector.FieldNode{{Length: 1}},
+			buffers(nil, []byte{0, 0, 0, 0, 1, 0, 0, 0}, []byte{0xff}), "invalid UTF-8 value at 0",
+		},
+		{
+			int32s, 1 << 61, []*vector.FieldNode{{Length: 1 << 61}},
+			buffers(nil, make([]byte, 8)), "8 bytes of data for 2305843009213693952 values of 32 bits",
+		},
+		{
+			bools, math.MaxInt, []*vector.FieldNode{{Length: math.MaxInt}},
+			buffers([]byte{0xff}, make([]byte, 8)), "validity bitmap has 1 bytes",
+		},
+		{
+			int32s, 9, []*vector.FieldNode{{Length: 9, NullCount: 1}},
+			buffers([]byte{0xff}, make([]byte, 36)), "validity bitmap has 1 bytes for 9 values",
+		},
+		{
+			list, 1 << 62, []*vector.FieldNode{{Length: 1 << 62}, {Length: 0}},
+			buffers(nil, nil, nil), "of 4611686018427387904 lists of size 4 overflows",
+		},
+		{
+			union, 1 << 62, []*vector.FieldNode{{Length: 1 << 62}, {Length: 0}},
+			buffers(make([]byte, 8), make([]byte, 8), nil, nil), "8 bytes of type ids",
+		},
+		{
+			union, 2, []*vector.FieldNode{{Length: 2}, {Length: 1}},
+			buffers(make([]byte, 2), make([]byte, 4), nil, make([]byte, 4)), "4 bytes of offsets for 2 values",
+		},
+		{
+			union, 1, []*vector.FieldNode{{Length: 1}, {Length: 1}},
+			buffers([]byte{0}, []byte{1, 0, 0, 0}, nil, make([]byte, 4)), "offset 1 at 0 out of child range [0, 1)",
+		},
+	}
+
+	for _, test := range tests {
+		s := &Schema{Fields: []*Field{test.field}}
+		batch := &vector.RecordBatch{Length: test.length, Nodes: test.nodes, Buffers: test.bufs}
+
+		err := s.Validate(batch)
+
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("validate %s batch with error %s", test.field.Type, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("validate %s batch with error %v, expected %s", test.field.Type, err, test.err)
+		}
+	}
+}
@@ -34,15 +34,19 @@ func (t VectorType) String() string {
 }
 
 var (
-	ValidityVector = &VectorLayout{Validity, 1}
-	OffsetVector   = &VectorLayout{Offset, 32}
-	TypeVector     = &VectorLayout{Type, 32}
-	BooleanVector  = &VectorLayout{Data, 1}
-	Value64Vector  = &VectorLayout{Data, 64}
-	Value32Vector  = &VectorLayout{Data, 32}
-	Value16Vector  = &VectorLayout{Data, 16}
-	Value8Vector   = &VectorLayout{Data, 8}
-	ByteVector     = Value8Vector
+	ValidityVector    = &VectorLayout{Validity, 1}
+	OffsetVector      = &VectorLayout{Offset, 32}
+	LargeOffsetVector = &VectorLayout{Offset, 64}
+	TypeVector        = &VectorLayout{Type, 32}
+	TypeIDVector      = &VectorLayout{Type, 8} // the union type ids since V4
+	BooleanVector     = &VectorLayout{Data, 1}
+	Value256Vector    = &VectorLayout{Data, 256}
+	Value128Vector    = &VectorLayout{Data, 128}
+	Value64Vector     = &VectorLayout{Data, 64}
+	Value32Vector     = &VectorLayout{Data, 32}
+	Value16Vector     = &VectorLayout{Data, 16}
+	Value8Vector      = &VectorLayout{Data, 8}
+	ByteVector        = Value8Vector
 )
 
 type VectorLayout struct {
@@ -55,8 +59,16 @@ func UnmarshalVectorLayout(layout *flatbuf.VectorLayout) (*VectorLayout, error)
 	case flatbuf.VectorTypeVALIDITY:
 		return ValidityVector, nil
 	case flatbuf.VectorTypeOFFSET:
+		if layout.BitWidth() == 64 {
+			return LargeOffsetVector, nil
+		}
+
 		return OffsetVector, nil
 	case flatbuf.VectorTypeTYPE:
+		if layout.BitWidth() == 8 {
+			return TypeIDVector, nil
+		}
+
 		return TypeVector, nil
 	case flatbuf.VectorTypeDATA:
 		return DataVector(int(layout.BitWidth()))
@@ -77,6 +89,8 @@ func (l *VectorLayout) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 
 func DataVector(bitWidth int) (*VectorLayout, error) {
 	switch bitWidth {
+	case 1:
+		return BooleanVector, nil
 	case 8:
 		return Value8Vector, nil
 	case 16:
@@ -85,8 +99,12 @@ func DataVector(bitWidth int) (*VectorLayout, error) {
 		return Value32Vector, nil
 	case 64:
 		return Value64Vector, nil
+	case 128:
+		return Value128Vector, nil
+	case 256:
+		return Value256Vector, nil
 	default:
-		return nil, errors.New("only 8, 16, 32, or 64 bits supported")
+		return nil, errors.New("only 1, 8, 16, 32, 64, 128 or 256 bits supported")
 	}
 }
 
@@ -2,6 +2,7 @@ package vector
 
 import (
 	"fmt"
+	"runtime"
 
 	fb "github.com/google/flatbuffers/go"
 
@@ -33,10 +34,84 @@ type RecordBatch struct {
 	Nodes   []*FieldNode
 	Buffers []*memory.Buffer
 	Layouts []*Buffer
+
+	// The memory backing Buffers, if it is owned by the batch.
+	Memory *memory.Memory
+
+	// The dictionaries referred to by dictionary encoded fields, keyed by their ids.
+	Dictionaries map[int64]*RecordBatch
+
+	// The compression of Buffers, nil if they are not compressed.
+	Compression *BodyCompression
+}
+
+// BodyCompression is the codec compressing each buffer of a record batch since V5,
+// a compressed buffer is prefixed by its uncompressed length in 64 bits, or -1 if it is stored raw.
+type BodyCompression struct {
+	Codec int8 // v5.CompressionTypeLZ4_FRAME or v5.CompressionTypeZSTD
+}
+
+// DictionaryBatch is the values of a dictionary, referred to by its id from the encoded fields.
+type DictionaryBatch struct {
+	ID   int64
+	Data *RecordBatch
+}
+
+func (d *DictionaryBatch) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	dataOffset, err := d.Data.Marshal(builder)
+
+	if err != nil {
+		return 0, fmt.Errorf("fail to marshal dictionaryBatch, %s", err)
+	}
+
+	flatbuf.DictionaryBatchStart(builder)
+	flatbuf.DictionaryBatchAddId(builder, d.ID)
+	flatbuf.DictionaryBatchAddData(builder, dataOffset)
+	return flatbuf.DictionaryBatchEnd(builder), nil
 }
 
-func UnmarshalRecordBatch(batch *flatbuf.RecordBatch, body []byte) (*RecordBatch, error) {
-	var nodes []*FieldNode
+func UnmarshalRecordBatch(batch *flatbuf.RecordBatch, body []byte) (rb *RecordBatch, err error) {
+	defer recoverMalformed(&err)
+
+	nodes, err := UnmarshalFieldNodes(batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	layouts, err := UnmarshalBuffers(batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newRecordBatch(int(batch.Length()), nodes, layouts, body)
+}
+
+// Returns the batch with its buffers located in the body.
+func newRecordBatch(length int, nodes []*FieldNode, layouts []*Buffer, body []byte) (*RecordBatch, error) {
+	var buffers []*memory.Buffer
+
+	for i, layout := range layouts {
+		if layout.Offset < 0 || layout.Size < 0 || layout.Offset > int64(len(body))-layout.Size {
+			return nil, fmt.Errorf("buffer %d [%d, %d) out of body range [0, %d)", i, layout.Offset, layout.Offset+layout.Size, len(body))
+		}
+
+		buffers = append(buffers, memory.NewBuffer(body[layout.Offset:layout.Offset+layout.Size]))
+	}
+
+	return &RecordBatch{
+		Length:  length,
+		Nodes:   nodes,
+		Buffers: buffers,
+		Layouts: layouts,
+	}, nil
+}
+
+// Returns the field nodes of the record batch.
+func UnmarshalFieldNodes(batch *flatbuf.RecordBatch) (nodes []*FieldNode, err error) {
+	defer recoverMalformed(&err)
+
 	var node flatbuf.FieldNode
 
 	for i := 0; i < batch.NodesLength(); i++ {
@@ -48,23 +123,101 @@ func UnmarshalRecordBatch(batch *flatbuf.RecordBatch, body []byte) (*RecordBatch
 		}
 	}
 
-	var buffers []*memory.Buffer
+	return nodes, nil
+}
+
+// Returns where the buffers of the record batch are located in its body.
+func UnmarshalBuffers(batch *flatbuf.RecordBatch) (buffers []*Buffer, err error) {
+	defer recoverMalformed(&err)
+
 	var buffer flatbuf.Buffer
 
 	for i := 0; i < batch.BuffersLength(); i++ {
 		if batch.Buffers(&buffer, i) {
-			buffers = append(buffers, memory.NewBuffer(body[buffer.Offset():buffer.Offset()+buffer.Length()]))
+			buffers = append(buffers, &Buffer{
+				Page:   int(buffer.Page()),
+				Offset: buffer.Offset(),
+				Size:   buffer.Length(),
+			})
 		}
 	}
 
-	return &RecordBatch{
-		Length:  int(batch.Length()),
-		Nodes:   nodes,
-		Buffers: buffers,
-	}, nil
+	return buffers, nil
+}
+
+// Turn the runtime panic raised by accessing a malformed flatbuffer into an error.
+func recoverMalformed(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(runtime.Error); ok {
+			*err = fmt.Errorf("malformed record batch, %s", e)
+		} else {
+			panic(r)
+		}
+	}
+}
+
+// Check the structure of the batch that does not depend on its schema.
+func (b *RecordBatch) Validate() error {
+	if b.Length < 0 {
+		return fmt.Errorf("negative batch length %d", b.Length)
+	}
+
+	for i, node := range b.Nodes {
+		if node.Length < 0 {
+			return fmt.Errorf("field node %d has negative length %d", i, node.Length)
+		}
+
+		if node.NullCount < 0 || node.NullCount > node.Length {
+			return fmt.Errorf("field node %d has null count %d out of range [0, %d]", i, node.NullCount, node.Length)
+		}
+	}
+
+	if b.Layouts != nil {
+		if len(b.Layouts) != len(b.Buffers) {
+			return fmt.Errorf("%d buffers but %d layouts", len(b.Buffers), len(b.Layouts))
+		}
+
+		for i, layout := range b.Layouts {
+			if layout.Offset < 0 || layout.Size < 0 {
+				return fmt.Errorf("buffer %d has invalid range [%d, %d)", i, layout.Offset, layout.Offset+layout.Size)
+			}
+
+			if int64(b.Buffers[i].Len()) != layout.Size {
+				return fmt.Errorf("buffer %d has %d bytes but its layout has %d", i, b.Buffers[i].Len(), layout.Size)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Retain the memory backing the batch and its dictionaries, each call must be paired with a Release.
+func (b *RecordBatch) Retain() {
+	if b.Memory != nil {
+		b.Memory.Retain()
+	}
+
+	for _, dict := range b.Dictionaries {
+		dict.Retain()
+	}
+}
+
+// Release the memory backing the batch and its dictionaries, the buffers must not be used afterwards.
+func (b *RecordBatch) Release() {
+	if b.Memory != nil {
+		b.Memory.Release()
+	}
+
+	for _, dict := range b.Dictionaries {
+		dict.Release()
+	}
 }
 
 func (b *RecordBatch) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
+	if b.Compression != nil {
+		return 0, errCompressed
+	}
+
 	nodesOffset, err := b.marshalNodes(builder)
 
 	if err != nil {
@@ -87,8 +240,9 @@ func (b *RecordBatch) Marshal(builder *fb.Builder) (fb.UOffsetT, error) {
 func (b *RecordBatch) marshalNodes(builder *fb.Builder) (fb.UOffsetT, error) {
 	flatbuf.RecordBatchStartNodesVector(builder, len(b.Nodes))
 
-	for _, node := range b.Nodes {
-		if _, err := node.Marshal(builder); err != nil {
+	// vectors are built back to front
+	for i := len(b.Nodes) - 1; i >= 0; i-- {
+		if _, err := b.Nodes[i].Marshal(builder); err != nil {
 			return 0, fmt.Errorf("fail to marshal node, %s", err)
 		}
 	}
@@ -99,8 +253,8 @@ func (b *RecordBatch) marshalNodes(builder *fb.Builder) (fb.UOffsetT, error) {
 func (b *RecordBatch) marshalLayouts(builder *fb.Builder) (fb.UOffsetT, error) {
 	flatbuf.RecordBatchStartBuffersVector(builder, len(b.Layouts))
 
-	for _, layout := range b.Layouts {
-		if _, err := layout.Marshal(builder); err != nil {
+	for i := len(b.Layouts) - 1; i >= 0; i-- {
+		if _, err := b.Layouts[i].Marshal(builder); err != nil {
 			return 0, fmt.Errorf("fail to marshal layout, %s", err)
 		}
 	}
@@ -0,0 +1,171 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+
+	fb "github.com/google/flatbuffers/go"
+
+	v5 "github.com/flier/arrow/flatbuf/v5"
+)
+
+var (
+	errCompressed = errors.New("compressed record batch is not supported before V5")
+)
+
+// Unmarshal the record batch of V4 or V5 metadata with its buffers located in the body.
+func UnmarshalRecordBatchV5(batch *v5.RecordBatch, body []byte) (rb *RecordBatch, err error) {
+	defer recoverMalformed(&err)
+
+	compression, err := UnmarshalBodyCompressionV5(batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := UnmarshalFieldNodesV5(batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	layouts, err := UnmarshalBuffersV5(batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	length := batch.Length()
+
+	if int64(int(length)) != length {
+		return nil, fmt.Errorf("batch length %d overflows", length)
+	}
+
+	rb, err = newRecordBatch(int(length), nodes, layouts, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rb.Compression = compression
+
+	return rb, nil
+}
+
+// Returns the compression of the buffers of the record batch of V5 metadata, or nil if they are not compressed.
+func UnmarshalBodyCompressionV5(batch *v5.RecordBatch) (compression *BodyCompression, err error) {
+	defer recoverMalformed(&err)
+
+	c := batch.Compression(nil)
+
+	if c == nil {
+		return nil, nil
+	}
+
+	if c.Method() != v5.BodyCompressionMethodBUFFER {
+		return nil, fmt.Errorf("unsupported body compression method %d", c.Method())
+	}
+
+	if _, found := v5.EnumNamesCompressionType[int(c.Codec())]; !found {
+		return nil, fmt.Errorf("unsupported body compression codec %d", c.Codec())
+	}
+
+	return &BodyCompression{Codec: c.Codec()}, nil
+}
+
+// Returns the field nodes of the record batch of V4 or V5 metadata.
+func UnmarshalFieldNodesV5(batch *v5.RecordBatch) (nodes []*FieldNode, err error) {
+	defer recoverMalformed(&err)
+
+	var node v5.FieldNode
+
+	for i := 0; i < batch.NodesLength(); i++ {
+		if batch.Nodes(&node, i) {
+			length, nullCount := node.Length(), node.NullCount()
+
+			if int64(int(length)) != length || int64(int(nullCount)) != nullCount {
+				return nil, fmt.Errorf("field node %d length %d overflows", i, length)
+			}
+
+			nodes = append(nodes, &FieldNode{
+				Length:    int(length),
+				NullCount: int(nullCount),
+			})
+		}
+	}
+
+	return nodes, nil
+}
+
+// Returns where the buffers of the record batch of V4 or V5 metadata are located in its body,
+// they may be compressed, see UnmarshalBodyCompressionV5.
+func UnmarshalBuffersV5(batch *v5.RecordBatch) (buffers []*Buffer, err error) {
+	defer recoverMalformed(&err)
+
+	var buffer v5.Buffer
+
+	for i := 0; i < batch.BuffersLength(); i++ {
+		if batch.Buffers(&buffer, i) {
+			buffers = append(buffers, &Buffer{
+				Offset: buffer.Offset(),
+				Size:   buffer.Length(),
+			})
+		}
+	}
+
+	return buffers, nil
+}
+
+// Marshal the record batch as V4 or V5 metadata, which have the same encoding.
+func (b *RecordBatch) MarshalV5(builder *fb.Builder) (fb.UOffsetT, error) {
+	v5.RecordBatchStartNodesVector(builder, len(b.Nodes))
+
+	// vectors are built back to front
+	for i := len(b.Nodes) - 1; i >= 0; i-- {
+		v5.CreateFieldNode(builder, int64(b.Nodes[i].Length), int64(b.Nodes[i].NullCount))
+	}
+
+	nodesOffset := builder.EndVector(len(b.Nodes))
+
+	v5.RecordBatchStartBuffersVector(builder, len(b.Layouts))
+
+	for i := len(b.Layouts) - 1; i >= 0; i-- {
+		v5.CreateBuffer(builder, b.Layouts[i].Offset, b.Layouts[i].Size)
+	}
+
+	buffersOffset := builder.EndVector(len(b.Layouts))
+
+	var compressionOffset fb.UOffsetT
+
+	if b.Compression != nil {
+		v5.BodyCompressionStart(builder)
+		v5.BodyCompressionAddCodec(builder, b.Compression.Codec)
+		v5.BodyCompressionAddMethod(builder, v5.BodyCompressionMethodBUFFER)
+		compressionOffset = v5.BodyCompressionEnd(builder)
+	}
+
+	v5.RecordBatchStart(builder)
+	v5.RecordBatchAddLength(builder, int64(b.Length))
+	v5.RecordBatchAddNodes(builder, nodesOffset)
+	v5.RecordBatchAddBuffers(builder, buffersOffset)
+
+	if b.Compression != nil {
+		v5.RecordBatchAddCompression(builder, compressionOffset)
+	}
+
+	return v5.RecordBatchEnd(builder), nil
+}
+
+// Marshal the dictionary batch as V4 or V5 metadata.
+func (d *DictionaryBatch) MarshalV5(builder *fb.Builder) (fb.UOffsetT, error) {
+	dataOffset, err := d.Data.MarshalV5(builder)
+
+	if err != nil {
+		return 0, fmt.Errorf("fail to marshal dictionaryBatch, %s", err)
+	}
+
+	v5.DictionaryBatchStart(builder)
+	v5.DictionaryBatchAddId(builder, d.ID)
+	v5.DictionaryBatchAddData(builder, dataOffset)
+	return v5.DictionaryBatchEnd(builder), nil
+}
@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"strconv"
+
+	v5 "github.com/flier/arrow/flatbuf/v5"
+)
+
+// MetadataVersion is the version of the Arrow metadata of a file or stream.
+type MetadataVersion int16
+
+const (
+	// The original format, the metadata describes the layouts of the fields.
+	V1 MetadataVersion = v5.MetadataVersionV1
+	V2 MetadataVersion = v5.MetadataVersionV2
+	V3 MetadataVersion = v5.MetadataVersionV3
+	// 64 bit lengths and encapsulated messages.
+	V4 MetadataVersion = v5.MetadataVersionV4
+	// Unions without validity bitmaps.
+	V5 MetadataVersion = v5.MetadataVersionV5
+)
+
+func (v MetadataVersion) String() string {
+	switch v {
+	case V1:
+		return "V1"
+	case V2:
+		return "V2"
+	case V3:
+		return "V3"
+	case V4:
+		return "V4"
+	case V5:
+		return "V5"
+	default:
+		return strconv.FormatInt(int64(v), 10)
+	}
+}
@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Insert the rows of the record batch, which is validated against the schema, by executing the prepared
+// statement with the values of the fields as its arguments, in their order. The statement may be prepared
+// in a transaction to insert the batch atomically.
+//
+// Nulls are nil, integers are int64 or uint64, floating points are float64, strings are string, binary values
+// are []byte, timestamps and dates are time.Time and durations are time.Duration. The other values are
+// formatted as strings, the decimals as their digits, and the nested values as JSON.
+func Insert(stmt *sql.Stmt, s *schema.Schema, batch *vector.RecordBatch) error {
+	cols, err := columns.New(s, batch)
+
+	if err != nil {
+		return err
+	}
+
+	args := make([]interface{}, len(cols))
+
+	for i := 0; i < batch.Length; i++ {
+		for j, c := range cols {
+			args[j] = value(c, i)
+		}
+
+		if _, err := stmt.Exec(args...); err != nil {
+			return fmt.Errorf("fail to insert row %d, %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the argument of the value of the column.
+func value(c *columns.Column, i int) interface{} {
+	if c.IsNull(i) {
+		return nil
+	}
+
+	if d := c.Dictionary(); d != nil {
+		return value(d, c.Index(i))
+	}
+
+	if c.Nested() {
+		return c.Format(i, time.RFC3339Nano)
+	}
+
+	switch tp := schema.StorageType(c.Field().Type).(type) {
+	case *schema.Int:
+		switch {
+		case tp.BitWidth == 8 && tp.Signed:
+			return int64(c.Int8(i))
+		case tp.BitWidth == 16 && tp.Signed:
+			return int64(c.Int16(i))
+		case tp.BitWidth == 32 && tp.Signed:
+			return int64(c.Int32(i))
+		case tp.Signed:
+			return c.Int64(i)
+		case tp.BitWidth == 8:
+			return int64(c.Uint8(i))
+		case tp.BitWidth == 16:
+			return int64(c.Uint16(i))
+		case tp.BitWidth == 32:
+			return int64(c.Uint32(i))
+		default:
+			return c.Uint64(i)
+		}
+
+	case *schema.FloatingPoint:
+		switch tp.Precision {
+		case schema.Single:
+			return float64(c.Float32(i))
+		case schema.Double:
+			return c.Float64(i)
+		default:
+			f, _ := strconv.ParseFloat(c.Format(i, time.RFC3339Nano), 64)
+
+			return f
+		}
+
+	case *schema.Timestamp:
+		return c.Time(i)
+
+	case *schema.DateType:
+		return c.Date(i)
+
+	case *schema.Duration:
+		return c.Duration(i)
+
+	case *schema.FixedSizeBinary:
+		return c.Bytes(i)
+	}
+
+	switch schema.StorageType(c.Field().Type) {
+	case schema.Bool:
+		return c.Bool(i)
+	case schema.Binary, schema.LargeBinary:
+		return c.Bytes(i)
+	}
+
+	return c.Format(i, time.RFC3339Nano)
+}
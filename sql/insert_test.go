@@ -0,0 +1,57 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow"
+)
+
+type order struct {
+	ID    int64
+	Item  *string `arrow:"item,dict"`
+	Count uint16
+	Price float32
+	Paid  bool
+	At    time.Time `arrow:"at,unit=ms"`
+	Tags  []string
+	Data  []byte
+}
+
+func TestInsert(t *testing.T) {
+	item := "apple"
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	batch, s, err := arrow.Marshal([]order{
+		{ID: 1, Item: &item, Count: 3, Price: 0.5, Paid: true, At: at, Tags: []string{"a"}, Data: []byte{1}},
+		{ID: 2, At: at},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := &testTable{}
+	db := openTable(t, "insert", table)
+
+	stmt, err := db.Prepare("INSERT INTO orders VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Insert(stmt, s, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]driver.Value{
+		{int64(1), "apple", int64(3), float64(0.5), true, at, `["a"]`, []byte{1}},
+		{int64(2), nil, int64(0), float64(0), false, at, `[]`, []byte{}},
+	}
+
+	if !reflect.DeepEqual(table.inserted, expected) {
+		t.Errorf("inserted %v, expected %v", table.inserted, expected)
+	}
+}
@@ -0,0 +1,552 @@
+// Package sql reads the rows of database/sql queries as record batches, and inserts record batches
+// through prepared statements, with any driver.
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+const DefaultBatchSize = 1024
+
+// ReadOptions controls how the rows are read.
+type ReadOptions struct {
+	BatchSize int // the number of rows of each batch, DefaultBatchSize if zero
+}
+
+// Reader reads the rows of a query as record batches of the Schema, mapped from the types of the columns.
+type Reader struct {
+	Schema *schema.Schema
+
+	opts    ReadOptions
+	rows    *sql.Rows
+	builder *arrow.RecordBuilder
+	values  []interface{} // the values of the current row
+	scan    []interface{} // the pointers to the values
+	err     error
+}
+
+// Create a reader of the rows, whose schema is mapped from their column types by ColumnField.
+// The rows are closed once read, or by the first error.
+func NewReader(rows *sql.Rows, opts ReadOptions) (*Reader, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	types, err := rows.ColumnTypes()
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to get column types, %s", err)
+	}
+
+	s := &schema.Schema{}
+
+	for _, ct := range types {
+		field, err := ColumnField(ct)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Fields = append(s.Fields, field)
+	}
+
+	builder, err := arrow.NewRecordBuilder(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{Schema: s, opts: opts, rows: rows, builder: builder}
+
+	r.values = make([]interface{}, len(types))
+	r.scan = make([]interface{}, len(types))
+
+	for i := range r.values {
+		r.scan[i] = &r.values[i]
+	}
+
+	return r, nil
+}
+
+// Returns the field of the column, mapped from the database type name and the scan type of the driver.
+//
+//   - BOOL and BOOLEAN are Bool
+//   - TINYINT, SMALLINT, INT, INTEGER, BIGINT and their aliases are Int of 8, 16, 32 and 64 bits,
+//     or of 64 bits if the driver scans them as 64 bit integers or doesn't tell, as SQLite's
+//   - REAL and FLOAT4 are single precision FloatingPoint, or double precision if the driver scans them so
+//     or doesn't tell, FLOAT, DOUBLE and FLOAT8 are double precision
+//   - DECIMAL and NUMERIC are Decimal of the precision and scale of the column, or Utf8 if they are unknown
+//   - DATE is Date in days, TIME is Time in microseconds, TIMESTAMP and DATETIME are Timestamp in microseconds,
+//     in UTC for the types with time zones
+//   - CHAR, VARCHAR, TEXT and the other character types are Utf8, BLOB, BINARY, VARBINARY and BYTEA are Binary
+//
+// The other types are mapped from their scan types, or are Utf8.
+// The columns are nullable unless the driver reports they are not.
+func ColumnField(ct *sql.ColumnType) (*schema.Field, error) {
+	nullable, ok := ct.Nullable()
+
+	if !ok {
+		nullable = true
+	}
+
+	tp := columnType(ct)
+
+	field, err := schema.NewField(ct.Name(), nullable, tp)
+
+	if err != nil {
+		return nil, fmt.Errorf("column %s of type %s has no field, %s", ct.Name(), ct.DatabaseTypeName(), err)
+	}
+
+	return field, nil
+}
+
+func columnType(ct *sql.ColumnType) schema.Type {
+	name := strings.ToUpper(strings.TrimSpace(ct.DatabaseTypeName()))
+
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+
+	unsigned := strings.Contains(name, "UNSIGNED")
+	name = strings.TrimSpace(strings.Replace(name, "UNSIGNED", "", 1))
+
+	scanned := scanType(ct.ScanType())
+	scannedInt, ok := scanned.(*schema.Int)
+	unknown := isUnknown(ct.ScanType())
+	wide := unknown || ok && scannedInt.BitWidth == 64
+
+	switch name {
+	case "BOOL", "BOOLEAN":
+		return schema.Bool
+
+	case "TINYINT", "INT1":
+		return intType(8, !unsigned, wide)
+	case "SMALLINT", "INT2", "SMALLSERIAL":
+		return intType(16, !unsigned, wide)
+	case "INT", "INTEGER", "INT4", "MEDIUMINT", "SERIAL":
+		return intType(32, !unsigned, wide)
+	case "BIGINT", "INT8", "BIGSERIAL":
+		return intType(64, !unsigned, wide)
+
+	case "REAL", "FLOAT4":
+		if f, ok := scanned.(*schema.FloatingPoint); unknown || ok && f.Precision == schema.Double {
+			return schema.NewFloatingPoint(schema.Double)
+		}
+
+		return schema.NewFloatingPoint(schema.Single)
+
+	case "FLOAT", "DOUBLE", "DOUBLE PRECISION", "FLOAT8":
+		return schema.NewFloatingPoint(schema.Double)
+
+	case "DECIMAL", "NUMERIC", "DEC":
+		if precision, scale, ok := ct.DecimalSize(); ok && precision > 0 && precision <= 38 {
+			return schema.NewDecimal(schema.Precision(precision), int(scale))
+		}
+
+		return schema.Utf8
+
+	case "DATE":
+		return schema.NewDate(schema.DateDay)
+
+	case "TIME", "TIME WITHOUT TIME ZONE":
+		return schema.NewTime(schema.Microsecond)
+
+	case "TIMESTAMP", "DATETIME", "TIMESTAMP WITHOUT TIME ZONE", "DATETIME2", "SMALLDATETIME":
+		return schema.NewTimeStamp(schema.Microsecond)
+
+	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE", "DATETIMEOFFSET":
+		tp := schema.NewTimeStamp(schema.Microsecond)
+		tp.Timezone = "UTC"
+
+		return tp
+
+	case "CHAR", "VARCHAR", "TEXT", "NCHAR", "NVARCHAR", "NTEXT", "CHARACTER", "CHARACTER VARYING",
+		"CLOB", "STRING", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "BPCHAR", "UUID", "JSON", "JSONB":
+		return schema.Utf8
+
+	case "BLOB", "BINARY", "VARBINARY", "BYTEA", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "IMAGE":
+		return schema.Binary
+	}
+
+	return scanned
+}
+
+func intType(bitWidth int, signed, wide bool) schema.Type {
+	if wide {
+		bitWidth = 64
+	}
+
+	return schema.NewInt(bitWidth, signed)
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+	anyType   = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// Returns whether the driver doesn't tell the type it scans the values as,
+// some drivers report the type of the next value, which is unknown before the first row.
+func isUnknown(t reflect.Type) bool {
+	return t == nil || t == anyType
+}
+
+// Returns the type of the values scanned as t, the sql.Null types as their values.
+func scanType(t reflect.Type) schema.Type {
+	if t == nil {
+		return schema.Utf8
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(sql.NullBool{}):
+		return schema.Bool
+	case reflect.TypeOf(sql.NullInt32{}):
+		return schema.NewInt(32, true)
+	case reflect.TypeOf(sql.NullInt64{}):
+		return schema.NewInt(64, true)
+	case reflect.TypeOf(sql.NullFloat64{}):
+		return schema.NewFloatingPoint(schema.Double)
+	case reflect.TypeOf(sql.NullTime{}), timeType:
+		return schema.NewTimeStamp(schema.Microsecond)
+	case bytesType, reflect.TypeOf(sql.RawBytes{}):
+		return schema.Binary
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return schema.Bool
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return schema.NewInt(int(t.Size())*8, true)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema.NewInt(int(t.Size())*8, false)
+	case reflect.Int, reflect.Uint:
+		return schema.NewInt(64, t.Kind() == reflect.Int)
+	case reflect.Float32:
+		return schema.NewFloatingPoint(schema.Single)
+	case reflect.Float64:
+		return schema.NewFloatingPoint(schema.Double)
+	}
+
+	return schema.Utf8
+}
+
+// Read the next batch of at most BatchSize rows, returns io.EOF after the last one.
+// The reader fails with the first error.
+func (r *Reader) Read() (*vector.RecordBatch, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	rows := 0
+
+	for ; rows < r.opts.BatchSize && r.rows.Next(); rows++ {
+		err := r.rows.Scan(r.scan...)
+
+		if err != nil {
+			err = fmt.Errorf("fail to scan row, %s", err)
+		} else {
+			err = r.appendRow()
+		}
+
+		if err != nil {
+			return nil, r.fail(err)
+		}
+	}
+
+	if rows < r.opts.BatchSize {
+		if err := r.rows.Err(); err != nil {
+			return nil, r.fail(fmt.Errorf("fail to read rows, %s", err))
+		}
+
+		r.rows.Close()
+	}
+
+	if rows == 0 {
+		r.err = io.EOF
+
+		return nil, io.EOF
+	}
+
+	batch, err := r.builder.NewRecordBatch()
+
+	if err != nil {
+		return nil, r.fail(err)
+	}
+
+	return batch, nil
+}
+
+func (r *Reader) fail(err error) error {
+	r.err = err
+	r.rows.Close()
+
+	return err
+}
+
+func (r *Reader) appendRow() error {
+	for i, field := range r.Schema.Fields {
+		v := r.values[i]
+		c := r.builder.Columns[i]
+
+		if v == nil {
+			if !field.Nullable {
+				return fmt.Errorf("column %s is not nullable but has a null", field.Name)
+			}
+
+			c.AppendNull()
+
+			continue
+		}
+
+		if err := appendValue(c, field, v); err != nil {
+			return fmt.Errorf("column %s has invalid value %v of %s, %s", field.Name, v, field.Type, err)
+		}
+	}
+
+	return nil
+}
+
+// Append the value scanned by the driver, which is one of the driver.Value types.
+func appendValue(c *arrow.ColumnBuilder, field *schema.Field, v interface{}) error {
+	switch tp := field.Type.(type) {
+	case *schema.Int:
+		return appendInt(c, tp, v)
+
+	case *schema.FloatingPoint:
+		f, err := toFloat(v)
+
+		if err != nil {
+			return err
+		}
+
+		if tp.Precision == schema.Single {
+			c.AppendFloat32(float32(f))
+		} else {
+			c.AppendFloat64(f)
+		}
+
+	case *schema.Decimal:
+		d, err := parseDecimal(text(v), tp.Scale)
+
+		if err != nil {
+			return err
+		}
+
+		c.AppendDecimal(d)
+
+	case *schema.DateType:
+		t, err := toTime(v, "2006-01-02")
+
+		if err != nil {
+			return err
+		}
+
+		c.AppendDate(t)
+
+	case *schema.TimeType:
+		t, err := toTime(v, "15:04:05.999999999")
+
+		if err != nil {
+			return err
+		}
+
+		h, m, s := t.Clock()
+
+		c.AppendTimeOfDay(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond()))
+
+	case *schema.Timestamp:
+		t, err := toTime(v, time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999")
+
+		if err != nil {
+			return err
+		}
+
+		c.AppendTime(t)
+
+	default:
+		switch field.Type {
+		case schema.Bool:
+			b, err := toBool(v)
+
+			if err != nil {
+				return err
+			}
+
+			c.AppendBool(b)
+
+		case schema.Binary:
+			if b, ok := v.([]byte); ok {
+				c.AppendBytes(b)
+			} else {
+				c.AppendBytes([]byte(text(v)))
+			}
+
+		default:
+			s := text(v)
+
+			if !utf8.ValidString(s) {
+				return errors.New("invalid UTF-8")
+			}
+
+			c.AppendString(s)
+		}
+	}
+
+	return nil
+}
+
+func appendInt(c *arrow.ColumnBuilder, tp *schema.Int, v interface{}) error {
+	var s string
+
+	switch v := v.(type) {
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case bool:
+		s = "0"
+
+		if v {
+			s = "1"
+		}
+	case float64:
+		if v != float64(int64(v)) {
+			return errors.New("not an integer")
+		}
+
+		s = strconv.FormatInt(int64(v), 10)
+	default:
+		s = strings.TrimSpace(text(v))
+	}
+
+	if tp.Signed {
+		n, err := strconv.ParseInt(s, 10, tp.BitWidth)
+
+		if err != nil {
+			return err
+		}
+
+		switch tp.BitWidth {
+		case 8:
+			c.AppendInt8(int8(n))
+		case 16:
+			c.AppendInt16(int16(n))
+		case 32:
+			c.AppendInt32(int32(n))
+		default:
+			c.AppendInt64(n)
+		}
+	} else {
+		n, err := strconv.ParseUint(s, 10, tp.BitWidth)
+
+		if err != nil {
+			return err
+		}
+
+		switch tp.BitWidth {
+		case 8:
+			c.AppendUint8(uint8(n))
+		case 16:
+			c.AppendUint16(uint16(n))
+		case 32:
+			c.AppendUint32(uint32(n))
+		default:
+			c.AppendUint64(n)
+		}
+	}
+
+	return nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	}
+
+	return strconv.ParseFloat(strings.TrimSpace(text(v)), 64)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	}
+
+	return strconv.ParseBool(strings.TrimSpace(text(v)))
+}
+
+// Returns the time, or parses the text of the value in the first of the layouts it matches.
+func toTime(v interface{}, layouts ...string) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+
+	s := strings.TrimSpace(text(v))
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("unknown time format")
+}
+
+// Returns the text of the value, times in the RFC 3339 format.
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// Parse the decimal as an unscaled integer of the scale, the extra fractional digits must be zeros.
+func parseDecimal(s string, scale int) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	if scale >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(pow10(scale)))
+	} else {
+		r.Quo(r, new(big.Rat).SetInt(pow10(-scale)))
+	}
+
+	if !r.IsInt() {
+		return nil, fmt.Errorf("decimal %s has more than %d fractional digits", s, scale)
+	}
+
+	return r.Num(), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,267 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// A driver of in-memory tables, with the column types of real drivers.
+type testDriver struct{}
+
+type testColumn struct {
+	name, dbType     string
+	nullable         bool
+	precision, scale int64
+	scan             reflect.Type
+}
+
+type testTable struct {
+	columns  []testColumn
+	rows     [][]driver.Value
+	inserted [][]driver.Value
+}
+
+var tables = make(map[string]*testTable)
+
+func init() {
+	sql.Register("arrowtest", testDriver{})
+}
+
+func (testDriver) Open(name string) (driver.Conn, error) { return &testConn{tables[name]}, nil }
+
+type testConn struct{ table *testTable }
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) { return &testStmt{c.table}, nil }
+func (c *testConn) Close() error                              { return nil }
+func (c *testConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+type testStmt struct{ table *testTable }
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return -1 }
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.table.inserted = append(s.table.inserted, args)
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testRows{table: s.table}, nil
+}
+
+type testRows struct {
+	table *testTable
+	next  int
+}
+
+func (r *testRows) Columns() []string {
+	names := make([]string, len(r.table.columns))
+
+	for i, c := range r.table.columns {
+		names[i] = c.name
+	}
+
+	return names
+}
+
+func (r *testRows) Close() error { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next == len(r.table.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.table.rows[r.next])
+	r.next++
+
+	return nil
+}
+
+func (r *testRows) ColumnTypeDatabaseTypeName(i int) string { return r.table.columns[i].dbType }
+func (r *testRows) ColumnTypeNullable(i int) (bool, bool)   { return r.table.columns[i].nullable, true }
+func (r *testRows) ColumnTypeScanType(i int) reflect.Type   { return r.table.columns[i].scan }
+
+func (r *testRows) ColumnTypePrecisionScale(i int) (int64, int64, bool) {
+	c := r.table.columns[i]
+
+	return c.precision, c.scale, c.precision > 0
+}
+
+func openTable(t *testing.T, name string, table *testTable) *sql.DB {
+	tables[name] = table
+
+	db, err := sql.Open("arrowtest", name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestRead(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	db := openTable(t, "read", &testTable{
+		columns: []testColumn{
+			{"id", "INTEGER", false, 0, 0, reflect.TypeOf(sql.NullInt64{})},
+			{"price", "DECIMAL", true, 10, 2, nil},
+			{"amount", "NUMERIC", true, 0, 0, nil},
+			{"at", "TIMESTAMP", true, 0, 0, reflect.TypeOf(time.Time{})},
+			{"day", "date", true, 0, 0, nil},
+			{"name", "VARCHAR(20)", true, 0, 0, nil},
+			{"data", "BLOB", true, 0, 0, nil},
+			{"ok", "BOOLEAN", true, 0, 0, nil},
+			{"ratio", "REAL", true, 0, 0, reflect.TypeOf(float32(0))},
+			{"level", "TINYINT UNSIGNED", true, 0, 0, reflect.TypeOf(uint8(0))},
+			{"other", "GEOMETRY", true, 0, 0, nil},
+		},
+		rows: [][]driver.Value{
+			{int64(1), []byte("12.34"), "1.5", at, "2024-01-02", "alice", []byte{1, 2}, int64(1), 0.5, int64(255), "POINT(1 2)"},
+			{int64(2), "-0.5", nil, "2024-01-02 03:04:05", at, []byte("bob"), nil, false, "1.25", int64(0), nil},
+			{int64(3), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		},
+	})
+
+	rows, err := db.Query("SELECT * FROM read")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(rows, ReadOptions{BatchSize: 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "struct<id: int64 not null, price: decimal128[10, 2], amount: utf8, at: timestamp[us], day: date32, " +
+		"name: utf8, data: binary, ok: bool, ratio: float32, level: uint8, other: utf8>"
+
+	if r.Schema.String() != expected {
+		t.Fatalf("schema %s, expected %s", r.Schema, expected)
+	}
+
+	var batches []*vector.RecordBatch
+
+	for {
+		batch, err := r.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		batches = append(batches, batch)
+	}
+
+	if len(batches) != 2 || batches[0].Length != 2 || batches[1].Length != 1 {
+		t.Fatalf("read %d batches, expected batches of 2 and 1 rows", len(batches))
+	}
+
+	cols, err := columns.New(r.Schema, batches[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rowsText := []string{
+		"1 12.34 1.5 2024-01-02T03:04:05.000006Z 2024-01-02 alice \x01\x02 true 0.5 255 POINT(1 2)",
+		"2 -0.50 <nil> 2024-01-02T03:04:05Z 2024-01-02 bob <nil> false 1.25 0 <nil>",
+	}
+
+	for i, text := range rowsText {
+		values := make([]string, len(cols))
+
+		for j, c := range cols {
+			if c.IsNull(i) {
+				values[j] = "<nil>"
+			} else {
+				values[j] = c.Format(i, time.RFC3339Nano)
+			}
+		}
+
+		if s := strings.Join(values, " "); s != text {
+			t.Errorf("row %d is %q, expected %q", i, s, text)
+		}
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("got error %v, expected EOF", err)
+	}
+}
+
+func TestReadUnknownScanType(t *testing.T) {
+	db := openTable(t, "unknown", &testTable{
+		columns: []testColumn{
+			{"n", "INT", true, 0, 0, nil},
+			{"m", "INT", true, 0, 0, reflect.TypeOf(int32(0))},
+			{"r", "REAL", true, 0, 0, nil},
+			{"s", "", true, 0, 0, nil},
+		},
+		rows: [][]driver.Value{{int64(1) << 40, int64(1), 0.1, int64(7)}},
+	})
+
+	rows, err := db.Query("SELECT * FROM unknown")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(rows, ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the types are widened unless the driver tells how it scans them
+	if expected := "struct<n: int64, m: int32, r: float64, s: utf8>"; r.Schema.String() != expected {
+		t.Fatalf("schema %s, expected %s", r.Schema, expected)
+	}
+
+	batch, err := r.Read()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := batch.Buffers[1].BigInt(0); n != 1<<40 {
+		t.Errorf("n is %d, expected %d", n, int64(1)<<40)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	db := openTable(t, "invalid", &testTable{
+		columns: []testColumn{{"n", "SMALLINT", true, 0, 0, reflect.TypeOf(int16(0))}},
+		rows:    [][]driver.Value{{int64(1)}, {"x"}},
+	})
+
+	rows, err := db.Query("SELECT * FROM invalid")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(rows, ReadOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Read(); err == nil || !strings.Contains(err.Error(), `column n has invalid value x of int16`) {
+		t.Errorf("got error %v, expected an invalid value", err)
+	}
+}
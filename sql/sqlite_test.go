@@ -0,0 +1,170 @@
+//go:build sqlite
+
+// The tests against SQLite need the github.com/mattn/go-sqlite3 driver and cgo,
+// run them with go test -tags sqlite.
+package sql
+
+import (
+	"database/sql"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/flier/arrow"
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/schema/vector"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every connection has its own in-memory database
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// Read the rows of the query, and returns the schema and the rows formatted as text.
+func readSQLite(t *testing.T, db *sql.DB, query string) (string, []string) {
+	rows, err := db.Query(query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(rows, ReadOptions{BatchSize: 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text []string
+
+	for {
+		batch, err := r.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		text = append(text, formatRows(t, r, batch)...)
+	}
+
+	return r.Schema.String(), text
+}
+
+func formatRows(t *testing.T, r *Reader, batch *vector.RecordBatch) []string {
+	cols, err := columns.New(r.Schema, batch)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text []string
+
+	for i := 0; i < batch.Length; i++ {
+		values := make([]string, len(cols))
+
+		for j, c := range cols {
+			if c.IsNull(i) {
+				values[j] = "<nil>"
+			} else {
+				values[j] = c.Format(i, time.RFC3339Nano)
+			}
+		}
+
+		text = append(text, strings.Join(values, " "))
+	}
+
+	return text
+}
+
+func TestSQLiteRead(t *testing.T) {
+	db := openSQLite(t)
+
+	// raw has no declared type, at holds timestamps as text
+	_, err := db.Exec(`CREATE TABLE events (id INTEGER NOT NULL, name TEXT, score REAL, raw, at TIMESTAMP, day DATE);
+		INSERT INTO events VALUES
+			(1099511627776, 'alice', 0.1, 42, '2024-01-02 03:04:05.000006', '2024-01-02'),
+			(2, 'bob', NULL, 'x', '2024-01-02T03:04:05Z', NULL),
+			(3, NULL, 2, NULL, NULL, '2024-01-03');`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the expressions have an empty database type name
+	s, rows := readSQLite(t, db, "SELECT id, name, score, raw, at, day, upper(name) AS shout, id * 2 AS twice FROM events ORDER BY id DESC")
+
+	expected := "struct<id: int64, name: utf8, score: float64, raw: utf8, at: timestamp[us], day: date32, shout: utf8, twice: utf8>"
+
+	if s != expected {
+		t.Errorf("schema %s, expected %s", s, expected)
+	}
+
+	expectedRows := []string{
+		"1099511627776 alice 0.1 42 2024-01-02T03:04:05.000006Z 2024-01-02 ALICE 2199023255552",
+		"3 <nil> 2 <nil> <nil> 2024-01-03 <nil> 6",
+		"2 bob <nil> x 2024-01-02T03:04:05Z <nil> BOB 4",
+	}
+
+	if strings.Join(rows, "\n") != strings.Join(expectedRows, "\n") {
+		t.Errorf("rows\n%s\nexpected\n%s", strings.Join(rows, "\n"), strings.Join(expectedRows, "\n"))
+	}
+}
+
+func TestSQLiteInsert(t *testing.T) {
+	item := "apple"
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	batch, s, err := arrow.Marshal([]order{
+		{ID: 1, Item: &item, Count: 3, Price: 0.5, Paid: true, At: at, Tags: []string{"a"}, Data: []byte{1}},
+		{ID: 2, At: at},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := openSQLite(t)
+
+	if _, err := db.Exec("CREATE TABLE orders (id INTEGER, item TEXT, count INTEGER, price REAL, paid BOOLEAN, at TIMESTAMP, tags, data BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+
+	stmt, err := db.Prepare("INSERT INTO orders VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Insert(stmt, s, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	stmt.Close()
+
+	_, rows := readSQLite(t, db, "SELECT * FROM orders ORDER BY id")
+
+	expected := []string{
+		"1 apple 3 0.5 true 2024-01-02T03:04:05.006Z [\"a\"] \x01",
+		"2 <nil> 0 0 false 2024-01-02T03:04:05.006Z [] ",
+	}
+
+	if strings.Join(rows, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("rows\n%s\nexpected\n%s", strings.Join(rows, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,430 @@
+package arrow
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/flier/arrow/internal/columns"
+	"github.com/flier/arrow/memory"
+	"github.com/flier/arrow/schema"
+	"github.com/flier/arrow/schema/vector"
+)
+
+// Unmarshal the record batch of the schema into the slice of structs, or pointers to them, pointed to by v.
+//
+// The schema must be the one derived from the element type by schema.FromStruct, as returned by Marshal,
+// their custom metadata aside.
+// The slice is replaced by one of the rows of the batch, nulls are decoded as nil pointers or zero values,
+// and the values are copied out of the buffers of the batch.
+func Unmarshal(batch *vector.RecordBatch, s *schema.Schema, v interface{}) error {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("fail to unmarshal to %T, expected a pointer to a slice of structs", v)
+	}
+
+	slice := rv.Elem()
+
+	c, err := codecOf(slice.Type().Elem())
+
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal to %T, %s", v, err)
+	}
+
+	if err := s.Diff(c.schema, schema.EqualOptions{IgnoreMetadata: true}); err != nil {
+		return fmt.Errorf("fail to unmarshal to %T, %s", v, err)
+	}
+
+	if err := c.schema.Validate(batch); err != nil {
+		return fmt.Errorf("fail to unmarshal to %T, %s", v, err)
+	}
+
+	rows := reflect.MakeSlice(slice.Type(), batch.Length, batch.Length)
+	targets := make([]reflect.Value, batch.Length)
+
+	for i := range targets {
+		row := rows.Index(i)
+
+		if row.Kind() == reflect.Ptr {
+			row.Set(reflect.New(row.Type().Elem()))
+			row = row.Elem()
+		}
+
+		targets[i] = row
+	}
+
+	d := &decoder{batch: batch, index: c.index, dictionaries: make(map[int64][]reflect.Value)}
+
+	for _, field := range c.schema.Fields {
+		fields, err := fieldTargets(targets, c.index[field], field.Name)
+
+		if err != nil {
+			return err
+		}
+
+		if err := d.decode(field, fields, field.Name); err != nil {
+			return err
+		}
+	}
+
+	slice.Set(rows)
+
+	return nil
+}
+
+type decoder struct {
+	batch        *vector.RecordBatch
+	node, buffer int
+	index        schema.StructIndex
+
+	// the decoded values of the dictionaries, shared with the decoders of the dictionaries
+	dictionaries map[int64][]reflect.Value
+}
+
+// Decode the field and its children into the targets, invalid targets skip the values.
+// The batch is validated against the schema, the buffers hold the values.
+func (d *decoder) decode(field *schema.Field, targets []reflect.Value, path string) error {
+	node := d.batch.Nodes[d.node]
+	d.node++
+
+	buffers := d.batch.Buffers[d.buffer : d.buffer+len(field.Layout.Vectors)]
+	d.buffer += len(field.Layout.Vectors)
+
+	validity := buffers[0]
+	values := make([]reflect.Value, node.Length)
+
+	for i, t := range targets {
+		switch {
+		case !t.IsValid():
+
+		case !isValid(validity, i):
+			t.Set(reflect.Zero(t.Type()))
+
+		case t.Kind() == reflect.Ptr:
+			t.Set(reflect.New(t.Type().Elem()))
+			values[i] = t.Elem()
+
+		default:
+			values[i] = t
+		}
+	}
+
+	if field.Dictionary != nil {
+		return d.decodeDictionary(field, values, buffers[1], path)
+	}
+
+	switch tp := field.Type.(type) {
+	case *schema.Int:
+		for i, v := range values {
+			if v.IsValid() {
+				setInt(v, columns.ReadInt64(buffers[1], tp, i))
+			}
+		}
+
+	case *schema.FloatingPoint:
+		for i, v := range values {
+			if !v.IsValid() {
+				continue
+			}
+
+			if tp.Precision == schema.Single {
+				v.SetFloat(float64(buffers[1].Float4(i)))
+			} else {
+				v.SetFloat(buffers[1].Float8(i))
+			}
+		}
+
+	case *schema.Timestamp:
+		loc, err := columns.Location(tp.Timezone)
+
+		if err != nil {
+			return fmt.Errorf("field %s has invalid time zone, %s", path, err)
+		}
+
+		for i, v := range values {
+			if v.IsValid() {
+				v.Set(reflect.ValueOf(columns.FromUnit(buffers[1].BigInt(i), tp.Unit).In(loc)))
+			}
+		}
+
+	case *schema.Duration:
+		for i, v := range values {
+			if v.IsValid() {
+				v.SetInt(buffers[1].BigInt(i) * columns.UnitNanos(tp.Unit))
+			}
+		}
+
+	case *schema.FixedSizeBinary:
+		data := buffers[1].Bytes()
+
+		for i, v := range values {
+			if v.IsValid() {
+				reflect.Copy(v, reflect.ValueOf(data[i*tp.ByteWidth:(i+1)*tp.ByteWidth]))
+			}
+		}
+
+	case *schema.FixedSizeList:
+		items := make([]reflect.Value, node.Length*tp.ListSize)
+
+		for i, v := range values {
+			if v.IsValid() {
+				for j := 0; j < tp.ListSize; j++ {
+					items[i*tp.ListSize+j] = v.Index(j)
+				}
+			}
+		}
+
+		return d.decode(field.Children[0], items, path+"."+field.Children[0].Name)
+
+	case *schema.Map:
+		return d.decodeMap(field, values, buffers[1], path)
+
+	default:
+		switch field.Type {
+		case schema.Bool:
+			for i, v := range values {
+				if v.IsValid() {
+					v.SetBool(isValid(buffers[1], i))
+				}
+			}
+
+		case schema.Utf8, schema.Binary:
+			offsets, data := buffers[1], buffers[2].Bytes()
+
+			for i, v := range values {
+				if !v.IsValid() {
+					continue
+				}
+
+				value := data[offsets.Int(i):offsets.Int(i+1)]
+
+				if v.Kind() == reflect.String {
+					v.SetString(string(value))
+				} else {
+					v.SetBytes(append([]byte{}, value...))
+				}
+			}
+
+		case schema.List:
+			offsets := buffers[1]
+
+			var items []reflect.Value
+
+			if node.Length > 0 {
+				items = make([]reflect.Value, offsets.Int(node.Length))
+			}
+
+			for i, v := range values {
+				if !v.IsValid() {
+					continue
+				}
+
+				start, end := int(offsets.Int(i)), int(offsets.Int(i+1))
+
+				v.Set(reflect.MakeSlice(v.Type(), end-start, end-start))
+
+				for j := start; j < end; j++ {
+					items[j] = v.Index(j - start)
+				}
+			}
+
+			return d.decode(field.Children[0], items, path+"."+field.Children[0].Name)
+
+		case schema.Struct:
+			for _, child := range field.Children {
+				fields, err := fieldTargets(values, d.index[child], path+"."+child.Name)
+
+				if err != nil {
+					return err
+				}
+
+				if err := d.decode(child, fields, path+"."+child.Name); err != nil {
+					return err
+				}
+			}
+
+		default:
+			return fmt.Errorf("field %s of type %s can't be unmarshaled", path, field.Type)
+		}
+	}
+
+	return nil
+}
+
+// Decode the keys and values of the entries, then build the maps of them.
+func (d *decoder) decodeMap(field *schema.Field, values []reflect.Value, offsets *memory.Buffer, path string) error {
+	entries := field.Children[0]
+
+	node := d.batch.Nodes[d.node]
+	d.node++
+	d.buffer += len(entries.Layout.Vectors)
+
+	keys := make([]reflect.Value, node.Length)
+	items := make([]reflect.Value, node.Length)
+
+	for i, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+
+		for j := int(offsets.Int(i)); j < int(offsets.Int(i+1)); j++ {
+			keys[j] = reflect.New(v.Type().Key()).Elem()
+			items[j] = reflect.New(v.Type().Elem()).Elem()
+		}
+	}
+
+	path += "." + entries.Name
+
+	if err := d.decode(entries.Children[0], keys, path+"."+entries.Children[0].Name); err != nil {
+		return err
+	}
+
+	if err := d.decode(entries.Children[1], items, path+"."+entries.Children[1].Name); err != nil {
+		return err
+	}
+
+	for i, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+
+		start, end := int(offsets.Int(i)), int(offsets.Int(i+1))
+
+		v.Set(reflect.MakeMapWithSize(v.Type(), end-start))
+
+		for j := start; j < end; j++ {
+			v.SetMapIndex(keys[j], items[j])
+		}
+	}
+
+	return nil
+}
+
+// Decode the values of the indices, the dictionary is decoded once for all the fields referring to it.
+func (d *decoder) decodeDictionary(field *schema.Field, values []reflect.Value, indices *memory.Buffer, path string) error {
+	var dictionary []reflect.Value
+
+	for i, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+
+		if dictionary == nil {
+			var err error
+
+			if dictionary, err = d.dictionary(field, v.Type(), path); err != nil {
+				return err
+			}
+		}
+
+		index := columns.ReadInt64(indices, field.Dictionary.Index(), i)
+
+		if index < 0 || index >= int64(len(dictionary)) {
+			return fmt.Errorf("field %s has index %d at %d out of the dictionary of %d values", path, index, i, len(dictionary))
+		}
+
+		value := dictionary[index]
+
+		if value.Kind() == reflect.Slice {
+			value = reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value)
+		}
+
+		v.Set(value)
+	}
+
+	return nil
+}
+
+// Returns the values of the dictionary of the field, decoded as the Go type.
+func (d *decoder) dictionary(field *schema.Field, t reflect.Type, path string) ([]reflect.Value, error) {
+	id := field.Dictionary.ID
+
+	if values, found := d.dictionaries[id]; found {
+		return values, nil
+	}
+
+	batch, found := d.batch.Dictionaries[id]
+
+	if !found {
+		return nil, fmt.Errorf("field %s refers to missing dictionary %d", path, id)
+	}
+
+	layout, err := schema.NewTypeLayout(field.Type)
+
+	if err != nil {
+		return nil, fmt.Errorf("field %s has no dictionary layout, %s", path, err)
+	}
+
+	f := *field
+	f.Dictionary = nil
+	f.Layout = layout
+
+	if err := (&schema.Schema{Fields: []*schema.Field{&f}}).Validate(batch); err != nil {
+		return nil, fmt.Errorf("invalid dictionary %d, %s", id, err)
+	}
+
+	values := make([]reflect.Value, batch.Length)
+
+	for i := range values {
+		values[i] = reflect.New(t).Elem()
+	}
+
+	dd := &decoder{batch: batch, index: d.index, dictionaries: d.dictionaries}
+
+	if err := dd.decode(&f, append([]reflect.Value{}, values...), path); err != nil {
+		return nil, err
+	}
+
+	d.dictionaries[id] = values
+
+	return values, nil
+}
+
+// Returns the Go struct field of the index in each target, the nil embedded pointers are allocated.
+func fieldTargets(targets []reflect.Value, index []int, path string) ([]reflect.Value, error) {
+	fields := make([]reflect.Value, len(targets))
+
+	for i, v := range targets {
+		if !v.IsValid() {
+			continue
+		}
+
+		for j, x := range index {
+			if j > 0 && v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					if !v.CanSet() {
+						return nil, fmt.Errorf("field %s is promoted through a nil pointer to unexported %s", path, v.Type().Elem())
+					}
+
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+
+				v = v.Elem()
+			}
+
+			v = v.Field(x)
+		}
+
+		fields[i] = v
+	}
+
+	return fields, nil
+}
+
+func isValid(bitmap *memory.Buffer, i int) bool {
+	if bitmap.Len() == 0 {
+		return true
+	}
+
+	return bitmap.Bytes()[i>>3]&(1<<uint(i&7)) != 0
+}
+
+// Set the Go integer to the bits, reinterpreted for unsigned integers.
+func setInt(v reflect.Value, bits int64) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(bits)
+	default:
+		v.SetUint(uint64(bits))
+	}
+}
@@ -0,0 +1,186 @@
+package vector
+
+import (
+	"fmt"
+
+	"github.com/flier/arrow/memory"
+)
+
+// LargeVarCharVector is a vector of strings, which are located by 64-bit offsets.
+type LargeVarCharVector struct {
+	*BaseValueVector
+
+	offsets *memory.Buffer
+}
+
+func NewLargeVarCharVector(offsets, data *memory.Buffer) *LargeVarCharVector {
+	return &LargeVarCharVector{&BaseValueVector{data}, offsets}
+}
+
+func (v *LargeVarCharVector) ValueCapacity() int { return largeValueCapacity(v.offsets) }
+
+func (v *LargeVarCharVector) Accessor() Accessor { return v }
+
+func (v *LargeVarCharVector) Mutator() Mutator { return v }
+
+func (v *LargeVarCharVector) BufferSize() int { return v.offsets.Len() + v.data.Len() }
+
+func (v *LargeVarCharVector) Offsets() *memory.Buffer { return v.offsets }
+
+// implement Accessor
+
+func (v *LargeVarCharVector) LargeVarChar(index int) (LargeVarChar, error) {
+	if index < 0 || index >= v.ValueCount() {
+		return "", errOutOfRange
+	}
+
+	return LargeVarChar(v.data.LargeVarChar(v.offsets, index)), nil
+}
+
+func (v *LargeVarCharVector) Get(index int) (interface{}, error) { return v.LargeVarChar(index) }
+
+func (v *LargeVarCharVector) ValueCount() int { return largeValueCount(v.offsets) }
+
+func (v *LargeVarCharVector) IsNull(index int) bool { return false }
+
+// implement Mutator
+
+// Sets the value at the index, the values after it are dropped.
+func (v *LargeVarCharVector) PutLargeVarChar(index int, value LargeVarChar) error {
+	if index < 0 || index >= v.ValueCount() {
+		return errOutOfRange
+	}
+
+	v.data.PutLargeVarChar(v.offsets, index, string(value))
+
+	return nil
+}
+
+func (v *LargeVarCharVector) SetValueCount(valueCount int) {
+	resizeOffsets(v.offsets, valueCount, 8)
+}
+
+// LargeVarBinaryVector is a vector of byte slices, which are located by 64-bit offsets.
+type LargeVarBinaryVector struct {
+	*BaseValueVector
+
+	offsets *memory.Buffer
+}
+
+func NewLargeVarBinaryVector(offsets, data *memory.Buffer) *LargeVarBinaryVector {
+	return &LargeVarBinaryVector{&BaseValueVector{data}, offsets}
+}
+
+func (v *LargeVarBinaryVector) ValueCapacity() int { return largeValueCapacity(v.offsets) }
+
+func (v *LargeVarBinaryVector) Accessor() Accessor { return v }
+
+func (v *LargeVarBinaryVector) Mutator() Mutator { return v }
+
+func (v *LargeVarBinaryVector) BufferSize() int { return v.offsets.Len() + v.data.Len() }
+
+func (v *LargeVarBinaryVector) Offsets() *memory.Buffer { return v.offsets }
+
+// implement Accessor
+
+func (v *LargeVarBinaryVector) LargeVarBinary(index int) (LargeVarBinary, error) {
+	if index < 0 || index >= v.ValueCount() {
+		return nil, errOutOfRange
+	}
+
+	return LargeVarBinary(v.data.LargeVarBinary(v.offsets, index)), nil
+}
+
+func (v *LargeVarBinaryVector) Get(index int) (interface{}, error) { return v.LargeVarBinary(index) }
+
+func (v *LargeVarBinaryVector) ValueCount() int { return largeValueCount(v.offsets) }
+
+func (v *LargeVarBinaryVector) IsNull(index int) bool { return false }
+
+// implement Mutator
+
+// Sets the value at the index, the values after it are dropped.
+func (v *LargeVarBinaryVector) PutLargeVarBinary(index int, value LargeVarBinary) error {
+	if index < 0 || index >= v.ValueCount() {
+		return errOutOfRange
+	}
+
+	v.data.PutLargeVarBinary(v.offsets, index, value)
+
+	return nil
+}
+
+func (v *LargeVarBinaryVector) SetValueCount(valueCount int) {
+	resizeOffsets(v.offsets, valueCount, 8)
+}
+
+func largeValueCapacity(offsets *memory.Buffer) int {
+	if offsets.Cap() < 8 {
+		return 0
+	}
+
+	return offsets.Cap()/8 - 1
+}
+
+func largeValueCount(offsets *memory.Buffer) int {
+	if offsets.Len() < 8 {
+		return 0
+	}
+
+	return offsets.Len()/8 - 1
+}
+
+// FixedBinaryVector is a vector of byte slices of the same width.
+type FixedBinaryVector struct {
+	*BaseValueVector
+
+	width int
+}
+
+func NewFixedBinaryVector(width int, data *memory.Buffer) *FixedBinaryVector {
+	return &FixedBinaryVector{&BaseValueVector{data}, width}
+}
+
+func (v *FixedBinaryVector) ValueCapacity() int { return v.data.Cap() / v.width }
+
+func (v *FixedBinaryVector) Accessor() Accessor { return v }
+
+func (v *FixedBinaryVector) Mutator() Mutator { return v }
+
+func (v *FixedBinaryVector) ByteWidth() int { return v.width }
+
+// implement Accessor
+
+func (v *FixedBinaryVector) FixedBinary(index int) (FixedBinary, error) {
+	if index < 0 || index >= v.ValueCount() {
+		return nil, errOutOfRange
+	}
+
+	return FixedBinary(v.data.FixedBinary(v.width, index)), nil
+}
+
+func (v *FixedBinaryVector) Get(index int) (interface{}, error) { return v.FixedBinary(index) }
+
+func (v *FixedBinaryVector) ValueCount() int { return v.data.Len() / v.width }
+
+func (v *FixedBinaryVector) IsNull(index int) bool { return false }
+
+// implement Mutator
+
+func (v *FixedBinaryVector) PutFixedBinary(index int, value FixedBinary) error {
+	if index < 0 || index >= v.ValueCount() {
+		return errOutOfRange
+	}
+
+	if len(value) != v.width {
+		return fmt.Errorf("value has %d bytes, expected %d", len(value), v.width)
+	}
+
+	v.data.PutFixedBinary(v.width, index, value)
+
+	return nil
+}
+
+func (v *FixedBinaryVector) SetValueCount(valueCount int) {
+	resize(v.data, valueCount*v.width)
+}
@@ -0,0 +1,81 @@
+package vector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+)
+
+func TestLargeVarCharVector(t *testing.T) {
+	v := NewLargeVarCharVector(memory.NewBuffer(nil), memory.NewBuffer(nil))
+
+	if v.ValueCount() != 0 {
+		t.Errorf("empty vector has %d values", v.ValueCount())
+	}
+
+	v.SetValueCount(2)
+
+	for i, s := range []LargeVarChar{"foo", "barbaz"} {
+		if err := v.PutLargeVarChar(i, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if value, err := v.Get(1); err != nil || value != LargeVarChar("barbaz") {
+		t.Errorf("value %v with error %v, expected barbaz", value, err)
+	}
+
+	if v.ValueCount() != 2 || v.BufferSize() != 3*8+9 {
+		t.Errorf("vector has %d values in %d bytes, expected 2 in 33", v.ValueCount(), v.BufferSize())
+	}
+
+	// the new values are empty
+	v.SetValueCount(3)
+
+	if value, err := v.LargeVarChar(2); err != nil || value != "" {
+		t.Errorf("value %q with error %v, expected empty", value, err)
+	}
+
+	if _, err := v.LargeVarChar(3); err != errOutOfRange {
+		t.Errorf("value out of range with error %v", err)
+	}
+}
+
+func TestLargeVarBinaryVector(t *testing.T) {
+	offsets := memory.NewBuffer(make([]byte, 24))
+	offsets.PutBigInt(1, 2)
+	offsets.PutBigInt(2, 5)
+
+	v := NewLargeVarBinaryVector(offsets, memory.NewBuffer([]byte{1, 2, 3, 4, 5}))
+
+	if value, err := v.LargeVarBinary(1); err != nil || !reflect.DeepEqual(value, LargeVarBinary{3, 4, 5}) {
+		t.Errorf("value %v with error %v, expected [3 4 5]", value, err)
+	}
+
+	if err := v.PutLargeVarBinary(-1, nil); err != errOutOfRange {
+		t.Errorf("put out of range with error %v", err)
+	}
+}
+
+func TestFixedBinaryVector(t *testing.T) {
+	v := NewFixedBinaryVector(2, memory.NewBuffer(nil))
+
+	v.SetValueCount(2)
+
+	if err := v.PutFixedBinary(1, FixedBinary{7, 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := v.Get(1); err != nil || !reflect.DeepEqual(value, FixedBinary{7, 8}) {
+		t.Errorf("value %v with error %v, expected [7 8]", value, err)
+	}
+
+	if value, err := v.FixedBinary(0); err != nil || !reflect.DeepEqual(value, FixedBinary{0, 0}) {
+		t.Errorf("value %v with error %v, expected [0 0]", value, err)
+	}
+
+	if err := v.PutFixedBinary(0, FixedBinary{1}); err == nil || err.Error() != "value has 1 bytes, expected 2" {
+		t.Errorf("put a short value with error %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package vector
+
+import (
+	"github.com/flier/arrow/memory"
+)
+
+// FixedSizeListVector is a vector of lists of the same size, whose values are held by the child vector.
+type FixedSizeListVector struct {
+	child    ValueVector
+	listSize int
+}
+
+func NewFixedSizeListVector(listSize int, child ValueVector) *FixedSizeListVector {
+	return &FixedSizeListVector{child, listSize}
+}
+
+func (v *FixedSizeListVector) ValueCapacity() int { return v.child.ValueCapacity() / v.listSize }
+
+func (v *FixedSizeListVector) Accessor() Accessor { return v }
+
+func (v *FixedSizeListVector) Mutator() Mutator { return v }
+
+func (v *FixedSizeListVector) BufferSize() int { return v.child.BufferSize() }
+
+func (v *FixedSizeListVector) Child() ValueVector { return v.child }
+
+func (v *FixedSizeListVector) ListSize() int { return v.listSize }
+
+// implement Accessor
+
+func (v *FixedSizeListVector) FixedSizeList(index int) ([]interface{}, error) {
+	if index < 0 || index >= v.ValueCount() {
+		return nil, errOutOfRange
+	}
+
+	return getValues(v.child.Accessor(), index*v.listSize, (index+1)*v.listSize)
+}
+
+func (v *FixedSizeListVector) Get(index int) (interface{}, error) { return v.FixedSizeList(index) }
+
+func (v *FixedSizeListVector) ValueCount() int { return v.child.Accessor().ValueCount() / v.listSize }
+
+func (v *FixedSizeListVector) IsNull(index int) bool { return false }
+
+// implement Mutator
+
+func (v *FixedSizeListVector) SetValueCount(valueCount int) {
+	v.child.Mutator().SetValueCount(valueCount * v.listSize)
+}
+
+// LargeListVector is a vector of lists, which are located in the child vector by 64-bit offsets.
+type LargeListVector struct {
+	offsets *memory.Buffer
+	child   ValueVector
+}
+
+func NewLargeListVector(offsets *memory.Buffer, child ValueVector) *LargeListVector {
+	return &LargeListVector{offsets, child}
+}
+
+func (v *LargeListVector) ValueCapacity() int { return largeValueCapacity(v.offsets) }
+
+func (v *LargeListVector) Accessor() Accessor { return v }
+
+func (v *LargeListVector) Mutator() Mutator { return v }
+
+func (v *LargeListVector) BufferSize() int { return v.offsets.Len() + v.child.BufferSize() }
+
+func (v *LargeListVector) Offsets() *memory.Buffer { return v.offsets }
+
+func (v *LargeListVector) Child() ValueVector { return v.child }
+
+// implement Accessor
+
+func (v *LargeListVector) LargeList(index int) ([]interface{}, error) {
+	if index < 0 || index >= v.ValueCount() {
+		return nil, errOutOfRange
+	}
+
+	return getValues(v.child.Accessor(), int(v.offsets.BigInt(index)), int(v.offsets.BigInt(index+1)))
+}
+
+func (v *LargeListVector) Get(index int) (interface{}, error) { return v.LargeList(index) }
+
+func (v *LargeListVector) ValueCount() int { return largeValueCount(v.offsets) }
+
+func (v *LargeListVector) IsNull(index int) bool { return false }
+
+// implement Mutator
+
+// Sets the length of the list at the index, which holds the next values of the child vector.
+func (v *LargeListVector) PutListLength(index, length int) error {
+	if index < 0 || index >= v.ValueCount() {
+		return errOutOfRange
+	}
+
+	v.offsets.PutBigInt(index+1, v.offsets.BigInt(index)+int64(length))
+
+	return nil
+}
+
+func (v *LargeListVector) SetValueCount(valueCount int) {
+	resizeOffsets(v.offsets, valueCount, 8)
+}
+
+// MapEntry is a key value pair of a map.
+type MapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// MapVector is a vector of maps, whose entries are located in the key and value vectors by 32-bit offsets.
+type MapVector struct {
+	offsets *memory.Buffer
+	keys    ValueVector
+	values  ValueVector
+}
+
+func NewMapVector(offsets *memory.Buffer, keys, values ValueVector) *MapVector {
+	return &MapVector{offsets, keys, values}
+}
+
+func (v *MapVector) ValueCapacity() int {
+	if v.offsets.Cap() < 4 {
+		return 0
+	}
+
+	return v.offsets.Cap()/4 - 1
+}
+
+func (v *MapVector) Accessor() Accessor { return v }
+
+func (v *MapVector) Mutator() Mutator { return v }
+
+func (v *MapVector) BufferSize() int {
+	return v.offsets.Len() + v.keys.BufferSize() + v.values.BufferSize()
+}
+
+func (v *MapVector) Offsets() *memory.Buffer { return v.offsets }
+
+func (v *MapVector) Keys() ValueVector { return v.keys }
+
+func (v *MapVector) Values() ValueVector { return v.values }
+
+// implement Accessor
+
+// Returns the entries of the map at the index in their order.
+func (v *MapVector) Map(index int) ([]MapEntry, error) {
+	if index < 0 || index >= v.ValueCount() {
+		return nil, errOutOfRange
+	}
+
+	start, end := int(v.offsets.Int(index)), int(v.offsets.Int(index+1))
+
+	keys, err := getValues(v.keys.Accessor(), start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := getValues(v.values.Accessor(), start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]MapEntry, len(keys))
+
+	for i := range keys {
+		entries[i] = MapEntry{keys[i], values[i]}
+	}
+
+	return entries, nil
+}
+
+func (v *MapVector) Get(index int) (interface{}, error) { return v.Map(index) }
+
+func (v *MapVector) ValueCount() int {
+	if v.offsets.Len() < 4 {
+		return 0
+	}
+
+	return v.offsets.Len()/4 - 1
+}
+
+func (v *MapVector) IsNull(index int) bool { return false }
+
+// implement Mutator
+
+// Sets the number of entries of the map at the index, which are the next values of the key and value vectors.
+func (v *MapVector) PutMapLength(index, length int) error {
+	if index < 0 || index >= v.ValueCount() {
+		return errOutOfRange
+	}
+
+	v.offsets.PutInt(index+1, v.offsets.Int(index)+int32(length))
+
+	return nil
+}
+
+func (v *MapVector) SetValueCount(valueCount int) {
+	resizeOffsets(v.offsets, valueCount, 4)
+}
+
+// Get the values of the accessor in the range [start, end).
+func getValues(accessor Accessor, start, end int) ([]interface{}, error) {
+	if start < 0 || start > end || end > accessor.ValueCount() {
+		return nil, errOutOfRange
+	}
+
+	values := make([]interface{}, 0, end-start)
+
+	for i := start; i < end; i++ {
+		value, err := accessor.Get(i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
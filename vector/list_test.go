@@ -0,0 +1,81 @@
+package vector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flier/arrow/memory"
+)
+
+func varChars(values ...string) *LargeVarCharVector {
+	v := NewLargeVarCharVector(memory.NewBuffer(nil), memory.NewBuffer(nil))
+
+	v.SetValueCount(len(values))
+
+	for i, s := range values {
+		v.PutLargeVarChar(i, LargeVarChar(s))
+	}
+
+	return v
+}
+
+func TestFixedSizeListVector(t *testing.T) {
+	v := NewFixedSizeListVector(2, varChars("a", "b", "c", "d", "e"))
+
+	if v.ValueCount() != 2 {
+		t.Errorf("vector has %d values, expected 2", v.ValueCount())
+	}
+
+	if value, err := v.Get(1); err != nil || !reflect.DeepEqual(value, []interface{}{LargeVarChar("c"), LargeVarChar("d")}) {
+		t.Errorf("value %v with error %v, expected [c d]", value, err)
+	}
+
+	if _, err := v.FixedSizeList(2); err != errOutOfRange {
+		t.Errorf("value out of range with error %v", err)
+	}
+}
+
+func TestLargeListVector(t *testing.T) {
+	v := NewLargeListVector(memory.NewBuffer(nil), varChars("a", "b", "c"))
+
+	v.SetValueCount(3)
+
+	for i, length := range []int{2, 0, 1} {
+		if err := v.PutListLength(i, length); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := [][]interface{}{{LargeVarChar("a"), LargeVarChar("b")}, {}, {LargeVarChar("c")}}
+
+	for i, list := range expected {
+		if value, err := v.LargeList(i); err != nil || !reflect.DeepEqual(value, list) {
+			t.Errorf("value %v at %d with error %v, expected %v", value, i, err, list)
+		}
+	}
+
+	// the list is longer than the child
+	v.PutListLength(2, 2)
+
+	if _, err := v.LargeList(2); err != errOutOfRange {
+		t.Errorf("value out of range with error %v", err)
+	}
+}
+
+func TestMapVector(t *testing.T) {
+	v := NewMapVector(memory.NewBuffer(nil), varChars("x", "y", "z"), NewFixedBinaryVector(1, memory.NewBuffer([]byte{1, 2, 3})))
+
+	v.SetValueCount(2)
+	v.PutMapLength(0, 1)
+	v.PutMapLength(1, 2)
+
+	expected := []MapEntry{{LargeVarChar("y"), FixedBinary{2}}, {LargeVarChar("z"), FixedBinary{3}}}
+
+	if value, err := v.Get(1); err != nil || !reflect.DeepEqual(value, expected) {
+		t.Errorf("value %v with error %v, expected %v", value, err, expected)
+	}
+
+	if v.BufferSize() != 3*4+v.Keys().BufferSize()+3 {
+		t.Errorf("vector has %d bytes", v.BufferSize())
+	}
+}
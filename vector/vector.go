@@ -47,6 +47,21 @@ type Time int32
 // +gen vector:"Time,Accessor,Mutator"
 type TimeStamp int64
 
+// +gen vector:"Time,Accessor,Mutator"
+type DateDay int32
+
+// +gen vector:"Time,Accessor,Mutator"
+type TimeSecond int32
+
+// +gen vector:"Time,Accessor,Mutator"
+type TimeMicro int64
+
+// +gen vector:"Time,Accessor,Mutator"
+type TimeNano int64
+
+// +gen vector:"Duration,Accessor,Mutator"
+type Duration int64
+
 // +gen vector:"Duration,Accessor,Mutator"
 type IntervalDay int64
 
@@ -61,6 +76,12 @@ type VarBinary []byte
 
 type Bit bool
 
+type LargeVarChar string
+
+type LargeVarBinary []byte
+
+type FixedBinary []byte
+
 var (
 	errOutOfRange = errors.New("out of range")
 )
@@ -76,3 +97,29 @@ func (v *BaseValueVector) BufferSize() int {
 func (v *BaseValueVector) Buffer() *memory.Buffer {
 	return v.data
 }
+
+// Resize the buffer to the size, the new bytes are zeros.
+func resize(b *memory.Buffer, size int) {
+	if n := b.Len(); n < size {
+		b.Write(make([]byte, size-n))
+	} else {
+		b.Truncate(size)
+	}
+}
+
+// Resize the offsets to the value count, the new values are empty.
+func resizeOffsets(offsets *memory.Buffer, valueCount, width int) {
+	n := offsets.Len()/width - 1
+
+	resize(offsets, (valueCount+1)*width)
+
+	for i := n + 1; i <= valueCount; i++ {
+		switch {
+		case i == 0:
+		case width == 8:
+			offsets.PutBigInt(i, offsets.BigInt(i-1))
+		default:
+			offsets.PutInt(i, offsets.Int(i-1))
+		}
+	}
+}